@@ -27,6 +27,12 @@ func main() {
 
 	app.MonitorSignals().
 		SetProviders().
+		SetOverrides().
+		SetHistory().
+		SetQuotes().
+		SetAlerts().
+		SetStream().
 		SetRoutes().
+		SetGRPC().
 		Serve()
 }
@@ -1,6 +1,7 @@
 package application
 
 import (
+	"errors"
 	"os"
 	"os/signal"
 	"strconv"
@@ -8,10 +9,20 @@ import (
 	"syscall"
 
 	"fx-service/internal/router"
+	"fx-service/internal/router/grpc"
+	"fx-service/internal/service/alerts"
+	"fx-service/internal/service/history"
+	"fx-service/internal/service/overrides"
 	"fx-service/internal/service/providers"
+	"fx-service/internal/service/quotes"
 	"fx-service/internal/service/ratecache"
+	"fx-service/internal/service/rates"
+	"fx-service/internal/service/spreads"
+	"fx-service/internal/service/stream"
 	"fx-service/pkg/config"
 	c "fx-service/pkg/console"
+	"fx-service/pkg/currency"
+	"fx-service/pkg/decimal"
 	"fx-service/pkg/e"
 	"fx-service/pkg/logger"
 )
@@ -20,10 +31,12 @@ type App struct {
 	Config *config.Config
 	Logger *logger.Logger
 	Router router.Router
+	GRPC   *grpcHandlers.GrpcServer
 }
 
-// NewApp initializes the App with the necessary dependencies
+// NewApp initializes the App with the necessary dependencies, and merges its codes into the error catalogue
 func NewApp(logger *logger.Logger) *App {
+	e.MergeCatalogue(errorMap)
 	return &App{
 		Logger: logger,
 	}
@@ -31,9 +44,6 @@ func NewApp(logger *logger.Logger) *App {
 
 // SetConfigs loads the application configuration from the defaults, config.json file and environment variables
 func (app *App) SetConfigs() error {
-	// Set up the error catalogue
-	e.SetCatalogue(errorMap)
-
 	// Try to locate the required config file.
 	// It will first look for arguments passed in, then it will look in the current working dir and the executable dir.
 	// If we don't have one, the default values will be used.
@@ -45,16 +55,98 @@ func (app *App) SetConfigs() error {
 		return err
 	}
 
+	return app.UseConfig(&appConfig)
+}
+
+// UseConfig checks the given configuration, and sets up the rate cache and the spreads with it
+func (app *App) UseConfig(appConfig *config.Config) error {
 	// Convert the supported currencies to uppercase for consistency
 	appConfig.CurrenciesToUppercase()
 
-	ratecache.GetInstance().SetExpiry(appConfig.CacheExpirySec)
+	rc := ratecache.GetInstance()
+	rc.SetExpiry(appConfig.CacheExpirySec)
+	if err := rc.SetTTLRules(appConfig.CacheTTLRules); err != nil {
+		return err
+	}
+
+	if err := spreads.Init(appConfig.Spreads); err != nil {
+		return err
+	}
+
+	// Rates and amounts are exact decimal strings in responses, unless the float output is enabled
+	decimal.SetFloatOutput(appConfig.FloatOutput)
+
+	app.Config = appConfig
 
-	app.Config = &appConfig
+	return nil
+}
 
+// Start initializes the providers and starts the services enabled in the config, returning the first error.
+// It runs the same steps as the Set* ones, for an App embedded in another program, which must not exit.
+func (app *App) Start() error {
+	steps := []func() error{
+		app.loadProviders, app.checkCurrencies, app.loadOverrides, app.openHistory, app.openQuotes, app.startAlerts, app.startStream,
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// fetchRates fetches the rates of the pairs polled by the alerts and the streams, with the configured strategy
+func (app *App) fetchRates(from string, to []string) error {
+	_, err := rates.GetRates(from, to, app.Config.Mode)
+	return err
+}
+
+// exitOnError prints the error with the given warning and exits, when a step of the boot failed
+func exitOnError(err error, warning string) {
+	if err == nil {
+		return
+	}
+	c.Warnf("%s. Cannot continue", warning)
+	e.FromError(err).Print(-1, 0)
+	os.Exit(1)
+}
+
+// loadProviders initializes the API providers - sets up API keys, etc. It fails when none could be enabled.
+func (app *App) loadProviders() error {
+	return providers.LoadProviders(&app.Config.Providers, app.Config.APITimeout)
+}
+
+// checkCurrencies rejects unknown currency codes, so that typos are caught at boot rather than by the first request.
+// The providers have added the codes they list which are not in ISO 4217 (e.g. "BTC") by now.
+func (app *App) checkCurrencies() error {
+	return currency.CheckCodes(app.Config.CurrenciesEnabled)
+}
+
+// loadOverrides loads the manual rate overrides saved by a previous run
+func (app *App) loadOverrides() error {
+	return overrides.Init(app.Config.Overrides)
+}
+
+// openHistory opens the historic rate store, if enabled in the config
+func (app *App) openHistory() error {
+	return history.Init(app.Config.History)
+}
+
+// openQuotes opens the locked quote store, if enabled in the config
+func (app *App) openQuotes() error {
+	return quotes.Init(app.Config.Quotes)
+}
+
+// startAlerts loads the webhook subscriptions, and starts polling the subscribed pairs, if enabled in the config
+func (app *App) startAlerts() error {
+	return alerts.Init(app.Config.Alerts, app.fetchRates)
+}
+
+// startStream starts refreshing the streamed pairs, if streaming is enabled in the config
+func (app *App) startStream() error {
+	return stream.Init(app.Config.Stream, app.fetchRates)
+}
+
 // SetProviders initializes API providers by checking API keys and loading supported currencies for enabled providers
 func (app *App) SetProviders() *App {
 	// Ensure the Config is set
@@ -63,9 +155,38 @@ func (app *App) SetProviders() *App {
 		return app
 	}
 
-	// Initialize the providers - sets up API keys, etc.
-	providers.InitProviders(&app.Config.Providers, app.Config.APITimeout)
+	exitOnError(app.loadProviders(), "No providers enabled")
+	exitOnError(app.checkCurrencies(), "Unknown currencies are enabled")
+	return app
+}
+
+// SetOverrides loads the manual rate overrides saved by a previous run
+func (app *App) SetOverrides() *App {
+	exitOnError(app.loadOverrides(), "Could not load the rate overrides")
+	return app
+}
+
+// SetHistory opens the historic rate store, if enabled in the config
+func (app *App) SetHistory() *App {
+	exitOnError(app.openHistory(), "Could not start the historic rate store")
+	return app
+}
+
+// SetQuotes opens the locked quote store, if enabled in the config
+func (app *App) SetQuotes() *App {
+	exitOnError(app.openQuotes(), "Could not start the quote store")
+	return app
+}
+
+// SetAlerts loads the webhook subscriptions, and starts polling the subscribed pairs, if enabled in the config
+func (app *App) SetAlerts() *App {
+	exitOnError(app.startAlerts(), "Could not start the rate alerts")
+	return app
+}
 
+// SetStream starts refreshing the streamed pairs, if streaming is enabled in the config
+func (app *App) SetStream() *App {
+	exitOnError(app.startStream(), "Could not start the rate streaming")
 	return app
 }
 
@@ -76,6 +197,12 @@ func (app *App) SetRoutes() *App {
 	case "gin":
 		app.Router = router.NewGinRouter(app.Logger, app.Config)
 		c.Info("Using Gin router")
+	case "chi":
+		app.Router = router.NewChiRouter(app.Logger, app.Config)
+		c.Info("Using chi router")
+	case "http", "net/http":
+		app.Router = router.NewHTTPRouter(app.Logger, app.Config)
+		c.Info("Using net/http router")
 	default:
 		app.Router = router.NewFiberRouter(app.Logger, app.Config)
 		c.Info("Using Fiber router")
@@ -86,6 +213,31 @@ func (app *App) SetRoutes() *App {
 	return app
 }
 
+// SetGRPC starts the gRPC server in the background, if enabled in the config
+func (app *App) SetGRPC() *App {
+	if !app.Config.GRPC.Enabled {
+		c.Info("gRPC API is disabled")
+		return app
+	}
+	if app.Config.GRPC.Port == app.Config.Port {
+		c.Warnf("The gRPC port must differ from the REST port (%d). Cannot continue", app.Config.Port)
+		os.Exit(1)
+	}
+
+	app.GRPC = router.NewGrpcServer(app.Logger, app.Config)
+	app.GRPC.RegisterServices()
+
+	address := ":" + strconv.FormatUint(app.Config.GRPC.Port, 10)
+	go func() {
+		if err := app.GRPC.Serve(address); err != nil {
+			c.Warnf("gRPC server could not listen on the given port")
+			e.FromError(err).Print(0, 0)
+		}
+	}()
+	c.Successf("gRPC API started on port %d", app.Config.GRPC.Port)
+	return app
+}
+
 // Serve starts listening to requests on the specified port
 func (app *App) Serve() {
 	portStr := strconv.FormatUint(app.Config.Port, 10)
@@ -121,6 +273,9 @@ func (app *App) MonitorSignals() *App {
 	go func() {
 		<-done
 		c.Out("Stopping server...")
+		if err := app.Close(); err != nil {
+			e.FromError(err).Print(-1, 0)
+		}
 		//app.Router.Stop()
 		os.Exit(0)
 	}()
@@ -130,6 +285,16 @@ func (app *App) MonitorSignals() *App {
 	return app
 }
 
-func GetErrorMap() map[string]string {
+// Close stops the streams and the gRPC server, and closes the stores, returning their errors
+func (app *App) Close() error {
+	// End the streams first, so that the gRPC server does not wait for them
+	stream.Close()
+	if app.GRPC != nil {
+		app.GRPC.Stop()
+	}
+	return errors.Join(history.Close(), quotes.Close(), alerts.Close())
+}
+
+func GetErrorMap() e.ErrorMap {
 	return errorMap
 }
@@ -1,9 +1,99 @@
 package application
 
-var errorMap = map[string]string{
-	"eNcF01": "No valid config file found",
-	"eGaPf1": "All providers have failed",
-	"eCRP68": "All providers failed in round-robin mode",
-	"ePrRnf": "To-symbol (quote) not found in response from API provider",
-	"eAGn2c": "Got non-200 response code from API provider (status: %d)",
+import (
+	"net/http"
+
+	"fx-service/pkg/e"
+)
+
+// errorMap is the error catalogue. The errors without a status are internal, and sent to clients with a 500.
+// Entries whose message holds internal details, such as file paths, have a public message for the clients.
+var errorMap = e.ErrorMap{
+	"eNcF01": {Message: "No valid config file found"},
+	"eGaPf1": {Message: "All providers have failed", Status: http.StatusServiceUnavailable, Retryable: true},
+	"eCRP68": {Message: "All providers failed in round-robin mode", Status: http.StatusServiceUnavailable, Retryable: true},
+	"ePrRnf": {Message: "To-symbol (quote) not found in response from API provider", Status: http.StatusNotFound},
+	"eAGn2c": {Message: "Got non-200 response code from API provider (status: %d)", Status: http.StatusBadGateway, Public: "Got an error response from the rate provider", Retryable: true, Fields: []string{"status"}},
+	"eCcTr1": {Message: "Invalid cache TTL rule '%s'. Use a pair (EUR/USD), base (HKD/*), quote (*/HKD), currency (HKD) or '*'"},
+	"eCcTr2": {Message: "Cache TTL rule '%s' must not be negative (got %d)"},
+	"eRrPn1": {Message: "Provider '%s' is not enabled", Status: http.StatusBadRequest},
+	"ePrNo1": {Message: "No providers enabled", Status: http.StatusServiceUnavailable},
+	"ePrUk1": {Message: "Unknown provider '%s'"},
+	"eHsDr1": {Message: "Unsupported history store driver '%s'. Use 'sqlite' or 'postgres'"},
+	"eHsDi1": {Message: "Invalid history downsample interval '%s'. Use a duration such as '1h' or '24h'"},
+	"eHsOp1": {Message: "Could not open the %s history store", Public: "Could not open the history store"},
+	"eHsAp1": {Message: "Could not save historic rates", Retryable: true},
+	"eHsPr1": {Message: "Could not prune historic rates"},
+	"eHsDs1": {Message: "Could not downsample historic rates"},
+	"eHsQr1": {Message: "Could not query historic rates", Retryable: true},
+	"eHsIv1": {Message: "Invalid interval '%s'. Use a duration of at least one minute, such as '15m', '1h', '1d' or '1w'", Status: http.StatusBadRequest},
+	"eHsTm1": {Message: "Invalid date or time '%s'. Use YYYY-MM-DD or RFC 3339", Status: http.StatusBadRequest},
+	"eRhNp1": {Message: "No enabled provider supports historical rates", Status: http.StatusServiceUnavailable},
+	"eRhNs1": {Message: "Provider '%s' does not support historical rates", Status: http.StatusBadRequest},
+	"eRhRg1": {Message: "The start of the range must be before the end", Status: http.StatusBadRequest},
+	"eRhTm1": {Message: "Too many intervals in range (%d). The maximum is %d", Status: http.StatusBadRequest},
+	"eRbNs1": {Message: "Historic rate storage is disabled", Status: http.StatusServiceUnavailable},
+	"eRbTl1": {Message: "Too many days to backfill (%d). The maximum is %d", Status: http.StatusBadRequest},
+	"eSpRp1": {Message: "Invalid pair in spread rule %d: '%s'. Use a pair (EUR/USD), base (EUR/*), quote (*/USD), currency (EUR) or '*'"},
+	"eSpRb1": {Message: "Invalid markup in spread rule %d: %s bps. Use at least 0 and less than 10000"},
+	"eSpRa1": {Message: "Invalid amount band in spread rule %d. The amounts must not be negative, and maxAmount must be above minAmount"},
+	"eQtDr1": {Message: "Unsupported quote store driver '%s'. Use 'memory' or 'sqlite'"},
+	"eQtOp1": {Message: "Could not open the sqlite quote store"},
+	"eQtSv1": {Message: "Could not save the quote", Retryable: true},
+	"eQtQr1": {Message: "Could not read the quote", Retryable: true},
+	"eQtPr1": {Message: "Could not prune expired quotes"},
+	"eQtDs1": {Message: "Locked quotes are disabled", Status: http.StatusServiceUnavailable},
+	"eQtNf1": {Message: "Quote '%s' not found", Status: http.StatusNotFound},
+	"eQtEx1": {Message: "Quote '%s' has expired", Status: http.StatusGone},
+	"eQtAc1": {Message: "Quote '%s' was already accepted", Status: http.StatusConflict},
+	"eOvLd1": {Message: "Could not load the rate overrides from '%s'", Public: "Could not load the rate overrides"},
+	"eOvSv1": {Message: "Could not save the rate overrides to '%s'", Public: "Could not save the rate overrides", Retryable: true},
+	"eOvAu1": {Message: "Could not access the rate override audit log '%s'", Public: "Could not access the rate override audit log", Retryable: true},
+	"eOvRt1": {Message: "Invalid override rate %s. The rate must be above zero", Status: http.StatusBadRequest},
+	"eOvRq1": {Message: "A reason and an author are required to change a rate override", Status: http.StatusBadRequest},
+	"eOvEx1": {Message: "Override expiry %s is in the past", Status: http.StatusBadRequest},
+	"eOvNf1": {Message: "No active override for %s/%s", Status: http.StatusNotFound},
+	"eAlDr1": {Message: "Unsupported alert store driver '%s'. Use 'memory' or 'sqlite'"},
+	"eAlOp1": {Message: "Could not open the sqlite alert store"},
+	"eAlSv1": {Message: "Could not save the subscription", Retryable: true},
+	"eAlQr1": {Message: "Could not read the subscriptions", Retryable: true},
+	"eAlDs1": {Message: "Rate alerts are disabled", Status: http.StatusServiceUnavailable},
+	"eAlNf1": {Message: "Subscription '%s' not found", Status: http.StatusNotFound},
+	"eAlCd1": {Message: "Invalid alert condition '%s'. Use 'absolute', 'percent' or 'cross'", Status: http.StatusBadRequest},
+	"eAlTh1": {Message: "Invalid alert threshold %s. The threshold must be above zero", Status: http.StatusBadRequest},
+	"eAlUr1": {Message: "Invalid callback URL '%s'. Use an absolute http or https URL", Status: http.StatusBadRequest},
+	"eAlUr2": {Message: "Invalid callback URL '%s'. Callbacks cannot target loopback, private or link-local addresses", Status: http.StatusBadRequest},
+	"eAlAu1": {Message: "This API key cannot register subscriptions", Status: http.StatusForbidden},
+	"eAlLm1": {Message: "Too many subscriptions. The maximum is %d per client", Status: http.StatusBadRequest},
+	"eStDs1": {Message: "Rate streaming is disabled", Status: http.StatusServiceUnavailable},
+	"eStLm1": {Message: "Too many pairs in the stream (%d). The maximum is %d", Status: http.StatusBadRequest},
+	"eStWs1": {Message: "Invalid WebSocket handshake: %s", Status: http.StatusBadRequest},
+	"eGqSc1": {Message: "Could not build the GraphQL schema"},
+	"eGqCx1": {Message: "Query is too complex (%d). The maximum is %d", Status: http.StatusBadRequest},
+	"eCyUk1": {Message: "Unknown currency codes in currenciesEnabled: %s. Use ISO 4217 codes, or codes listed by an enabled provider"},
+	"eRqTl1": {Message: "The request body is too large. The maximum is %d bytes", Status: http.StatusRequestEntityTooLarge},
+	"eRqBd1": {Message: "Could not read the request body", Status: http.StatusBadRequest},
+	"eRqBd2": {Message: "Invalid request body. Expected %s", Status: http.StatusBadRequest},
+	"eRqNf1": {Message: "Not found", Status: http.StatusNotFound},
+	"eRqCm1": {Message: "Missing base or quote currency codes. Ensure URL and query is correct", Status: http.StatusBadRequest},
+	"eRqCb1": {Message: "Invalid base currency code, %s", Status: http.StatusBadRequest},
+	"eRqCq1": {Message: "Invalid quote currency code, %s", Status: http.StatusBadRequest},
+	"eRqCc1": {Message: "Invalid currency code, %s", Status: http.StatusBadRequest},
+	"eRqAm1": {Message: "Missing amount to convert", Status: http.StatusBadRequest},
+	"eRqAm2": {Message: "Invalid amount, %s", Status: http.StatusBadRequest},
+	"eRqRd1": {Message: "Unsupported rounding value '%s'. Use one of: %s", Status: http.StatusBadRequest},
+	"eRqSt1": {Message: "Unsupported strategy '%s'. Use one of: %s", Status: http.StatusBadRequest},
+	"eRqFf1": {Message: "Invalid format flag, %s", Status: http.StatusBadRequest},
+	"eRqLm1": {Message: "Invalid limit, %s", Status: http.StatusBadRequest},
+	"eCvNo1": {Message: "No conversions requested", Status: http.StatusBadRequest},
+	"eCvTm1": {Message: "Too many conversions, the maximum is %d", Status: http.StatusBadRequest},
+	"eCvIt1": {Message: "Conversion %d: %s", Status: http.StatusBadRequest},
+	"eMxCn1": {Message: "A matrix needs at least 2 currencies", Status: http.StatusBadRequest},
+	"eMxCn2": {Message: "Too many currencies, the maximum is %d", Status: http.StatusBadRequest},
+	"eHsFt1": {Message: "Date must not be in the future", Status: http.StatusBadRequest},
+	"eCcNc1": {Message: "Pair is not cached, %s/%s", Status: http.StatusNotFound},
+	"eOvRt2": {Message: "Missing override rate", Status: http.StatusBadRequest},
+	"eAlTh2": {Message: "Missing alert threshold", Status: http.StatusBadRequest},
+	"eGqVr1": {Message: "Invalid variables, %s", Status: http.StatusBadRequest},
+	"eFmUk1": {Message: "Unsupported format '%s'. Use json, csv, xml, msgpack or protobuf", Status: http.StatusBadRequest},
 }
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"fx-service/internal/reply"
+	"fx-service/pkg/config"
+	"github.com/gin-gonic/gin"
+	"github.com/gofiber/fiber/v2"
+)
+
+// isAdminAuthorized checks the Authorization header value against the configured admin bearer token
+// An empty token never authorizes, so admin routes are locked unless a token is configured
+func isAdminAuthorized(cfg config.AdminConfig, authHeader string) bool {
+	if cfg.Token == "" {
+		return false
+	}
+	token, found := strings.CutPrefix(authHeader, "Bearer ")
+	if !found {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(cfg.Token)) == 1
+}
+
+// FiberAdminAuth requires the admin bearer token on every request, for Fiber router
+func FiberAdminAuth(cfg config.AdminConfig) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !isAdminAuthorized(cfg, c.Get(fiber.HeaderAuthorization)) {
+			return c.Status(fiber.StatusUnauthorized).JSON(reply.Error("Unauthorized"))
+		}
+		return c.Next()
+	}
+}
+
+// GinAdminAuth requires the admin bearer token on every request, for Gin router
+func GinAdminAuth(cfg config.AdminConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !isAdminAuthorized(cfg, c.GetHeader("Authorization")) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, reply.Error("Unauthorized"))
+			return
+		}
+		c.Next()
+	}
+}
+
+// HTTPAdminAuth requires the admin bearer token on every request, for net/http and chi routers
+func HTTPAdminAuth(cfg config.AdminConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !isAdminAuthorized(cfg, r.Header.Get("Authorization")) {
+				writeJSON(w, http.StatusUnauthorized, reply.Error("Unauthorized"))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
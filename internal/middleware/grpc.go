@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+
+	"fx-service/internal/service/stats"
+	"fx-service/pkg/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+)
+
+// grpcPeerIP returns the address of the caller, or "" if it is unknown
+func grpcPeerIP(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return p.Addr.String()
+	}
+	return ""
+}
+
+// GrpcLogger logs each unary call and counts it in the statistics, for the gRPC server
+func GrpcLogger(baseLogger *logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctxLogger := makeContextLogger(baseLogger, info.FullMethod, grpcPeerIP(ctx))
+		ctxLogger.Info(fmt.Sprintf("req: grpc %s", info.FullMethod), nil)
+
+		// Increment the global hit counter
+		updateHitCount(info.FullMethod)
+		defer stats.GetInstance().IncRequestCount()
+
+		return handler(ctx, req)
+	}
+}
+
+// GrpcStreamLogger logs each streaming call and counts it in the statistics, for the gRPC server
+func GrpcStreamLogger(baseLogger *logger.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctxLogger := makeContextLogger(baseLogger, info.FullMethod, grpcPeerIP(ss.Context()))
+		ctxLogger.Info(fmt.Sprintf("req: grpc %s", info.FullMethod), nil)
+
+		// Increment the global hit counter
+		updateHitCount(info.FullMethod)
+		defer stats.GetInstance().IncRequestCount()
+
+		return handler(srv, ss)
+	}
+}
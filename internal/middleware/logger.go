@@ -2,6 +2,9 @@ package middleware
 
 import (
 	"fmt"
+	"net"
+	"net/http"
+
 	"fx-service/internal/service/stats"
 	"fx-service/pkg/logger"
 	"github.com/gin-gonic/gin"
@@ -68,3 +71,32 @@ func GinLogger(baseLogger *logger.Logger) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// HTTPLogger logs the request and counts the hit, for net/http and chi routers
+func HTTPLogger(baseLogger *logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path := r.URL.Path
+
+			// Create a new logger with the path and IP
+			ctxLogger := makeContextLogger(baseLogger, path, clientIP(r))
+
+			if path != "/favicon.ico" {
+				ctxLogger.Info(fmt.Sprintf("req: %s %s", r.Method, r.URL.RequestURI()), nil)
+			}
+
+			// Increment the global hit counter
+			updateHitCount(path)
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientIP returns the IP address of the client of a net/http request
+func clientIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
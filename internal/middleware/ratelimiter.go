@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"encoding/json"
 	"net/http"
 	"time"
 
@@ -47,3 +48,34 @@ func GinRateLimiter(cfg config.RateLimiterConfig) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// HTTPRateLimiter limits the number of requests per IP address for net/http and chi routers
+func HTTPRateLimiter(cfg config.RateLimiterConfig) func(http.Handler) http.Handler {
+	rl := newGinRateLimiter()
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			v := rl.getVisitor(clientIP(r), cfg.Timeframe)
+
+			rl.mu.Lock()
+			limited := v.count >= cfg.MaxRequests
+			if !limited {
+				v.count++
+			}
+			rl.mu.Unlock()
+
+			if limited {
+				writeJSON(w, http.StatusTooManyRequests, reply.Error("Rate limit exceeded"))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// writeJSON sends a JSON response from a net/http middleware
+func writeJSON(w http.ResponseWriter, status int, payload interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(payload)
+}
@@ -0,0 +1,201 @@
+package reply
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tinylib/msgp/msgp"
+	"google.golang.org/protobuf/proto"
+)
+
+// Table is the tabular form of a result, for the CSV format
+type Table struct {
+	Header []string
+	Rows   [][]interface{}
+}
+
+// Encode encodes a response body in a format. The CSV format encodes the table, the Protobuf format the typed message
+// of the result (see proto/fx/v1/fx.proto), and the others the body.
+func Encode(format Format, body interface{}, table *Table, message proto.Message) ([]byte, error) {
+	switch format {
+	case JSON:
+		return json.Marshal(body)
+	case CSV:
+		if table == nil {
+			return nil, fmt.Errorf("the response has no table to send as CSV")
+		}
+		return encodeCSV(table)
+	case Protobuf:
+		if message == nil {
+			return nil, fmt.Errorf("the response has no message to send as Protobuf")
+		}
+		return proto.MarshalOptions{Deterministic: true}.Marshal(message)
+	}
+
+	// The other formats send the same document as JSON, with the same decimal and date representations
+	document, err := toDocument(body)
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case XML:
+		return encodeXML(document)
+	case MessagePack:
+		return msgp.AppendIntf(nil, document)
+	default:
+		return nil, fmt.Errorf("unsupported format '%s'", format)
+	}
+}
+
+// toDocument converts a body to the JSON document it is sent as: maps, slices, strings, numbers, booleans and nulls.
+// Integers stay integers, and other numbers become floats.
+func toDocument(body interface{}) (interface{}, error) {
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+	return withNumbers(document), nil
+}
+
+// withNumbers replaces the JSON numbers of a document by integers or floats
+func withNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = withNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = withNumbers(item)
+		}
+	}
+	return value
+}
+
+// encodeCSV writes the header and the rows of a table
+func encodeCSV(table *Table) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(table.Header); err != nil {
+		return nil, err
+	}
+	for _, row := range table.Rows {
+		record := make([]string, len(row))
+		for i, cell := range row {
+			record[i] = cellString(cell)
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// cellString returns the text of a table cell. Nil values are empty.
+func cellString(cell interface{}) string {
+	switch v := cell.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case *string:
+		if v == nil {
+			return ""
+		}
+		return *v
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// encodeXML writes a document as XML, in a <response> element.
+// Object keys become elements, or <entry key="..."> when they are not valid element names. Array items are <item> elements.
+func encodeXML(document interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&buf)
+	if err := writeXML(encoder, xml.StartElement{Name: xml.Name{Local: "response"}}, document); err != nil {
+		return nil, err
+	}
+	if err := encoder.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeXML writes a value of a document in an element
+func writeXML(encoder *xml.Encoder, start xml.StartElement, value interface{}) error {
+	if err := encoder.EncodeToken(start); err != nil {
+		return err
+	}
+	switch v := value.(type) {
+	case nil:
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if err := writeXML(encoder, xmlElement(key), v[key]); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if err := writeXML(encoder, xml.StartElement{Name: xml.Name{Local: "item"}}, item); err != nil {
+				return err
+			}
+		}
+	default:
+		if err := encoder.EncodeToken(xml.CharData(fmt.Sprint(v))); err != nil {
+			return err
+		}
+	}
+	return encoder.EncodeToken(start.End())
+}
+
+// xmlElement returns the element of an object key: the key itself, or <entry key="..."> when it is not a valid name
+func xmlElement(key string) xml.StartElement {
+	if isXMLName(key) {
+		return xml.StartElement{Name: xml.Name{Local: key}}
+	}
+	return xml.StartElement{Name: xml.Name{Local: "entry"}, Attr: []xml.Attr{{Name: xml.Name{Local: "key"}, Value: key}}}
+}
+
+// isXMLName tells whether a key can be used as an element name: ASCII letters, digits, "_", "-" and ".", not starting
+// with a digit, "-", "." or "xml"
+func isXMLName(key string) bool {
+	if key == "" || strings.HasPrefix(strings.ToLower(key), "xml") {
+		return false
+	}
+	for i, c := range key {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_':
+		case i > 0 && (c >= '0' && c <= '9' || c == '-' || c == '.'):
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,83 @@
+package reply
+
+import (
+	"reflect"
+	"testing"
+
+	"fx-service/pkg/decimal"
+	"fx-service/pkg/fxpb"
+	"github.com/tinylib/msgp/msgp"
+	"google.golang.org/protobuf/proto"
+)
+
+// testBody is a response with a decimal, an integer, a boolean, a null, a list and keys which are not XML names
+func testBody() map[string]interface{} {
+	return Result(map[string]interface{}{
+		"base":     "USD",
+		"quotes":   map[string]decimal.Decimal{"EUR": decimal.MustParse("0.9")},
+		"calls":    2,
+		"cached":   true,
+		"provider": nil,
+		"list":     []string{"a", "b<c"},
+		"1d":       "x",
+	})
+}
+
+func TestEncodeCSV(t *testing.T) {
+	provider := "FixerApi"
+	table := &Table{
+		Header: []string{"base", "quote", "rate", "provider", "note"},
+		Rows:   [][]interface{}{{"USD", "EUR", decimal.MustParse("0.9"), &provider, "a, \"b\""}, {"USD", "JPY", decimal.MustParse("151.2"), nil, nil}},
+	}
+	raw, err := Encode(CSV, testBody(), table, nil)
+	expected := "base,quote,rate,provider,note\nUSD,EUR,0.9,FixerApi,\"a, \"\"b\"\"\"\nUSD,JPY,151.2,,\n"
+	if err != nil || string(raw) != expected {
+		t.Errorf("expected %q, got %q (%v)", expected, raw, err)
+	}
+
+	if _, err := Encode(CSV, testBody(), nil, nil); err == nil {
+		t.Errorf("expected an error without a table")
+	}
+}
+
+func TestEncodeXML(t *testing.T) {
+	raw, err := Encode(XML, testBody(), nil, nil)
+	expected := `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<response><result><entry key="1d">x</entry><base>USD</base><cached>true</cached>` +
+		`<calls>2</calls><list><item>a</item><item>b&lt;c</item></list><provider></provider><quotes><EUR>0.9</EUR></quotes></result></response>`
+	if err != nil || string(raw) != expected {
+		t.Errorf("expected %s, got %s (%v)", expected, raw, err)
+	}
+}
+
+// expectedDocument is the document of testBody, as decoded from MessagePack
+var expectedDocument = map[string]interface{}{"result": map[string]interface{}{
+	"base": "USD", "quotes": map[string]interface{}{"EUR": "0.9"}, "calls": int64(2), "cached": true, "provider": nil,
+	"list": []interface{}{"a", "b<c"}, "1d": "x",
+}}
+
+func TestEncodeMessagePack(t *testing.T) {
+	raw, err := Encode(MessagePack, testBody(), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	document, rest, err := msgp.ReadIntfBytes(raw)
+	if err != nil || len(rest) != 0 || !reflect.DeepEqual(document, expectedDocument) {
+		t.Errorf("expected %v, got %v (%v)", expectedDocument, document, err)
+	}
+}
+
+func TestEncodeProtobuf(t *testing.T) {
+	message := &fxpb.GetRatesResponse{Base: "USD", Rates: map[string]string{"EUR": "0.9", "JPY": "151.2"}, Cached: true}
+	raw, err := Encode(Protobuf, testBody(), nil, message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded fxpb.GetRatesResponse
+	if err := proto.Unmarshal(raw, &decoded); err != nil || !proto.Equal(&decoded, message) {
+		t.Errorf("expected the typed message, got %v (%v)", &decoded, err)
+	}
+
+	if _, err := Encode(Protobuf, testBody(), nil, nil); err == nil {
+		t.Errorf("expected an error without a message")
+	}
+}
@@ -0,0 +1,118 @@
+package reply
+
+import (
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"fx-service/pkg/e"
+)
+
+// Format is a format the responses can be sent in, other than the default JSON
+type Format string
+
+const (
+	JSON        Format = "json"
+	CSV         Format = "csv"      // The table of the result, for spreadsheets
+	XML         Format = "xml"      // The same document as JSON
+	MessagePack Format = "msgpack"  // The same document as JSON, in MessagePack
+	Protobuf    Format = "protobuf" // The result, as its message of proto/fx/v1/fx.proto
+)
+
+// Formats are the formats of the responses, in order of preference when the client accepts several equally
+var Formats = []Format{JSON, CSV, XML, MessagePack, Protobuf}
+
+// mediaTypes are the media types of each format, the first one being the content type of the responses
+var mediaTypes = map[Format][]string{
+	JSON:        {"application/json"},
+	CSV:         {"text/csv"},
+	XML:         {"application/xml", "text/xml"},
+	MessagePack: {"application/msgpack", "application/x-msgpack", "application/vnd.msgpack"},
+	Protobuf:    {"application/x-protobuf", "application/protobuf", "application/vnd.google.protobuf"},
+}
+
+// formatNames are the names of the formats in the format= parameter, with their aliases
+var formatNames = map[string]Format{
+	"json":        JSON,
+	"csv":         CSV,
+	"xml":         XML,
+	"msgpack":     MessagePack,
+	"messagepack": MessagePack,
+	"protobuf":    Protobuf,
+	"proto":       Protobuf,
+}
+
+// ContentType returns the content type of the responses in the format
+func (f Format) ContentType() string {
+	switch f {
+	case CSV, XML:
+		return mediaTypes[f][0] + "; charset=utf-8"
+	default:
+		return mediaTypes[f][0]
+	}
+}
+
+// Negotiate returns the format asked for by the format= parameter, or else by the Accept header. Defaults to JSON,
+// also when none of the accepted types can be sent. Returns the eFmUk1 error for an unknown format parameter.
+func Negotiate(accept, param string) (Format, error) {
+	if param != "" {
+		format, ok := formatNames[strings.ToLower(param)]
+		if !ok {
+			return "", e.FromCode("eFmUk1", param)
+		}
+		return format, nil
+	}
+	for _, mediaRange := range acceptedRanges(accept) {
+		if format, ok := formatOf(mediaRange); ok {
+			return format, nil
+		}
+	}
+	return JSON, nil
+}
+
+// acceptedRanges returns the media ranges of an Accept header, by decreasing quality, without the refused ones (q=0)
+func acceptedRanges(accept string) []string {
+	type weighted struct {
+		mediaRange string
+		quality    float64
+	}
+	var ranges []weighted
+	for _, part := range strings.Split(accept, ",") {
+		mediaRange, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		if quality > 0 {
+			ranges = append(ranges, weighted{mediaRange, quality})
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].quality > ranges[j].quality })
+
+	list := make([]string, len(ranges))
+	for i, r := range ranges {
+		list[i] = r.mediaRange
+	}
+	return list
+}
+
+// formatOf returns the format of a media range. A wildcard range matches the first format of its type, e.g. text/* is CSV.
+func formatOf(mediaRange string) (Format, bool) {
+	if mediaRange == "*/*" {
+		return JSON, true
+	}
+	for _, format := range Formats {
+		for _, mediaType := range mediaTypes[format] {
+			if mediaRange == mediaType || (strings.HasSuffix(mediaRange, "/*") && strings.HasPrefix(mediaType, mediaRange[:len(mediaRange)-1])) {
+				return format, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,51 @@
+package reply
+
+import (
+	"net/http"
+	"testing"
+
+	"fx-service/pkg/e"
+)
+
+func TestNegotiate(t *testing.T) {
+	e.SetCatalogue(e.ErrorMap{
+		"eFmUk1": {Message: "Unsupported format '%s'", Status: http.StatusBadRequest},
+	})
+
+	tests := []struct {
+		name     string
+		accept   string
+		param    string
+		expected Format
+		code     string
+	}{
+		{name: "no preference", expected: JSON},
+		{name: "any", accept: "*/*", expected: JSON},
+		{name: "browser", accept: "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", expected: XML},
+		{name: "csv", accept: "text/csv", expected: CSV},
+		{name: "text wildcard", accept: "text/*", expected: CSV},
+		{name: "by quality", accept: "application/json;q=0.5, application/msgpack", expected: MessagePack},
+		{name: "refused", accept: "application/x-protobuf;q=0, application/protobuf", expected: Protobuf},
+		{name: "parameter over header", accept: "application/json", param: "CSV", expected: CSV},
+		{name: "parameter alias", param: "proto", expected: Protobuf},
+		{name: "unknown parameter", param: "yaml", code: "eFmUk1"},
+		{name: "none acceptable", accept: "text/html, application/json;q=0", expected: JSON},
+		{name: "unknown type", accept: "application/yaml", expected: JSON},
+		{name: "unknown type with a parameter", accept: "application/yaml", param: "xml", expected: XML},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, err := Negotiate(tt.accept, tt.param)
+			if tt.code != "" {
+				if code := e.FromError(err).GetCode(); err == nil || code != tt.code {
+					t.Errorf("expected the %s error, got %v", tt.code, err)
+				}
+				return
+			}
+			if err != nil || format != tt.expected {
+				t.Errorf("expected %s, got %s (%v)", tt.expected, format, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,10 @@
+package reply
+
+// WithMeta wraps the response data, or the error, in the v2 response shape, with the metadata of the response
+func WithMeta(result, err, meta interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"result": result,
+		"error":  err,
+		"meta":   meta,
+	}
+}
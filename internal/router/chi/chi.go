@@ -0,0 +1,53 @@
+package chiHandlers
+
+import (
+	"net/http"
+
+	"fx-service/internal/middleware"
+	coreHandlers "fx-service/internal/router/core"
+	nethttpHandlers "fx-service/internal/router/nethttp"
+	"fx-service/pkg/config"
+	"fx-service/pkg/logger"
+	"github.com/go-chi/chi/v5"
+)
+
+// ChiRouter serves the API with chi. Handlers are shared with the net/http router, as chi uses net/http handlers.
+type ChiRouter struct {
+	Logger *logger.Logger
+	Config *config.Config
+	Mux    *chi.Mux
+}
+
+func (r *ChiRouter) RegisterMiddleware() {
+	r.Mux.Use(middleware.HTTPLogger(r.Logger))
+	r.Mux.Use(middleware.HTTPRateLimiter(r.Config.RateLimiter))
+}
+
+func (r *ChiRouter) RegisterRoutes() {
+	// Register the routes shared by every router
+	for _, route := range coreHandlers.Routes(r.Config) {
+		routes := chi.Router(r.Mux)
+		if route.Admin {
+			routes = r.Mux.With(middleware.HTTPAdminAuth(r.Config.Admin))
+		}
+		routes.Method(route.Method, nethttpHandlers.Pattern(route.Path), nethttpHandlers.Adapt(route.Path, route.Handler, r.Config.MaxBodyBytes))
+	}
+
+	// Register the streaming routes, only if enabled
+	for _, route := range coreHandlers.StreamRoutes(r.Config) {
+		r.Mux.Method(route.Method, route.Path, nethttpHandlers.StreamRates(r.Config, route.Version)) // ?base=USD&quote=EUR,GBP (Server-Sent Events, or WebSocket on upgrade)
+	}
+
+	// Handle 404, for unknown routes and for known routes with another method, as the other routers do
+	notFound := nethttpHandlers.Adapt("/", coreHandlers.NotFound(r.Config), r.Config.MaxBodyBytes)
+	r.Mux.NotFound(notFound)
+	r.Mux.MethodNotAllowed(notFound)
+}
+
+func (r *ChiRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.Mux.ServeHTTP(w, req)
+}
+
+func (r *ChiRouter) Serve(addr string) error {
+	return http.ListenAndServe(addr, r.Mux)
+}
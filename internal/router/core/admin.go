@@ -0,0 +1,172 @@
+package coreHandlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"fx-service/internal/service/history"
+	"fx-service/internal/service/providers"
+	"fx-service/internal/service/ratecache"
+	"fx-service/internal/service/rates"
+	"fx-service/pkg/config"
+	"fx-service/pkg/e"
+)
+
+// adminCurrency applies the case sensitivity setting to a currency code given to an admin endpoint
+func adminCurrency(cfg *config.Config, ccy string) string {
+	if !cfg.CurrenciesCaseSensitive {
+		return strings.ToUpper(ccy)
+	}
+	return ccy
+}
+
+// adminMode returns the strategy mode given to an admin endpoint, or the configured mode if none is given
+func adminMode(cfg *config.Config, strategy string) (config.Mode, error) {
+	if strategy == "" {
+		return cfg.Mode, nil
+	}
+	mode, err := config.ParseMode(strings.ToLower(strategy))
+	if err != nil {
+		return mode, e.FromCode("eRqSt1", strategy, strings.Join(config.ModeNameList(), ", "))
+	}
+	return mode, nil
+}
+
+// ListCache returns every unexpired entry in the rate cache, with its age and TTL
+func ListCache(cfg *config.Config) Handler {
+	return func(req *Request) *Response {
+		entries := ratecache.GetInstance().Entries()
+		return Result(Map{
+			"count":   len(entries),
+			"entries": entries,
+		})
+	}
+}
+
+// GetCacheEntry returns the cache entry for a single pair
+func GetCacheEntry(cfg *config.Config) Handler {
+	return func(req *Request) *Response {
+		from := adminCurrency(cfg, req.Param("from"))
+		to := adminCurrency(cfg, req.Param("to"))
+
+		entry := ratecache.GetInstance().GetEntry(from, to)
+		if entry == nil {
+			return Error(http.StatusNotFound, e.FromCode("eCcNc1", from, to))
+		}
+		return Result(entry)
+	}
+}
+
+// InvalidateCachePair removes a single pair from the rate cache
+func InvalidateCachePair(cfg *config.Config) Handler {
+	return func(req *Request) *Response {
+		from := adminCurrency(cfg, req.Param("from"))
+		to := adminCurrency(cfg, req.Param("to"))
+
+		removed := 0
+		if ratecache.GetInstance().Delete(from, to) {
+			removed = 1
+		}
+		return Result(Map{"removed": removed})
+	}
+}
+
+// InvalidateCacheBase removes every pair with the given base currency from the rate cache
+func InvalidateCacheBase(cfg *config.Config) Handler {
+	return func(req *Request) *Response {
+		from := adminCurrency(cfg, req.Param("from"))
+		removed := ratecache.GetInstance().DeleteBase(from)
+		return Result(Map{"removed": removed})
+	}
+}
+
+// ClearCache removes every entry from the rate cache
+func ClearCache(cfg *config.Config) Handler {
+	return func(req *Request) *Response {
+		rc := ratecache.GetInstance()
+		removed := len(rc.Entries())
+		rc.Clear()
+		return Result(Map{"removed": removed})
+	}
+}
+
+// RefreshCachePair fetches a pair from upstream, bypassing the cache, and stores the result in the cache.
+// Use ?provider={name} to call a single enabled provider, or ?strategy={mode} to override the configured mode.
+func RefreshCachePair(cfg *config.Config) Handler {
+	return func(req *Request) *Response {
+		ccyBase, ccyQuote, err := validateAndParseCurrencies(cfg, req)
+		if err != nil {
+			return Error(http.StatusBadRequest, err)
+		}
+
+		mode, err := adminMode(cfg, req.QueryValue("strategy"))
+		if err != nil {
+			return Error(http.StatusBadRequest, err)
+		}
+
+		providerName := req.QueryValue("provider")
+		if _, ok := providers.EnabledProviders[providerName]; providerName != "" && !ok {
+			return Error(http.StatusBadRequest, e.FromCode("eRrPn1", providerName))
+		}
+
+		rateResult, err := rates.RefreshRate(ccyBase, ccyQuote, mode, providerName)
+		if err != nil {
+			return Error(http.StatusInternalServerError, err)
+		}
+
+		return Result(Map{
+			"base":     ccyBase,
+			"quote":    ccyQuote,
+			"rate":     rateResult.Rate,
+			"provider": rateResult.Provider,
+			"entry":    ratecache.GetInstance().GetEntry(ccyBase, ccyQuote),
+		})
+	}
+}
+
+// BackfillHistory fetches daily rates for a base currency from upstream historical endpoints, and saves them in the
+// historic rate store. Query: ?quote=EUR,GBP&start={date}&end={date}&strategy={mode}. The end defaults to yesterday.
+func BackfillHistory(cfg *config.Config) Handler {
+	return func(req *Request) *Response {
+		if !history.Enabled() {
+			return Error(http.StatusServiceUnavailable, e.FromCode("eRbNs1"))
+		}
+
+		ccyBase, ccyQuoteList, err := parseBaseAndQuotes(cfg, req.Param("from"), req.QueryValue("quote"))
+		if err != nil {
+			return Error(http.StatusBadRequest, err)
+		}
+
+		start, err := history.ParseTime(req.QueryValue("start"))
+		if err != nil {
+			return Error(http.StatusBadRequest, err)
+		}
+		end := time.Now()
+		if endStr := req.QueryValue("end"); endStr != "" {
+			if end, err = history.ParseTime(endStr); err != nil {
+				return Error(http.StatusBadRequest, err)
+			}
+		}
+
+		mode, err := adminMode(cfg, req.QueryValue("strategy"))
+		if err != nil {
+			return Error(http.StatusBadRequest, err)
+		}
+
+		backfillResult, err := rates.Backfill(ccyBase, ccyQuoteList, start, end, mode)
+		if err != nil {
+			return Error(http.StatusInternalServerError, err)
+		}
+
+		return Result(Map{
+			"base":    ccyBase,
+			"quotes":  ccyQuoteList,
+			"start":   backfillResult.Start.Format(time.DateOnly),
+			"end":     backfillResult.End.Format(time.DateOnly),
+			"calls":   backfillResult.Calls,
+			"saved":   backfillResult.Saved,
+			"skipped": backfillResult.Skipped,
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package coreHandlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"fx-service/internal/service/rates"
+	"fx-service/internal/service/spreads"
+	"fx-service/internal/validate"
+	"fx-service/pkg/config"
+	"fx-service/pkg/e"
+	util "fx-service/pkg/helpers"
+)
+
+// parseConvertOptions parses the optional rounding mode (defaults to the configured one) and format flag
+func parseConvertOptions(cfg *config.Config, rounding, format string) (config.Rounding, bool, error) {
+	mode, err := validate.Rounding(cfg, rounding)
+	if err != nil {
+		return mode, false, err
+	}
+
+	formatted := false
+	if format != "" {
+		if formatted, err = strconv.ParseBool(format); err != nil {
+			return mode, false, e.FromCode("eRqFf1", format)
+		}
+	}
+	return mode, formatted, nil
+}
+
+// convertResultMap builds the response for a single conversion
+func convertResultMap(cfg *config.Config, result *rates.ConvertResult, rounding config.Rounding, format bool) Map {
+	response := Map{
+		"base":     result.Base,
+		"quote":    result.Quote,
+		"amount":   result.Amount,
+		"rate":     result.Rate,
+		"result":   result.Result,
+		"decimals": result.Decimals,
+		"rounding": rounding.String(),
+		"cached":   result.WasCached,
+		"override": result.Override,
+	}
+	if result.Price != nil {
+		for key, value := range priceMap(*result.Price) {
+			response[key] = value
+		}
+	}
+	if format {
+		response["formatted"] = util.NumberFormatString(result.Result.String(), result.Decimals, ".", ",")
+	}
+	if cfg.ShowProvider {
+		response["provider"] = result.Provider
+	}
+	return response
+}
+
+// Convert converts an amount between two currencies, rounded to the minor units of the quote currency
+// Query: ?from=USD&to=JPY&amount=1234.56&rounding={half-even|half-up|truncate}&format=true
+func Convert(cfg *config.Config) Handler {
+	return func(req *Request) *Response {
+		ccyBase, ccyQuote, err := validate.CurrencyPair(cfg, req.QueryValue("from"), req.QueryValue("to"))
+		if err != nil {
+			return Error(http.StatusBadRequest, err)
+		}
+
+		amount, err := validate.Amount(req.QueryValue("amount"))
+		if err != nil {
+			return Error(http.StatusBadRequest, err)
+		}
+
+		rounding, format, err := parseConvertOptions(cfg, req.QueryValue("rounding"), req.QueryValue("format"))
+		if err != nil {
+			return Error(http.StatusBadRequest, err)
+		}
+
+		convertResult, err := rates.Convert(ccyBase, ccyQuote, amount, cfg.Mode, rounding, spreads.ClientFor(req.APIKey()))
+		if err != nil {
+			return Error(http.StatusInternalServerError, err)
+		}
+
+		return Result(convertResultMap(cfg, convertResult, rounding, format)).withSource(convertResult.WasCached, convertResult.Provider, ccyBase, ccyQuote)
+	}
+}
+
+// ConvertBatch converts several amounts in one request. The body is a JSON list of {"from", "to", "amount"}.
+// Each conversion in the response has either its result, or an error. Query: ?rounding={mode}&format=true
+func ConvertBatch(cfg *config.Config) Handler {
+	return func(req *Request) *Response {
+		var requests []rates.ConvertRequest
+		if err := json.Unmarshal(req.Body, &requests); err != nil {
+			return Error(http.StatusBadRequest, e.FromCode("eRqBd2", "a list of conversions"))
+		}
+		if len(requests) == 0 {
+			return Error(http.StatusBadRequest, e.FromCode("eCvNo1"))
+		}
+		if len(requests) > rates.MaxConvertBatch {
+			return Error(http.StatusBadRequest, e.FromCode("eCvTm1", rates.MaxConvertBatch))
+		}
+
+		rounding, format, err := parseConvertOptions(cfg, req.QueryValue("rounding"), req.QueryValue("format"))
+		if err != nil {
+			return Error(http.StatusBadRequest, err)
+		}
+
+		// Validate every conversion first, so that a single mistake does not cost any upstream calls
+		for i, conversion := range requests {
+			if requests[i].From, requests[i].To, err = validate.CurrencyPair(cfg, conversion.From, conversion.To); err != nil {
+				return Error(http.StatusBadRequest, e.FromCode("eCvIt1", i, e.Public(err, http.StatusBadRequest).Message))
+			}
+			if conversion.Amount == nil {
+				return Error(http.StatusBadRequest, e.FromCode("eCvIt1", i, e.Public(e.FromCode("eRqAm1"), http.StatusBadRequest).Message))
+			}
+		}
+
+		items := rates.ConvertBatch(requests, cfg.Mode, rounding, spreads.ClientFor(req.APIKey()))
+		conversions := make([]Map, len(items))
+		for i, item := range items {
+			if item.Error != nil {
+				conversions[i] = Map{
+					"base":   requests[i].From,
+					"quote":  requests[i].To,
+					"amount": requests[i].Amount,
+					"error":  e.Public(item.Error, http.StatusInternalServerError).Message,
+				}
+				continue
+			}
+			conversions[i] = convertResultMap(cfg, item.Result, rounding, format)
+		}
+
+		return Result(Map{
+			"conversions": conversions,
+		})
+	}
+}
@@ -0,0 +1,277 @@
+package coreHandlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+
+	"fmt"
+	"fx-service/internal/reply"
+	"fx-service/internal/service/overrides"
+	"fx-service/internal/service/ratecache"
+	"fx-service/pkg/config"
+	"fx-service/pkg/e"
+	"google.golang.org/protobuf/proto"
+)
+
+// apiKeyHeader identifies the client, for client-specific spreads, quotes and subscriptions
+const apiKeyHeader = "X-API-Key"
+
+// Map is the shape of a JSON object in a response
+type Map map[string]interface{}
+
+// Request is what a handler gets from an HTTP request, whichever router received it
+type Request struct {
+	Method  string
+	Params  map[string]string // Path parameters, e.g. "from" and "to" for /rate/:from/:to
+	Query   url.Values
+	Header  http.Header
+	Body    []byte
+	Context context.Context
+}
+
+// ReadBody reads the body of an HTTP request, up to maxBytes (no limit if 0), for the routers built on net/http.
+// Returns the eRqTl1 error (413) for a larger body, and eRqBd1 (400) when the body can't be read.
+func ReadBody(w http.ResponseWriter, r *http.Request, maxBytes int64) ([]byte, error) {
+	if maxBytes > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, e.FromCode("eRqTl1", maxBytes)
+		}
+		return nil, e.FromCode("eRqBd1").SetPrevious(err)
+	}
+	return body, nil
+}
+
+// Param returns a path parameter, or an empty string
+func (req *Request) Param(name string) string {
+	return req.Params[name]
+}
+
+// QueryValue returns the first non-empty query parameter of a name and its aliases, or an empty string
+func (req *Request) QueryValue(name string, aliases ...string) string {
+	if value := req.Query.Get(name); value != "" {
+		return value
+	}
+	for _, alias := range aliases {
+		if value := req.Query.Get(alias); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// APIKey returns the API key identifying the client, if any
+func (req *Request) APIKey() string {
+	return req.Header.Get(apiKeyHeader)
+}
+
+// Response is what a handler returns, for the router to send.
+// The body is sent as JSON, unless a file or a raw body is given. A nil body sends the status only.
+type Response struct {
+	Status int
+	Body   interface{}
+	Header map[string]string
+	File   string
+	Raw    []byte // Sent as is, with the Content-Type given in the header
+
+	// What the body was built from, so it can be sent in the shape of another API version
+	enveloped bool
+	result    interface{}
+	failure   interface{}
+	cached    *bool
+	provider  string
+	format    reply.Format  // The format negotiated with the client, if the route supports several
+	table     *reply.Table  // The tabular form of the result, for the CSV format
+	message   proto.Message // The typed message of the result, for the Protobuf format
+}
+
+// withSource records whether the result came from the cache, and from which provider, for the v2 metadata.
+// A result served from the cache can be kept by the client for as long as its rates stay there (see cacheMaxAge).
+func (res *Response) withSource(cached bool, provider *string, base string, quotes ...string) *Response {
+	res.cached = &cached
+	if provider != nil {
+		res.provider = *provider
+	}
+	if maxAge, ok := cacheMaxAge(base, quotes); cached && ok {
+		// The prices depend on the spreads of the API key, so only the client may keep the result
+		res.withHeader("Cache-Control", fmt.Sprintf("private, max-age=%d", maxAge))
+	}
+	return res
+}
+
+// cacheMaxAge returns the shortest time (in seconds) the rates of the pairs stay in the rate cache.
+// Returns false when a rate is not cached, or is forced by a manual override, which may be removed at any time.
+func cacheMaxAge(base string, quotes []string) (int, bool) {
+	maxAge := -1
+	for _, quote := range quotes {
+		entry := ratecache.GetInstance().GetEntry(base, quote)
+		if entry == nil || overrides.Get(base, quote) != nil {
+			return 0, false
+		}
+		if maxAge < 0 || entry.ExpiresIn < maxAge {
+			maxAge = entry.ExpiresIn
+		}
+	}
+	return maxAge, maxAge > 0
+}
+
+// Handler handles a request, whichever router received it
+type Handler func(req *Request) *Response
+
+// Result returns a successful response, in the standard response shape
+func Result(data interface{}) *Response {
+	return &Response{Status: http.StatusOK, Body: reply.Result(data), enveloped: true, result: data}
+}
+
+// Error returns an error response, in the standard response shape, with the given status code.
+// The data is a message, or an error. An error of the catalogue is sent with the status, public message and
+// retryable flag of its entry, as {"code", "message", "retryable"}, and other errors as their message (see e.Public).
+func Error(status int, data interface{}) *Response {
+	err, ok := data.(error)
+	if !ok {
+		return &Response{Status: status, Body: reply.Error(data), enveloped: true, failure: data}
+	}
+
+	public := e.Public(err, status)
+	data = public.Message
+	if public.Code != "" {
+		data = Map{"code": public.Code, "message": public.Message, "retryable": public.Retryable}
+	}
+	return &Response{Status: public.Status, Body: reply.Error(data), enveloped: true, failure: public}
+}
+
+// Route is a route served by every router. Paths use the :param syntax shared by Gin and Fiber.
+type Route struct {
+	Method  string
+	Path    string
+	Handler Handler
+	Admin   bool       // Requires the admin bearer token
+	Version APIVersion // The version of the API the route belongs to, if any
+}
+
+// Routes returns the routes to serve, with the optional ones only if enabled in the config.
+// The API routes are served without a prefix, as they always were, and under the prefix of each version.
+// The Negotiated routes send their results in the format negotiated with the client, in the shape of their version.
+// The stream is not in the list, as it writes to the connection itself and is served by each router (see StreamRoutes).
+func Routes(cfg *config.Config) []Route {
+	api := apiRoutes(cfg)
+	routes := []Route{{Method: http.MethodGet, Path: "/favicon.ico", Handler: Favicon(cfg)}}
+	for _, route := range api {
+		route.Handler = encoded(route.Handler)
+		routes = append(routes, route)
+	}
+	for _, version := range Versions {
+		for _, route := range api {
+			route.Path = version.Prefix() + route.Path
+			route.Handler = encoded(Versioned(cfg, version, route.Handler))
+			route.Version = version
+			routes = append(routes, route)
+		}
+	}
+
+	// The OpenAPI document, and its interactive docs, only if enabled
+	if cfg.OpenAPI.Enabled {
+		routes = append(routes, Route{Method: http.MethodGet, Path: "/openapi.json", Handler: OpenAPI(cfg)})
+		if cfg.OpenAPI.Docs {
+			routes = append(routes, Route{Method: http.MethodGet, Path: "/docs", Handler: Docs(cfg)})
+		}
+	}
+
+	return routes
+}
+
+// StreamRoutes returns the streaming routes, without a prefix and under the prefix of each version, only if enabled
+func StreamRoutes(cfg *config.Config) []Route {
+	if !cfg.Stream.Enabled {
+		return nil
+	}
+	routes := []Route{{Method: http.MethodGet, Path: "/stream"}}
+	for _, version := range Versions {
+		routes = append(routes, Route{Method: http.MethodGet, Path: version.Prefix() + "/stream", Version: version})
+	}
+	return routes
+}
+
+// apiRoutes returns the routes of the API, without a version prefix
+func apiRoutes(cfg *config.Config) []Route {
+	routes := []Route{
+		{Method: http.MethodGet, Path: "/rate/:from/:to", Handler: Negotiated(GetRate(cfg))},
+		{Method: http.MethodGet, Path: "/rate/:from/:to/history", Handler: Negotiated(GetRateHistory(cfg))}, // ?start=2024-01-01&end=2024-02-01&interval=1d
+		{Method: http.MethodGet, Path: "/rates", Handler: Negotiated(GetRates(cfg))},                        // ?from=USD&to=EUR,GBP
+		{Method: http.MethodGet, Path: "/rates/:date", Handler: Negotiated(GetRatesOnDate(cfg))},            // ?base=USD&quote=EUR,GBP
+		{Method: http.MethodGet, Path: "/convert", Handler: Convert(cfg)},                                   // ?from=USD&to=JPY&amount=1234.56
+		{Method: http.MethodPost, Path: "/convert", Handler: ConvertBatch(cfg)},                             // [{"from":"USD","to":"JPY","amount":1234.56}]
+		{Method: http.MethodGet, Path: "/matrix", Handler: Negotiated(GetMatrix(cfg))},                      // ?currencies=USD,EUR,GBP,JPY
+		{Method: http.MethodGet, Path: "/currencies", Handler: ListCurrencies(cfg)},
+		{Method: http.MethodGet, Path: "/status", Handler: GetStatus(cfg)},
+		{Method: http.MethodGet, Path: "/health", Handler: HealthCheck(cfg)},
+	}
+
+	// The quote routes, only if enabled
+	if cfg.Quotes.Enabled {
+		routes = append(routes,
+			Route{Method: http.MethodPost, Path: "/quotes", Handler: CreateQuote(cfg)}, // {"from":"USD","to":"EUR","amount":1000}
+			Route{Method: http.MethodGet, Path: "/quotes/:id", Handler: GetQuote(cfg)},
+			Route{Method: http.MethodPost, Path: "/quotes/:id/accept", Handler: AcceptQuote(cfg)},
+		)
+	}
+
+	// The webhook routes, only if enabled
+	if cfg.Alerts.Enabled {
+		routes = append(routes,
+			Route{Method: http.MethodPost, Path: "/subscriptions", Handler: CreateSubscription(cfg)}, // {"from":"EUR","to":"USD","condition":"percent","threshold":0.5,"url":"https://..."}
+			Route{Method: http.MethodGet, Path: "/subscriptions", Handler: ListSubscriptions(cfg)},
+			Route{Method: http.MethodGet, Path: "/subscriptions/:id", Handler: GetSubscription(cfg)},
+			Route{Method: http.MethodDelete, Path: "/subscriptions/:id", Handler: DeleteSubscription(cfg)},
+		)
+	}
+
+	// The GraphQL route, only if enabled
+	if cfg.GraphQL.Enabled {
+		routes = append(routes,
+			Route{Method: http.MethodGet, Path: "/graphql", Handler: GraphQL(cfg)},  // ?query={rate(base:"USD",quote:"EUR"){rate}}
+			Route{Method: http.MethodPost, Path: "/graphql", Handler: GraphQL(cfg)}, // {"query":"...","operationName":"...","variables":{...}}
+		)
+	}
+
+	// The JSON-RPC route, only if enabled
+	if cfg.JSONRPC.Enabled {
+		routes = append(routes,
+			Route{Method: http.MethodPost, Path: "/rpc", Handler: JSONRPC(cfg)}, // {"jsonrpc":"2.0","method":"rates.get","params":{"base":"USD","quote":"EUR"},"id":1}
+		)
+	}
+
+	// The admin routes, only if enabled
+	if cfg.Admin.Enabled {
+		routes = append(routes,
+			Route{Method: http.MethodGet, Path: "/admin/cache", Handler: ListCache(cfg), Admin: true},
+			Route{Method: http.MethodDelete, Path: "/admin/cache", Handler: ClearCache(cfg), Admin: true},
+			Route{Method: http.MethodDelete, Path: "/admin/cache/:from", Handler: InvalidateCacheBase(cfg), Admin: true},
+			Route{Method: http.MethodGet, Path: "/admin/cache/:from/:to", Handler: GetCacheEntry(cfg), Admin: true},
+			Route{Method: http.MethodDelete, Path: "/admin/cache/:from/:to", Handler: InvalidateCachePair(cfg), Admin: true},
+			Route{Method: http.MethodPost, Path: "/admin/cache/:from/:to/refresh", Handler: RefreshCachePair(cfg), Admin: true}, // ?provider=FixerApi or ?strategy=race
+			Route{Method: http.MethodPost, Path: "/admin/history/:from/backfill", Handler: BackfillHistory(cfg), Admin: true},   // ?quote=EUR,GBP&start=2024-01-01&end=2024-06-30
+			Route{Method: http.MethodGet, Path: "/admin/overrides", Handler: ListOverrides(cfg), Admin: true},
+			Route{Method: http.MethodGet, Path: "/admin/overrides/audit", Handler: GetOverrideAudit(cfg), Admin: true},           // ?limit=100
+			Route{Method: http.MethodPut, Path: "/admin/overrides/:from/:to", Handler: SetOverride(cfg), Admin: true},            // {"rate":7.8,"reason":"HKD peg","author":"treasury"}
+			Route{Method: http.MethodPost, Path: "/admin/overrides/:from/:to/expire", Handler: ExpireOverride(cfg), Admin: true}, // {"reason":"...","author":"...","expiresAt":"..."}
+			Route{Method: http.MethodDelete, Path: "/admin/overrides/:from/:to", Handler: DeleteOverride(cfg), Admin: true},      // {"reason":"...","author":"..."}
+		)
+	}
+
+	return routes
+}
+
+// NotFound handles the requests which match no route
+func NotFound(cfg *config.Config) Handler {
+	return func(req *Request) *Response {
+		return Error(http.StatusNotFound, e.FromCode("eRqNf1"))
+	}
+}
@@ -0,0 +1,45 @@
+package coreHandlers
+
+import (
+	"fx-service/internal/service/providers"
+	"fx-service/pkg/config"
+	"fx-service/pkg/currency"
+)
+
+// currencyMap builds the response for a single currency, with the providers which support it
+func currencyMap(cfg *config.Config, code string) Map {
+	ccy, ok := currency.Lookup(code)
+	if !ok {
+		ccy = currency.Currency{Code: code, Countries: []string{}}
+	}
+	supportedBy := providers.SupportedBy(code)
+
+	result := Map{
+		"code":       ccy.Code,
+		"numeric":    ccy.Numeric,
+		"name":       ccy.Name,
+		"minorUnits": ccy.MinorUnits,
+		"symbol":     ccy.Symbol,
+		"countries":  ccy.Countries,
+		"iso":        ccy.ISO,
+		"supported":  len(supportedBy) > 0,
+	}
+	if cfg.ShowProvider {
+		result["providers"] = supportedBy
+	}
+	return result
+}
+
+// ListCurrencies returns the enabled currencies, with their metadata and whether any enabled provider supports them
+func ListCurrencies(cfg *config.Config) Handler {
+	return func(req *Request) *Response {
+		currencies := make([]Map, 0, len(cfg.CurrenciesEnabled))
+		for _, code := range cfg.CurrenciesEnabled {
+			currencies = append(currencies, currencyMap(cfg, code))
+		}
+
+		return Result(Map{
+			"currencies": currencies,
+		})
+	}
+}
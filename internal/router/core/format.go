@@ -0,0 +1,251 @@
+package coreHandlers
+
+import (
+	"net/http"
+	"time"
+
+	"fx-service/internal/reply"
+	"fx-service/internal/service/history"
+	"fx-service/internal/service/rates"
+	"fx-service/pkg/decimal"
+	"fx-service/pkg/fxpb"
+	util "fx-service/pkg/helpers"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Negotiated serves the results of a handler in the format asked for by the format= parameter or the Accept header:
+// JSON, CSV, XML, MessagePack or Protobuf. Errors are always sent as JSON.
+func Negotiated(handler Handler) Handler {
+	return func(req *Request) *Response {
+		format, err := reply.Negotiate(req.Header.Get("Accept"), req.QueryValue("format"))
+		if err != nil {
+			return Error(http.StatusBadRequest, err).withHeader("Vary", "Accept")
+		}
+		res := handler(req)
+		res.format = format
+		return res.withHeader("Vary", "Accept")
+	}
+}
+
+// encoded sends the result of a response in the format it was negotiated in, once it is in the shape of its version
+func encoded(handler Handler) Handler {
+	return func(req *Request) *Response {
+		res := handler(req)
+		if res.format == "" || res.format == reply.JSON || !res.enveloped || res.failure != nil {
+			return res
+		}
+
+		raw, err := reply.Encode(res.format, res.Body, res.table, res.message)
+		if err != nil {
+			return Error(http.StatusInternalServerError, err)
+		}
+		res.Raw = raw
+		return res.withHeader("Content-Type", res.format.ContentType())
+	}
+}
+
+// withHeader sets a header of the response
+func (res *Response) withHeader(key, value string) *Response {
+	if res.Header == nil {
+		res.Header = map[string]string{}
+	}
+	res.Header[key] = value
+	return res
+}
+
+// withTable sets the tabular form of the result, for the CSV format
+func (res *Response) withTable(header []string, rows ...[]interface{}) *Response {
+	res.table = &reply.Table{Header: header, Rows: rows}
+	return res
+}
+
+// withMessage sets the typed message of the result, for the Protobuf format
+func (res *Response) withMessage(message proto.Message) *Response {
+	res.message = message
+	return res
+}
+
+// resultRow returns the columns of a result which it has, in order, and their values
+func resultRow(result Map, columns ...string) ([]string, []interface{}) {
+	var header []string
+	var row []interface{}
+	for _, column := range columns {
+		if value, ok := result[column]; ok {
+			header = append(header, column)
+			row = append(row, value)
+		}
+	}
+	return header, row
+}
+
+// ratesRows returns a row for each quote currency of a rates result, in the order asked for, with its prices if any
+func ratesRows(base string, quotes []string, rateList map[string]decimal.Decimal, prices Map, overrides []string) ([]string, [][]interface{}) {
+	header := []string{"base", "quote", "rate"}
+	if prices != nil {
+		header = append(header, "bid", "mid", "ask")
+	}
+	header = append(header, "override")
+
+	var rows [][]interface{}
+	for _, quote := range quotes {
+		rate, ok := rateList[quote]
+		if !ok {
+			continue
+		}
+		row := []interface{}{base, quote, rate}
+		if price, ok := prices[quote].(Map); ok {
+			row = append(row, price["bid"], price["mid"], price["ask"])
+		}
+		rows = append(rows, append(row, util.SliceContains(overrides, quote)))
+	}
+	return header, rows
+}
+
+// matrixRows returns a row for each base currency of a matrix, with its rate to each quote currency in a column.
+// Unavailable rates are empty.
+func matrixRows(matrix *rates.GetMatrixResult, currencies []string) ([]string, [][]interface{}) {
+	header := append([]string{"base"}, currencies...)
+	rows := make([][]interface{}, 0, len(currencies))
+	for _, from := range currencies {
+		row := []interface{}{from}
+		for _, to := range currencies {
+			row = append(row, matrixRate(matrix.Cells[from][to]))
+		}
+		rows = append(rows, row)
+	}
+	return header, rows
+}
+
+// bucketRows returns a row for each bucket of a rate history
+func bucketRows(base, quote string, buckets []history.Bucket) ([]string, [][]interface{}) {
+	header := []string{"base", "quote", "start", "end", "open", "high", "low", "close", "count", "source"}
+	rows := make([][]interface{}, 0, len(buckets))
+	for _, b := range buckets {
+		rows = append(rows, []interface{}{
+			base, quote, b.Start.Format(time.RFC3339), b.End.Format(time.RFC3339), b.Open, b.High, b.Low, b.Close, b.Count, b.Source,
+		})
+	}
+	return header, rows
+}
+
+// priceMessage returns the bid, mid and ask prices of a result for the Protobuf format, or nil without spreads
+func priceMessage(price Map) *fxpb.Price {
+	bid, ok := price["bid"].(decimal.Decimal)
+	if !ok {
+		return nil
+	}
+	mid, _ := price["mid"].(decimal.Decimal)
+	ask, _ := price["ask"].(decimal.Decimal)
+	return &fxpb.Price{Bid: bid.String(), Mid: mid.String(), Ask: ask.String()}
+}
+
+// shownProvider returns the provider of a result when it is shown, or an empty string
+func shownProvider(result Map) string {
+	if provider, ok := result["provider"].(*string); ok && provider != nil {
+		return *provider
+	}
+	return ""
+}
+
+// rateMessage returns the message of a rate result, for the Protobuf format
+func rateMessage(result Map, base, quote string, rateResult *rates.GetRateResult) *fxpb.GetRateResponse {
+	return &fxpb.GetRateResponse{
+		Base:     base,
+		Quote:    quote,
+		Rate:     rateResult.Rate.String(),
+		Cached:   rateResult.WasCached,
+		Override: rateResult.Override,
+		Price:    priceMessage(result),
+		Provider: shownProvider(result),
+	}
+}
+
+// ratesMessage returns the message of a rates result, for the Protobuf format
+func ratesMessage(result Map, base string, rateResult *rates.GetRatesResult, prices Map) *fxpb.GetRatesResponse {
+	message := &fxpb.GetRatesResponse{
+		Base:      base,
+		Rates:     make(map[string]string, len(rateResult.Rates)),
+		Cached:    rateResult.WasCached,
+		Overrides: rateResult.Overrides,
+		Provider:  shownProvider(result),
+	}
+	for quote, rate := range rateResult.Rates {
+		message.Rates[quote] = rate.String()
+	}
+	if prices != nil {
+		message.Prices = make(map[string]*fxpb.Price, len(prices))
+		for quote, price := range prices {
+			if price, ok := price.(Map); ok {
+				message.Prices[quote] = priceMessage(price)
+			}
+		}
+	}
+	return message
+}
+
+// ratesOnDateMessage returns the message of the rates on a past date, for the Protobuf format
+func ratesOnDateMessage(base, day string, ratesResult *rates.GetRatesOnResult) *fxpb.RatesOnDate {
+	message := &fxpb.RatesOnDate{
+		Base:    base,
+		Date:    day,
+		Rates:   make(map[string]string, len(ratesResult.Rates)),
+		Sources: ratesResult.Sources,
+	}
+	for quote, rate := range ratesResult.Rates {
+		message.Rates[quote] = rate.String()
+	}
+	return message
+}
+
+// historyMessage returns the message of a rate history, for the Protobuf format
+func historyMessage(base, quote string, historyResult *rates.GetHistoryResult) *fxpb.RateHistory {
+	message := &fxpb.RateHistory{
+		Base:     base,
+		Quote:    quote,
+		Start:    timestamppb.New(historyResult.Start),
+		End:      timestamppb.New(historyResult.End),
+		Interval: historyResult.Interval.String(),
+		Change:   historyResult.Change,
+		Buckets:  make([]*fxpb.HistoryBucket, len(historyResult.Buckets)),
+	}
+	for i, b := range historyResult.Buckets {
+		message.Buckets[i] = &fxpb.HistoryBucket{
+			Start:  timestamppb.New(b.Start),
+			End:    timestamppb.New(b.End),
+			Open:   b.Open.String(),
+			High:   b.High.String(),
+			Low:    b.Low.String(),
+			Close:  b.Close.String(),
+			Count:  int32(b.Count),
+			Source: b.Source,
+		}
+	}
+	return message
+}
+
+// matrixMessage returns the message of a matrix, for the Protobuf format. Unavailable rates are empty.
+func matrixMessage(showProvider bool, matrix *rates.GetMatrixResult, currencies []string) *fxpb.RateMatrix {
+	message := &fxpb.RateMatrix{
+		Currencies: currencies,
+		Rates:      make(map[string]*fxpb.MatrixRow, len(currencies)),
+		Calls:      int32(matrix.Calls),
+		Pivot:      matrix.Pivot,
+	}
+	for _, from := range currencies {
+		row := &fxpb.MatrixRow{Cells: make(map[string]*fxpb.MatrixCell, len(currencies))}
+		for _, to := range currencies {
+			cell := matrix.Cells[from][to]
+			entry := &fxpb.MatrixCell{Source: cell.Source, Age: int32(cell.Age)}
+			if cell.Source != rates.MatrixSourceUnavailable {
+				entry.Rate = cell.Rate.String()
+			}
+			if showProvider && cell.Provider != nil {
+				entry.Provider = *cell.Provider
+			}
+			row.Cells[to] = entry
+		}
+		message.Rates[from] = row
+	}
+	return message
+}
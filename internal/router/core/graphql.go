@@ -0,0 +1,30 @@
+package coreHandlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	graphqlHandlers "fx-service/internal/router/graphql"
+	"fx-service/pkg/config"
+	"fx-service/pkg/e"
+)
+
+// GraphQL runs a GraphQL query, sent as a JSON body {"query", "operationName", "variables"}, or as query parameters.
+// The result is sent as GraphQL expects, {"data", "errors"}, and not in the usual response envelope.
+func GraphQL(cfg *config.Config) Handler {
+	return func(req *Request) *Response {
+		var gqlReq graphqlHandlers.Request
+		if req.Method == http.MethodGet {
+			var err error
+			gqlReq, err = graphqlHandlers.NewRequest(req.QueryValue("query"), req.QueryValue("operationName"), req.QueryValue("variables"))
+			if err != nil {
+				return Error(http.StatusBadRequest, err)
+			}
+		} else if err := json.Unmarshal(req.Body, &gqlReq); err != nil {
+			return Error(http.StatusBadRequest, e.FromCode("eRqBd2", `{"query", "operationName", "variables"}`))
+		}
+
+		result := graphqlHandlers.Execute(req.Context, cfg, gqlReq, req.APIKey())
+		return &Response{Status: http.StatusOK, Body: result}
+	}
+}
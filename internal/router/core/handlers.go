@@ -0,0 +1,164 @@
+package coreHandlers
+
+import (
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"fx-service/internal/service/providers"
+	"fx-service/internal/service/ratecache"
+	"fx-service/internal/service/rates"
+	"fx-service/internal/service/spreads"
+	"fx-service/internal/service/stats"
+	"fx-service/internal/validate"
+	"fx-service/pkg/config"
+	"fx-service/pkg/e"
+	util "fx-service/pkg/helpers"
+)
+
+// faviconPath is the icon sent to browsers, relative to the working directory
+const faviconPath = "./public/favicon.ico"
+
+// validateAndParseCurrencies checks for case sensitivity and whether the currencies in the path are supported
+func validateAndParseCurrencies(cfg *config.Config, req *Request) (string, string, error) {
+	// Get the currency codes from the URL
+	return validate.CurrencyPair(cfg, req.Param("from"), req.Param("to"))
+}
+
+// GetRate returns the exchange rate between two currencies
+func GetRate(cfg *config.Config) Handler {
+	return func(req *Request) *Response {
+		ccyBase, ccyQuote, err := validateAndParseCurrencies(cfg, req)
+		if err != nil {
+			return Error(http.StatusBadRequest, err)
+		}
+
+		rateResult, err := rates.GetRate(ccyBase, ccyQuote, cfg.Mode)
+		if err != nil {
+			return Error(http.StatusInternalServerError, err)
+		}
+
+		result := Map{
+			"base":     ccyBase,
+			"quote":    ccyQuote,
+			"rate":     rateResult.Rate,
+			"cached":   rateResult.WasCached,
+			"override": rateResult.Override,
+		}
+		addPrices(req, result, ccyBase, ccyQuote, rateResult.Rate)
+
+		if cfg.ShowProvider {
+			result["provider"] = rateResult.Provider
+		}
+
+		header, row := resultRow(result, "base", "quote", "rate", "bid", "mid", "ask", "cached", "override", "provider")
+		return Result(result).withSource(rateResult.WasCached, rateResult.Provider, ccyBase, ccyQuote).withTable(header, row).
+			withMessage(rateMessage(result, ccyBase, ccyQuote, rateResult))
+	}
+}
+
+// parseBaseAndQuotes checks for case sensitivity and whether the given base and comma-delimited quote currencies
+// are supported
+func parseBaseAndQuotes(cfg *config.Config, ccyBase, quotes string) (string, []string, error) {
+	if quotes == "" {
+		return "", nil, e.FromCode("eRqCm1")
+	}
+	return validate.BaseAndQuotes(cfg, ccyBase, strings.Split(quotes, ","))
+}
+
+// parseQueryBaseAndQuotes parses the base and quote currencies of the query: ?base=USD&quote=EUR,GBP,
+// or ?from=USD&to=EUR,GBP as documented
+func parseQueryBaseAndQuotes(cfg *config.Config, req *Request) (string, []string, error) {
+	return parseBaseAndQuotes(cfg, req.QueryValue("base", "from"), req.QueryValue("quote", "to"))
+}
+
+// GetRates returns the exchange rates between a base currency and multiple quote currencies
+func GetRates(cfg *config.Config) Handler {
+	return func(req *Request) *Response {
+		ccyBase, ccyQuoteList, err := parseQueryBaseAndQuotes(cfg, req)
+		if err != nil {
+			return Error(http.StatusBadRequest, err)
+		}
+
+		// Get the rates from the provider (or from the cache) using the current strategy
+		rateResult, err := rates.GetRates(ccyBase, ccyQuoteList, cfg.Mode)
+		if err != nil {
+			// TODO parse the different kinds of error and give friendly API responses
+			//  instead of just returning the error message to the front end
+			return Error(http.StatusInternalServerError, err)
+		}
+
+		result := Map{
+			"base":   ccyBase,
+			"quotes": rateResult.Rates,
+			"cached": rateResult.WasCached,
+		}
+		if len(rateResult.Overrides) > 0 {
+			result["overrides"] = rateResult.Overrides
+		}
+		var prices Map
+		if spreads.Enabled() {
+			prices = quotePrices(req, ccyBase, rateResult.Rates)
+			result["prices"] = prices
+		}
+
+		if cfg.ShowProvider {
+			result["provider"] = rateResult.Provider
+		}
+
+		header, rows := ratesRows(ccyBase, ccyQuoteList, rateResult.Rates, prices, rateResult.Overrides)
+		return Result(result).withSource(rateResult.WasCached, rateResult.Provider, ccyBase, ccyQuoteList...).withTable(header, rows...).
+			withMessage(ratesMessage(result, ccyBase, rateResult, prices))
+	}
+}
+
+// GetStatus returns the strategy mode, the request counts, the providers and the cache expiry
+func GetStatus(cfg *config.Config) Handler {
+	return func(req *Request) *Response {
+		modeName := cfg.Mode.String()
+		enabled := util.GetMapKeys(providers.EnabledProviders)
+		available := util.GetMapKeys(providers.InstalledProviders)
+		rc := ratecache.GetInstance()
+		return Result(Map{
+			"mode":  modeName,
+			"stats": stats.GetInstance().GetStats(),
+			"providers": Map{
+				"enabled":   enabled,
+				"available": available,
+			},
+			"cache": Map{
+				"defaultTtl":   int(rc.GetExpiry().Seconds()),
+				"ttlRules":     rc.GetTTLRules(),
+				"effectiveTtl": rc.EffectiveTTLs(cfg.CurrenciesEnabled),
+			},
+		})
+	}
+}
+
+// HealthCheck tells load balancers and orchestrators the service is up
+func HealthCheck(cfg *config.Config) Handler {
+	return func(req *Request) *Response {
+		return Result(Map{
+			"status": "healthy",
+		})
+	}
+}
+
+// Favicon handles favicon requests from browsers
+func Favicon(cfg *config.Config) Handler {
+	return func(req *Request) *Response {
+		if _, err := os.Stat(faviconPath); err != nil {
+			return Error(http.StatusNotFound, e.FromCode("eRqNf1"))
+		}
+		return &Response{
+			Status: http.StatusOK,
+			File:   faviconPath,
+			Header: map[string]string{
+				"Content-Type":  "image/x-icon",
+				"Cache-Control": "public, max-age=86400", // Cache for 1 day (86400 seconds)
+				"Expires":       time.Now().Add(24 * time.Hour).Format(http.TimeFormat),
+			},
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package coreHandlers
+
+import (
+	"net/http"
+	"time"
+
+	"fx-service/internal/service/history"
+	"fx-service/internal/service/rates"
+	"fx-service/pkg/config"
+	"fx-service/pkg/e"
+)
+
+// defaultHistoryRange is the range of a history query, when no start is given
+const defaultHistoryRange = 30 * 24 * time.Hour
+
+// GetRateHistory returns OHLC buckets for a currency pair over a time range
+// Query: ?start={date}&end={date}&interval={1h|1d|1w...}. Defaults to the last 30 days, in daily buckets.
+func GetRateHistory(cfg *config.Config) Handler {
+	return func(req *Request) *Response {
+		ccyBase, ccyQuote, err := validateAndParseCurrencies(cfg, req)
+		if err != nil {
+			return Error(http.StatusBadRequest, err)
+		}
+
+		// The range can't go past the current time
+		end := time.Now().UTC()
+		if endStr := req.QueryValue("end"); endStr != "" {
+			if end, err = history.ParseTime(endStr); err != nil {
+				return Error(http.StatusBadRequest, err)
+			}
+			if now := time.Now().UTC(); end.After(now) {
+				end = now
+			}
+		}
+
+		start := end.Add(-defaultHistoryRange)
+		if startStr := req.QueryValue("start"); startStr != "" {
+			if start, err = history.ParseTime(startStr); err != nil {
+				return Error(http.StatusBadRequest, err)
+			}
+		}
+
+		interval := 24 * time.Hour
+		if intervalStr := req.QueryValue("interval"); intervalStr != "" {
+			if interval, err = history.ParseInterval(intervalStr); err != nil {
+				return Error(http.StatusBadRequest, err)
+			}
+		}
+
+		historyResult, err := rates.GetHistory(ccyBase, ccyQuote, start, end, interval, cfg.Mode)
+		if err != nil {
+			return Error(http.StatusInternalServerError, err)
+		}
+
+		if !cfg.ShowProvider {
+			historyResult.HideProviderNames()
+		}
+
+		header, rows := bucketRows(ccyBase, ccyQuote, historyResult.Buckets)
+		return Result(Map{
+			"base":     ccyBase,
+			"quote":    ccyQuote,
+			"start":    historyResult.Start,
+			"end":      historyResult.End,
+			"interval": historyResult.Interval.String(),
+			"change":   historyResult.Change,
+			"buckets":  historyResult.Buckets,
+		}).withTable(header, rows...).withMessage(historyMessage(ccyBase, ccyQuote, historyResult))
+	}
+}
+
+// GetRatesOnDate returns the last observed rates on a past date, for a base and multiple quote currencies
+// Query: ?base=USD&quote=EUR,GBP
+func GetRatesOnDate(cfg *config.Config) Handler {
+	return func(req *Request) *Response {
+		date, err := history.ParseTime(req.Param("date"))
+		if err != nil {
+			return Error(http.StatusBadRequest, err)
+		}
+		if date.After(time.Now()) {
+			return Error(http.StatusBadRequest, e.FromCode("eHsFt1"))
+		}
+
+		ccyBase, ccyQuoteList, err := parseQueryBaseAndQuotes(cfg, req)
+		if err != nil {
+			return Error(http.StatusBadRequest, err)
+		}
+
+		ratesResult, err := rates.GetRatesOn(date, ccyBase, ccyQuoteList, cfg.Mode)
+		if err != nil {
+			return Error(http.StatusInternalServerError, err)
+		}
+
+		if !cfg.ShowProvider {
+			ratesResult.HideProviderNames()
+		}
+
+		day := ratesResult.Date.Format(time.DateOnly)
+		var rows [][]interface{}
+		for _, quote := range ccyQuoteList {
+			if rate, ok := ratesResult.Rates[quote]; ok {
+				rows = append(rows, []interface{}{ccyBase, quote, day, rate, ratesResult.Sources[quote]})
+			}
+		}
+
+		return Result(Map{
+			"base":    ccyBase,
+			"date":    day,
+			"quotes":  ratesResult.Rates,
+			"sources": ratesResult.Sources,
+		}).withTable([]string{"base", "quote", "date", "rate", "source"}, rows...).
+			withMessage(ratesOnDateMessage(ccyBase, day, ratesResult))
+	}
+}
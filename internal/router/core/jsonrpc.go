@@ -0,0 +1,21 @@
+package coreHandlers
+
+import (
+	"net/http"
+
+	jsonrpcHandlers "fx-service/internal/router/jsonrpc"
+	"fx-service/pkg/config"
+)
+
+// JSONRPC runs a JSON-RPC 2.0 call, or a batch of calls, with the methods rates.get, rates.getMany, rates.convert
+// and status.get. The response is sent as JSON-RPC expects, and not in the usual response envelope.
+// Nothing is sent (204 No Content) when every call was a notification.
+func JSONRPC(cfg *config.Config) Handler {
+	return func(req *Request) *Response {
+		response := jsonrpcHandlers.Handle(cfg, req.Body, req.APIKey())
+		if response == nil {
+			return &Response{Status: http.StatusNoContent}
+		}
+		return &Response{Status: http.StatusOK, Body: response}
+	}
+}
@@ -0,0 +1,93 @@
+package coreHandlers
+
+import (
+	"net/http"
+	"strings"
+
+	"fx-service/internal/service/rates"
+	"fx-service/internal/validate"
+	"fx-service/pkg/config"
+	"fx-service/pkg/e"
+	util "fx-service/pkg/helpers"
+)
+
+// parseMatrixCurrencies parses the comma-delimited list of currencies for a matrix, without duplicates.
+// Defaults to every enabled currency, which is bounded the same way.
+func parseMatrixCurrencies(cfg *config.Config, list string) ([]string, error) {
+	var currencies []string
+	if list == "" {
+		currencies = append(currencies, cfg.CurrenciesEnabled...)
+	} else {
+		for _, ccy := range strings.Split(list, ",") {
+			ccy, err := validate.Currency(cfg, ccy)
+			if err != nil {
+				return nil, err
+			}
+			if !util.SliceContains(currencies, ccy) {
+				currencies = append(currencies, ccy)
+			}
+		}
+	}
+
+	if len(currencies) < 2 {
+		return nil, e.FromCode("eMxCn1")
+	}
+	if len(currencies) > rates.MaxMatrixCurrencies {
+		return nil, e.FromCode("eMxCn2", rates.MaxMatrixCurrencies)
+	}
+	return currencies, nil
+}
+
+// matrixRate returns the rate of a matrix cell, or nil when it is unavailable
+func matrixRate(cell rates.MatrixCell) interface{} {
+	if cell.Source == rates.MatrixSourceUnavailable {
+		return nil
+	}
+	return cell.Rate
+}
+
+// GetMatrix returns the rates between every pair of currencies, with the source and age of each rate
+// Query: ?currencies=USD,EUR,GBP,JPY (defaults to every enabled currency)
+func GetMatrix(cfg *config.Config) Handler {
+	return func(req *Request) *Response {
+		currencies, err := parseMatrixCurrencies(cfg, req.QueryValue("currencies"))
+		if err != nil {
+			return Error(http.StatusBadRequest, err)
+		}
+
+		matrix, err := rates.GetMatrix(currencies, cfg.Mode)
+		if err != nil {
+			return Error(http.StatusInternalServerError, err)
+		}
+
+		table := make(Map, len(currencies))
+		for _, from := range currencies {
+			row := make(Map, len(currencies))
+			for _, to := range currencies {
+				cell := matrix.Cells[from][to]
+				entry := Map{
+					"rate":   matrixRate(cell),
+					"source": cell.Source,
+					"age":    cell.Age,
+				}
+				if cfg.ShowProvider && cell.Provider != nil {
+					entry["provider"] = cell.Provider
+				}
+				row[to] = entry
+			}
+			table[from] = row
+		}
+
+		result := Map{
+			"currencies": currencies,
+			"rates":      table,
+			"calls":      matrix.Calls,
+		}
+		if matrix.Pivot != "" {
+			result["pivot"] = matrix.Pivot
+		}
+
+		header, rows := matrixRows(matrix, currencies)
+		return Result(result).withTable(header, rows...).withMessage(matrixMessage(cfg.ShowProvider, matrix, currencies))
+	}
+}
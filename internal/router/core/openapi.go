@@ -0,0 +1,389 @@
+package coreHandlers
+
+import (
+	_ "embed"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"fx-service/pkg/config"
+	"fx-service/pkg/e"
+	util "fx-service/pkg/helpers"
+)
+
+// openAPIVersion is the version of the OpenAPI specification the document follows
+const openAPIVersion = "3.0.3"
+
+// docsPage is the interactive documentation, a self-contained page which renders the OpenAPI document
+//
+//go:embed docs.html
+var docsPage []byte
+
+// pathParams describes the path parameters shared by the routes
+var pathParams = map[string]Map{
+	"from": {"description": "The base currency", "schema": ref("CurrencyCode")},
+	"to":   {"description": "The quote currency", "schema": ref("CurrencyCode")},
+	"date": {"description": "A past date, as 2024-01-31 or an RFC 3339 time", "schema": Map{"type": "string"}},
+	"id":   {"description": "The ID returned when it was created", "schema": Map{"type": "string"}},
+}
+
+// OpenAPI serves the OpenAPI 3 document of the routes enabled in the config
+func OpenAPI(cfg *config.Config) Handler {
+	// Built on the first request, as the routes include this one
+	var once sync.Once
+	var document Map
+	return func(req *Request) *Response {
+		once.Do(func() {
+			document = BuildOpenAPI(cfg)
+		})
+		return &Response{Status: http.StatusOK, Body: document}
+	}
+}
+
+// Docs serves the interactive documentation of the API, rendered from /openapi.json
+func Docs(cfg *config.Config) Handler {
+	return func(req *Request) *Response {
+		return &Response{
+			Status: http.StatusOK,
+			Raw:    docsPage,
+			Header: map[string]string{"Content-Type": "text/html; charset=utf-8"},
+		}
+	}
+}
+
+// ServedRoutes returns every route served for the config: the shared routes, and the streaming routes if enabled
+func ServedRoutes(cfg *config.Config) []Route {
+	return append(Routes(cfg), StreamRoutes(cfg)...)
+}
+
+// OpenAPIPath converts a route path from the :param syntax to the {param} syntax of OpenAPI
+func OpenAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if name, found := strings.CutPrefix(segment, ":"); found {
+			segments[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// BuildOpenAPI returns the OpenAPI 3 document of the routes enabled in the config, with their parameters,
+// response shapes and error codes
+func BuildOpenAPI(cfg *config.Config) Map {
+	routes := ServedRoutes(cfg)
+
+	// The routes without a prefix which are also served under the prefix of a version
+	versioned := map[string]bool{}
+	for _, route := range routes {
+		if route.Version == V1 {
+			versioned[route.Method+" "+strings.TrimPrefix(route.Path, V1.Prefix())] = true
+		}
+	}
+
+	ops := map[APIVersion]map[string]Map{}
+	paths := Map{}
+	for _, route := range routes {
+		if ops[route.Version] == nil {
+			ops[route.Version] = operations(route.Version)
+		}
+		op, ok := ops[route.Version][route.Method+" "+strings.TrimPrefix(route.Path, route.Version.Prefix())]
+		if !ok {
+			op = Map{"summary": route.Method + " " + route.Path, "responses": Map{"200": Map{"description": "OK"}}}
+		}
+
+		switch {
+		case route.Version != Unversioned:
+			if id, ok := op["operationId"].(string); ok {
+				op["operationId"] = "v" + strconv.Itoa(int(route.Version)) + strings.ToUpper(id[:1]) + id[1:]
+			}
+			op["tags"] = versionTags(op, route.Version)
+		case versioned[route.Method+" "+route.Path]:
+			op["deprecated"] = true
+			op["description"] = strings.TrimSpace(fmt.Sprint(op["description"], " Served as "+V1.Prefix()+route.Path+"."))
+		}
+
+		var params []interface{}
+		for _, segment := range strings.Split(route.Path, "/") {
+			if name, found := strings.CutPrefix(segment, ":"); found {
+				param := Map{"name": name, "in": "path", "required": true}
+				for key, value := range pathParams[name] {
+					param[key] = value
+				}
+				params = append(params, param)
+			}
+		}
+		if query, ok := op["parameters"].([]interface{}); ok {
+			params = append(params, query...)
+		}
+		if len(params) > 0 {
+			op["parameters"] = params
+		}
+
+		responses := op["responses"].(Map)
+		responses["429"] = responseRef("TooManyRequests")
+		if route.Admin {
+			responses["401"] = responseRef("Unauthorized")
+			op["security"] = []Map{{"adminToken": []string{}}}
+		}
+
+		path := OpenAPIPath(route.Path)
+		item, _ := paths[path].(Map)
+		if item == nil {
+			item = Map{}
+			paths[path] = item
+		}
+		item[strings.ToLower(route.Method)] = op
+	}
+
+	return Map{
+		"openapi": openAPIVersion,
+		"info": Map{
+			"title":       "FX service",
+			"version":     "1.0.0",
+			"description": "Exchange rates from several providers, with caching, conversions, quotes and alerts.",
+		},
+		"servers": []Map{{"url": ".", "description": "Where this document is served"}},
+		"paths":   paths,
+		"components": Map{
+			"schemas":   schemas(cfg),
+			"responses": errorResponses(),
+			"securitySchemes": Map{
+				"adminToken": Map{"type": "http", "scheme": "bearer", "description": "The admin token of the config"},
+				"apiKey":     Map{"type": "apiKey", "in": "header", "name": apiKeyHeader, "description": "Identifies the client, for client-specific spreads, quotes and subscriptions"},
+			},
+		},
+		"security": []Map{{}, {"apiKey": []string{}}},
+	}
+}
+
+// versionTags prefixes the tags of an operation with the version of its route, e.g. "v2 Rates"
+func versionTags(op Map, version APIVersion) []string {
+	tags, _ := op["tags"].([]string)
+	prefixed := make([]string, len(tags))
+	for i, tag := range tags {
+		prefixed[i] = version.Prefix()[1:] + " " + tag
+	}
+	return prefixed
+}
+
+// ref refers to a schema of the components
+func ref(name string) Map {
+	return Map{"$ref": "#/components/schemas/" + name}
+}
+
+// responseRef refers to a response of the components
+func responseRef(name string) Map {
+	return Map{"$ref": "#/components/responses/" + name}
+}
+
+// object returns an object schema, with every property required unless listed as optional
+func object(properties Map, optional ...string) Map {
+	var required []string
+	for name := range properties {
+		if !util.SliceContains(optional, name) {
+			required = append(required, name)
+		}
+	}
+	sort.Strings(required)
+	schema := Map{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// array returns the schema of a list of items
+func array(items Map) Map {
+	return Map{"type": "array", "items": items}
+}
+
+// mapOf returns the schema of an object keyed by currency, or any other string
+func mapOf(values Map) Map {
+	return Map{"type": "object", "additionalProperties": values}
+}
+
+// enum returns the schema of a string with a fixed set of values
+func enum(values ...string) Map {
+	return Map{"type": "string", "enum": values}
+}
+
+// typed returns the schema of a value of a JSON type, with a description
+func typed(kind, description string) Map {
+	schema := Map{"type": kind}
+	if description != "" {
+		schema["description"] = description
+	}
+	return schema
+}
+
+// nullable returns a schema which also allows null
+func nullable(schema Map) Map {
+	if _, isRef := schema["$ref"]; isRef {
+		return Map{"allOf": []Map{schema}, "nullable": true}
+	}
+	schema["nullable"] = true
+	return schema
+}
+
+// queryParam describes a query parameter
+func queryParam(name, description string, required bool, schema Map) Map {
+	return Map{"name": name, "in": "query", "description": description, "required": required, "schema": schema}
+}
+
+// jsonBody describes a JSON request body
+func jsonBody(schema Map) Map {
+	return Map{"required": true, "content": Map{"application/json": Map{"schema": schema}}}
+}
+
+// resultResponse describes a successful response, with the data in the response shape of the version
+func resultResponse(version APIVersion, description, schema string) Map {
+	if version == V2 {
+		return Map{
+			"description": description,
+			"headers":     requestIDHeaders(),
+			"content": Map{"application/json": Map{"schema": object(Map{
+				"result": ref(schema),
+				"error":  Map{"nullable": true, "description": "Always null", "type": "object"},
+				"meta":   ref("Meta"),
+			})}},
+		}
+	}
+	return Map{
+		"description": description,
+		"content": Map{"application/json": Map{"schema": object(Map{
+			"result": ref(schema),
+		})}},
+	}
+}
+
+// requestIDHeaders describes the request ID header of the v2 responses
+func requestIDHeaders() Map {
+	return Map{requestIDHeader: Map{
+		"description": "The request ID, as sent by the client or generated",
+		"schema":      Map{"type": "string"},
+	}}
+}
+
+// responses lists the responses of an operation: a successful one, and the errors it may return in the shape of the version
+func responses(version APIVersion, success Map, errors ...int) Map {
+	list := Map{"200": success}
+	for _, status := range errors {
+		name := errorResponseNames[status]
+		if version == V2 {
+			name += "V2"
+		}
+		list[strconv.Itoa(status)] = responseRef(name)
+	}
+	return list
+}
+
+// errorResponseNames are the names of the error responses of the components, by status
+var errorResponseNames = map[int]string{
+	http.StatusBadRequest:          "BadRequest",
+	http.StatusUnauthorized:        "Unauthorized",
+	http.StatusForbidden:           "Forbidden",
+	http.StatusNotFound:            "NotFound",
+	http.StatusConflict:            "Conflict",
+	http.StatusGone:                "Gone",
+	http.StatusTooManyRequests:     "TooManyRequests",
+	http.StatusInternalServerError: "InternalError",
+	http.StatusServiceUnavailable:  "Unavailable",
+}
+
+// errorResponses returns the error responses of the components, in the standard response shape and in the v2 shape
+func errorResponses() Map {
+	list := Map{}
+	for status, name := range errorResponseNames {
+		list[name] = Map{
+			"description": http.StatusText(status),
+			"content":     Map{"application/json": Map{"schema": ref("Error")}},
+		}
+		list[name+"V2"] = Map{
+			"description": http.StatusText(status),
+			"headers":     requestIDHeaders(),
+			"content":     Map{"application/json": Map{"schema": ref("ErrorV2")}},
+		}
+	}
+	return list
+}
+
+// errorSummary returns the status of an error of the catalogue, and whether it is retryable, e.g. "503, retryable"
+func errorSummary(entry e.Entry) string {
+	status := entry.Status
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+	if entry.Retryable {
+		return strconv.Itoa(status) + ", retryable"
+	}
+	return strconv.Itoa(status)
+}
+
+// schemas returns the schemas of the components, for the currencies and the decimal output of the config
+func schemas(cfg *config.Config) Map {
+	decimal := Map{"type": "string", "description": "An exact decimal number, as a string", "example": "1.0842"}
+	if cfg.FloatOutput {
+		decimal = Map{"type": "number", "description": "A decimal number", "example": 1.0842}
+	}
+
+	var codes, descriptions []string
+	for code, entry := range e.Catalogue() {
+		codes = append(codes, code)
+		message := entry.Public
+		if message == "" {
+			message = entry.Message
+		}
+		descriptions = append(descriptions, "- `"+code+"` ("+errorSummary(entry)+"): "+message)
+	}
+	sort.Strings(codes)
+	sort.Strings(descriptions)
+
+	errorCode := Map{"type": "string", "description": "The error codes of the service:\n\n" + strings.Join(descriptions, "\n")}
+	if len(codes) > 0 {
+		errorCode["enum"] = codes
+	}
+
+	list := Map{
+		"Decimal":      decimal,
+		"DecimalInput": Map{"description": "A decimal number, as a JSON number or string", "oneOf": []Map{{"type": "string"}, {"type": "number"}}},
+		"CurrencyCode": Map{"type": "string", "description": "An enabled ISO 4217 currency code", "enum": append([]string{}, cfg.CurrenciesEnabled...)},
+		"ErrorCode":    errorCode,
+		"ErrorDetail": object(Map{
+			"code":      ref("ErrorCode"),
+			"message":   typed("string", ""),
+			"retryable": typed("boolean", "Whether the same request may succeed later"),
+		}),
+		"Error": object(Map{
+			"result": Map{"nullable": true, "description": "Always null", "type": "object"},
+			"error": Map{
+				"description": "A message, or the code and message of an error of the service",
+				"oneOf":       []Map{{"type": "string"}, ref("ErrorDetail")},
+			},
+		}),
+		"ErrorDetailV2": object(Map{
+			"code":      nullable(ref("ErrorCode")),
+			"message":   typed("string", ""),
+			"retryable": typed("boolean", "Whether the same request may succeed later"),
+			"fields":    typed("object", "The details of the error which may be shown, when it has any"),
+			"requestId": typed("string", ""),
+		}, "fields"),
+		"ErrorV2": object(Map{
+			"result": Map{"nullable": true, "description": "Always null", "type": "object"},
+			"error":  ref("ErrorDetailV2"),
+			"meta":   ref("Meta"),
+		}),
+		"Meta": object(Map{
+			"requestId":  typed("string", "The request ID, as sent by the client in the "+requestIDHeader+" header or generated"),
+			"durationMs": typed("number", "The time spent handling the request, in milliseconds"),
+			"cached":     typed("boolean", "Whether the result came from the cache, for the rates and conversions"),
+			"provider":   typed("string", "The provider of the result, when the config shows the providers"),
+		}, "cached", "provider"),
+	}
+	for name, schema := range resultSchemas() {
+		list[name] = schema
+	}
+	return list
+}
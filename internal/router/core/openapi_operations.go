@@ -0,0 +1,635 @@
+package coreHandlers
+
+import (
+	"net/http"
+
+	"fx-service/internal/reply"
+)
+
+// Tags grouping the operations of the OpenAPI document
+const (
+	tagRates         = "Rates"
+	tagConversions   = "Conversions"
+	tagService       = "Service"
+	tagQuotes        = "Quotes"
+	tagSubscriptions = "Subscriptions"
+	tagQueries       = "GraphQL and JSON-RPC"
+	tagAdmin         = "Admin"
+)
+
+// operations returns the OpenAPI operations of every route of a version, keyed by method and path in the :param syntax,
+// without the prefix of the version. The path parameters, the rate limit and the admin authentication are added
+// for each route by BuildOpenAPI.
+func operations(v APIVersion) map[string]Map {
+	currency := ref("CurrencyCode")
+	quoteList := Map{"type": "string", "description": "Comma-delimited quote currencies, e.g. EUR,GBP"}
+	rounding := queryParam("rounding", "The rounding mode of the result. Defaults to the rounding of the config", false, enum("half-even", "half-up", "truncate"))
+	format := queryParam("format", "Also return the result formatted with thousands separators", false, typed("boolean", ""))
+	strategy := queryParam("strategy", "The strategy used instead of the one of the config, e.g. race", false, typed("string", ""))
+
+	ops := map[string]Map{
+		"GET /favicon.ico": {
+			"operationId": "favicon",
+			"tags":        []string{tagService},
+			"summary":     "The icon shown by browsers",
+			"responses": responses(v, Map{
+				"description": "The icon",
+				"content":     Map{"image/x-icon": Map{"schema": Map{"type": "string", "format": "binary"}}},
+			}, http.StatusNotFound),
+		},
+		"GET /rate/:from/:to": {
+			"operationId": "getRate",
+			"tags":        []string{tagRates},
+			"summary":     "The exchange rate between two currencies",
+			"description": "With the bid, mid and ask prices when spreads are enabled.",
+			"responses":   responses(v, resultResponse(v, "The rate", "Rate"), http.StatusBadRequest, http.StatusInternalServerError),
+		},
+		"GET /rate/:from/:to/history": {
+			"operationId": "getRateHistory",
+			"tags":        []string{tagRates},
+			"summary":     "The OHLC buckets of a currency pair over a time range",
+			"parameters": []interface{}{
+				dateParam("start", "The start of the range. Defaults to 30 days before the end", false),
+				dateParam("end", "The end of the range. Defaults to now", false),
+				queryParam("interval", "The size of the buckets, e.g. 1h, 1d or 1w. Defaults to 1d", false, typed("string", "")),
+			},
+			"responses": responses(v, resultResponse(v, "The buckets", "RateHistory"), http.StatusBadRequest, http.StatusInternalServerError),
+		},
+		"GET /rates": {
+			"operationId": "getRates",
+			"tags":        []string{tagRates},
+			"summary":     "The exchange rates between a base currency and several quote currencies",
+			"parameters": []interface{}{
+				queryParam("from", "The base currency. Also accepted as base", true, currency),
+				queryParam("to", "The quote currencies. Also accepted as quote", true, quoteList),
+			},
+			"responses": responses(v, resultResponse(v, "The rates", "Rates"), http.StatusBadRequest, http.StatusInternalServerError),
+		},
+		"GET /rates/:date": {
+			"operationId": "getRatesOnDate",
+			"tags":        []string{tagRates},
+			"summary":     "The last observed rates on a past date",
+			"parameters": []interface{}{
+				queryParam("base", "The base currency. Also accepted as from", true, currency),
+				queryParam("quote", "The quote currencies. Also accepted as to", true, quoteList),
+			},
+			"responses": responses(v, resultResponse(v, "The rates", "RatesOnDate"), http.StatusBadRequest, http.StatusInternalServerError),
+		},
+		"GET /convert": {
+			"operationId": "convert",
+			"tags":        []string{tagConversions},
+			"summary":     "Converts an amount between two currencies",
+			"description": "The result is rounded to the minor units of the quote currency. The amount is converted at the bid when spreads are enabled.",
+			"parameters": []interface{}{
+				queryParam("from", "The base currency", true, currency),
+				queryParam("to", "The quote currency", true, currency),
+				queryParam("amount", "The amount to convert, e.g. 1234.56", true, typed("string", "")),
+				rounding,
+				format,
+			},
+			"responses": responses(v, resultResponse(v, "The conversion", "Conversion"), http.StatusBadRequest, http.StatusInternalServerError),
+		},
+		"POST /convert": {
+			"operationId": "convertBatch",
+			"tags":        []string{tagConversions},
+			"summary":     "Converts several amounts in one request",
+			"description": "Each conversion in the response has either its result, or an error.",
+			"parameters":  []interface{}{rounding, format},
+			"requestBody": jsonBody(array(ref("ConversionRequest"))),
+			"responses":   responses(v, resultResponse(v, "The conversions", "ConversionBatch"), http.StatusBadRequest),
+		},
+		"GET /matrix": {
+			"operationId": "getMatrix",
+			"tags":        []string{tagRates},
+			"summary":     "The rates between every pair of currencies, with the source and age of each rate",
+			"parameters": []interface{}{
+				queryParam("currencies", "Comma-delimited currencies. Defaults to every enabled currency", false, typed("string", "")),
+			},
+			"responses": responses(v, resultResponse(v, "The matrix", "Matrix"), http.StatusBadRequest, http.StatusInternalServerError),
+		},
+		"GET /currencies": {
+			"operationId": "listCurrencies",
+			"tags":        []string{tagService},
+			"summary":     "The enabled currencies, with their metadata",
+			"responses":   responses(v, resultResponse(v, "The currencies", "Currencies")),
+		},
+		"GET /status": {
+			"operationId": "getStatus",
+			"tags":        []string{tagService},
+			"summary":     "The strategy, the request counts, the providers and the cache expiry of the service",
+			"responses":   responses(v, resultResponse(v, "The status", "Status")),
+		},
+		"GET /health": {
+			"operationId": "healthCheck",
+			"tags":        []string{tagService},
+			"summary":     "Tells load balancers and orchestrators the service is up",
+			"responses":   responses(v, resultResponse(v, "The service is up", "Health")),
+		},
+		"GET /stream": {
+			"operationId": "streamRates",
+			"tags":        []string{tagRates},
+			"summary":     "Streams the rates of a base currency as they change",
+			"description": "Sent as Server-Sent Events, or over a WebSocket when the request is an upgrade.",
+			"parameters": []interface{}{
+				queryParam("base", "The base currency. Also accepted as from", true, currency),
+				queryParam("quote", "The quote currencies. Also accepted as to", true, quoteList),
+			},
+			"responses": responses(v, Map{
+				"description": "The events, each with the rates as JSON",
+				"content":     Map{"text/event-stream": Map{"schema": typed("string", "")}},
+			}, http.StatusBadRequest, http.StatusInternalServerError),
+		},
+
+		"POST /quotes": {
+			"operationId": "createQuote",
+			"tags":        []string{tagQuotes},
+			"summary":     "Locks the current rate for a pair and amount, until the quote expires",
+			"requestBody": jsonBody(ref("QuoteRequest")),
+			"responses":   responses(v, resultResponse(v, "The quote", "Quote"), http.StatusBadRequest, http.StatusInternalServerError),
+		},
+		"GET /quotes/:id": {
+			"operationId": "getQuote",
+			"tags":        []string{tagQuotes},
+			"summary":     "A quote, with its status",
+			"responses":   responses(v, resultResponse(v, "The quote", "Quote"), http.StatusNotFound, http.StatusGone),
+		},
+		"POST /quotes/:id/accept": {
+			"operationId": "acceptQuote",
+			"tags":        []string{tagQuotes},
+			"summary":     "Accepts an open quote, so that its rate is honoured",
+			"responses":   responses(v, resultResponse(v, "The accepted quote", "Quote"), http.StatusNotFound, http.StatusConflict, http.StatusGone),
+		},
+
+		"POST /subscriptions": {
+			"operationId": "createSubscription",
+			"tags":        []string{tagSubscriptions},
+			"summary":     "Registers a webhook, called when the rate of a pair meets a condition",
+			"description": "The response has the secret signing the callbacks. It is not returned again.",
+			"requestBody": jsonBody(ref("SubscriptionRequest")),
+			"responses":   responses(v, resultResponse(v, "The subscription", "Subscription"), http.StatusBadRequest, http.StatusForbidden, http.StatusInternalServerError),
+		},
+		"GET /subscriptions": {
+			"operationId": "listSubscriptions",
+			"tags":        []string{tagSubscriptions},
+			"summary":     "The webhooks registered by the client",
+			"responses":   responses(v, resultResponse(v, "The subscriptions", "Subscriptions"), http.StatusBadRequest, http.StatusInternalServerError),
+		},
+		"GET /subscriptions/:id": {
+			"operationId": "getSubscription",
+			"tags":        []string{tagSubscriptions},
+			"summary":     "A webhook registered by the client",
+			"responses":   responses(v, resultResponse(v, "The subscription", "Subscription"), http.StatusNotFound),
+		},
+		"DELETE /subscriptions/:id": {
+			"operationId": "deleteSubscription",
+			"tags":        []string{tagSubscriptions},
+			"summary":     "Removes a webhook registered by the client",
+			"responses":   responses(v, resultResponse(v, "The subscription was removed", "Removed"), http.StatusNotFound),
+		},
+
+		"GET /graphql": {
+			"operationId": "graphQLQuery",
+			"tags":        []string{tagQueries},
+			"summary":     "Runs a GraphQL query",
+			"description": "The result is sent as GraphQL expects, and not in the usual response shape.",
+			"parameters": []interface{}{
+				queryParam("query", "The GraphQL query", true, typed("string", "")),
+				queryParam("operationName", "The operation to run, when the query has several", false, typed("string", "")),
+				queryParam("variables", "The variables, as a JSON object", false, typed("string", "")),
+			},
+			"responses": responses(v, jsonResponse("The result", ref("GraphQLResponse")), http.StatusBadRequest),
+		},
+		"POST /graphql": {
+			"operationId": "graphQL",
+			"tags":        []string{tagQueries},
+			"summary":     "Runs a GraphQL query",
+			"description": "The result is sent as GraphQL expects, and not in the usual response shape.",
+			"requestBody": jsonBody(ref("GraphQLRequest")),
+			"responses":   responses(v, jsonResponse("The result", ref("GraphQLResponse")), http.StatusBadRequest),
+		},
+		"POST /rpc": {
+			"operationId": "jsonRPC",
+			"tags":        []string{tagQueries},
+			"summary":     "Runs a JSON-RPC 2.0 call, or a batch of calls",
+			"description": "The methods are rates.get, rates.getMany, rates.convert and status.get. The response is sent as JSON-RPC expects, and not in the usual response shape.",
+			"requestBody": jsonBody(Map{"oneOf": []Map{ref("JSONRPCRequest"), array(ref("JSONRPCRequest"))}}),
+			"responses": Map{
+				"200": jsonResponse("The response, or the responses of a batch", Map{"oneOf": []Map{ref("JSONRPCResponse"), array(ref("JSONRPCResponse"))}}),
+				"204": Map{"description": "Every call was a notification"},
+			},
+		},
+
+		"GET /admin/cache": {
+			"operationId": "listCache",
+			"tags":        []string{tagAdmin},
+			"summary":     "Every unexpired entry in the rate cache, with its age and TTL",
+			"responses":   responses(v, resultResponse(v, "The entries", "CacheEntries")),
+		},
+		"DELETE /admin/cache": {
+			"operationId": "clearCache",
+			"tags":        []string{tagAdmin},
+			"summary":     "Removes every entry from the rate cache",
+			"responses":   responses(v, resultResponse(v, "The number of entries removed", "Removed")),
+		},
+		"DELETE /admin/cache/:from": {
+			"operationId": "invalidateCacheBase",
+			"tags":        []string{tagAdmin},
+			"summary":     "Removes every pair with the base currency from the rate cache",
+			"responses":   responses(v, resultResponse(v, "The number of entries removed", "Removed")),
+		},
+		"GET /admin/cache/:from/:to": {
+			"operationId": "getCacheEntry",
+			"tags":        []string{tagAdmin},
+			"summary":     "The cache entry of a pair",
+			"responses":   responses(v, resultResponse(v, "The entry", "CacheEntry"), http.StatusNotFound),
+		},
+		"DELETE /admin/cache/:from/:to": {
+			"operationId": "invalidateCachePair",
+			"tags":        []string{tagAdmin},
+			"summary":     "Removes a pair from the rate cache",
+			"responses":   responses(v, resultResponse(v, "The number of entries removed", "Removed")),
+		},
+		"POST /admin/cache/:from/:to/refresh": {
+			"operationId": "refreshCachePair",
+			"tags":        []string{tagAdmin},
+			"summary":     "Fetches a pair from upstream, bypassing the cache, and stores the result in the cache",
+			"parameters": []interface{}{
+				queryParam("provider", "A single enabled provider to call", false, typed("string", "")),
+				strategy,
+			},
+			"responses": responses(v, resultResponse(v, "The refreshed rate", "CacheRefresh"), http.StatusBadRequest, http.StatusInternalServerError),
+		},
+		"POST /admin/history/:from/backfill": {
+			"operationId": "backfillHistory",
+			"tags":        []string{tagAdmin},
+			"summary":     "Fetches daily rates from upstream historical endpoints, and saves them in the historic rate store",
+			"parameters": []interface{}{
+				queryParam("quote", "The quote currencies", true, quoteList),
+				dateParam("start", "The first day to fetch", true),
+				dateParam("end", "The last day to fetch. Defaults to yesterday", false),
+				strategy,
+			},
+			"responses": responses(v, resultResponse(v, "The days saved and skipped", "Backfill"), http.StatusBadRequest, http.StatusInternalServerError, http.StatusServiceUnavailable),
+		},
+		"GET /admin/overrides": {
+			"operationId": "listOverrides",
+			"tags":        []string{tagAdmin},
+			"summary":     "The active rate overrides",
+			"responses":   responses(v, resultResponse(v, "The overrides", "Overrides")),
+		},
+		"GET /admin/overrides/audit": {
+			"operationId": "getOverrideAudit",
+			"tags":        []string{tagAdmin},
+			"summary":     "The last changes to the overrides, oldest first",
+			"parameters": []interface{}{
+				queryParam("limit", "The number of changes. Defaults to 100", false, Map{"type": "integer", "minimum": 1}),
+			},
+			"responses": responses(v, resultResponse(v, "The changes", "AuditTrail"), http.StatusBadRequest, http.StatusInternalServerError),
+		},
+		"PUT /admin/overrides/:from/:to": {
+			"operationId": "setOverride",
+			"tags":        []string{tagAdmin},
+			"summary":     "Forces the rate of a pair, ahead of the cache and every provider",
+			"requestBody": jsonBody(ref("OverrideRequest")),
+			"responses":   responses(v, resultResponse(v, "The override", "Override"), http.StatusBadRequest, http.StatusInternalServerError),
+		},
+		"POST /admin/overrides/:from/:to/expire": {
+			"operationId": "expireOverride",
+			"tags":        []string{tagAdmin},
+			"summary":     "Changes the expiry of the override of a pair, or removes it straight away if no expiry is given",
+			"requestBody": jsonBody(ref("OverrideChange")),
+			"responses":   responses(v, resultResponse(v, "The override", "Override"), http.StatusBadRequest, http.StatusNotFound),
+		},
+		"DELETE /admin/overrides/:from/:to": {
+			"operationId": "deleteOverride",
+			"tags":        []string{tagAdmin},
+			"summary":     "Removes the override of a pair",
+			"requestBody": jsonBody(ref("OverrideChange")),
+			"responses":   responses(v, resultResponse(v, "The override was removed", "Removed"), http.StatusBadRequest, http.StatusNotFound),
+		},
+
+		"GET /openapi.json": {
+			"operationId": "getOpenAPI",
+			"tags":        []string{tagService},
+			"summary":     "This OpenAPI document",
+			"responses":   responses(v, jsonResponse("The document", typed("object", ""))),
+		},
+		"GET /docs": {
+			"operationId": "docs",
+			"tags":        []string{tagService},
+			"summary":     "The interactive documentation of the API",
+			"responses": responses(v, Map{
+				"description": "The page",
+				"content":     Map{"text/html": Map{"schema": typed("string", "")}},
+			}),
+		},
+	}
+
+	// The routes which send their result in the format negotiated with the client, with the columns of their CSV table
+	// and their message in proto/fx/v1/fx.proto
+	for key, doc := range map[string][2]string{
+		"GET /rate/:from/:to":         {"base, quote, rate, bid, mid and ask with spreads, cached, override, provider when shown", "fx.v1.GetRateResponse"},
+		"GET /rate/:from/:to/history": {"base, quote, start, end, open, high, low, close, count, source", "fx.v1.RateHistory"},
+		"GET /rates":                  {"base, quote, rate, bid, mid and ask with spreads, override", "fx.v1.GetRatesResponse"},
+		"GET /rates/:date":            {"base, quote, date, rate, source", "fx.v1.RatesOnDate"},
+		"GET /matrix":                 {"base, then the rate to each currency", "fx.v1.RateMatrix"},
+	} {
+		ops[key] = negotiated(v, ops[key], doc[0], doc[1])
+	}
+	return ops
+}
+
+// dateParam describes a query parameter given as a date, e.g. 2024-01-31, or as an RFC 3339 time
+func dateParam(name, description string, required bool) Map {
+	return queryParam(name, description, required, Map{"type": "string", "example": "2024-01-31"})
+}
+
+// negotiated documents the formats of an operation which sends its result in the format negotiated with the client,
+// with the columns of its CSV table and its Protobuf message
+func negotiated(v APIVersion, op Map, columns, message string) Map {
+	names := make([]string, len(reply.Formats))
+	for i, format := range reply.Formats {
+		names[i] = string(format)
+	}
+	params, _ := op["parameters"].([]interface{})
+	op["parameters"] = append(params, queryParam("format", "The format of the result, instead of the Accept header", false, enum(names...)))
+
+	list := op["responses"].(Map)
+	content := list["200"].(Map)["content"].(Map)
+	content["text/csv"] = Map{"schema": typed("string", "A header, and a row for each rate. The columns are "+columns)}
+	content["application/xml"] = Map{"schema": typed("string", "The JSON document, with an element for each key, and <item> elements for the lists")}
+	content["application/msgpack"] = Map{"schema": Map{"type": "string", "format": "binary", "description": "The JSON document, in MessagePack"}}
+	content["application/x-protobuf"] = Map{"schema": Map{"type": "string", "format": "binary", "description": "The result, as a " + message + " message, without the envelope"}}
+	for status, response := range responses(v, nil, http.StatusBadRequest) {
+		if status != "200" {
+			list[status] = response
+		}
+	}
+	return op
+}
+
+// jsonResponse describes a successful response sent as is, without the standard response shape
+func jsonResponse(description string, schema Map) Map {
+	return Map{"description": description, "content": Map{"application/json": Map{"schema": schema}}}
+}
+
+// resultSchemas returns the schemas of the requests and of the results of the operations
+func resultSchemas() Map {
+	decimal := ref("Decimal")
+	currency := ref("CurrencyCode")
+	dateTime := Map{"type": "string", "format": "date-time"}
+	date := Map{"type": "string", "format": "date"}
+	integer := typed("integer", "")
+	text := typed("string", "")
+	provider := typed("string", "The provider of the rate, when the config shows the providers")
+
+	return Map{
+		"Price": object(Map{"bid": decimal, "mid": decimal, "ask": decimal}),
+		"Rate": object(Map{
+			"base":     currency,
+			"quote":    currency,
+			"rate":     decimal,
+			"cached":   typed("boolean", ""),
+			"override": typed("boolean", "The rate was forced by a manual override"),
+			"bid":      decimal,
+			"mid":      decimal,
+			"ask":      decimal,
+			"provider": provider,
+		}, "bid", "mid", "ask", "provider"),
+		"Rates": object(Map{
+			"base":      currency,
+			"quotes":    mapOf(decimal),
+			"cached":    typed("boolean", ""),
+			"overrides": Map{"type": "array", "items": currency, "description": "The quote currencies forced by a manual override"},
+			"prices":    Map{"type": "object", "additionalProperties": ref("Price"), "description": "When spreads are enabled"},
+			"provider":  provider,
+		}, "overrides", "prices", "provider"),
+		"Bucket": object(Map{
+			"start":  dateTime,
+			"end":    dateTime,
+			"open":   decimal,
+			"high":   decimal,
+			"low":    decimal,
+			"close":  decimal,
+			"count":  typed("integer", "The number of observations. 0 means there is no data for the interval"),
+			"source": typed("string", "store, or the provider used to fill a gap"),
+		}),
+		"RateHistory": object(Map{
+			"base":     currency,
+			"quote":    currency,
+			"start":    dateTime,
+			"end":      dateTime,
+			"interval": text,
+			"change":   nullable(typed("number", "The percentage change over the whole range, null if there is no data")),
+			"buckets":  array(ref("Bucket")),
+		}),
+		"RatesOnDate": object(Map{
+			"base":    currency,
+			"date":    date,
+			"quotes":  mapOf(decimal),
+			"sources": Map{"type": "object", "additionalProperties": text, "description": "Where each rate came from: store, or the provider"},
+		}),
+		"ConversionRequest": object(Map{
+			"from":   currency,
+			"to":     currency,
+			"amount": ref("DecimalInput"),
+		}),
+		"Conversion": object(Map{
+			"base":      currency,
+			"quote":     currency,
+			"amount":    decimal,
+			"rate":      decimal,
+			"result":    decimal,
+			"decimals":  typed("integer", "The minor units of the quote currency"),
+			"rounding":  enum("half-even", "half-up", "truncate"),
+			"cached":    typed("boolean", ""),
+			"override":  typed("boolean", ""),
+			"bid":       decimal,
+			"mid":       decimal,
+			"ask":       decimal,
+			"formatted": typed("string", "When asked for with format"),
+			"provider":  provider,
+		}, "bid", "mid", "ask", "formatted", "provider"),
+		"ConversionFailure": object(Map{
+			"base":   currency,
+			"quote":  currency,
+			"amount": decimal,
+			"error":  text,
+		}),
+		"ConversionBatch": object(Map{
+			"conversions": array(Map{"oneOf": []Map{ref("Conversion"), ref("ConversionFailure")}}),
+		}),
+		"MatrixCell": object(Map{
+			"rate":     nullable(decimal),
+			"source":   enum("identity", "override", "cache", "inverse", "provider", "triangulated", "unavailable"),
+			"age":      typed("integer", "The age in seconds of the oldest rate used for this cell"),
+			"provider": provider,
+		}, "provider"),
+		"Matrix": object(Map{
+			"currencies": array(currency),
+			"rates":      Map{"type": "object", "additionalProperties": mapOf(ref("MatrixCell")), "description": "Keyed by base, then quote"},
+			"calls":      typed("integer", "The number of upstream calls"),
+			"pivot":      typed("string", "The currency used for the inverted and triangulated rates"),
+		}, "pivot"),
+		"Currency": object(Map{
+			"code":       currency,
+			"numeric":    typed("string", "The ISO 4217 numeric code"),
+			"name":       text,
+			"minorUnits": typed("integer", "The number of decimal places. -1 if not applicable"),
+			"symbol":     text,
+			"countries":  array(text),
+			"iso":        typed("boolean", "False for currencies only reported by providers"),
+			"supported":  typed("boolean", "Whether any enabled provider supports the currency"),
+			"providers":  Map{"type": "array", "items": text, "description": "When the config shows the providers"},
+		}, "providers"),
+		"Currencies": object(Map{"currencies": array(ref("Currency"))}),
+		"Status": object(Map{
+			"mode": text,
+			"stats": object(Map{
+				"hitCount":     integer,
+				"requestCount": integer,
+				"errorCount":   integer,
+				"failCount":    integer,
+				"pathCount":    mapOf(integer),
+			}),
+			"providers": object(Map{"enabled": array(text), "available": array(text)}),
+			"cache": object(Map{
+				"defaultTtl":   typed("integer", "In seconds"),
+				"ttlRules":     Map{"type": "object", "additionalProperties": integer, "description": "In seconds, by rule"},
+				"effectiveTtl": Map{"type": "object", "additionalProperties": integer, "description": "In seconds, by pair"},
+			}),
+		}),
+		"Health": object(Map{"status": enum("healthy")}),
+		"QuoteRequest": object(Map{
+			"from":     currency,
+			"to":       currency,
+			"amount":   ref("DecimalInput"),
+			"rounding": enum("half-even", "half-up", "truncate"),
+		}, "rounding"),
+		"Quote": object(Map{
+			"id":         text,
+			"base":       currency,
+			"quote":      currency,
+			"amount":     decimal,
+			"rate":       decimal,
+			"bid":        decimal,
+			"ask":        decimal,
+			"result":     decimal,
+			"decimals":   integer,
+			"rounding":   text,
+			"status":     enum("open", "accepted", "expired"),
+			"createdAt":  dateTime,
+			"expiresAt":  dateTime,
+			"expiresIn":  typed("integer", "In seconds"),
+			"acceptedAt": dateTime,
+			"provider":   provider,
+		}, "acceptedAt", "provider"),
+		"SubscriptionRequest": object(Map{
+			"from":      currency,
+			"to":        currency,
+			"condition": enum("absolute", "percent", "cross"),
+			"threshold": ref("DecimalInput"),
+			"url":       typed("string", "The webhook called when the condition is met"),
+		}),
+		"Subscription": object(Map{
+			"id":        text,
+			"base":      currency,
+			"quote":     currency,
+			"condition": enum("absolute", "percent", "cross"),
+			"threshold": decimal,
+			"url":       text,
+			"createdAt": dateTime,
+			"secret":    typed("string", "Signs the callbacks. Only returned when the subscription is created"),
+		}, "secret"),
+		"Subscriptions": object(Map{"count": integer, "subscriptions": array(ref("Subscription"))}),
+		"Removed":       object(Map{"removed": typed("integer", "The number of entries removed")}),
+		"CacheEntry": object(Map{
+			"base":      currency,
+			"quote":     currency,
+			"rate":      decimal,
+			"setAt":     dateTime,
+			"age":       typed("integer", "In seconds"),
+			"ttl":       typed("integer", "In seconds"),
+			"expiresIn": typed("integer", "In seconds"),
+		}),
+		"CacheEntries": object(Map{"count": integer, "entries": array(ref("CacheEntry"))}),
+		"CacheRefresh": object(Map{
+			"base":     currency,
+			"quote":    currency,
+			"rate":     decimal,
+			"provider": text,
+			"entry":    nullable(ref("CacheEntry")),
+		}),
+		"Backfill": object(Map{
+			"base":    currency,
+			"quotes":  array(currency),
+			"start":   date,
+			"end":     date,
+			"calls":   integer,
+			"saved":   integer,
+			"skipped": integer,
+		}),
+		"OverrideRequest": object(Map{
+			"rate":      ref("DecimalInput"),
+			"reason":    text,
+			"author":    text,
+			"expiresAt": typed("string", "A date or RFC 3339 time. Empty for no expiry"),
+		}, "reason", "author", "expiresAt"),
+		"OverrideChange": object(Map{
+			"reason":    text,
+			"author":    text,
+			"expiresAt": typed("string", "A date or RFC 3339 time. Empty for now"),
+		}, "reason", "author", "expiresAt"),
+		"Override": object(Map{
+			"base":      currency,
+			"quote":     currency,
+			"rate":      decimal,
+			"reason":    text,
+			"author":    text,
+			"createdAt": dateTime,
+			"expiresAt": nullable(Map{"type": "string", "format": "date-time"}),
+		}),
+		"Overrides": object(Map{"count": integer, "overrides": array(ref("Override"))}),
+		"AuditEntry": object(Map{
+			"time":         dateTime,
+			"action":       enum("set", "expire", "delete"),
+			"base":         currency,
+			"quote":        currency,
+			"rate":         typed("string", "The rate after the change. Missing when deleted"),
+			"previousRate": typed("string", "The rate before the change. Missing when created"),
+			"expiresAt":    dateTime,
+			"reason":       text,
+			"author":       text,
+		}, "rate", "previousRate", "expiresAt"),
+		"AuditTrail": object(Map{"count": integer, "entries": array(ref("AuditEntry"))}),
+		"GraphQLRequest": object(Map{
+			"query":         text,
+			"operationName": text,
+			"variables":     typed("object", ""),
+		}, "operationName", "variables"),
+		"GraphQLResponse": object(Map{
+			"data": nullable(typed("object", "")),
+			"errors": array(object(Map{
+				"message":    text,
+				"path":       array(text),
+				"extensions": typed("object", "With the error code of the service"),
+			}, "path", "extensions")),
+		}, "errors"),
+		"JSONRPCRequest": object(Map{
+			"jsonrpc": enum("2.0"),
+			"method":  enum("rates.get", "rates.getMany", "rates.convert", "status.get"),
+			"params":  Map{"description": "By name or by position", "oneOf": []Map{{"type": "object"}, {"type": "array"}}},
+			"id":      Map{"description": "Missing for a notification", "oneOf": []Map{{"type": "string"}, {"type": "integer"}}},
+		}, "params", "id"),
+		"JSONRPCResponse": object(Map{
+			"jsonrpc": enum("2.0"),
+			"result":  typed("object", ""),
+			"error": object(Map{
+				"code":    integer,
+				"message": text,
+				"data":    typed("object", ""),
+			}, "data"),
+			"id": Map{"nullable": true, "oneOf": []Map{{"type": "string"}, {"type": "integer"}}},
+		}, "result", "error"),
+	}
+}
@@ -0,0 +1,163 @@
+package coreHandlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"fx-service/internal/service/history"
+	"fx-service/internal/service/overrides"
+	"fx-service/pkg/config"
+	"fx-service/pkg/decimal"
+	"fx-service/pkg/e"
+)
+
+// overrideRequest is the body of a change to a rate override
+type overrideRequest struct {
+	Rate      *decimal.Decimal `json:"rate"` // A JSON number or string
+	Reason    string           `json:"reason"`
+	Author    string           `json:"author"`
+	ExpiresAt string           `json:"expiresAt"` // A date or RFC 3339 time. Empty for no expiry, or now when expiring
+}
+
+// parseOverrideRequest parses the body of a change to a rate override
+func parseOverrideRequest(req *Request) (overrideRequest, *time.Time, error) {
+	var body overrideRequest
+	if err := json.Unmarshal(req.Body, &body); err != nil {
+		return body, nil, err
+	}
+	if body.ExpiresAt == "" {
+		return body, nil, nil
+	}
+	expiresAt, err := history.ParseTime(body.ExpiresAt)
+	if err != nil {
+		return body, nil, err
+	}
+	return body, &expiresAt, nil
+}
+
+// overrideMap builds the response for an override
+func overrideMap(override *overrides.Override) Map {
+	result := Map{
+		"base":      override.Base,
+		"quote":     override.Quote,
+		"rate":      override.Rate,
+		"reason":    override.Reason,
+		"author":    override.Author,
+		"createdAt": override.CreatedAt.Format(time.RFC3339),
+		"expiresAt": nil,
+	}
+	if override.ExpiresAt != nil {
+		result["expiresAt"] = override.ExpiresAt.Format(time.RFC3339)
+	}
+	return result
+}
+
+// ListOverrides returns the active rate overrides
+func ListOverrides(cfg *config.Config) Handler {
+	return func(req *Request) *Response {
+		list := overrides.List()
+		result := make([]Map, len(list))
+		for i := range list {
+			result[i] = overrideMap(&list[i])
+		}
+		return Result(Map{
+			"count":     len(result),
+			"overrides": result,
+		})
+	}
+}
+
+// SetOverride forces the rate of a pair, ahead of the cache and every provider.
+// Body: {"rate", "reason", "author"}, with an optional "expiresAt".
+func SetOverride(cfg *config.Config) Handler {
+	return func(req *Request) *Response {
+		ccyBase, ccyQuote, err := validateAndParseCurrencies(cfg, req)
+		if err != nil {
+			return Error(http.StatusBadRequest, err)
+		}
+
+		body, expiresAt, err := parseOverrideRequest(req)
+		if err != nil {
+			return Error(http.StatusBadRequest, e.FromCode("eRqBd2", `{"rate", "reason", "author", "expiresAt"}`))
+		}
+		if body.Rate == nil {
+			return Error(http.StatusBadRequest, e.FromCode("eOvRt2"))
+		}
+
+		override, err := overrides.Set(ccyBase, ccyQuote, *body.Rate, body.Reason, body.Author, expiresAt)
+		if err != nil {
+			return Error(http.StatusInternalServerError, err)
+		}
+		return Result(overrideMap(override))
+	}
+}
+
+// ExpireOverride changes the expiry of the override of a pair, or removes it straight away if no expiry is given.
+// Body: {"reason", "author"}, with an optional "expiresAt".
+func ExpireOverride(cfg *config.Config) Handler {
+	return func(req *Request) *Response {
+		ccyBase, ccyQuote, err := validateAndParseCurrencies(cfg, req)
+		if err != nil {
+			return Error(http.StatusBadRequest, err)
+		}
+
+		body, expiresAt, err := parseOverrideRequest(req)
+		if err != nil {
+			return Error(http.StatusBadRequest, e.FromCode("eRqBd2", `{"reason", "author", "expiresAt"}`))
+		}
+		if expiresAt == nil {
+			now := time.Now().UTC()
+			expiresAt = &now
+		}
+
+		override, err := overrides.Expire(ccyBase, ccyQuote, *expiresAt, body.Reason, body.Author)
+		if err != nil {
+			return Error(http.StatusInternalServerError, err)
+		}
+		return Result(overrideMap(override))
+	}
+}
+
+// DeleteOverride removes the override of a pair. Body: {"reason", "author"}
+func DeleteOverride(cfg *config.Config) Handler {
+	return func(req *Request) *Response {
+		ccyBase, ccyQuote, err := validateAndParseCurrencies(cfg, req)
+		if err != nil {
+			return Error(http.StatusBadRequest, err)
+		}
+
+		body, _, err := parseOverrideRequest(req)
+		if err != nil {
+			return Error(http.StatusBadRequest, e.FromCode("eRqBd2", `{"reason", "author"}`))
+		}
+
+		if err := overrides.Delete(ccyBase, ccyQuote, body.Reason, body.Author); err != nil {
+			return Error(http.StatusInternalServerError, err)
+		}
+		return Result(Map{"removed": 1})
+	}
+}
+
+// GetOverrideAudit returns the last changes to the overrides, oldest first. Query: ?limit=100
+func GetOverrideAudit(cfg *config.Config) Handler {
+	return func(req *Request) *Response {
+		limit := 100
+		if limitStr := req.QueryValue("limit"); limitStr != "" {
+			var err error
+			if limit, err = strconv.Atoi(limitStr); err != nil || limit <= 0 {
+				return Error(http.StatusBadRequest, e.FromCode("eRqLm1", limitStr))
+			}
+		}
+
+		entries, err := overrides.AuditTrail(limit)
+		if err != nil {
+			return Error(http.StatusInternalServerError, err)
+		}
+		return Result(Map{
+			"count":   len(entries),
+			"entries": entries,
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package coreHandlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"fx-service/internal/service/quotes"
+	"fx-service/internal/service/spreads"
+	"fx-service/internal/validate"
+	"fx-service/pkg/config"
+	"fx-service/pkg/decimal"
+	"fx-service/pkg/e"
+)
+
+// quoteRequest is the body of a request for a new quote
+type quoteRequest struct {
+	From     string           `json:"from"`
+	To       string           `json:"to"`
+	Amount   *decimal.Decimal `json:"amount"` // A JSON number or string
+	Rounding string           `json:"rounding"`
+}
+
+// quoteMap builds the response for a quote
+func quoteMap(cfg *config.Config, quote *quotes.Quote) Map {
+	now := time.Now()
+	response := Map{
+		"id":        quote.ID,
+		"base":      quote.Base,
+		"quote":     quote.Quote,
+		"amount":    quote.Amount,
+		"rate":      quote.Rate,
+		"bid":       quote.Bid,
+		"ask":       quote.Ask,
+		"result":    quote.Result,
+		"decimals":  quote.Decimals,
+		"rounding":  quote.Rounding,
+		"status":    quote.Status(now),
+		"createdAt": quote.CreatedAt.Format(time.RFC3339),
+		"expiresAt": quote.ExpiresAt.Format(time.RFC3339),
+		"expiresIn": max(0, int(quote.ExpiresAt.Sub(now).Seconds())),
+	}
+	if quote.AcceptedAt != nil {
+		response["acceptedAt"] = quote.AcceptedAt.Format(time.RFC3339)
+	}
+	if cfg.ShowProvider {
+		response["provider"] = quote.Provider
+	}
+	return response
+}
+
+// CreateQuote locks the current rate (spread included) for a pair and amount, until the quote expires.
+// The body is {"from", "to", "amount"}, with an optional "rounding" mode.
+func CreateQuote(cfg *config.Config) Handler {
+	return func(req *Request) *Response {
+		var body quoteRequest
+		if err := json.Unmarshal(req.Body, &body); err != nil {
+			return Error(http.StatusBadRequest, e.FromCode("eRqBd2", `{"from", "to", "amount"}`))
+		}
+
+		ccyBase, ccyQuote, err := validate.CurrencyPair(cfg, body.From, body.To)
+		if err != nil {
+			return Error(http.StatusBadRequest, err)
+		}
+		if body.Amount == nil {
+			return Error(http.StatusBadRequest, e.FromCode("eRqAm1"))
+		}
+
+		rounding, _, err := parseConvertOptions(cfg, body.Rounding, "")
+		if err != nil {
+			return Error(http.StatusBadRequest, err)
+		}
+
+		quote, err := quotes.Create(ccyBase, ccyQuote, *body.Amount, cfg.Mode, rounding, spreads.ClientFor(req.APIKey()))
+		if err != nil {
+			return Error(http.StatusInternalServerError, err)
+		}
+
+		return Result(quoteMap(cfg, quote))
+	}
+}
+
+// GetQuote returns a quote, with its status: open, accepted or expired
+func GetQuote(cfg *config.Config) Handler {
+	return func(req *Request) *Response {
+		quote, err := quotes.Get(req.Param("id"), spreads.ClientFor(req.APIKey()))
+		if err != nil {
+			return Error(http.StatusInternalServerError, err)
+		}
+
+		return Result(quoteMap(cfg, quote))
+	}
+}
+
+// AcceptQuote accepts an open quote, so that its rate is honoured. A quote can only be accepted once.
+func AcceptQuote(cfg *config.Config) Handler {
+	return func(req *Request) *Response {
+		quote, err := quotes.Accept(req.Param("id"), spreads.ClientFor(req.APIKey()))
+		if err != nil {
+			return Error(http.StatusInternalServerError, err)
+		}
+
+		return Result(quoteMap(cfg, quote))
+	}
+}
@@ -0,0 +1,36 @@
+package coreHandlers
+
+import (
+	"fx-service/internal/service/spreads"
+	"fx-service/pkg/decimal"
+)
+
+// priceMap builds the bid, mid and ask prices for a response
+func priceMap(price spreads.Price) Map {
+	return Map{
+		"bid": price.Bid,
+		"mid": price.Mid,
+		"ask": price.Ask,
+	}
+}
+
+// addPrices adds the bid, mid and ask prices of a single rate to a response, when spreads are enabled
+func addPrices(req *Request, result Map, from, to string, mid decimal.Decimal) {
+	if !spreads.Enabled() {
+		return
+	}
+	price := spreads.Quote(mid, from, to, spreads.ClientFor(req.APIKey()), nil)
+	for key, value := range priceMap(price) {
+		result[key] = value
+	}
+}
+
+// quotePrices returns the bid, mid and ask prices of several quotes, keyed by quote currency
+func quotePrices(req *Request, from string, rateList map[string]decimal.Decimal) Map {
+	client := spreads.ClientFor(req.APIKey())
+	prices := make(Map, len(rateList))
+	for to, mid := range rateList {
+		prices[to] = priceMap(spreads.Quote(mid, from, to, client, nil))
+	}
+	return prices
+}
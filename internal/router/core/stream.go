@@ -0,0 +1,41 @@
+package coreHandlers
+
+import (
+	"net/http"
+	"time"
+
+	"fx-service/internal/service/stream"
+	"fx-service/pkg/config"
+)
+
+// OpenStream checks a stream request, ?base=USD&quote=EUR,GBP, and the WebSocket handshake if it is an upgrade request,
+// then opens the stream. Serving the stream is left to the router, as it writes to the connection itself.
+// It returns the error response to send instead, in the shape of the API version, if the stream could not be opened.
+func OpenStream(cfg *config.Config, version APIVersion, req *Request) (s *stream.Stream, isWebSocket bool, failed *Response) {
+	start := time.Now()
+	s, isWebSocket, failed = openStream(cfg, req)
+	if failed != nil && version == V2 {
+		failed = withMeta(cfg, req, failed, start)
+	}
+	return s, isWebSocket, failed
+}
+
+// openStream checks a stream request, and opens the stream
+func openStream(cfg *config.Config, req *Request) (s *stream.Stream, isWebSocket bool, failed *Response) {
+	ccyBase, ccyQuoteList, err := parseQueryBaseAndQuotes(cfg, req)
+	if err != nil {
+		return nil, false, Error(http.StatusBadRequest, err)
+	}
+
+	isWebSocket = stream.IsWebSocket(req.Header.Get("Upgrade"), req.Header.Get("Connection"))
+	if isWebSocket {
+		if err := stream.CheckHandshake(req.Header.Get(stream.HeaderWebSocketVersion), req.Header.Get(stream.HeaderWebSocketKey)); err != nil {
+			return nil, false, Error(http.StatusInternalServerError, err)
+		}
+	}
+
+	if s, err = stream.Open(ccyBase, ccyQuoteList); err != nil {
+		return nil, false, Error(http.StatusInternalServerError, err)
+	}
+	return s, isWebSocket, nil
+}
@@ -0,0 +1,107 @@
+package coreHandlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"fx-service/internal/service/alerts"
+	"fx-service/internal/validate"
+	"fx-service/pkg/config"
+	"fx-service/pkg/decimal"
+	"fx-service/pkg/e"
+)
+
+// subscriptionRequest is the body of a request for a new webhook subscription
+type subscriptionRequest struct {
+	From      string           `json:"from"`
+	To        string           `json:"to"`
+	Condition string           `json:"condition"` // "absolute", "percent" or "cross"
+	Threshold *decimal.Decimal `json:"threshold"` // A JSON number or string
+	URL       string           `json:"url"`
+}
+
+// subscriptionMap builds the response for a subscription. The secret is only returned when it is created.
+func subscriptionMap(sub *alerts.Subscription, withSecret bool) Map {
+	result := Map{
+		"id":        sub.ID,
+		"base":      sub.Base,
+		"quote":     sub.Quote,
+		"condition": sub.Condition,
+		"threshold": sub.Threshold,
+		"url":       sub.URL,
+		"createdAt": sub.CreatedAt.Format(time.RFC3339),
+	}
+	if withSecret {
+		result["secret"] = sub.Secret
+	}
+	return result
+}
+
+// CreateSubscription registers a webhook, called when the rate of a pair meets a condition.
+// The body is {"from", "to", "condition", "threshold", "url"}. The response has the secret signing the callbacks.
+func CreateSubscription(cfg *config.Config) Handler {
+	return func(req *Request) *Response {
+		var body subscriptionRequest
+		if err := json.Unmarshal(req.Body, &body); err != nil {
+			return Error(http.StatusBadRequest, e.FromCode("eRqBd2", `{"from", "to", "condition", "threshold", "url"}`))
+		}
+
+		ccyBase, ccyQuote, err := validate.CurrencyPair(cfg, body.From, body.To)
+		if err != nil {
+			return Error(http.StatusBadRequest, err)
+		}
+		if body.Threshold == nil {
+			return Error(http.StatusBadRequest, e.FromCode("eAlTh2"))
+		}
+
+		sub, err := alerts.Create(ccyBase, ccyQuote, body.Condition, *body.Threshold, body.URL, req.APIKey())
+		if err != nil {
+			return Error(http.StatusInternalServerError, err)
+		}
+
+		return Result(subscriptionMap(sub, true))
+	}
+}
+
+// ListSubscriptions returns the webhooks registered by the client
+func ListSubscriptions(cfg *config.Config) Handler {
+	return func(req *Request) *Response {
+		subs, err := alerts.List(req.APIKey())
+		if err != nil {
+			return Error(http.StatusInternalServerError, err)
+		}
+
+		result := make([]Map, len(subs))
+		for i := range subs {
+			result[i] = subscriptionMap(&subs[i], false)
+		}
+		return Result(Map{
+			"count":         len(result),
+			"subscriptions": result,
+		})
+	}
+}
+
+// GetSubscription returns a webhook registered by the client
+func GetSubscription(cfg *config.Config) Handler {
+	return func(req *Request) *Response {
+		sub, err := alerts.Get(req.Param("id"), req.APIKey())
+		if err != nil {
+			return Error(http.StatusInternalServerError, err)
+		}
+
+		return Result(subscriptionMap(sub, false))
+	}
+}
+
+// DeleteSubscription removes a webhook registered by the client
+func DeleteSubscription(cfg *config.Config) Handler {
+	return func(req *Request) *Response {
+		if err := alerts.Delete(req.Param("id"), req.APIKey()); err != nil {
+			return Error(http.StatusInternalServerError, err)
+		}
+
+		return Result(Map{"removed": 1})
+	}
+}
@@ -0,0 +1,132 @@
+package coreHandlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"fx-service/internal/reply"
+	"fx-service/pkg/config"
+	"fx-service/pkg/e"
+)
+
+// requestIDHeader identifies a request, in the v2 responses and in the logs of the client
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength is the longest request ID accepted from a client. Longer ones are replaced.
+const maxRequestIDLength = 128
+
+// APIVersion is a version of the API, served under its own path prefix
+type APIVersion int
+
+const (
+	Unversioned APIVersion = iota // The routes without a prefix, served as v1 for the existing clients
+	V1                            // {"result", "error"}, with the error as a message, or as {"code", "message"}
+	V2                            // {"result", "error", "meta"}, with the error code, fields and request ID, and the timing and source
+)
+
+// Versions are the versions of the API served under a prefix
+var Versions = []APIVersion{V1, V2}
+
+// Prefix returns the path prefix of the routes of the version, e.g. /v2
+func (v APIVersion) Prefix() string {
+	if v == Unversioned {
+		return ""
+	}
+	return "/v" + strconv.Itoa(int(v))
+}
+
+// Meta is the metadata of a v2 response
+type Meta struct {
+	RequestID  string  `json:"requestId"`
+	DurationMs float64 `json:"durationMs"`         // Time spent handling the request, in milliseconds
+	Cached     *bool   `json:"cached,omitempty"`   // Whether the result came from the cache, for the rates and conversions
+	Provider   string  `json:"provider,omitempty"` // The provider of the result, when the config shows the providers
+}
+
+// ErrorDetail is the error of a v2 response
+type ErrorDetail struct {
+	Code      *string  `json:"code"` // The code of the error catalogue, or null for the errors without a code
+	Message   string   `json:"message"`
+	Retryable bool     `json:"retryable"`        // Whether the same request may succeed later
+	Fields    e.Fields `json:"fields,omitempty"` // The fields of the error which the catalogue allows to be shown
+	RequestID string   `json:"requestId"`
+}
+
+// Versioned serves a handler with the response shape of an API version.
+// The responses which are not in the standard shape (files, GraphQL, JSON-RPC) are sent as they are.
+func Versioned(cfg *config.Config, version APIVersion, handler Handler) Handler {
+	if version != V2 {
+		return handler
+	}
+	return func(req *Request) *Response {
+		start := time.Now()
+		return withMeta(cfg, req, handler(req), start)
+	}
+}
+
+// withMeta sends a response in the v2 shape, with the request ID and the time spent since the start of the request
+func withMeta(cfg *config.Config, req *Request, res *Response, start time.Time) *Response {
+	requestID := requestIDOf(req)
+	if res.Header == nil {
+		res.Header = map[string]string{}
+	}
+	res.Header[requestIDHeader] = requestID
+	if !res.enveloped {
+		return res
+	}
+
+	meta := Meta{
+		RequestID:  requestID,
+		DurationMs: float64(time.Since(start).Microseconds()) / 1000,
+		Cached:     res.cached,
+	}
+	if cfg.ShowProvider {
+		meta.Provider = res.provider
+	}
+
+	if res.failure != nil {
+		res.Body = reply.WithMeta(nil, errorDetail(res.failure, requestID), meta)
+	} else {
+		res.Body = reply.WithMeta(res.result, nil, meta)
+	}
+	return res
+}
+
+// requestIDOf returns the request ID sent by the client, or a new one
+func requestIDOf(req *Request) string {
+	if id := req.Header.Get(requestIDHeader); id != "" && len(id) <= maxRequestIDLength && isPrintable(id) {
+		return id
+	}
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// isPrintable tells whether a string only has printable ASCII characters, so it can be sent back in a header
+func isPrintable(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// errorDetail builds the error of a v2 response, with the code, retryable flag and public fields of the catalogue entry
+func errorDetail(failure interface{}, requestID string) ErrorDetail {
+	public, ok := failure.(e.PublicError)
+	if !ok {
+		return ErrorDetail{Message: fmt.Sprint(failure), RequestID: requestID}
+	}
+
+	detail := ErrorDetail{Message: public.Message, Retryable: public.Retryable, Fields: public.Fields, RequestID: requestID}
+	if public.Code != "" {
+		detail.Code = &public.Code
+	}
+	return detail
+}
@@ -0,0 +1,67 @@
+package fiberHandlers
+
+import (
+	"net/http"
+	"net/url"
+
+	coreHandlers "fx-service/internal/router/core"
+	"fx-service/internal/service/stats"
+	"fx-service/pkg/e"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// newRequest copies what the handlers need from a Fiber request.
+// Fiber reuses its buffers once the handler returns, so nothing refers to them.
+func newRequest(ctx *fiber.Ctx) *coreHandlers.Request {
+	params := make(map[string]string)
+	for name, value := range ctx.AllParams() {
+		params[name] = utils.CopyString(value)
+	}
+
+	query, _ := url.ParseQuery(string(ctx.Request().URI().QueryString()))
+
+	header := make(http.Header)
+	ctx.Request().Header.VisitAll(func(key, value []byte) {
+		header.Add(string(key), string(value))
+	})
+
+	return &coreHandlers.Request{
+		Method:  utils.CopyString(ctx.Method()),
+		Params:  params,
+		Query:   query,
+		Header:  header,
+		Body:    append([]byte(nil), ctx.Body()...),
+		Context: ctx.Context(),
+	}
+}
+
+// send sends a handler response to the client
+func send(ctx *fiber.Ctx, res *coreHandlers.Response) error {
+	defer stats.GetInstance().IncRequestCount()
+	for key, value := range res.Header {
+		ctx.Set(key, value)
+	}
+	ctx.Status(res.Status)
+	switch {
+	case res.File != "":
+		return ctx.SendFile(res.File)
+	case res.Raw != nil:
+		return ctx.Send(res.Raw)
+	case res.Body == nil:
+		return ctx.SendStatus(res.Status)
+	default:
+		return ctx.JSON(res.Body)
+	}
+}
+
+// adapt serves a router-agnostic handler with Fiber, for request bodies of at most maxBodyBytes.
+// Fiber's own BodyLimit already refuses them when listening, but not when mounted as an http.Handler.
+func adapt(handler coreHandlers.Handler, maxBodyBytes int64) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		if maxBodyBytes > 0 && int64(len(ctx.Body())) > maxBodyBytes {
+			return send(ctx, coreHandlers.Error(http.StatusRequestEntityTooLarge, e.FromCode("eRqTl1", maxBodyBytes)))
+		}
+		return send(ctx, handler(newRequest(ctx)))
+	}
+}
@@ -1,12 +1,14 @@
 package fiberHandlers
 
 import (
+	"net/http"
+
 	"fx-service/internal/middleware"
+	coreHandlers "fx-service/internal/router/core"
 	"fx-service/pkg/config"
 	"fx-service/pkg/logger"
 	"github.com/gofiber/fiber/v2"
-	"net/http"
-	"time"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
 )
 
 type FiberRouter struct {
@@ -25,25 +27,30 @@ func (r *FiberRouter) RegisterMiddleware() {
 }
 
 func (r *FiberRouter) RegisterRoutes() {
-
-	// Handle favicon requests from browsers
-	r.App.Get("/favicon.ico", func(c *fiber.Ctx) error {
-		c.Set("Content-Type", "image/x-icon")
-		c.Set("Cache-Control", "public, max-age=86400") // Cache for 1 day (86400 seconds)
-		c.Set("Expires", time.Now().Add(24*time.Hour).Format(http.TimeFormat))
-		return c.SendFile("./public/favicon.ico")
-	})
+	// Register the routes shared by every router
+	for _, route := range coreHandlers.Routes(r.Config) {
+		handlers := []fiber.Handler{adapt(route.Handler, r.Config.MaxBodyBytes)}
+		if route.Admin {
+			handlers = append([]fiber.Handler{middleware.FiberAdminAuth(r.Config.Admin)}, handlers...)
+		}
+		r.App.Add(route.Method, route.Path, handlers...)
+	}
+
+	// Register the streaming routes, only if enabled
+	for _, route := range coreHandlers.StreamRoutes(r.Config) {
+		r.App.Add(route.Method, route.Path, StreamRates(r.Config, route.Version)) // ?base=USD&quote=EUR,GBP (Server-Sent Events, or WebSocket on upgrade)
+	}
 
 	// Handle 404
-	r.App.All("/", func(c *fiber.Ctx) error {
-		return replyError(c, http.StatusNotFound, "Not found")
-	})
+	r.App.Use(adapt(coreHandlers.NotFound(r.Config), r.Config.MaxBodyBytes))
+}
 
-	// Register the routes
-	r.App.Get("/rate/:from/:to", GetRate(r.Config))
-	r.App.Get("/rates", GetRates(r.Config)) // ?base=USD&quote=EUR,GBP
-	r.App.Get("/status", GetStatus(r.Config))
-	r.App.Get("/health", HealthCheck(r.Config))
+// ServeHTTP serves a net/http request, converted to a Fiber request. Streams can't be served this way.
+func (r *FiberRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	// The conversion routes on the request URI, which is not changed when mounted with http.StripPrefix
+	req = req.Clone(req.Context())
+	req.RequestURI = req.URL.RequestURI()
+	adaptor.FiberApp(r.App)(w, req)
 }
 
 func (r *FiberRouter) Serve(addr string) error {
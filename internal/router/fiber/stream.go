@@ -0,0 +1,53 @@
+package fiberHandlers
+
+import (
+	"bufio"
+	"net"
+
+	coreHandlers "fx-service/internal/router/core"
+	"fx-service/internal/service/stream"
+	"fx-service/pkg/config"
+	"github.com/gofiber/fiber/v2"
+)
+
+// StreamRates pushes the rates of a base currency against the quote currencies, every time they are updated.
+// WebSocket upgrade requests get a WebSocket of JSON messages, other requests get Server-Sent Events.
+func StreamRates(cfg *config.Config, version coreHandlers.APIVersion) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		req := newRequest(ctx)
+		s, isWebSocket, failed := coreHandlers.OpenStream(cfg, version, req)
+		if failed != nil {
+			return send(ctx, failed)
+		}
+
+		if isWebSocket {
+			// The handshake response is written on the hijacked connection
+			key := req.Header.Get(stream.HeaderWebSocketKey)
+			ctx.Context().HijackSetNoResponse(true)
+			ctx.Context().Hijack(func(conn net.Conn) {
+				defer s.Close()
+				ws, err := stream.Accept(conn, bufio.NewReader(conn), key, s.WriteTimeout())
+				if err != nil {
+					return
+				}
+				_ = s.ServeWebSocket(ws)
+			})
+			return nil
+		}
+
+		ctx.Set(fiber.HeaderContentType, "text/event-stream")
+		ctx.Set(fiber.HeaderCacheControl, "no-cache")
+		ctx.Set(fiber.HeaderConnection, "keep-alive")
+		ctx.Set("X-Accel-Buffering", "no") // Stop reverse proxies from buffering the events
+		ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			defer s.Close()
+			_ = s.ServeSSE(nil, func(event []byte) error {
+				if _, err := w.Write(event); err != nil {
+					return err
+				}
+				return w.Flush()
+			})
+		})
+		return nil
+	}
+}
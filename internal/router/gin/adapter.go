@@ -0,0 +1,61 @@
+package ginHandlers
+
+import (
+	"net/http"
+
+	coreHandlers "fx-service/internal/router/core"
+	"fx-service/internal/service/stats"
+	"github.com/gin-gonic/gin"
+)
+
+// newRequest copies what the handlers need from a Gin request, with a body of at most maxBodyBytes
+func newRequest(c *gin.Context, maxBodyBytes int64) (*coreHandlers.Request, error) {
+	params := make(map[string]string, len(c.Params))
+	for _, param := range c.Params {
+		params[param.Key] = param.Value
+	}
+
+	body, err := coreHandlers.ReadBody(c.Writer, c.Request, maxBodyBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &coreHandlers.Request{
+		Method:  c.Request.Method,
+		Params:  params,
+		Query:   c.Request.URL.Query(),
+		Header:  c.Request.Header,
+		Body:    body,
+		Context: c.Request.Context(),
+	}, nil
+}
+
+// send sends a handler response to the client
+func send(c *gin.Context, res *coreHandlers.Response) {
+	defer stats.GetInstance().IncRequestCount()
+	for key, value := range res.Header {
+		c.Header(key, value)
+	}
+	switch {
+	case res.File != "":
+		c.File(res.File)
+	case res.Raw != nil:
+		c.Data(res.Status, res.Header["Content-Type"], res.Raw)
+	case res.Body == nil:
+		c.Status(res.Status)
+	default:
+		c.JSON(res.Status, res.Body)
+	}
+}
+
+// adapt serves a router-agnostic handler with Gin, for request bodies of at most maxBodyBytes
+func adapt(handler coreHandlers.Handler, maxBodyBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		req, err := newRequest(c, maxBodyBytes)
+		if err != nil {
+			send(c, coreHandlers.Error(http.StatusBadRequest, err))
+			return
+		}
+		send(c, handler(req))
+	}
+}
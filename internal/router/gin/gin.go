@@ -1,7 +1,10 @@
 package ginHandlers
 
 import (
+	"net/http"
+
 	"fx-service/internal/middleware"
+	coreHandlers "fx-service/internal/router/core"
 	"fx-service/pkg/config"
 	"fx-service/pkg/logger"
 	"github.com/gin-gonic/gin"
@@ -25,9 +28,26 @@ func (r *GinRouter) RegisterMiddleware() {
 }
 
 func (r *GinRouter) RegisterRoutes() {
-	r.Engine.GET("/rate/:from/:to", GetRate(r.Config))
-	r.Engine.GET("/rates", GetRates(r.Config))
-	r.Engine.GET("/status", GetStatus(r.Config))
+	// Register the routes shared by every router
+	for _, route := range coreHandlers.Routes(r.Config) {
+		handlers := []gin.HandlerFunc{adapt(route.Handler, r.Config.MaxBodyBytes)}
+		if route.Admin {
+			handlers = append([]gin.HandlerFunc{middleware.GinAdminAuth(r.Config.Admin)}, handlers...)
+		}
+		r.Engine.Handle(route.Method, route.Path, handlers...)
+	}
+
+	// Register the streaming routes, only if enabled
+	for _, route := range coreHandlers.StreamRoutes(r.Config) {
+		r.Engine.Handle(route.Method, route.Path, StreamRates(r.Config, route.Version)) // ?base=USD&quote=EUR,GBP (Server-Sent Events, or WebSocket on upgrade)
+	}
+
+	// Handle 404
+	r.Engine.NoRoute(adapt(coreHandlers.NotFound(r.Config), r.Config.MaxBodyBytes))
+}
+
+func (r *GinRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.Engine.ServeHTTP(w, req)
 }
 
 func (r *GinRouter) Serve(addr string) error {
@@ -1,19 +1,16 @@
 package ginHandlers
 
 import (
-	"fx-service/internal/reply"
+	coreHandlers "fx-service/internal/router/core"
 	"github.com/gin-gonic/gin"
-	"net/http"
 )
 
 // replyResult sends a successful response to the client
 func replyResult(c *gin.Context, data interface{}) {
-	payload := reply.Result(data)
-	c.JSON(http.StatusOK, payload)
+	send(c, coreHandlers.Result(data))
 }
 
 // replyError sends an error response to the client, with the given status code
 func replyError(c *gin.Context, status int, data interface{}) {
-	payload := reply.Error(data)
-	c.JSON(status, payload)
+	send(c, coreHandlers.Error(status, data))
 }
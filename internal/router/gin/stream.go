@@ -0,0 +1,59 @@
+package ginHandlers
+
+import (
+	"net/http"
+	"time"
+
+	coreHandlers "fx-service/internal/router/core"
+	"fx-service/internal/service/stream"
+	"fx-service/pkg/config"
+	"github.com/gin-gonic/gin"
+)
+
+// StreamRates pushes the rates of a base currency against the quote currencies, every time they are updated.
+// WebSocket upgrade requests get a WebSocket of JSON messages, other requests get Server-Sent Events.
+func StreamRates(cfg *config.Config, version coreHandlers.APIVersion) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		req, err := newRequest(c, cfg.MaxBodyBytes)
+		if err != nil {
+			send(c, coreHandlers.Error(http.StatusBadRequest, err))
+			return
+		}
+		s, isWebSocket, failed := coreHandlers.OpenStream(cfg, version, req)
+		if failed != nil {
+			send(c, failed)
+			return
+		}
+		defer s.Close()
+
+		if isWebSocket {
+			conn, rw, err := c.Writer.Hijack()
+			if err != nil {
+				replyError(c, http.StatusInternalServerError, err.Error())
+				return
+			}
+			ws, err := stream.Accept(conn, rw.Reader, req.Header.Get(stream.HeaderWebSocketKey), s.WriteTimeout())
+			if err != nil {
+				return
+			}
+			_ = s.ServeWebSocket(ws)
+			return
+		}
+
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Header("X-Accel-Buffering", "no") // Stop reverse proxies from buffering the events
+		c.Status(http.StatusOK)
+
+		// Clients which stop reading are disconnected after the write timeout
+		rc := http.NewResponseController(c.Writer)
+		_ = s.ServeSSE(c.Request.Context().Done(), func(event []byte) error {
+			_ = rc.SetWriteDeadline(time.Now().Add(s.WriteTimeout()))
+			if _, err := c.Writer.Write(event); err != nil {
+				return err
+			}
+			return rc.Flush()
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package graphqlHandlers
+
+import (
+	"strings"
+
+	"fx-service/internal/service/providers"
+	"fx-service/pkg/config"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// defaultListSize is the assumed length of the lists whose length is not known before running the query
+const defaultListSize = 10
+
+// analysis computes the cost of a query before running it.
+// Every field costs 1, and the fields selected in a list cost once per item.
+// Introspection fields (e.g. __schema) are free, as they do not reach the service layer.
+type analysis struct {
+	cfg       *config.Config
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+}
+
+// analyse returns the complexity of the operation to run. The document must be valid.
+func analyse(cfg *config.Config, document *ast.Document, operationName string, variables map[string]interface{}) int {
+	a := &analysis{
+		cfg:       cfg,
+		fragments: make(map[string]*ast.FragmentDefinition),
+		variables: variables,
+	}
+
+	var operation *ast.OperationDefinition
+	for _, definition := range document.Definitions {
+		switch definition := definition.(type) {
+		case *ast.FragmentDefinition:
+			a.fragments[definition.Name.Value] = definition
+		case *ast.OperationDefinition:
+			if operationName == "" || (definition.Name != nil && definition.Name.Value == operationName) {
+				operation = definition
+			}
+		}
+	}
+	if operation == nil {
+		return 0
+	}
+	return a.selectionCost(operation.SelectionSet)
+}
+
+// selectionCost returns the cost of a selection set, with its fragments
+func (a *analysis) selectionCost(set *ast.SelectionSet) int {
+	if set == nil {
+		return 0
+	}
+
+	cost := 0
+	for _, selection := range set.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if !strings.HasPrefix(selection.Name.Value, "__") {
+				cost += 1 + a.selectionCost(selection.SelectionSet)*a.listSize(selection)
+			}
+		case *ast.InlineFragment:
+			cost += a.selectionCost(selection.SelectionSet)
+		case *ast.FragmentSpread:
+			if fragment, ok := a.fragments[selection.Name.Value]; ok {
+				cost += a.selectionCost(fragment.SelectionSet)
+			}
+		}
+	}
+	return cost
+}
+
+// listSize returns how many items a field may return
+func (a *analysis) listSize(field *ast.Field) int {
+	switch field.Name.Value {
+	case "rates":
+		return a.listArgument(field, "quotes", 1)
+	case "currencies":
+		return a.listArgument(field, "codes", len(a.cfg.CurrenciesEnabled))
+	case "providers":
+		return len(providers.InstalledProviders)
+	case "ttlRules", "effectiveTtl", "paths":
+		return defaultListSize
+	}
+	return 1
+}
+
+// listArgument returns the length of a list argument, given inline or as a variable
+func (a *analysis) listArgument(field *ast.Field, name string, fallback int) int {
+	for _, argument := range field.Arguments {
+		if argument.Name.Value != name {
+			continue
+		}
+		switch value := argument.Value.(type) {
+		case *ast.ListValue:
+			return max(len(value.Values), 1)
+		case *ast.Variable:
+			if list, ok := a.variables[value.Name.Value].([]interface{}); ok {
+				return max(len(list), 1)
+			}
+		}
+	}
+	return max(fallback, 1)
+}
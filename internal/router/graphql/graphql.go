@@ -0,0 +1,133 @@
+package graphqlHandlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"fx-service/pkg/config"
+	"fx-service/pkg/e"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// Request is a GraphQL request, sent as a JSON body or as query parameters
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// NewRequest builds a request from the query parameters of a GET request. The variables are a JSON object.
+func NewRequest(query, operationName, variables string) (Request, error) {
+	req := Request{Query: query, OperationName: operationName}
+	if variables != "" {
+		if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+			return req, e.FromCode("eGqVr1", err.Error())
+		}
+	}
+	return req, nil
+}
+
+// requestContext holds the state of a single request, for the resolvers
+type requestContext struct {
+	cfg    *config.Config
+	apiKey string
+	loader *rateLoader
+}
+
+type contextKey struct{}
+
+// fromContext returns the state of the request being resolved
+func fromContext(ctx context.Context) *requestContext {
+	return ctx.Value(contextKey{}).(*requestContext)
+}
+
+// errorResult returns a result with a single error and no data
+func errorResult(err error) *graphql.Result {
+	return &graphql.Result{Errors: withErrorCodes(gqlerrors.FormatErrors(err))}
+}
+
+// Execute parses, validates and runs a request. The query is rejected before running when it is too complex.
+// Errors are returned in the result, as GraphQL expects, with the service error code in their extensions.
+func Execute(ctx context.Context, cfg *config.Config, req Request, apiKey string) *graphql.Result {
+	if schemaErr != nil {
+		return errorResult(e.FromCode("eGqSc1").SetPrevious(schemaErr))
+	}
+	if req.Query == "" {
+		return errorResult(fmt.Errorf("missing query"))
+	}
+
+	document, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+	validation := graphql.ValidateDocument(&schema, document, nil)
+	if !validation.IsValid {
+		return &graphql.Result{Errors: validation.Errors}
+	}
+
+	complexity := analyse(cfg, document, req.OperationName, req.Variables)
+	if cfg.GraphQL.MaxComplexity > 0 && complexity > cfg.GraphQL.MaxComplexity {
+		return errorResult(e.FromCode("eGqCx1", complexity, cfg.GraphQL.MaxComplexity))
+	}
+
+	result := graphql.Execute(graphql.ExecuteParams{
+		Schema:        schema,
+		AST:           document,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context: context.WithValue(ctx, contextKey{}, &requestContext{
+			cfg:    cfg,
+			apiKey: apiKey,
+			loader: newRateLoader(cfg.Mode),
+		}),
+	})
+	result.Errors = withErrorCodes(result.Errors)
+	return result
+}
+
+// exceptionOf returns the service exception which caused a GraphQL error, if any
+func exceptionOf(err error) *e.Exception {
+	for err != nil {
+		switch original := err.(type) {
+		case *e.Exception:
+			return original
+		case gqlerrors.FormattedError:
+			err = original.OriginalError()
+		case *gqlerrors.Error:
+			err = original.OriginalError
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// withErrorCodes replaces the message of the errors caused by the service layer with their public message,
+// and adds their code and retryable flag in the extensions
+func withErrorCodes(errs []gqlerrors.FormattedError) []gqlerrors.FormattedError {
+	for i, err := range errs {
+		ex := exceptionOf(err)
+		if ex == nil {
+			continue
+		}
+		// Only the public message of an exception is sent, as it may hold internal details
+		public := e.Public(ex, http.StatusInternalServerError)
+		errs[i].Message = public.Message
+		if public.Code == "" {
+			continue
+		}
+		if errs[i].Extensions == nil {
+			errs[i].Extensions = make(map[string]interface{})
+		}
+		errs[i].Extensions["code"] = public.Code
+		errs[i].Extensions["retryable"] = public.Retryable
+	}
+	return errs
+}
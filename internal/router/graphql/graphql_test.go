@@ -0,0 +1,183 @@
+package graphqlHandlers
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"testing"
+
+	"fx-service/internal/service/providers"
+	"fx-service/internal/service/ratecache"
+	"fx-service/pkg/config"
+	"fx-service/pkg/decimal"
+	"fx-service/pkg/e"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+// countingProvider returns the same rate for every pair, and counts the upstream calls
+type countingProvider struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (p *countingProvider) CheckApiKey() bool             { return true }
+func (p *countingProvider) GetName() string               { return "counting" }
+func (p *countingProvider) Supports(currency string) bool { return true }
+func (p *countingProvider) GetRate(from, to string) (decimal.Decimal, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls = append(p.calls, from+to)
+	return decimal.NewFromInt(2), nil
+}
+func (p *countingProvider) GetRates(from string, to []string) (providers.RateList, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls = append(p.calls, from+"x"+decimal.NewFromInt(int64(len(to))).String())
+	rates := make(providers.RateList)
+	for _, quote := range to {
+		rates[quote] = decimal.NewFromInt(2)
+	}
+	return rates, nil
+}
+
+func testConfig() *config.Config {
+	return &config.Config{
+		CurrenciesEnabled: []string{"USD", "EUR", "GBP", "JPY"},
+		Mode:              config.First,
+		Rounding:          config.HalfEven,
+		GraphQL:           config.GraphQLConfig{Enabled: true, MaxComplexity: 50},
+	}
+}
+
+func useProviders(t *testing.T, enabled map[string]providers.ProviderInterface) {
+	previous := providers.EnabledProviders
+	providers.EnabledProviders = enabled
+	t.Cleanup(func() { providers.EnabledProviders = previous })
+
+	cache := ratecache.GetInstance()
+	cache.SetExpiry(3600)
+	cache.Clear()
+	t.Cleanup(cache.Clear)
+}
+
+func mustParse(t *testing.T, query string) *ast.Document {
+	t.Helper()
+	document, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return document
+}
+
+// errorCode returns the service error code of the first error of the result
+func errorCode(result *graphql.Result) interface{} {
+	if len(result.Errors) == 0 {
+		return nil
+	}
+	return result.Errors[0].Extensions["code"]
+}
+
+// TestExecuteBatching checks the pairs asked for by several fields are fetched with one call per base currency
+func TestExecuteBatching(t *testing.T) {
+	provider := &countingProvider{}
+	useProviders(t, map[string]providers.ProviderInterface{"counting": provider})
+
+	result := Execute(context.Background(), testConfig(), Request{Query: `{
+		eur: rate(base: "usd", quote: "eur") { rate cached }
+		gbp: rate(base: "USD", quote: "GBP") { rate }
+		convert(from: "USD", to: "JPY", amount: "10.5") { result decimals }
+		rates(base: "EUR", quotes: ["USD", "GBP"]) { quote rate }
+	}`}, "")
+	if result.HasErrors() {
+		t.Fatalf("unexpected errors: %v", result.Errors)
+	}
+
+	// The bases are fetched in the order the fields are resolved, which is not guaranteed
+	sort.Strings(provider.calls)
+	if len(provider.calls) != 2 || provider.calls[0] != "EURx2" || provider.calls[1] != "USDx3" {
+		t.Errorf("expected one call for the 3 USD pairs and one for the 2 EUR pairs, got %v", provider.calls)
+	}
+
+	data := result.Data.(map[string]interface{})
+	if eur := data["eur"].(map[string]interface{}); eur["rate"] != "2" || eur["cached"] != false {
+		t.Errorf("expected the uncached USD/EUR rate, got %v", eur)
+	}
+	if converted := data["convert"].(map[string]interface{}); converted["result"] != "21" || converted["decimals"] != 0 {
+		t.Errorf("expected 21 JPY, got %v", converted)
+	}
+	if list := data["rates"].([]interface{}); len(list) != 2 || list[1].(map[string]interface{})["quote"] != "GBP" {
+		t.Errorf("expected the EUR rates in the requested order, got %v", list)
+	}
+}
+
+// TestExecuteComplexity checks too complex queries are rejected before running
+func TestExecuteComplexity(t *testing.T) {
+	e.SetCatalogue(e.ErrorMap{"eGqCx1": {Message: "Query is too complex (%d). The maximum is %d"}})
+	provider := &countingProvider{}
+	useProviders(t, map[string]providers.ProviderInterface{"counting": provider})
+
+	// 1 for the field, and 6 for each of the 10 quote currencies
+	result := Execute(context.Background(), testConfig(), Request{
+		Query:     `query($quotes: [String!]!) { rates(base: "USD", quotes: $quotes) { base quote rate cached override provider } }`,
+		Variables: map[string]interface{}{"quotes": []interface{}{"EUR", "GBP", "JPY", "EUR", "GBP", "JPY", "EUR", "GBP", "JPY", "EUR"}},
+	}, "")
+	if errorCode(result) != "eGqCx1" || result.Errors[0].Message != "Query is too complex (61). The maximum is 50" {
+		t.Errorf("expected the query to be too complex, got %v", result.Errors)
+	}
+
+	if len(provider.calls) != 0 {
+		t.Errorf("expected the rejected query not to fetch rates, got %v", provider.calls)
+	}
+
+	// Fragments are counted where they are spread: 6 for each alias (the field, rate, price and its 3 fields)
+	query := `{ a: rate(base: "USD", quote: "EUR") { ...price } b: rate(base: "USD", quote: "GBP") { ...price } }
+		fragment price on Rate { rate price { bid mid ask } }`
+	if complexity := analyse(testConfig(), mustParse(t, query), "", nil); complexity != 12 {
+		t.Errorf("expected a complexity of 12, got %d", complexity)
+	}
+
+	// Introspection is not limited
+	result = Execute(context.Background(), testConfig(), Request{
+		Query: `{ __schema { queryType { fields { name args { name type { kind ofType { kind ofType { name } } } } } } } }`,
+	}, "")
+	if result.HasErrors() {
+		t.Errorf("expected introspection to run, got %v", result.Errors)
+	}
+}
+
+// TestExecuteErrors checks the request errors, and the service error codes in the extensions
+func TestExecuteErrors(t *testing.T) {
+	e.SetCatalogue(e.ErrorMap{
+		"eGaPf1": {Message: "All providers have failed"},
+		"eRqCm1": {Message: "Missing base or quote currency codes. Ensure URL and query is correct", Status: http.StatusBadRequest},
+		"eRqCq1": {Message: "Invalid quote currency code, %s", Status: http.StatusBadRequest},
+	})
+	useProviders(t, map[string]providers.ProviderInterface{})
+
+	result := Execute(context.Background(), testConfig(), Request{Query: `{ rate(base: "USD", quote: "EUR") { rate } }`}, "")
+	if errorCode(result) != "eGaPf1" || result.Errors[0].Message != "All providers have failed" {
+		t.Errorf("expected eGaPf1, got %v", result.Errors)
+	}
+
+	result = Execute(context.Background(), testConfig(), Request{Query: `{ rate(base: "USD", quote: "XXX") { rate } }`}, "")
+	if len(result.Errors) != 1 || result.Errors[0].Message != "Invalid quote currency code, XXX" || errorCode(result) != "eRqCq1" {
+		t.Errorf("expected an invalid quote currency, got %v", result.Errors)
+	}
+
+	result = Execute(context.Background(), testConfig(), Request{Query: `{ rates(base: "", quotes: ["EUR"]) { rate } }`}, "")
+	if len(result.Errors) != 1 || errorCode(result) != "eRqCm1" {
+		t.Errorf("expected a missing base currency, got %v", result.Errors)
+	}
+
+	result = Execute(context.Background(), testConfig(), Request{Query: `{ rate(base: "USD") { rate } }`}, "")
+	if len(result.Errors) != 1 || result.Data != nil {
+		t.Errorf("expected a validation error, got %v", result.Errors)
+	}
+
+	if _, err := NewRequest("{ mode }", "", "[1]"); err == nil {
+		t.Errorf("expected the variables to be rejected")
+	}
+}
@@ -0,0 +1,67 @@
+package graphqlHandlers
+
+import (
+	"sync"
+
+	"fx-service/internal/service/rates"
+	"fx-service/pkg/config"
+	util "fx-service/pkg/helpers"
+)
+
+// rateBatch is the outcome of a single GetRates call, shared by every field which asked for one of its pairs
+type rateBatch struct {
+	result *rates.GetRatesResult
+	err    error
+}
+
+// rateLoader batches the rates asked for by the fields of a request.
+// The resolvers queue their pairs and return a thunk; the first thunk run for a base currency
+// fetches every quote queued for it so far with one GetRates call, so one query asking for
+// several pairs from the same base makes one upstream multi-quote call.
+type rateLoader struct {
+	mode    config.Mode
+	mu      sync.Mutex
+	pending map[string][]string   // Quote currencies not fetched yet, by base currency
+	batches map[string]*rateBatch // Fetched rates, by pair
+}
+
+func newRateLoader(mode config.Mode) *rateLoader {
+	return &rateLoader{
+		mode:    mode,
+		pending: make(map[string][]string),
+		batches: make(map[string]*rateBatch),
+	}
+}
+
+// load queues the pair, and returns a function waiting for the rates fetched with it
+func (l *rateLoader) load(base, quote string) func() (*rates.GetRatesResult, error) {
+	l.mu.Lock()
+	if _, fetched := l.batches[base+quote]; !fetched && !util.SliceContains(l.pending[base], quote) {
+		l.pending[base] = append(l.pending[base], quote)
+	}
+	l.mu.Unlock()
+
+	return func() (*rates.GetRatesResult, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		batch, fetched := l.batches[base+quote]
+		if !fetched {
+			batch = l.fetch(base)
+		}
+		return batch.result, batch.err
+	}
+}
+
+// fetch gets the rates of every quote currency queued for the base currency. The lock must be held.
+func (l *rateLoader) fetch(base string) *rateBatch {
+	quotes := l.pending[base]
+	delete(l.pending, base)
+
+	batch := &rateBatch{}
+	batch.result, batch.err = rates.GetRates(base, quotes, l.mode)
+	for _, quote := range quotes {
+		l.batches[base+quote] = batch
+	}
+	return batch
+}
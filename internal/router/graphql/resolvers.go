@@ -0,0 +1,264 @@
+package graphqlHandlers
+
+import (
+	"sort"
+
+	"fx-service/internal/service/providers"
+	"fx-service/internal/service/ratecache"
+	"fx-service/internal/service/rates"
+	"fx-service/internal/service/spreads"
+	"fx-service/internal/service/stats"
+	"fx-service/internal/validate"
+	"fx-service/pkg/config"
+	"fx-service/pkg/currency"
+	util "fx-service/pkg/helpers"
+	"github.com/graphql-go/graphql"
+)
+
+// stringList converts a list argument to strings
+func stringList(arg interface{}) []string {
+	values, _ := arg.([]interface{})
+	list := make([]string, 0, len(values))
+	for _, value := range values {
+		list = append(list, value.(string))
+	}
+	return list
+}
+
+// priceValue builds the bid, mid and ask prices of a pair
+func priceValue(price spreads.Price) map[string]interface{} {
+	return map[string]interface{}{
+		"bid": price.Bid.String(),
+		"mid": price.Mid.String(),
+		"ask": price.Ask.String(),
+	}
+}
+
+// providerName returns the provider name, when it is shown in the responses
+func providerName(cfg *config.Config, provider *string) interface{} {
+	if !cfg.ShowProvider || provider == nil {
+		return nil
+	}
+	return *provider
+}
+
+// rateValue builds a Rate, from the rates fetched for its base currency
+func rateValue(rc *requestContext, result *rates.GetRatesResult, quote string) (map[string]interface{}, error) {
+	mid, err := result.Rate(quote)
+	if err != nil {
+		return nil, err
+	}
+	value := map[string]interface{}{
+		"base":     result.Base,
+		"quote":    quote,
+		"rate":     mid.String(),
+		"cached":   result.WasCached,
+		"override": util.SliceContains(result.Overrides, quote),
+		"provider": providerName(rc.cfg, result.Provider),
+	}
+	if spreads.Enabled() {
+		value["price"] = priceValue(spreads.Quote(mid, result.Base, quote, spreads.ClientFor(rc.apiKey), nil))
+	}
+	return value, nil
+}
+
+// resolveRate queues the pair with the other rates of the request, and returns the rate once they are fetched
+func resolveRate(p graphql.ResolveParams) (interface{}, error) {
+	rc := fromContext(p.Context)
+	ccyBase, ccyQuote, err := validate.CurrencyPair(rc.cfg, p.Args["base"].(string), p.Args["quote"].(string))
+	if err != nil {
+		return nil, err
+	}
+
+	load := rc.loader.load(ccyBase, ccyQuote)
+	return func() (interface{}, error) {
+		result, err := load()
+		if err != nil {
+			return nil, err
+		}
+		return rateValue(rc, result, ccyQuote)
+	}, nil
+}
+
+// resolveRates queues the pairs with the other rates of the request, and returns the rates once they are fetched
+func resolveRates(p graphql.ResolveParams) (interface{}, error) {
+	rc := fromContext(p.Context)
+	ccyBase, ccyQuoteList, err := validate.BaseAndQuotes(rc.cfg, p.Args["base"].(string), stringList(p.Args["quotes"]))
+	if err != nil {
+		return nil, err
+	}
+
+	loads := make([]func() (*rates.GetRatesResult, error), len(ccyQuoteList))
+	for i, quote := range ccyQuoteList {
+		loads[i] = rc.loader.load(ccyBase, quote)
+	}
+
+	return func() (interface{}, error) {
+		values := make([]interface{}, len(ccyQuoteList))
+		for i, load := range loads {
+			result, err := load()
+			if err != nil {
+				return nil, err
+			}
+			if values[i], err = rateValue(rc, result, ccyQuoteList[i]); err != nil {
+				return nil, err
+			}
+		}
+		return values, nil
+	}, nil
+}
+
+// resolveConvert queues the pair with the other rates of the request, and converts the amount once they are fetched
+func resolveConvert(p graphql.ResolveParams) (interface{}, error) {
+	rc := fromContext(p.Context)
+	ccyBase, ccyQuote, err := validate.CurrencyPair(rc.cfg, p.Args["from"].(string), p.Args["to"].(string))
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := validate.Amount(p.Args["amount"].(string))
+	if err != nil {
+		return nil, err
+	}
+	name, _ := p.Args["rounding"].(string)
+	rounding, err := validate.Rounding(rc.cfg, name)
+	if err != nil {
+		return nil, err
+	}
+
+	load := rc.loader.load(ccyBase, ccyQuote)
+	return func() (interface{}, error) {
+		ratesResult, err := load()
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := ratesResult.Convert(ccyQuote, amount, rounding, spreads.ClientFor(rc.apiKey))
+		if err != nil {
+			return nil, err
+		}
+		value := map[string]interface{}{
+			"base":     result.Base,
+			"quote":    result.Quote,
+			"amount":   result.Amount.String(),
+			"rate":     result.Rate.String(),
+			"result":   result.Result.String(),
+			"decimals": result.Decimals,
+			"rounding": rounding.String(),
+			"cached":   result.WasCached,
+			"override": result.Override,
+			"provider": providerName(rc.cfg, result.Provider),
+		}
+		if result.Price != nil {
+			value["price"] = priceValue(*result.Price)
+		}
+		return value, nil
+	}, nil
+}
+
+// resolveCurrencies returns the metadata of the enabled currencies, or of the given ones
+func resolveCurrencies(p graphql.ResolveParams) (interface{}, error) {
+	rc := fromContext(p.Context)
+	codes := rc.cfg.CurrenciesEnabled
+	if arg, ok := p.Args["codes"]; ok {
+		codes = stringList(arg)
+		for i, code := range codes {
+			var err error
+			if codes[i], err = validate.Currency(rc.cfg, code); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	values := make([]interface{}, 0, len(codes))
+	for _, code := range codes {
+		ccy, ok := currency.Lookup(code)
+		if !ok {
+			ccy = currency.Currency{Code: code, Countries: []string{}}
+		}
+		supportedBy := providers.SupportedBy(code)
+
+		value := map[string]interface{}{
+			"code":       ccy.Code,
+			"numeric":    ccy.Numeric,
+			"name":       ccy.Name,
+			"minorUnits": ccy.MinorUnits,
+			"symbol":     ccy.Symbol,
+			"countries":  ccy.Countries,
+			"iso":        ccy.ISO,
+			"supported":  len(supportedBy) > 0,
+		}
+		if rc.cfg.ShowProvider {
+			value["providers"] = supportedBy
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// resolveProviders returns the installed providers, sorted by name
+func resolveProviders(_ graphql.ResolveParams) (interface{}, error) {
+	names := util.GetMapKeys(providers.InstalledProviders)
+	sort.Strings(names)
+
+	values := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		_, enabled := providers.EnabledProviders[name]
+		_, historical := providers.InstalledProviders[name].(providers.HistoricalProvider)
+		values = append(values, map[string]interface{}{
+			"name":       name,
+			"enabled":    enabled,
+			"priority":   providers.ProviderPriority[name],
+			"historical": historical,
+		})
+	}
+	return values, nil
+}
+
+// ttlValues converts expiry rules to a list, sorted by key
+func ttlValues(ttls map[string]int) []interface{} {
+	keys := util.GetMapKeys(ttls)
+	sort.Strings(keys)
+
+	values := make([]interface{}, 0, len(keys))
+	for _, key := range keys {
+		values = append(values, map[string]interface{}{"key": key, "ttl": ttls[key]})
+	}
+	return values
+}
+
+// resolveCache returns the number of cache entries, and their expiry
+func resolveCache(p graphql.ResolveParams) (interface{}, error) {
+	rc := fromContext(p.Context)
+	cache := ratecache.GetInstance()
+	return map[string]interface{}{
+		"entries":      len(cache.Entries()),
+		"defaultTtl":   int(cache.GetExpiry().Seconds()),
+		"ttlRules":     ttlValues(cache.GetTTLRules()),
+		"effectiveTtl": ttlValues(cache.EffectiveTTLs(rc.cfg.CurrenciesEnabled)),
+	}, nil
+}
+
+// resolveStats returns the request counts, and the count by path sorted by path
+func resolveStats(_ graphql.ResolveParams) (interface{}, error) {
+	snapshot := stats.GetInstance().GetSnapshot()
+	paths := util.GetMapKeys(snapshot.PathCount)
+	sort.Strings(paths)
+
+	pathCounts := make([]interface{}, 0, len(paths))
+	for _, path := range paths {
+		pathCounts = append(pathCounts, map[string]interface{}{"path": path, "count": snapshot.PathCount[path]})
+	}
+	return map[string]interface{}{
+		"hitCount":     snapshot.HitCount,
+		"requestCount": snapshot.RequestCount,
+		"errorCount":   snapshot.ErrorCount,
+		"failCount":    snapshot.FailCount,
+		"paths":        pathCounts,
+	}, nil
+}
+
+// resolveMode returns the strategy mode
+func resolveMode(p graphql.ResolveParams) (interface{}, error) {
+	return fromContext(p.Context).cfg.Mode.String(), nil
+}
@@ -0,0 +1,180 @@
+package graphqlHandlers
+
+import (
+	"github.com/graphql-go/graphql"
+)
+
+// Rates and amounts are exact decimal numbers, sent as strings so they do not lose precision as floats
+
+var priceType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "Price",
+	Description: "Bid, mid and ask prices of a pair, when spreads are enabled",
+	Fields: graphql.Fields{
+		"bid": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"mid": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"ask": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+	},
+})
+
+var rateType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "Rate",
+	Description: "Exchange rate of a currency pair",
+	Fields: graphql.Fields{
+		"base":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"quote":    &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"rate":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"cached":   &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean), Description: "Every rate fetched with this one was cached"},
+		"override": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean), Description: "The rate was forced by a manual override"},
+		"provider": &graphql.Field{Type: graphql.String, Description: "Only when the provider names are shown"},
+		"price":    &graphql.Field{Type: priceType},
+	},
+})
+
+var conversionType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "Conversion",
+	Description: "Amount converted between two currencies, rounded to the minor units of the quote currency",
+	Fields: graphql.Fields{
+		"base":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"quote":    &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"amount":   &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"rate":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"result":   &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"decimals": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		"rounding": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"cached":   &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+		"override": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+		"provider": &graphql.Field{Type: graphql.String},
+		"price":    &graphql.Field{Type: priceType, Description: "When spreads are enabled, the amount is converted at the bid"},
+	},
+})
+
+var currencyType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "Currency",
+	Description: "Metadata of an enabled currency",
+	Fields: graphql.Fields{
+		"code":       &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"numeric":    &graphql.Field{Type: graphql.String, Description: "ISO 4217 numeric code"},
+		"name":       &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"minorUnits": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Description: "-1 if not applicable"},
+		"symbol":     &graphql.Field{Type: graphql.String},
+		"countries":  &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String)))},
+		"iso":        &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+		"supported":  &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean), Description: "An enabled provider supports it"},
+		"providers":  &graphql.Field{Type: graphql.NewList(graphql.NewNonNull(graphql.String)), Description: "Only when the provider names are shown"},
+	},
+})
+
+var providerType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "Provider",
+	Description: "Status of an installed rates provider",
+	Fields: graphql.Fields{
+		"name":       &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"enabled":    &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+		"priority":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Description: "0 for no priority"},
+		"historical": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean), Description: "It serves rates for past dates"},
+	},
+})
+
+var ttlType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "Ttl",
+	Description: "Expiry of the cache entries matching a rule or a currency",
+	Fields: graphql.Fields{
+		"key": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"ttl": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Description: "In seconds"},
+	},
+})
+
+var cacheType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "Cache",
+	Description: "Statistics of the rate cache",
+	Fields: graphql.Fields{
+		"entries":      &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Description: "Unexpired entries"},
+		"defaultTtl":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Description: "In seconds"},
+		"ttlRules":     &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(ttlType)))},
+		"effectiveTtl": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(ttlType)))},
+	},
+})
+
+var pathCountType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "PathCount",
+	Fields: graphql.Fields{
+		"path":  &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"count": &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+	},
+})
+
+var statsType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "Stats",
+	Description: "Request counts since the service started. Counts are floats, as they may exceed 32-bit integers",
+	Fields: graphql.Fields{
+		"hitCount":     &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+		"requestCount": &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+		"errorCount":   &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+		"failCount":    &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+		"paths":        &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(pathCountType)))},
+	},
+})
+
+var queryType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Query",
+	Fields: graphql.Fields{
+		"rate": &graphql.Field{
+			Type:        rateType,
+			Description: "Exchange rate between two currencies",
+			Args: graphql.FieldConfigArgument{
+				"base":  &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				"quote": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+			},
+			Resolve: resolveRate,
+		},
+		"rates": &graphql.Field{
+			Type:        graphql.NewList(graphql.NewNonNull(rateType)),
+			Description: "Exchange rates between a base currency and several quote currencies",
+			Args: graphql.FieldConfigArgument{
+				"base":   &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				"quotes": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String)))},
+			},
+			Resolve: resolveRates,
+		},
+		"convert": &graphql.Field{
+			Type:        conversionType,
+			Description: "Converts an amount between two currencies",
+			Args: graphql.FieldConfigArgument{
+				"from":     &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				"to":       &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				"amount":   &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				"rounding": &graphql.ArgumentConfig{Type: graphql.String, Description: "half-even, half-up or truncate. Defaults to the configured one"},
+			},
+			Resolve: resolveConvert,
+		},
+		"currencies": &graphql.Field{
+			Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(currencyType))),
+			Description: "The enabled currencies, or the given ones",
+			Args: graphql.FieldConfigArgument{
+				"codes": &graphql.ArgumentConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+			},
+			Resolve: resolveCurrencies,
+		},
+		"providers": &graphql.Field{
+			Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(providerType))),
+			Description: "The installed providers, and whether they are enabled",
+			Resolve:     resolveProviders,
+		},
+		"cache": &graphql.Field{
+			Type:    graphql.NewNonNull(cacheType),
+			Resolve: resolveCache,
+		},
+		"stats": &graphql.Field{
+			Type:    graphql.NewNonNull(statsType),
+			Resolve: resolveStats,
+		},
+		"mode": &graphql.Field{
+			Type:        graphql.NewNonNull(graphql.String),
+			Description: "The strategy to fetch exchange rates from the providers",
+			Resolve:     resolveMode,
+		},
+	},
+})
+
+// schema is built once, as it does not depend on the configuration
+var schema, schemaErr = graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
@@ -0,0 +1,38 @@
+package grpcHandlers
+
+import (
+	"net"
+
+	"fx-service/pkg/config"
+	"fx-service/pkg/fxpb"
+	"fx-service/pkg/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+// GrpcServer serves the gRPC API, next to the REST router
+type GrpcServer struct {
+	Logger *logger.Logger
+	Config *config.Config
+	Server *grpc.Server
+}
+
+// RegisterServices registers the FxService, and the reflection service used by tools such as grpcurl
+func (r *GrpcServer) RegisterServices() {
+	fxpb.RegisterFxServiceServer(r.Server, &FxServer{Config: r.Config})
+	reflection.Register(r.Server)
+}
+
+// Serve listens on the given address, and serves the calls until the server stops
+func (r *GrpcServer) Serve(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return r.Server.Serve(listener)
+}
+
+// Stop stops accepting calls, and waits for the calls in progress to finish
+func (r *GrpcServer) Stop() {
+	r.Server.GracefulStop()
+}
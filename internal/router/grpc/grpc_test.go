@@ -0,0 +1,176 @@
+package grpcHandlers
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"fx-service/internal/service/ratecache"
+	"fx-service/internal/service/stream"
+	"fx-service/pkg/config"
+	"fx-service/pkg/decimal"
+	"fx-service/pkg/e"
+	"fx-service/pkg/fxpb"
+	"fx-service/pkg/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// testCatalogue has the error codes returned in the tests
+var testCatalogue = e.ErrorMap{
+	"eGaPf1": {Message: "All providers have failed", Status: http.StatusServiceUnavailable},
+	"eStDs1": {Message: "Rate streaming is disabled", Status: http.StatusServiceUnavailable},
+	"eRqCq1": {Message: "Invalid quote currency code, %s", Status: http.StatusBadRequest},
+	"eRqAm2": {Message: "Invalid amount, %s", Status: http.StatusBadRequest},
+	"eAlAu1": {Message: "This API key cannot register subscriptions", Status: http.StatusForbidden},
+}
+
+// newTestClient serves the FxService in memory, and returns a client connected to it
+func newTestClient(t *testing.T, cfg *config.Config) fxpb.FxServiceClient {
+	t.Helper()
+	e.SetCatalogue(testCatalogue)
+
+	listener := bufconn.Listen(1 << 20)
+	server := &GrpcServer{Logger: logger.NewLogger(), Config: cfg, Server: grpc.NewServer()}
+	server.RegisterServices()
+	go func() { _ = server.Server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return fxpb.NewFxServiceClient(conn)
+}
+
+func testConfig() *config.Config {
+	return &config.Config{
+		CurrenciesEnabled: []string{"USD", "EUR", "GBP", "JPY"},
+		Mode:              config.First,
+		Rounding:          config.HalfEven,
+	}
+}
+
+// TestGetRateAndConvert checks the unary calls are served from the rates service layer
+func TestGetRateAndConvert(t *testing.T) {
+	client := newTestClient(t, testConfig())
+	cache := ratecache.GetInstance()
+	cache.SetExpiry(3600)
+	cache.Clear()
+	t.Cleanup(cache.Clear)
+	cache.Set("USD", "JPY", decimal.MustParse("151.235"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rate, err := client.GetRate(ctx, &fxpb.GetRateRequest{Base: "usd", Quote: "jpy"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rate.GetBase() != "USD" || rate.GetRate() != "151.235" || !rate.GetCached() {
+		t.Errorf("expected the cached USD/JPY rate, got %v", rate)
+	}
+
+	converted, err := client.Convert(ctx, &fxpb.ConvertRequest{From: "USD", To: "JPY", Amount: "10.5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if converted.GetResult() != "1588" || converted.GetDecimals() != 0 || converted.GetRounding() != "half-even" {
+		t.Errorf("expected 1588 JPY rounded half-even, got %v", converted)
+	}
+
+	var trailer metadata.MD
+	_, err = client.GetRate(ctx, &fxpb.GetRateRequest{Base: "USD", Quote: "XXX"}, grpc.Trailer(&trailer))
+	if status.Code(err) != codes.InvalidArgument || status.Convert(err).Message() != "Invalid quote currency code, XXX" {
+		t.Errorf("expected InvalidArgument for an unsupported currency, got %v", err)
+	}
+	if values := trailer.Get(errorCodeTrailer); len(values) != 1 || values[0] != "eRqCq1" {
+		t.Errorf("expected the eRqCq1 error code in the trailer, got %v", trailer)
+	}
+	_, err = client.Convert(ctx, &fxpb.ConvertRequest{From: "USD", To: "JPY", Amount: "ten"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for an invalid amount, got %v", err)
+	}
+}
+
+// TestStatusFromError checks service errors are mapped to status codes, with the error code in the trailer
+func TestStatusFromError(t *testing.T) {
+	client := newTestClient(t, testConfig())
+	ratecache.GetInstance().Clear()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// No provider is enabled, so the uncached rate cannot be fetched
+	var trailer metadata.MD
+	_, err := client.GetRate(ctx, &fxpb.GetRateRequest{Base: "USD", Quote: "EUR"}, grpc.Trailer(&trailer))
+	if status.Code(err) != codes.Unavailable || status.Convert(err).Message() != "All providers have failed" {
+		t.Errorf("expected Unavailable, got %v", err)
+	}
+	if values := trailer.Get(errorCodeTrailer); len(values) != 1 || values[0] != "eGaPf1" {
+		t.Errorf("expected the eGaPf1 error code in the trailer, got %v", trailer)
+	}
+
+	if code := status.Code(statusFromError(ctx, context.DeadlineExceeded)); code != codes.DeadlineExceeded {
+		t.Errorf("expected DeadlineExceeded, got %v", code)
+	}
+	if code := status.Code(statusFromError(ctx, e.Throw("eUnknown", "test"))); code != codes.Internal {
+		t.Errorf("expected Internal for an uncatalogued code, got %v", code)
+	}
+	if code := status.Code(statusFromError(ctx, e.FromCode("eAlAu1"))); code != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied for a 403 code, got %v", code)
+	}
+}
+
+// TestWatchRates checks the cached rates and the updates are streamed
+func TestWatchRates(t *testing.T) {
+	client := newTestClient(t, testConfig())
+	cache := ratecache.GetInstance()
+	cache.SetExpiry(3600)
+	cache.Clear()
+	t.Cleanup(cache.Clear)
+	cache.Set("USD", "EUR", decimal.MustParse("0.9"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream.Close()
+	watch, err := client.WatchRates(ctx, &fxpb.WatchRatesRequest{Base: "USD", Quotes: []string{"EUR", "GBP"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := watch.Recv(); status.Code(err) != codes.Unavailable {
+		t.Fatalf("expected Unavailable while streaming is disabled, got %v", err)
+	}
+
+	if err := stream.Init(config.StreamConfig{Enabled: true, MaxPairs: 5, HeartbeatSec: 60}, nil); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(stream.Close)
+
+	watch, err = client.WatchRates(ctx, &fxpb.WatchRatesRequest{Base: "USD", Quotes: []string{"EUR", "GBP"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	update, err := watch.Recv()
+	if err != nil || update.GetQuote() != "EUR" || update.GetRate() != "0.9" {
+		t.Fatalf("expected the cached USD/EUR rate, got %v (%v)", update, err)
+	}
+
+	// The stream subscribed before sending the cached rates
+	cache.Set("USD", "GBP", decimal.MustParse("0.8"))
+	update, err = watch.Recv()
+	if err != nil || update.GetType() != stream.MessageRate || update.GetQuote() != "GBP" || update.GetRate() != "0.8" {
+		t.Fatalf("expected the USD/GBP update, got %v (%v)", update, err)
+	}
+}
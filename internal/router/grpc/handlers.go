@@ -0,0 +1,218 @@
+package grpcHandlers
+
+import (
+	"context"
+	"sort"
+
+	"fx-service/internal/service/providers"
+	"fx-service/internal/service/ratecache"
+	"fx-service/internal/service/rates"
+	"fx-service/internal/service/spreads"
+	"fx-service/internal/service/stats"
+	"fx-service/internal/validate"
+	"fx-service/pkg/config"
+	"fx-service/pkg/currency"
+	"fx-service/pkg/fxpb"
+	util "fx-service/pkg/helpers"
+	"google.golang.org/grpc/metadata"
+)
+
+// apiKeyMetadata is the metadata key identifying the client, for the bid/ask spreads
+const apiKeyMetadata = "x-api-key"
+
+// FxServer implements the FxService RPCs with the rates service layer, the same as the REST handlers
+type FxServer struct {
+	fxpb.UnimplementedFxServiceServer
+	Config *config.Config
+}
+
+// apiKey returns the API key sent in the call metadata, or "" if there is none
+func apiKey(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(apiKeyMetadata); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// priceMessage builds the bid, mid and ask prices for a response
+func priceMessage(price spreads.Price) *fxpb.Price {
+	return &fxpb.Price{
+		Bid: price.Bid.String(),
+		Mid: price.Mid.String(),
+		Ask: price.Ask.String(),
+	}
+}
+
+// providerName returns the provider name, when it is shown in the responses
+func providerName(cfg *config.Config, provider *string) string {
+	if !cfg.ShowProvider || provider == nil {
+		return ""
+	}
+	return *provider
+}
+
+// GetRate returns the exchange rate between two currencies
+func (s *FxServer) GetRate(ctx context.Context, req *fxpb.GetRateRequest) (*fxpb.GetRateResponse, error) {
+	ccyBase, ccyQuote, err := validate.CurrencyPair(s.Config, req.GetBase(), req.GetQuote())
+	if err != nil {
+		return nil, statusFromError(ctx, err)
+	}
+
+	rateResult, err := rates.GetRate(ccyBase, ccyQuote, s.Config.Mode)
+	if err != nil {
+		return nil, statusFromError(ctx, err)
+	}
+
+	response := &fxpb.GetRateResponse{
+		Base:     ccyBase,
+		Quote:    ccyQuote,
+		Rate:     rateResult.Rate.String(),
+		Cached:   rateResult.WasCached,
+		Override: rateResult.Override,
+		Provider: providerName(s.Config, rateResult.Provider),
+	}
+	if spreads.Enabled() {
+		response.Price = priceMessage(spreads.Quote(rateResult.Rate, ccyBase, ccyQuote, spreads.ClientFor(apiKey(ctx)), nil))
+	}
+	return response, nil
+}
+
+// GetRates returns the exchange rates between a base currency and multiple quote currencies
+func (s *FxServer) GetRates(ctx context.Context, req *fxpb.GetRatesRequest) (*fxpb.GetRatesResponse, error) {
+	ccyBase, ccyQuoteList, err := validate.BaseAndQuotes(s.Config, req.GetBase(), req.GetQuotes())
+	if err != nil {
+		return nil, statusFromError(ctx, err)
+	}
+
+	rateResult, err := rates.GetRates(ccyBase, ccyQuoteList, s.Config.Mode)
+	if err != nil {
+		return nil, statusFromError(ctx, err)
+	}
+
+	response := &fxpb.GetRatesResponse{
+		Base:      ccyBase,
+		Rates:     make(map[string]string, len(rateResult.Rates)),
+		Cached:    rateResult.WasCached,
+		Overrides: rateResult.Overrides,
+		Provider:  providerName(s.Config, rateResult.Provider),
+	}
+	for quote, rate := range rateResult.Rates {
+		response.Rates[quote] = rate.String()
+	}
+	if spreads.Enabled() {
+		client := spreads.ClientFor(apiKey(ctx))
+		response.Prices = make(map[string]*fxpb.Price, len(rateResult.Rates))
+		for quote, mid := range rateResult.Rates {
+			response.Prices[quote] = priceMessage(spreads.Quote(mid, ccyBase, quote, client, nil))
+		}
+	}
+	return response, nil
+}
+
+// Convert converts an amount between two currencies, rounded to the minor units of the quote currency
+func (s *FxServer) Convert(ctx context.Context, req *fxpb.ConvertRequest) (*fxpb.ConvertResponse, error) {
+	ccyBase, ccyQuote, err := validate.CurrencyPair(s.Config, req.GetFrom(), req.GetTo())
+	if err != nil {
+		return nil, statusFromError(ctx, err)
+	}
+	amount, err := validate.Amount(req.GetAmount())
+	if err != nil {
+		return nil, statusFromError(ctx, err)
+	}
+	rounding, err := validate.Rounding(s.Config, req.GetRounding())
+	if err != nil {
+		return nil, statusFromError(ctx, err)
+	}
+
+	result, err := rates.Convert(ccyBase, ccyQuote, amount, s.Config.Mode, rounding, spreads.ClientFor(apiKey(ctx)))
+	if err != nil {
+		return nil, statusFromError(ctx, err)
+	}
+
+	response := &fxpb.ConvertResponse{
+		Base:     result.Base,
+		Quote:    result.Quote,
+		Amount:   result.Amount.String(),
+		Rate:     result.Rate.String(),
+		Result:   result.Result.String(),
+		Decimals: int32(result.Decimals),
+		Rounding: rounding.String(),
+		Cached:   result.WasCached,
+		Override: result.Override,
+		Provider: providerName(s.Config, result.Provider),
+	}
+	if result.Price != nil {
+		response.Price = priceMessage(*result.Price)
+	}
+	return response, nil
+}
+
+// ListCurrencies returns the enabled currencies, with their metadata and whether any enabled provider supports them
+func (s *FxServer) ListCurrencies(_ context.Context, _ *fxpb.ListCurrenciesRequest) (*fxpb.ListCurrenciesResponse, error) {
+	response := &fxpb.ListCurrenciesResponse{
+		Currencies: make([]*fxpb.Currency, 0, len(s.Config.CurrenciesEnabled)),
+	}
+	for _, code := range s.Config.CurrenciesEnabled {
+		ccy, ok := currency.Lookup(code)
+		if !ok {
+			ccy = currency.Currency{Code: code, Countries: []string{}}
+		}
+		supportedBy := providers.SupportedBy(code)
+
+		message := &fxpb.Currency{
+			Code:       ccy.Code,
+			Numeric:    ccy.Numeric,
+			Name:       ccy.Name,
+			MinorUnits: int32(ccy.MinorUnits),
+			Symbol:     ccy.Symbol,
+			Countries:  ccy.Countries,
+			Iso:        ccy.ISO,
+			Supported:  len(supportedBy) > 0,
+		}
+		if s.Config.ShowProvider {
+			message.Providers = supportedBy
+		}
+		response.Currencies = append(response.Currencies, message)
+	}
+	return response, nil
+}
+
+// Status returns the strategy mode, the request counts, the providers and the cache expiry
+func (s *FxServer) Status(_ context.Context, _ *fxpb.StatusRequest) (*fxpb.StatusResponse, error) {
+	snapshot := stats.GetInstance().GetSnapshot()
+	enabled := util.GetMapKeys(providers.EnabledProviders)
+	available := util.GetMapKeys(providers.InstalledProviders)
+	sort.Strings(enabled)
+	sort.Strings(available)
+	rc := ratecache.GetInstance()
+
+	return &fxpb.StatusResponse{
+		Mode: s.Config.Mode.String(),
+		Stats: &fxpb.Stats{
+			HitCount:     snapshot.HitCount,
+			RequestCount: snapshot.RequestCount,
+			ErrorCount:   snapshot.ErrorCount,
+			FailCount:    snapshot.FailCount,
+			PathCount:    snapshot.PathCount,
+		},
+		EnabledProviders:   enabled,
+		AvailableProviders: available,
+		Cache: &fxpb.CacheStatus{
+			DefaultTtl:   int32(rc.GetExpiry().Seconds()),
+			TtlRules:     toInt32Map(rc.GetTTLRules()),
+			EffectiveTtl: toInt32Map(rc.EffectiveTTLs(s.Config.CurrenciesEnabled)),
+		},
+	}, nil
+}
+
+func toInt32Map(m map[string]int) map[string]int32 {
+	result := make(map[string]int32, len(m))
+	for key, value := range m {
+		result[key] = int32(value)
+	}
+	return result
+}
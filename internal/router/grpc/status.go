@@ -0,0 +1,60 @@
+package grpcHandlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"fx-service/pkg/e"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// errorCodeTrailer is the trailer holding the service error code (e.g. "eGaPf1") of a failed call
+const errorCodeTrailer = "error-code"
+
+// statusCode derives the gRPC status code of a service error from the HTTP status of its catalogue entry
+func statusCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound, http.StatusGone:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	default:
+		return codes.Internal
+	}
+}
+
+// statusFromError converts an error of the service layer, or of the validation of a request, to a gRPC status error.
+// The service error code is set in the error-code trailer, when the call is still open.
+func statusFromError(ctx context.Context, err error) error {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+
+	// Only the public message of an exception is sent, as it may hold internal details
+	public := e.Public(err, http.StatusInternalServerError)
+	if public.Code != "" {
+		_ = grpc.SetTrailer(ctx, metadata.Pairs(errorCodeTrailer, public.Code))
+	}
+	return status.Error(statusCode(public.Status), public.Message)
+}
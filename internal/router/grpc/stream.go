@@ -0,0 +1,42 @@
+package grpcHandlers
+
+import (
+	"fx-service/internal/service/stream"
+	"fx-service/internal/validate"
+	"fx-service/pkg/fxpb"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// rateUpdate builds the message for a stream message
+func rateUpdate(msg stream.Message) *fxpb.RateUpdate {
+	update := &fxpb.RateUpdate{
+		Type:    msg.Type,
+		Base:    msg.Base,
+		Quote:   msg.Quote,
+		Skipped: int32(msg.Skipped),
+		Time:    timestamppb.New(msg.Time),
+	}
+	if msg.Rate != nil {
+		update.Rate = msg.Rate.String()
+	}
+	return update
+}
+
+// WatchRates sends the cached rates of the pairs, then every update, until the client cancels or the service stops
+func (s *FxServer) WatchRates(req *fxpb.WatchRatesRequest, srv grpc.ServerStreamingServer[fxpb.RateUpdate]) error {
+	ccyBase, ccyQuoteList, err := validate.BaseAndQuotes(s.Config, req.GetBase(), req.GetQuotes())
+	if err != nil {
+		return statusFromError(srv.Context(), err)
+	}
+
+	st, err := stream.Open(ccyBase, ccyQuoteList)
+	if err != nil {
+		return statusFromError(srv.Context(), err)
+	}
+	defer st.Close()
+
+	return st.Run(srv.Context().Done(), func(msg stream.Message) error {
+		return srv.Send(rateUpdate(msg))
+	})
+}
@@ -0,0 +1,175 @@
+package jsonrpcHandlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"fx-service/pkg/config"
+	"fx-service/pkg/e"
+)
+
+// Version is the only JSON-RPC version supported
+const Version = "2.0"
+
+// MaxBatch is the maximum number of calls in a single batch
+const MaxBatch = 100
+
+// Error codes defined by the JSON-RPC 2.0 specification
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// Error codes of the service errors, in the range reserved for implementation-defined server errors
+const (
+	CodeServerError = -32000 // Service errors without a more specific code
+	CodeUnavailable = -32001 // The service is unavailable, e.g. the rates could not be fetched from any provider
+	CodeNotFound    = -32002 // What was asked for was not found, e.g. the provider did not return the rate
+)
+
+// errorCode derives the JSON-RPC code of a service error from the HTTP status of its catalogue entry
+func errorCode(status int) int {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeInvalidParams
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return CodeUnavailable
+	default:
+		return CodeServerError
+	}
+}
+
+// Request is a single call. A call without an id is a notification, which gets no response.
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// Response is the response to a single call: either a result, or an error
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// Error is the error of a failed call. The data holds the service error code and retryable flag (e.g. {"code": "eGaPf1", "retryable": true}), if any.
+type Error struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+func (err *Error) Error() string {
+	return err.Message
+}
+
+// invalidParams returns an error for params which are missing, malformed or not supported
+func invalidParams(err error) *Error {
+	return &Error{Code: CodeInvalidParams, Message: err.Error()}
+}
+
+// errorFromService converts an error of the service layer to a JSON-RPC error
+func errorFromService(err error) *Error {
+	var rpcErr *Error
+	if errors.As(err, &rpcErr) {
+		return rpcErr
+	}
+
+	// Only the public message of an exception is sent, as it may hold internal details
+	public := e.Public(err, http.StatusInternalServerError)
+	if public.Code == "" {
+		return &Error{Code: CodeInternalError, Message: public.Message}
+	}
+	return &Error{Code: errorCode(public.Status), Message: public.Message, Data: map[string]interface{}{"code": public.Code, "retryable": public.Retryable}}
+}
+
+// errorResponse returns the response to a call which failed
+func errorResponse(id json.RawMessage, err *Error) *Response {
+	return &Response{JSONRPC: Version, Error: err, ID: id}
+}
+
+// isValidID checks the id is a string, a number or null
+func isValidID(id json.RawMessage) bool {
+	if len(id) == 0 {
+		return true
+	}
+	switch id[0] {
+	case '"', '-', 'n', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		return true
+	}
+	return false
+}
+
+// call runs a single call, and returns its response, or nil for a notification
+func call(cfg *config.Config, raw json.RawMessage, apiKey string) *Response {
+	var req Request
+	if err := json.Unmarshal(raw, &req); err != nil || req.JSONRPC != Version || req.Method == "" || !isValidID(req.ID) {
+		return errorResponse(nil, &Error{Code: CodeInvalidRequest, Message: "Invalid Request"})
+	}
+
+	method, ok := methods[req.Method]
+	var result interface{}
+	var err error
+	if ok {
+		result, err = method(&params{cfg: cfg, apiKey: apiKey, raw: req.Params})
+	} else {
+		err = &Error{Code: CodeMethodNotFound, Message: fmt.Sprintf("Method not found: %s", req.Method)}
+	}
+
+	if req.ID == nil {
+		return nil
+	}
+	if err != nil {
+		return errorResponse(req.ID, errorFromService(err))
+	}
+	return &Response{JSONRPC: Version, Result: result, ID: req.ID}
+}
+
+// Handle runs a single call or a batch of calls, and returns the response or the list of responses.
+// It returns nil when there is nothing to respond, as every call was a notification.
+// Each call of a batch succeeds or fails on its own. The responses are in the order of the calls, without the notifications.
+func Handle(cfg *config.Config, body []byte, apiKey string) interface{} {
+	body = bytes.TrimSpace(body)
+	if !json.Valid(body) {
+		return errorResponse(nil, &Error{Code: CodeParseError, Message: "Parse error"})
+	}
+	if body[0] != '[' {
+		if response := call(cfg, body, apiKey); response != nil {
+			return response
+		}
+		return nil
+	}
+
+	var batch []json.RawMessage
+	if err := json.Unmarshal(body, &batch); err != nil || len(batch) == 0 {
+		return errorResponse(nil, &Error{Code: CodeInvalidRequest, Message: "Invalid Request"})
+	}
+	if len(batch) > MaxBatch {
+		return errorResponse(nil, &Error{
+			Code:    CodeInvalidRequest,
+			Message: fmt.Sprintf("Too many calls in the batch, the maximum is %d", MaxBatch),
+		})
+	}
+
+	responses := make([]*Response, 0, len(batch))
+	for _, raw := range batch {
+		if response := call(cfg, raw, apiKey); response != nil {
+			responses = append(responses, response)
+		}
+	}
+	if len(responses) == 0 {
+		return nil
+	}
+	return responses
+}
@@ -0,0 +1,163 @@
+package jsonrpcHandlers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"fx-service/internal/service/providers"
+	"fx-service/internal/service/ratecache"
+	"fx-service/pkg/config"
+	"fx-service/pkg/decimal"
+	"fx-service/pkg/e"
+)
+
+func testConfig() *config.Config {
+	return &config.Config{
+		CurrenciesEnabled: []string{"USD", "EUR", "GBP", "JPY"},
+		Mode:              config.First,
+		Rounding:          config.HalfEven,
+	}
+}
+
+// handleJSON runs the body, and returns the response encoded then decoded, as a client would see it
+func handleJSON(t *testing.T, body string) interface{} {
+	t.Helper()
+	response := Handle(testConfig(), []byte(body), "")
+	if response == nil {
+		return nil
+	}
+	encoded, err := json.Marshal(response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	return decoded
+}
+
+// errorOf returns the error code and data of a response
+func errorOf(response interface{}) (float64, interface{}) {
+	rpcErr, ok := response.(map[string]interface{})["error"].(map[string]interface{})
+	if !ok {
+		return 0, nil
+	}
+	return rpcErr["code"].(float64), rpcErr["data"]
+}
+
+// setUpCatalogue sets the codes the calls may fail with, with their statuses
+func setUpCatalogue(t *testing.T) {
+	previous := e.Catalogue()
+	e.SetCatalogue(e.ErrorMap{
+		"eGaPf1": {Message: "All providers have failed", Status: 503},
+		"eRqCm1": {Message: "Missing currency codes", Status: 400},
+		"eRqCq1": {Message: "Invalid quote currency code, %s", Status: 400},
+		"eRqAm1": {Message: "Missing amount to convert", Status: 400},
+	})
+	t.Cleanup(func() { e.SetCatalogue(previous) })
+}
+
+func setUpCache(t *testing.T) {
+	setUpCatalogue(t)
+	cache := ratecache.GetInstance()
+	cache.SetExpiry(3600)
+	cache.Clear()
+	t.Cleanup(cache.Clear)
+	cache.Set("USD", "EUR", decimal.MustParse("0.9"))
+	cache.Set("USD", "JPY", decimal.MustParse("151.235"))
+}
+
+// TestHandleCall checks the params can be given by name or by position
+func TestHandleCall(t *testing.T) {
+	setUpCache(t)
+
+	response := handleJSON(t, `{"jsonrpc":"2.0","method":"rates.get","params":{"base":"usd","quote":"eur"},"id":1}`)
+	result := response.(map[string]interface{})
+	if result["id"] != 1.0 || result["jsonrpc"] != "2.0" || result["result"].(map[string]interface{})["rate"] != "0.9" {
+		t.Errorf("expected the cached USD/EUR rate, got %v", response)
+	}
+
+	response = handleJSON(t, `{"jsonrpc":"2.0","method":"rates.convert","params":["USD","JPY","10.5"],"id":"a"}`)
+	result = response.(map[string]interface{})
+	if result["id"] != "a" || result["result"].(map[string]interface{})["result"] != "1588" {
+		t.Errorf("expected 1588 JPY, got %v", response)
+	}
+
+	response = handleJSON(t, `{"jsonrpc":"2.0","method":"rates.getMany","params":["USD","EUR"],"id":2}`)
+	if code, _ := errorOf(response); code != CodeInvalidParams {
+		t.Errorf("expected invalid params for a string of quotes, got %v", response)
+	}
+}
+
+// TestHandleBatch checks each call of a batch succeeds or fails on its own, and notifications get no response
+func TestHandleBatch(t *testing.T) {
+	setUpCache(t)
+
+	response := handleJSON(t, `[
+		{"jsonrpc":"2.0","method":"rates.getMany","params":{"base":"USD","quotes":["EUR","JPY"]},"id":1},
+		{"jsonrpc":"2.0","method":"rates.get","params":{"base":"USD","quote":"XXX"},"id":2},
+		{"jsonrpc":"2.0","method":"rates.delete","id":3},
+		{"jsonrpc":"2.0","method":"status.get"},
+		{"jsonrpc":"1.0","method":"status.get","id":4},
+		5
+	]`)
+	responses, ok := response.([]interface{})
+	if !ok || len(responses) != 5 {
+		t.Fatalf("expected 5 responses, got %v", response)
+	}
+
+	quotes := responses[0].(map[string]interface{})["result"].(map[string]interface{})["quotes"].(map[string]interface{})
+	if quotes["EUR"] != "0.9" || quotes["JPY"] != "151.235" {
+		t.Errorf("expected the cached USD rates, got %v", responses[0])
+	}
+	expected := []float64{CodeInvalidParams, CodeMethodNotFound, CodeInvalidRequest, CodeInvalidRequest}
+	for i, code := range expected {
+		if actual, _ := errorOf(responses[i+1]); actual != code {
+			t.Errorf("expected error %d for response %d, got %v", int(code), i+1, responses[i+1])
+		}
+	}
+
+	if response := handleJSON(t, `[{"jsonrpc":"2.0","method":"status.get"}]`); response != nil {
+		t.Errorf("expected no response to notifications, got %v", response)
+	}
+	if code, _ := errorOf(handleJSON(t, `[]`)); code != CodeInvalidRequest {
+		t.Errorf("expected an empty batch to be invalid")
+	}
+	if code, _ := errorOf(handleJSON(t, `[{"jsonrpc":"2.0"`)); code != CodeParseError {
+		t.Errorf("expected a parse error")
+	}
+}
+
+// TestHandleServiceError checks the service errors are converted with their catalogue code in the data
+func TestHandleServiceError(t *testing.T) {
+	setUpCatalogue(t)
+	ratecache.GetInstance().Clear()
+	previous := providers.EnabledProviders
+	providers.EnabledProviders = map[string]providers.ProviderInterface{}
+	t.Cleanup(func() { providers.EnabledProviders = previous })
+
+	response := handleJSON(t, `{"jsonrpc":"2.0","method":"rates.get","params":["USD","GBP"],"id":1}`)
+	code, data := errorOf(response)
+	if code != CodeUnavailable || data.(map[string]interface{})["code"] != "eGaPf1" {
+		t.Errorf("expected eGaPf1 as an unavailable error, got %v", response)
+	}
+}
+
+// TestHandleValidationError checks the invalid currencies and amounts are invalid params, with their catalogue code in the data
+func TestHandleValidationError(t *testing.T) {
+	setUpCache(t)
+
+	calls := map[string]string{
+		`{"jsonrpc":"2.0","method":"rates.get","params":["USD","XXX"],"id":1}`:                  "eRqCq1",
+		`{"jsonrpc":"2.0","method":"rates.getMany","params":{"base":"USD","quotes":[]},"id":2}`: "eRqCm1",
+		`{"jsonrpc":"2.0","method":"rates.convert","params":{"from":"USD","to":"EUR"},"id":3}`:  "eRqAm1",
+	}
+	for call, expected := range calls {
+		response := handleJSON(t, call)
+		code, data := errorOf(response)
+		if code != CodeInvalidParams || data == nil || data.(map[string]interface{})["code"] != expected {
+			t.Errorf("expected %s as invalid params, got %v", expected, response)
+		}
+	}
+}
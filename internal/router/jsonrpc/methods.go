@@ -0,0 +1,224 @@
+package jsonrpcHandlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"fx-service/internal/service/providers"
+	"fx-service/internal/service/ratecache"
+	"fx-service/internal/service/rates"
+	"fx-service/internal/service/spreads"
+	"fx-service/internal/service/stats"
+	"fx-service/internal/validate"
+	"fx-service/pkg/config"
+	"fx-service/pkg/decimal"
+	"fx-service/pkg/e"
+	util "fx-service/pkg/helpers"
+)
+
+// params holds the params of a call, with the configuration and the API key of the request
+type params struct {
+	cfg    *config.Config
+	apiKey string
+	raw    json.RawMessage
+}
+
+// decode decodes the params, given by name as an object or by position as an array, in the order of the names
+func (p *params) decode(target interface{}, names ...string) error {
+	raw := bytes.TrimSpace(p.raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		raw = []byte("{}")
+	}
+
+	if raw[0] == '[' {
+		var positional []json.RawMessage
+		if err := json.Unmarshal(raw, &positional); err != nil {
+			return invalidParams(err)
+		}
+		if len(positional) > len(names) {
+			return invalidParams(fmt.Errorf("too many params, expected %s", strings.Join(names, ", ")))
+		}
+		named := make(map[string]json.RawMessage, len(positional))
+		for i, value := range positional {
+			named[names[i]] = value
+		}
+		var err error
+		if raw, err = json.Marshal(named); err != nil {
+			return invalidParams(err)
+		}
+	} else if raw[0] != '{' {
+		return invalidParams(fmt.Errorf("params must be an object or an array"))
+	}
+
+	if err := json.Unmarshal(raw, target); err != nil {
+		return invalidParams(fmt.Errorf("invalid params, expected %s", strings.Join(names, ", ")))
+	}
+	return nil
+}
+
+// methods are the methods which can be called, by name
+var methods = map[string]func(p *params) (interface{}, error){
+	"rates.get":     getRate,
+	"rates.getMany": getRates,
+	"rates.convert": convert,
+	"status.get":    getStatus,
+}
+
+// addPrices adds the bid, mid and ask prices of a single rate to a result, when spreads are enabled
+func addPrices(p *params, result map[string]interface{}, from, to string, mid decimal.Decimal) {
+	if !spreads.Enabled() {
+		return
+	}
+	price := spreads.Quote(mid, from, to, spreads.ClientFor(p.apiKey), nil)
+	result["bid"] = price.Bid
+	result["mid"] = price.Mid
+	result["ask"] = price.Ask
+}
+
+// getRate returns the exchange rate between two currencies. Params: {"base", "quote"}
+func getRate(p *params) (interface{}, error) {
+	var req struct {
+		Base  string `json:"base"`
+		Quote string `json:"quote"`
+	}
+	if err := p.decode(&req, "base", "quote"); err != nil {
+		return nil, err
+	}
+	ccyBase, ccyQuote, err := validate.CurrencyPair(p.cfg, req.Base, req.Quote)
+	if err != nil {
+		return nil, err
+	}
+
+	rateResult, err := rates.GetRate(ccyBase, ccyQuote, p.cfg.Mode)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]interface{}{
+		"base":     ccyBase,
+		"quote":    ccyQuote,
+		"rate":     rateResult.Rate,
+		"cached":   rateResult.WasCached,
+		"override": rateResult.Override,
+	}
+	addPrices(p, result, ccyBase, ccyQuote, rateResult.Rate)
+	if p.cfg.ShowProvider {
+		result["provider"] = rateResult.Provider
+	}
+	return result, nil
+}
+
+// getRates returns the exchange rates between a base currency and several quote currencies. Params: {"base", "quotes"}
+func getRates(p *params) (interface{}, error) {
+	var req struct {
+		Base   string   `json:"base"`
+		Quotes []string `json:"quotes"`
+	}
+	if err := p.decode(&req, "base", "quotes"); err != nil {
+		return nil, err
+	}
+	ccyBase, ccyQuoteList, err := validate.BaseAndQuotes(p.cfg, req.Base, req.Quotes)
+	if err != nil {
+		return nil, err
+	}
+
+	rateResult, err := rates.GetRates(ccyBase, ccyQuoteList, p.cfg.Mode)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]interface{}{
+		"base":   ccyBase,
+		"quotes": rateResult.Rates,
+		"cached": rateResult.WasCached,
+	}
+	if len(rateResult.Overrides) > 0 {
+		result["overrides"] = rateResult.Overrides
+	}
+	if spreads.Enabled() {
+		client := spreads.ClientFor(p.apiKey)
+		prices := make(map[string]interface{}, len(rateResult.Rates))
+		for to, mid := range rateResult.Rates {
+			price := spreads.Quote(mid, ccyBase, to, client, nil)
+			prices[to] = map[string]interface{}{"bid": price.Bid, "mid": price.Mid, "ask": price.Ask}
+		}
+		result["prices"] = prices
+	}
+	if p.cfg.ShowProvider {
+		result["provider"] = rateResult.Provider
+	}
+	return result, nil
+}
+
+// convert converts an amount between two currencies, rounded to the minor units of the quote currency.
+// Params: {"from", "to", "amount", "rounding"}. The amount is a JSON number or string, and the rounding mode is optional.
+func convert(p *params) (interface{}, error) {
+	var req struct {
+		From     string           `json:"from"`
+		To       string           `json:"to"`
+		Amount   *decimal.Decimal `json:"amount"`
+		Rounding string           `json:"rounding"`
+	}
+	if err := p.decode(&req, "from", "to", "amount", "rounding"); err != nil {
+		return nil, err
+	}
+	ccyBase, ccyQuote, err := validate.CurrencyPair(p.cfg, req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+	if req.Amount == nil {
+		return nil, e.FromCode("eRqAm1")
+	}
+	rounding, err := validate.Rounding(p.cfg, req.Rounding)
+	if err != nil {
+		return nil, err
+	}
+
+	convertResult, err := rates.Convert(ccyBase, ccyQuote, *req.Amount, p.cfg.Mode, rounding, spreads.ClientFor(p.apiKey))
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]interface{}{
+		"base":     convertResult.Base,
+		"quote":    convertResult.Quote,
+		"amount":   convertResult.Amount,
+		"rate":     convertResult.Rate,
+		"result":   convertResult.Result,
+		"decimals": convertResult.Decimals,
+		"rounding": rounding.String(),
+		"cached":   convertResult.WasCached,
+		"override": convertResult.Override,
+	}
+	if convertResult.Price != nil {
+		result["bid"] = convertResult.Price.Bid
+		result["mid"] = convertResult.Price.Mid
+		result["ask"] = convertResult.Price.Ask
+	}
+	if p.cfg.ShowProvider {
+		result["provider"] = convertResult.Provider
+	}
+	return result, nil
+}
+
+// getStatus returns the strategy mode, the request counts, the providers and the cache expiry. No params.
+func getStatus(p *params) (interface{}, error) {
+	enabled := util.GetMapKeys(providers.EnabledProviders)
+	available := util.GetMapKeys(providers.InstalledProviders)
+	rc := ratecache.GetInstance()
+	return map[string]interface{}{
+		"mode":  p.cfg.Mode.String(),
+		"stats": stats.GetInstance().GetStats(),
+		"providers": map[string]interface{}{
+			"enabled":   enabled,
+			"available": available,
+		},
+		"cache": map[string]interface{}{
+			"defaultTtl":   int(rc.GetExpiry().Seconds()),
+			"ttlRules":     rc.GetTTLRules(),
+			"effectiveTtl": rc.EffectiveTTLs(p.cfg.CurrenciesEnabled),
+		},
+	}, nil
+}
@@ -0,0 +1,90 @@
+package nethttpHandlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	coreHandlers "fx-service/internal/router/core"
+	"fx-service/internal/service/stats"
+)
+
+// Pattern converts a route path from the :param syntax to the {param} syntax of net/http and chi
+func Pattern(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if name, found := strings.CutPrefix(segment, ":"); found {
+			segments[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// paramNames returns the names of the path parameters of a route path in the :param syntax
+func paramNames(path string) []string {
+	var names []string
+	for _, segment := range strings.Split(path, "/") {
+		if name, found := strings.CutPrefix(segment, ":"); found {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// NewRequest copies what the handlers need from a net/http request, with a body of at most maxBodyBytes
+// and the given path parameters. Both net/http and chi set the path parameters on the request.
+func NewRequest(w http.ResponseWriter, r *http.Request, maxBodyBytes int64, names ...string) (*coreHandlers.Request, error) {
+	params := make(map[string]string, len(names))
+	for _, name := range names {
+		params[name] = r.PathValue(name)
+	}
+
+	body, err := coreHandlers.ReadBody(w, r, maxBodyBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &coreHandlers.Request{
+		Method:  r.Method,
+		Params:  params,
+		Query:   r.URL.Query(),
+		Header:  r.Header,
+		Body:    body,
+		Context: r.Context(),
+	}, nil
+}
+
+// Send sends a handler response to the client
+func Send(w http.ResponseWriter, r *http.Request, res *coreHandlers.Response) {
+	defer stats.GetInstance().IncRequestCount()
+	for key, value := range res.Header {
+		w.Header().Set(key, value)
+	}
+	switch {
+	case res.File != "":
+		http.ServeFile(w, r, res.File)
+	case res.Raw != nil:
+		w.WriteHeader(res.Status)
+		_, _ = w.Write(res.Raw)
+	case res.Body == nil:
+		w.WriteHeader(res.Status)
+	default:
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(res.Status)
+		_ = json.NewEncoder(w).Encode(res.Body)
+	}
+}
+
+// Adapt serves a router-agnostic handler with net/http, for a route path in the :param syntax
+// and request bodies of at most maxBodyBytes
+func Adapt(path string, handler coreHandlers.Handler, maxBodyBytes int64) http.HandlerFunc {
+	names := paramNames(path)
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := NewRequest(w, r, maxBodyBytes, names...)
+		if err != nil {
+			Send(w, r, coreHandlers.Error(http.StatusBadRequest, err))
+			return
+		}
+		Send(w, r, handler(req))
+	}
+}
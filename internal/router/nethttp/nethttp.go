@@ -0,0 +1,54 @@
+package nethttpHandlers
+
+import (
+	"net/http"
+
+	"fx-service/internal/middleware"
+	coreHandlers "fx-service/internal/router/core"
+	"fx-service/pkg/config"
+	"fx-service/pkg/logger"
+)
+
+// HTTPRouter serves the API with the standard library ServeMux.
+// It is an http.Handler, so it can be mounted in an existing http.Server.
+type HTTPRouter struct {
+	Logger  *logger.Logger
+	Config  *config.Config
+	Mux     *http.ServeMux
+	handler http.Handler // The mux, wrapped in the middleware
+}
+
+func (r *HTTPRouter) RegisterMiddleware() {
+	r.handler = middleware.HTTPLogger(r.Logger)(middleware.HTTPRateLimiter(r.Config.RateLimiter)(r.Mux))
+}
+
+func (r *HTTPRouter) RegisterRoutes() {
+	// Register the routes shared by every router
+	for _, route := range coreHandlers.Routes(r.Config) {
+		var handler http.Handler = Adapt(route.Path, route.Handler, r.Config.MaxBodyBytes)
+		if route.Admin {
+			handler = middleware.HTTPAdminAuth(r.Config.Admin)(handler)
+		}
+		r.Mux.Handle(route.Method+" "+Pattern(route.Path), handler)
+	}
+
+	// Register the streaming routes, only if enabled
+	for _, route := range coreHandlers.StreamRoutes(r.Config) {
+		r.Mux.Handle(route.Method+" "+route.Path, StreamRates(r.Config, route.Version)) // ?base=USD&quote=EUR,GBP (Server-Sent Events, or WebSocket on upgrade)
+	}
+
+	// Handle 404
+	r.Mux.Handle("/", Adapt("/", coreHandlers.NotFound(r.Config), r.Config.MaxBodyBytes))
+}
+
+func (r *HTTPRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if r.handler == nil {
+		r.Mux.ServeHTTP(w, req)
+		return
+	}
+	r.handler.ServeHTTP(w, req)
+}
+
+func (r *HTTPRouter) Serve(addr string) error {
+	return http.ListenAndServe(addr, r)
+}
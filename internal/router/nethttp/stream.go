@@ -0,0 +1,58 @@
+package nethttpHandlers
+
+import (
+	"net/http"
+	"time"
+
+	coreHandlers "fx-service/internal/router/core"
+	"fx-service/internal/service/stream"
+	"fx-service/pkg/config"
+)
+
+// StreamRates pushes the rates of a base currency against the quote currencies, every time they are updated.
+// WebSocket upgrade requests get a WebSocket of JSON messages, other requests get Server-Sent Events.
+func StreamRates(cfg *config.Config, version coreHandlers.APIVersion) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := NewRequest(w, r, cfg.MaxBodyBytes)
+		if err != nil {
+			Send(w, r, coreHandlers.Error(http.StatusBadRequest, err))
+			return
+		}
+		s, isWebSocket, failed := coreHandlers.OpenStream(cfg, version, req)
+		if failed != nil {
+			Send(w, r, failed)
+			return
+		}
+		defer s.Close()
+
+		rc := http.NewResponseController(w)
+		if isWebSocket {
+			conn, rw, err := rc.Hijack()
+			if err != nil {
+				Send(w, r, coreHandlers.Error(http.StatusInternalServerError, err.Error()))
+				return
+			}
+			ws, err := stream.Accept(conn, rw.Reader, req.Header.Get(stream.HeaderWebSocketKey), s.WriteTimeout())
+			if err != nil {
+				return
+			}
+			_ = s.ServeWebSocket(ws)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.Header().Set("X-Accel-Buffering", "no") // Stop reverse proxies from buffering the events
+		w.WriteHeader(http.StatusOK)
+
+		// Clients which stop reading are disconnected after the write timeout
+		_ = s.ServeSSE(r.Context().Done(), func(event []byte) error {
+			_ = rc.SetWriteDeadline(time.Now().Add(s.WriteTimeout()))
+			if _, err := w.Write(event); err != nil {
+				return err
+			}
+			return rc.Flush()
+		})
+	}
+}
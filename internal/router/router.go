@@ -1,15 +1,29 @@
 package router
 
 import (
+	"errors"
+	"math"
+	"net/http"
+
+	"fx-service/internal/middleware"
+	"fx-service/internal/router/chi"
+	coreHandlers "fx-service/internal/router/core"
 	"fx-service/internal/router/fiber"
 	"fx-service/internal/router/gin"
+	"fx-service/internal/router/grpc"
+	"fx-service/internal/router/nethttp"
 	"fx-service/pkg/config"
+	"fx-service/pkg/e"
 	"fx-service/pkg/logger"
 	"github.com/gin-gonic/gin"
+	"github.com/go-chi/chi/v5"
 	"github.com/gofiber/fiber/v2"
+	"google.golang.org/grpc"
 )
 
+// Router serves the API. Every router is also an http.Handler, so it can be mounted in an existing http.Server.
 type Router interface {
+	http.Handler
 	RegisterMiddleware()
 	RegisterRoutes()
 	Serve(addr string) error
@@ -19,7 +33,13 @@ type Router interface {
 func NewFiberRouter(logger *logger.Logger, config *config.Config) Router {
 	// Create a new Fiber app with a custom error handler
 	fiberApp := fiber.New(fiber.Config{
+		BodyLimit: fiberBodyLimit(config.MaxBodyBytes),
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
+			var fiberErr *fiber.Error
+			if errors.As(err, &fiberErr) && fiberErr.Code == fiber.StatusRequestEntityTooLarge {
+				res := coreHandlers.Error(fiberErr.Code, e.FromCode("eRqTl1", config.MaxBodyBytes))
+				return c.Status(res.Status).JSON(res.Body)
+			}
 			return c.Status(fiber.StatusInternalServerError).SendString(err.Error())
 		},
 	})
@@ -31,6 +51,14 @@ func NewFiberRouter(logger *logger.Logger, config *config.Config) Router {
 	}
 }
 
+// fiberBodyLimit returns the Fiber BodyLimit for the configured maximum body size, where 0 means no limit
+func fiberBodyLimit(maxBodyBytes int64) int {
+	if maxBodyBytes <= 0 || maxBodyBytes > math.MaxInt32 {
+		return math.MaxInt32
+	}
+	return int(maxBodyBytes)
+}
+
 // NewGinRouter creates a new instance of the GinRouter
 func NewGinRouter(logger *logger.Logger, config *config.Config) Router {
 	//gin.SetMode(gin.ReleaseMode) // TODO - Set this in the config
@@ -42,3 +70,35 @@ func NewGinRouter(logger *logger.Logger, config *config.Config) Router {
 		Engine: engine,
 	}
 }
+
+// NewHTTPRouter creates a new instance of the HTTPRouter, with the standard library ServeMux
+func NewHTTPRouter(logger *logger.Logger, config *config.Config) Router {
+	return &nethttpHandlers.HTTPRouter{
+		Logger: logger,
+		Config: config,
+		Mux:    http.NewServeMux(),
+	}
+}
+
+// NewChiRouter creates a new instance of the ChiRouter
+func NewChiRouter(logger *logger.Logger, config *config.Config) Router {
+	return &chiHandlers.ChiRouter{
+		Logger: logger,
+		Config: config,
+		Mux:    chi.NewRouter(),
+	}
+}
+
+// NewGrpcServer creates a new instance of the GrpcServer, with the logging interceptors
+func NewGrpcServer(logger *logger.Logger, config *config.Config) *grpcHandlers.GrpcServer {
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(middleware.GrpcLogger(logger)),
+		grpc.StreamInterceptor(middleware.GrpcStreamLogger(logger)),
+	)
+
+	return &grpcHandlers.GrpcServer{
+		Logger: logger,
+		Config: config,
+		Server: server,
+	}
+}
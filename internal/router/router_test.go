@@ -0,0 +1,730 @@
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"context"
+	chiHandlers "fx-service/internal/router/chi"
+	coreHandlers "fx-service/internal/router/core"
+	fiberHandlers "fx-service/internal/router/fiber"
+	ginHandlers "fx-service/internal/router/gin"
+	nethttpHandlers "fx-service/internal/router/nethttp"
+	"fx-service/internal/service/ratecache"
+	"fx-service/internal/service/rates"
+	"fx-service/pkg/config"
+	"fx-service/pkg/decimal"
+	"fx-service/pkg/e"
+	"fx-service/pkg/fxclient"
+	"fx-service/pkg/fxpb"
+	"fx-service/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/go-chi/chi/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+// contractCase is a request, with the response every router must send
+type contractCase struct {
+	name   string
+	method string
+	path   string
+	header map[string]string
+	body   string
+	status int
+	check  func(t *testing.T, body map[string]interface{})
+}
+
+func contractConfig() *config.Config {
+	return &config.Config{
+		CurrenciesEnabled: []string{"USD", "EUR", "GBP", "JPY"},
+		Mode:              config.First,
+		Rounding:          config.HalfEven,
+		RateLimiter:       config.RateLimiterConfig{Enabled: true, MaxRequests: 1000, Timeframe: 1},
+		Admin:             config.AdminConfig{Enabled: true, Token: "secret"},
+		JSONRPC:           config.JSONRPCConfig{Enabled: true},
+		OpenAPI:           config.OpenAPIConfig{Enabled: true},
+	}
+}
+
+// contractRouters returns a router of each kind, with the middleware and routes registered
+func contractRouters(cfg *config.Config) map[string]Router {
+	gin.SetMode(gin.TestMode)
+	log := logger.NewLogger()
+	log.Out = io.Discard
+
+	routers := map[string]Router{
+		"fiber":    NewFiberRouter(log, cfg),
+		"gin":      NewGinRouter(log, cfg),
+		"net/http": NewHTTPRouter(log, cfg),
+		"chi":      NewChiRouter(log, cfg),
+	}
+	for _, r := range routers {
+		r.RegisterMiddleware()
+		r.RegisterRoutes()
+	}
+	return routers
+}
+
+// roundTrip sends a request to a router, and returns its response
+func roundTrip(t *testing.T, r Router, req *http.Request) *http.Response {
+	t.Helper()
+	if fr, ok := r.(*fiberHandlers.FiberRouter); ok {
+		res, err := fr.App.Test(req, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, req)
+	return recorder.Result()
+}
+
+// serve sends a request to a router, and returns the status and the decoded JSON body, if any
+func serve(t *testing.T, r Router, req *http.Request) (int, map[string]interface{}) {
+	t.Helper()
+	res := roundTrip(t, r, req)
+	defer res.Body.Close()
+
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var body map[string]interface{}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &body); err != nil {
+			t.Fatalf("expected a JSON body, got %q", raw)
+		}
+	}
+	return res.StatusCode, body
+}
+
+// result returns the result of a response in the standard shape
+func result(body map[string]interface{}) map[string]interface{} {
+	result, _ := body["result"].(map[string]interface{})
+	return result
+}
+
+// expectError checks the error of a response in the standard shape. Catalogued errors have their code,
+// the others (e.g. of the middleware) only have their message.
+func expectError(code, message string) func(t *testing.T, body map[string]interface{}) {
+	return func(t *testing.T, body map[string]interface{}) {
+		var expected interface{} = message
+		if code != "" {
+			expected = map[string]interface{}{"code": code, "message": message, "retryable": false}
+		}
+		if !reflect.DeepEqual(body["error"], expected) || body["result"] != nil {
+			t.Errorf("expected the error %v, got %v", expected, body)
+		}
+	}
+}
+
+// contractCatalogue holds the error codes the contract cases are answered with
+var contractCatalogue = e.ErrorMap{
+	"eRqNf1": {Message: "Not found", Status: http.StatusNotFound},
+	"eRqCm1": {Message: "Missing base or quote currency codes. Ensure URL and query is correct", Status: http.StatusBadRequest},
+	"eRqCq1": {Message: "Invalid quote currency code, %s", Status: http.StatusBadRequest},
+	"eCvNo1": {Message: "No conversions requested", Status: http.StatusBadRequest},
+	"eCvIt1": {Message: "Conversion %d: %s", Status: http.StatusBadRequest},
+	"eRqAm1": {Message: "Missing amount to convert", Status: http.StatusBadRequest},
+}
+
+var contractCases = []contractCase{
+	{
+		name: "rate", method: http.MethodGet, path: "/rate/usd/eur", status: http.StatusOK,
+		check: func(t *testing.T, body map[string]interface{}) {
+			if r := result(body); r["base"] != "USD" || r["quote"] != "EUR" || r["rate"] != "0.9" || r["cached"] != true {
+				t.Errorf("expected the cached USD/EUR rate, got %v", body)
+			}
+		},
+	},
+	{
+		name: "rate with an unsupported currency", method: http.MethodGet, path: "/rate/USD/XXX", status: http.StatusBadRequest,
+		check: expectError("eRqCq1", "Invalid quote currency code, XXX"),
+	},
+	{
+		name: "rates as documented", method: http.MethodGet, path: "/rates?from=USD&to=EUR,JPY", status: http.StatusOK,
+		check: func(t *testing.T, body map[string]interface{}) {
+			quotes, _ := result(body)["quotes"].(map[string]interface{})
+			if quotes["EUR"] != "0.9" || quotes["JPY"] != "151.235" {
+				t.Errorf("expected the cached USD rates, got %v", body)
+			}
+		},
+	},
+	{
+		name: "rates with base and quote", method: http.MethodGet, path: "/rates?base=USD&quote=EUR", status: http.StatusOK,
+		check: func(t *testing.T, body map[string]interface{}) {
+			if result(body)["base"] != "USD" {
+				t.Errorf("expected the cached USD rates, got %v", body)
+			}
+		},
+	},
+	{
+		name: "rates without quotes", method: http.MethodGet, path: "/rates?from=USD", status: http.StatusBadRequest,
+		check: expectError("eRqCm1", "Missing base or quote currency codes. Ensure URL and query is correct"),
+	},
+	{
+		name: "convert", method: http.MethodGet, path: "/convert?from=USD&to=JPY&amount=10.5&format=true", status: http.StatusOK,
+		check: func(t *testing.T, body map[string]interface{}) {
+			if r := result(body); r["result"] != "1588" || r["formatted"] != "1,588" || r["rounding"] != "half-even" {
+				t.Errorf("expected 1588 JPY, got %v", body)
+			}
+		},
+	},
+	{
+		name: "convert batch", method: http.MethodPost, path: "/convert", body: `[{"from":"USD","to":"EUR","amount":"100"}]`, status: http.StatusOK,
+		check: func(t *testing.T, body map[string]interface{}) {
+			conversions, _ := result(body)["conversions"].([]interface{})
+			if len(conversions) != 1 || conversions[0].(map[string]interface{})["result"] != "90.00" {
+				t.Errorf("expected 90.00 EUR, got %v", body)
+			}
+		},
+	},
+	{
+		name: "empty convert batch", method: http.MethodPost, path: "/convert", body: `[]`, status: http.StatusBadRequest,
+		check: expectError("eCvNo1", "No conversions requested"),
+	},
+	{
+		name: "convert batch without an amount", method: http.MethodPost, path: "/convert",
+		body: `[{"from":"USD","to":"EUR","amount":"1"},{"from":"USD","to":"JPY"}]`, status: http.StatusBadRequest,
+		check: expectError("eCvIt1", "Conversion 1: Missing amount to convert"),
+	},
+	{
+		name: "currencies", method: http.MethodGet, path: "/currencies", status: http.StatusOK,
+		check: func(t *testing.T, body map[string]interface{}) {
+			if currencies, _ := result(body)["currencies"].([]interface{}); len(currencies) != 4 {
+				t.Errorf("expected the 4 enabled currencies, got %v", body)
+			}
+		},
+	},
+	{
+		name: "health", method: http.MethodGet, path: "/health", status: http.StatusOK,
+		check: func(t *testing.T, body map[string]interface{}) {
+			if result(body)["status"] != "healthy" {
+				t.Errorf("expected a healthy status, got %v", body)
+			}
+		},
+	},
+	{
+		// The icon is served from the working directory, which has none in tests
+		name: "favicon", method: http.MethodGet, path: "/favicon.ico", status: http.StatusNotFound,
+		check: expectError("eRqNf1", "Not found"),
+	},
+	{
+		name: "root", method: http.MethodGet, path: "/", status: http.StatusNotFound,
+		check: expectError("eRqNf1", "Not found"),
+	},
+	{
+		name: "unknown route", method: http.MethodGet, path: "/rate/USD", status: http.StatusNotFound,
+		check: expectError("eRqNf1", "Not found"),
+	},
+	{
+		name: "known route with another method", method: http.MethodDelete, path: "/rates", status: http.StatusNotFound,
+		check: expectError("eRqNf1", "Not found"),
+	},
+	{
+		name: "disabled route", method: http.MethodPost, path: "/quotes", body: `{}`, status: http.StatusNotFound,
+		check: expectError("eRqNf1", "Not found"),
+	},
+	{
+		name: "admin without a token", method: http.MethodGet, path: "/admin/cache/USD/EUR", status: http.StatusUnauthorized,
+		check: expectError("", "Unauthorized"),
+	},
+	{
+		name: "admin", method: http.MethodGet, path: "/admin/cache/usd/eur", header: map[string]string{"Authorization": "Bearer secret"},
+		status: http.StatusOK,
+		check: func(t *testing.T, body map[string]interface{}) {
+			if r := result(body); r["base"] != "USD" || r["quote"] != "EUR" {
+				t.Errorf("expected the USD/EUR cache entry, got %v", body)
+			}
+		},
+	},
+	{
+		name: "json-rpc", method: http.MethodPost, path: "/rpc", body: `{"jsonrpc":"2.0","method":"rates.get","params":["USD","EUR"],"id":1}`,
+		status: http.StatusOK,
+		check: func(t *testing.T, body map[string]interface{}) {
+			if rpcResult, _ := body["result"].(map[string]interface{}); body["id"] != 1.0 || rpcResult["rate"] != "0.9" {
+				t.Errorf("expected the cached USD/EUR rate, got %v", body)
+			}
+		},
+	},
+	{
+		name: "openapi", method: http.MethodGet, path: "/openapi.json", status: http.StatusOK,
+		check: func(t *testing.T, body map[string]interface{}) {
+			paths, _ := body["paths"].(map[string]interface{})
+			if body["openapi"] != "3.0.3" || paths["/rate/{from}/{to}"] == nil {
+				t.Errorf("expected the OpenAPI document, got %v", body)
+			}
+		},
+	},
+	{
+		name: "disabled docs", method: http.MethodGet, path: "/docs", status: http.StatusNotFound,
+		check: expectError("eRqNf1", "Not found"),
+	},
+	{
+		name: "v1 rate", method: http.MethodGet, path: "/v1/rate/usd/eur", status: http.StatusOK,
+		check: func(t *testing.T, body map[string]interface{}) {
+			if r := result(body); r["rate"] != "0.9" || body["meta"] != nil {
+				t.Errorf("expected the USD/EUR rate without metadata, got %v", body)
+			}
+		},
+	},
+	{
+		name: "v2 rate", method: http.MethodGet, path: "/v2/rate/usd/eur", header: map[string]string{"X-Request-ID": "req-1"},
+		status: http.StatusOK,
+		check: func(t *testing.T, body map[string]interface{}) {
+			meta, _ := body["meta"].(map[string]interface{})
+			if r := result(body); r["rate"] != "0.9" || body["error"] != nil || meta["requestId"] != "req-1" || meta["cached"] != true {
+				t.Errorf("expected the cached USD/EUR rate with its metadata, got %v", body)
+			}
+			if _, ok := meta["durationMs"].(float64); !ok {
+				t.Errorf("expected the duration in the metadata, got %v", meta)
+			}
+		},
+	},
+	{
+		name: "v2 error", method: http.MethodGet, path: "/v2/rate/USD/XXX", status: http.StatusBadRequest,
+		check: func(t *testing.T, body map[string]interface{}) {
+			detail, _ := body["error"].(map[string]interface{})
+			if detail["message"] != "Invalid quote currency code, XXX" || detail["code"] != "eRqCq1" || body["result"] != nil {
+				t.Errorf("expected the error detail, got %v", body)
+			}
+			if id, _ := detail["requestId"].(string); len(id) != 32 {
+				t.Errorf("expected a generated request ID, got %v", detail)
+			}
+		},
+	},
+	{
+		name: "v2 unknown route", method: http.MethodGet, path: "/v2/rate/USD", status: http.StatusNotFound,
+		check: expectError("eRqNf1", "Not found"),
+	},
+	{
+		name: "json-rpc notification", method: http.MethodPost, path: "/rpc", body: `{"jsonrpc":"2.0","method":"status.get"}`,
+		status: http.StatusNoContent,
+		check: func(t *testing.T, body map[string]interface{}) {
+			if body != nil {
+				t.Errorf("expected no body, got %v", body)
+			}
+		},
+	},
+}
+
+// TestRouterContract checks every router sends the same responses to the same requests
+func TestRouterContract(t *testing.T) {
+	catalogue := e.Catalogue()
+	e.SetCatalogue(contractCatalogue)
+	t.Cleanup(func() { e.SetCatalogue(catalogue) })
+
+	cache := ratecache.GetInstance()
+	cache.SetExpiry(3600)
+	cache.Clear()
+	t.Cleanup(cache.Clear)
+	cache.Set("USD", "EUR", decimal.MustParse("0.9"))
+	cache.Set("USD", "JPY", decimal.MustParse("151.235"))
+
+	for name, r := range contractRouters(contractConfig()) {
+		for _, tc := range contractCases {
+			t.Run(name+"/"+tc.name, func(t *testing.T) {
+				req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+				for key, value := range tc.header {
+					req.Header.Set(key, value)
+				}
+
+				status, body := serve(t, r, req)
+				if status != tc.status {
+					t.Errorf("expected status %d, got %d: %v", tc.status, status, body)
+				}
+				tc.check(t, body)
+			})
+		}
+	}
+}
+
+// TestRouterMounted checks every router serves the API when mounted under a prefix in another net/http server
+func TestRouterMounted(t *testing.T) {
+	cache := ratecache.GetInstance()
+	cache.SetExpiry(3600)
+	cache.Clear()
+	t.Cleanup(cache.Clear)
+	cache.Set("USD", "EUR", decimal.MustParse("0.9"))
+
+	for name, r := range contractRouters(contractConfig()) {
+		mux := http.NewServeMux()
+		mux.Handle("/fx/", http.StripPrefix("/fx", r))
+		server := httptest.NewServer(mux)
+
+		res, err := http.Get(server.URL + "/fx/rate/USD/EUR")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var body map[string]interface{}
+		if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		server.Close()
+
+		if res.StatusCode != http.StatusOK || result(body)["rate"] != "0.9" {
+			t.Errorf("%s: expected the cached USD/EUR rate, got %d %v", name, res.StatusCode, body)
+		}
+	}
+}
+
+// openAPIConfig enables every optional route
+func openAPIConfig() *config.Config {
+	cfg := contractConfig()
+	cfg.Quotes.Enabled = true
+	cfg.Alerts.Enabled = true
+	cfg.GraphQL.Enabled = true
+	cfg.Stream.Enabled = true
+	cfg.OpenAPI.Docs = true
+	return cfg
+}
+
+// registeredRoutes returns the routes registered in a router, as "METHOD /path" with the {param} syntax.
+// The net/http mux can't list its routes, so it returns false for it.
+func registeredRoutes(t *testing.T, r Router) ([]string, bool) {
+	var routes []string
+	switch r := r.(type) {
+	case *ginHandlers.GinRouter:
+		for _, route := range r.Engine.Routes() {
+			routes = append(routes, route.Method+" "+coreHandlers.OpenAPIPath(route.Path))
+		}
+	case *fiberHandlers.FiberRouter:
+		for _, route := range r.App.GetRoutes(true) {
+			// Fiber also registers HEAD for every GET route
+			if route.Method != http.MethodHead {
+				routes = append(routes, route.Method+" "+coreHandlers.OpenAPIPath(route.Path))
+			}
+		}
+	case *chiHandlers.ChiRouter:
+		err := chi.Walk(r.Mux, func(method, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+			routes = append(routes, method+" "+route)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	default:
+		return nil, false
+	}
+	sort.Strings(routes)
+	return routes, true
+}
+
+// TestOpenAPIMatchesRoutes checks the OpenAPI document describes exactly the routes registered in every router
+func TestOpenAPIMatchesRoutes(t *testing.T) {
+	cfg := openAPIConfig()
+	var documented []string
+	for path, item := range coreHandlers.BuildOpenAPI(cfg)["paths"].(coreHandlers.Map) {
+		for method, op := range item.(coreHandlers.Map) {
+			route := strings.ToUpper(method) + " " + path
+			documented = append(documented, route)
+			if op.(coreHandlers.Map)["operationId"] == nil {
+				t.Errorf("%s is not described in the OpenAPI operations", route)
+			}
+		}
+	}
+	sort.Strings(documented)
+
+	for name, r := range contractRouters(cfg) {
+		registered, ok := registeredRoutes(t, r)
+		if ok {
+			if !reflect.DeepEqual(registered, documented) {
+				t.Errorf("%s: expected the registered routes to be documented\nregistered: %v\ndocumented: %v", name, registered, documented)
+			}
+			continue
+		}
+
+		// Each documented route must be matched by its own pattern instead
+		mux := r.(*nethttpHandlers.HTTPRouter).Mux
+		for _, route := range documented {
+			method, path, _ := strings.Cut(route, " ")
+			if _, pattern := mux.Handler(httptest.NewRequest(method, path, nil)); pattern != route {
+				t.Errorf("%s: expected %s to be registered, matched %q", name, route, pattern)
+			}
+		}
+	}
+}
+
+// TestDocs checks every router serves the docs page as HTML
+func TestDocs(t *testing.T) {
+	for name, r := range contractRouters(openAPIConfig()) {
+		res := roundTrip(t, r, httptest.NewRequest(http.MethodGet, "/docs", nil))
+		page, _ := io.ReadAll(res.Body)
+		res.Body.Close()
+
+		if res.StatusCode != http.StatusOK || !strings.HasPrefix(res.Header.Get("Content-Type"), "text/html") ||
+			!strings.Contains(string(page), "openapi.json") {
+			t.Errorf("%s: expected the docs page, got %d %s", name, res.StatusCode, res.Header.Get("Content-Type"))
+		}
+	}
+}
+
+// TestV2Error checks the v2 errors carry the code of the catalogue and the request ID, also in the header
+func TestV2Error(t *testing.T) {
+	catalogue := e.Catalogue()
+	e.SetCatalogue(e.ErrorMap{"eHsIv1": {Message: "Invalid interval '%s'", Status: http.StatusBadRequest}})
+	t.Cleanup(func() { e.SetCatalogue(catalogue) })
+
+	for name, r := range contractRouters(contractConfig()) {
+		req := httptest.NewRequest(http.MethodGet, "/v2/rate/USD/EUR/history?interval=often", nil)
+		req.Header.Set("X-Request-ID", "req-2")
+		res := roundTrip(t, r, req)
+		var body struct {
+			Error coreHandlers.ErrorDetail `json:"error"`
+			Meta  coreHandlers.Meta        `json:"meta"`
+		}
+		err := json.NewDecoder(res.Body).Decode(&body)
+		res.Body.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		if res.StatusCode != http.StatusBadRequest || body.Error.Code == nil || *body.Error.Code != "eHsIv1" ||
+			body.Error.Message != "Invalid interval 'often'" || body.Error.RequestID != "req-2" || body.Meta.RequestID != "req-2" {
+			t.Errorf("%s: expected the eHsIv1 error, got %d %+v", name, res.StatusCode, body)
+		}
+		if id := res.Header.Get("X-Request-ID"); id != "req-2" {
+			t.Errorf("%s: expected the request ID in the header, got %q", name, id)
+		}
+	}
+}
+
+// TestProtobuf checks every router sends the results in the Protobuf format as the typed messages of the gRPC API
+func TestProtobuf(t *testing.T) {
+	cache := ratecache.GetInstance()
+	cache.SetExpiry(3600)
+	cache.Clear()
+	t.Cleanup(cache.Clear)
+	cache.Set("USD", "EUR", decimal.MustParse("0.9"))
+	cache.Set("USD", "JPY", decimal.MustParse("151.235"))
+
+	for name, r := range contractRouters(contractConfig()) {
+		res := roundTrip(t, r, httptest.NewRequest(http.MethodGet, "/v2/rate/usd/eur?format=protobuf", nil))
+		raw, _ := io.ReadAll(res.Body)
+		res.Body.Close()
+		var rate fxpb.GetRateResponse
+		if err := proto.Unmarshal(raw, &rate); err != nil || rate.GetBase() != "USD" || rate.GetQuote() != "EUR" ||
+			rate.GetRate() != "0.9" || !rate.GetCached() {
+			t.Errorf("%s: expected the USD/EUR rate message, got %v (%v)", name, &rate, err)
+		}
+
+		res = roundTrip(t, r, httptest.NewRequest(http.MethodGet, "/rates?from=USD&to=EUR,JPY&format=protobuf", nil))
+		raw, _ = io.ReadAll(res.Body)
+		res.Body.Close()
+		var list fxpb.GetRatesResponse
+		if err := proto.Unmarshal(raw, &list); err != nil || list.GetBase() != "USD" ||
+			!reflect.DeepEqual(list.GetRates(), map[string]string{"EUR": "0.9", "JPY": "151.235"}) {
+			t.Errorf("%s: expected the USD rates message, got %v (%v)", name, &list, err)
+		}
+	}
+}
+
+// TestBodyLimit checks the routers refuse the request bodies larger than the configured limit
+func TestBodyLimit(t *testing.T) {
+	catalogue := e.Catalogue()
+	e.SetCatalogue(e.ErrorMap{"eRqTl1": {Message: "The request body is too large. The maximum is %d bytes", Status: http.StatusRequestEntityTooLarge}})
+	t.Cleanup(func() { e.SetCatalogue(catalogue) })
+
+	cfg := contractConfig()
+	cfg.MaxBodyBytes = 64
+	for name, r := range contractRouters(cfg) {
+		// Served as an http.Handler, as Fiber's test server fails the whole request past its own BodyLimit
+		body := `[` + strings.Repeat(`{"from":"USD","to":"EUR","amount":"100"},`, 10) + `{"from":"USD","to":"EUR","amount":"100"}]`
+		recorder := httptest.NewRecorder()
+		r.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/convert", strings.NewReader(body)))
+		var res map[string]interface{}
+		if err := json.NewDecoder(recorder.Body).Decode(&res); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if recorder.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("%s: expected a 413, got %d %v", name, recorder.Code, res)
+			continue
+		}
+		if detail, _ := res["error"].(map[string]interface{}); detail["code"] != "eRqTl1" || detail["message"] != "The request body is too large. The maximum is 64 bytes" {
+			t.Errorf("%s: expected the eRqTl1 error, got %v", name, res)
+		}
+
+		recorder = httptest.NewRecorder()
+		r.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/convert", strings.NewReader(`[{"from":"USD","to":"EUR","amount":"1"}]`)))
+		if recorder.Code != http.StatusOK {
+			t.Errorf("%s: expected a body under the limit to be served, got %d", name, recorder.Code)
+		}
+	}
+}
+
+// TestMatrixBounds checks the enabled currencies a matrix defaults to are held to the same bounds as a given list
+func TestMatrixBounds(t *testing.T) {
+	catalogue := e.Catalogue()
+	e.SetCatalogue(e.ErrorMap{
+		"eMxCn1": {Message: "A matrix needs at least 2 currencies", Status: http.StatusBadRequest},
+		"eMxCn2": {Message: "Too many currencies, the maximum is %d", Status: http.StatusBadRequest},
+	})
+	t.Cleanup(func() { e.SetCatalogue(catalogue) })
+
+	many := contractConfig()
+	many.CurrenciesEnabled = nil
+	for i := 0; i <= rates.MaxMatrixCurrencies; i++ {
+		many.CurrenciesEnabled = append(many.CurrenciesEnabled, fmt.Sprintf("C%02d", i))
+	}
+	one := contractConfig()
+	one.CurrenciesEnabled = []string{"USD"}
+
+	for _, tt := range []struct {
+		cfg           *config.Config
+		code, message string
+	}{
+		{many, "eMxCn2", fmt.Sprintf("Too many currencies, the maximum is %d", rates.MaxMatrixCurrencies)},
+		{one, "eMxCn1", "A matrix needs at least 2 currencies"},
+	} {
+		for name, r := range contractRouters(tt.cfg) {
+			status, body := serve(t, r, httptest.NewRequest(http.MethodGet, "/matrix", nil))
+			if status != http.StatusBadRequest {
+				t.Errorf("%s: expected a 400, got %d %v", name, status, body)
+				continue
+			}
+			expectError(tt.code, tt.message)(t, body)
+		}
+	}
+}
+
+// TestFormats checks every router sends the results in the negotiated format, and the errors as JSON
+func TestFormats(t *testing.T) {
+	catalogue := e.Catalogue()
+	e.SetCatalogue(e.ErrorMap{
+		"eRqCq1": {Message: "Invalid quote currency code, %s", Status: http.StatusBadRequest},
+		"eFmUk1": {Message: "Unsupported format '%s'", Status: http.StatusBadRequest},
+	})
+	t.Cleanup(func() { e.SetCatalogue(catalogue) })
+
+	cache := ratecache.GetInstance()
+	cache.SetExpiry(3600)
+	cache.Clear()
+	t.Cleanup(cache.Clear)
+	cache.Set("USD", "EUR", decimal.MustParse("0.9"))
+	cache.Set("USD", "JPY", decimal.MustParse("151.235"))
+
+	tests := []struct {
+		name        string
+		path        string
+		accept      string
+		status      int
+		contentType string
+		body        string
+	}{
+		{
+			name: "csv", path: "/rates?from=USD&to=JPY,EUR&format=csv", status: http.StatusOK, contentType: "text/csv; charset=utf-8",
+			body: "base,quote,rate,override\nUSD,JPY,151.235,false\nUSD,EUR,0.9,false\n",
+		},
+		{
+			name: "csv by accept", path: "/v1/rate/usd/eur", accept: "text/csv", status: http.StatusOK, contentType: "text/csv; charset=utf-8",
+			body: "base,quote,rate,cached,override\nUSD,EUR,0.9,true,false\n",
+		},
+		{
+			name: "xml", path: "/v2/rate/usd/eur", accept: "application/xml", status: http.StatusOK, contentType: "application/xml; charset=utf-8",
+			body: "<meta><cached>true</cached>",
+		},
+		{
+			name: "msgpack", path: "/rate/usd/eur?format=msgpack", status: http.StatusOK, contentType: "application/msgpack",
+			body: "\xa4rate\xa30.9",
+		},
+		{
+			name: "protobuf", path: "/rate/usd/eur", accept: "application/x-protobuf", status: http.StatusOK, contentType: "application/x-protobuf",
+			body: "0.9",
+		},
+		{
+			name: "error as json", path: "/rate/USD/XXX?format=csv", status: http.StatusBadRequest, contentType: "application/json",
+			body: `"code":"eRqCq1"`,
+		},
+		{
+			name: "unknown format", path: "/rates?from=USD&to=EUR&format=yaml", status: http.StatusBadRequest, contentType: "application/json",
+			body: `"code":"eFmUk1"`,
+		},
+		{
+			name: "unknown accepted type", path: "/rate/usd/eur", accept: "text/html", status: http.StatusOK, contentType: "application/json",
+			body: `"rate":"0.9"`,
+		},
+		{
+			name: "route without formats", path: "/currencies?format=csv", status: http.StatusOK, contentType: "application/json",
+			body: `"currencies":`,
+		},
+	}
+
+	for name, r := range contractRouters(contractConfig()) {
+		for _, tt := range tests {
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+				if tt.accept != "" {
+					req.Header.Set("Accept", tt.accept)
+				}
+				res := roundTrip(t, r, req)
+				body, _ := io.ReadAll(res.Body)
+				res.Body.Close()
+
+				if res.StatusCode != tt.status || !strings.HasPrefix(res.Header.Get("Content-Type"), tt.contentType) ||
+					!strings.Contains(string(body), tt.body) {
+					t.Errorf("expected %d %s with %q, got %d %s with %q",
+						tt.status, tt.contentType, tt.body, res.StatusCode, res.Header.Get("Content-Type"), body)
+				}
+			})
+		}
+	}
+}
+
+// TestClientCache checks every router lets the Go client cache the rates served from the rate cache,
+// for no longer than they stay there
+func TestClientCache(t *testing.T) {
+	cache := ratecache.GetInstance()
+	cache.SetExpiry(3600)
+	cache.Clear()
+	t.Cleanup(cache.Clear)
+	cache.Set("USD", "EUR", decimal.MustParse("0.9"))
+	cache.Set("USD", "JPY", decimal.MustParse("151.235"))
+
+	for name, r := range contractRouters(contractConfig()) {
+		res := roundTrip(t, r, httptest.NewRequest(http.MethodGet, "/rate/USD/EUR", nil))
+		res.Body.Close()
+		maxAge, err := strconv.Atoi(strings.TrimPrefix(res.Header.Get("Cache-Control"), "private, max-age="))
+		if err != nil || maxAge <= 0 || maxAge > 3600 {
+			t.Errorf("%s: expected the remaining expiry of USD/EUR as the max-age, got %q", name, res.Header.Get("Cache-Control"))
+		}
+
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			calls.Add(1)
+			r.ServeHTTP(w, req)
+		}))
+		client := fxclient.New(server.URL, fxclient.WithCache(10))
+		ctx := context.Background()
+
+		for i := 0; i < 2; i++ {
+			rate, err := client.Rate(ctx, "USD", "EUR")
+			if err != nil || rate.Rate.String() != "0.9" {
+				t.Errorf("%s: expected the cached USD/EUR rate, got %v (%v)", name, rate, err)
+			}
+			quotes, err := client.Rates(ctx, "USD", "EUR", "JPY")
+			if err != nil || quotes.Quotes["JPY"].String() != "151.235" {
+				t.Errorf("%s: expected the cached USD rates, got %v (%v)", name, quotes, err)
+			}
+			conversion, err := client.Convert(ctx, "USD", "EUR", decimal.MustParse("10"), nil)
+			if err != nil || conversion.Result.String() != "9.00" {
+				t.Errorf("%s: expected 9.00 EUR, got %v (%v)", name, conversion, err)
+			}
+		}
+		server.Close()
+
+		if calls.Load() != 3 {
+			t.Errorf("%s: expected the client to keep the responses, got %d calls", name, calls.Load())
+		}
+	}
+}
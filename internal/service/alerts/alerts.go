@@ -0,0 +1,141 @@
+// Package alerts notifies webhook subscribers when a rate moves beyond a threshold, or crosses a level
+package alerts
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"fx-service/pkg/decimal"
+)
+
+// Alert conditions
+const (
+	ConditionAbsolute = "absolute" // The rate moved by at least the threshold, since the last alert
+	ConditionPercent  = "percent"  // The rate moved by at least the threshold (in percent), since the last alert
+	ConditionCross    = "cross"    // The rate crossed the threshold level, in either direction
+)
+
+// Directions of a move
+const (
+	DirectionUp   = "up"
+	DirectionDown = "down"
+)
+
+// changePercentPlaces is the number of decimal places of the percentage changes
+const changePercentPlaces = 8
+
+// Subscription is a webhook called when the rate of a pair meets a condition
+type Subscription struct {
+	ID        string
+	Base      string
+	Quote     string
+	Condition string          // ConditionAbsolute, ConditionPercent or ConditionCross
+	Threshold decimal.Decimal // The change (absolute or in percent), or the level to cross
+	URL       string          // The callback URL
+	Secret    string          // The key of the HMAC signature of the callbacks
+	APIKey    string          // The client who registered the webhook. Only that client can read or delete it
+	CreatedAt time.Time
+}
+
+// Event is the payload sent to a webhook
+type Event struct {
+	ID             string          `json:"id"` // Unique, and the same for every delivery attempt
+	SubscriptionID string          `json:"subscriptionId"`
+	Condition      string          `json:"condition"`
+	Base           string          `json:"base"`
+	Quote          string          `json:"quote"`
+	Rate           decimal.Decimal `json:"rate"`
+	PreviousRate   decimal.Decimal `json:"previousRate"` // The reference rate (absolute and percent) or the last observed rate (cross)
+	Threshold      decimal.Decimal `json:"threshold"`
+	Change         decimal.Decimal `json:"change"`
+	ChangePercent  decimal.Decimal `json:"changePercent"`
+	Direction      string          `json:"direction"`
+	Time           time.Time       `json:"time"`
+}
+
+// Store saves subscriptions. Implementations must be safe for concurrent use.
+type Store interface {
+	// Save saves a new subscription
+	Save(ctx context.Context, subscription Subscription) error
+	// List returns every subscription
+	List(ctx context.Context) ([]Subscription, error)
+	// Delete deletes the subscription with the given ID. Returns false if it did not exist.
+	Delete(ctx context.Context, id string) (bool, error)
+	// Close releases the resources of the store
+	Close() error
+}
+
+// subscriptionState is what a subscription remembers between observations. It is not persisted:
+// after a restart, the first observed rate becomes the reference again.
+type subscriptionState struct {
+	// For ConditionAbsolute and ConditionPercent: the rate when the last alert fired (or the first observed rate).
+	// For ConditionCross: the last observed rate.
+	reference *decimal.Decimal
+}
+
+// evaluate checks the observed rate against the subscription condition, and updates the state.
+// Returns the event to send, or nil if the condition is not met.
+func evaluate(sub Subscription, state *subscriptionState, rate decimal.Decimal, at time.Time) *Event {
+	if state.reference == nil {
+		state.reference = &rate
+		return nil
+	}
+
+	previous := *state.reference
+	change := rate.Sub(previous)
+	fired := false
+	switch sub.Condition {
+	case ConditionAbsolute:
+		fired = !change.IsZero() && abs(change).Cmp(sub.Threshold) >= 0
+	case ConditionPercent:
+		fired = !previous.IsZero() && !change.IsZero() &&
+			abs(change).Mul(decimal.NewFromInt(100)).Div(previous, changePercentPlaces).Cmp(sub.Threshold) >= 0
+	case ConditionCross:
+		fired = (previous.Cmp(sub.Threshold) < 0 && rate.Cmp(sub.Threshold) >= 0) ||
+			(previous.Cmp(sub.Threshold) > 0 && rate.Cmp(sub.Threshold) <= 0)
+		// Crossing compares consecutive observations
+		state.reference = &rate
+	}
+	if !fired {
+		return nil
+	}
+	state.reference = &rate
+
+	event := &Event{
+		SubscriptionID: sub.ID,
+		Condition:      sub.Condition,
+		Base:           sub.Base,
+		Quote:          sub.Quote,
+		Rate:           rate,
+		PreviousRate:   previous,
+		Threshold:      sub.Threshold,
+		Change:         change,
+		Direction:      DirectionUp,
+		Time:           at,
+	}
+	if !previous.IsZero() {
+		event.ChangePercent = change.Mul(decimal.NewFromInt(100)).Div(previous, changePercentPlaces).Trim()
+	}
+	if change.Sign() < 0 {
+		event.Direction = DirectionDown
+	}
+	return event
+}
+
+func abs(d decimal.Decimal) decimal.Decimal {
+	if d.Sign() < 0 {
+		return d.Neg()
+	}
+	return d
+}
+
+// newID returns a random ID (32 hexadecimal characters)
+func newID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b[:]), nil
+}
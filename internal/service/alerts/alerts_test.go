@@ -0,0 +1,304 @@
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"fx-service/internal/service/providers"
+	"fx-service/pkg/config"
+	"fx-service/pkg/decimal"
+	"fx-service/pkg/e"
+)
+
+// testCatalogue has the error codes checked by the tests
+var testCatalogue = e.ErrorMap{
+	"eAlNf1": {Message: "Subscription '%s' not found"},
+	"eAlUr1": {Message: "Invalid callback URL '%s'. Use an absolute http or https URL"},
+	"eAlUr2": {Message: "Invalid callback URL '%s'. Callbacks cannot target loopback, private or link-local addresses"},
+	"eAlAu1": {Message: "This API key cannot register subscriptions"},
+}
+
+// TestEvaluate checks when each condition fires, and how the reference rate moves
+func TestEvaluate(t *testing.T) {
+	at := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		condition string
+		threshold string
+		rates     []string
+		fired     []string // The direction of each observation's event, or "" when none fires
+	}{
+		{"absolute", ConditionAbsolute, "0.01", []string{"1.10", "1.105", "1.111", "1.115", "1.09"}, []string{"", "", DirectionUp, "", DirectionDown}},
+		{"percent", ConditionPercent, "1", []string{"100", "100.5", "101", "101.5", "99.9"}, []string{"", "", DirectionUp, "", DirectionDown}},
+		{"cross", ConditionCross, "1.1", []string{"1.09", "1.095", "1.1", "1.12", "1.08", "1.07"}, []string{"", "", DirectionUp, "", DirectionDown, ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := Subscription{ID: "sub", Base: "EUR", Quote: "USD", Condition: tt.condition, Threshold: decimal.MustParse(tt.threshold)}
+			state := &subscriptionState{}
+			for i, rate := range tt.rates {
+				event := evaluate(sub, state, decimal.MustParse(rate), at)
+				direction := ""
+				if event != nil {
+					direction = event.Direction
+				}
+				if direction != tt.fired[i] {
+					t.Errorf("observation %d (%s): expected %q, got %q", i, rate, tt.fired[i], direction)
+				}
+			}
+		})
+	}
+}
+
+// receiver is a local webhook receiver, which fails the first attempts with the given status
+type receiver struct {
+	mu       sync.Mutex
+	failures int
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+	received chan struct{}
+}
+
+func newReceiver(t *testing.T, failures, status int) (*receiver, *httptest.Server) {
+	r := &receiver{failures: failures, status: status, received: make(chan struct{}, 10)}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		r.requests = append(r.requests, req)
+		r.bodies = append(r.bodies, body)
+		fail := len(r.requests) <= r.failures
+		r.mu.Unlock()
+		if fail {
+			w.WriteHeader(r.status)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		r.received <- struct{}{}
+	}))
+	t.Cleanup(server.Close)
+	return r, server
+}
+
+// TestDeliverRetries checks that callbacks are signed, and retried with the same event ID
+func TestDeliverRetries(t *testing.T) {
+	r, server := newReceiver(t, 2, http.StatusServiceUnavailable)
+	d := NewDispatcher(time.Second, 5, time.Millisecond, true)
+	defer d.Close()
+
+	sub := Subscription{ID: "sub", URL: server.URL, Secret: "s3cret"}
+	event := Event{ID: "evt-1", SubscriptionID: "sub", Rate: decimal.MustParse("1.1")}
+	if err := d.Deliver(sub, event); err != nil {
+		t.Fatalf("Deliver failed: %v", err)
+	}
+
+	if len(r.requests) != 3 {
+		t.Fatalf("expected 3 attempts, got %d", len(r.requests))
+	}
+	for i, req := range r.requests {
+		if req.Header.Get(HeaderID) != "evt-1" {
+			t.Errorf("attempt %d: expected the event ID evt-1, got %q", i, req.Header.Get(HeaderID))
+		}
+		timestamp, err := strconv.ParseInt(req.Header.Get(HeaderTimestamp), 10, 64)
+		if err != nil {
+			t.Fatalf("attempt %d: invalid timestamp: %v", i, err)
+		}
+		if req.Header.Get(HeaderSignature) != Sign("s3cret", timestamp, r.bodies[i]) {
+			t.Errorf("attempt %d: invalid signature %q", i, req.Header.Get(HeaderSignature))
+		}
+	}
+}
+
+// TestDeliverRejected checks that client errors are not retried
+func TestDeliverRejected(t *testing.T) {
+	r, server := newReceiver(t, 10, http.StatusGone)
+	d := NewDispatcher(time.Second, 5, time.Millisecond, true)
+	defer d.Close()
+
+	if err := d.Deliver(Subscription{ID: "sub", URL: server.URL}, Event{ID: "evt-1"}); err == nil {
+		t.Fatalf("expected the delivery to fail")
+	}
+	if len(r.requests) != 1 {
+		t.Errorf("expected a single attempt, got %d", len(r.requests))
+	}
+}
+
+// TestDeliverRedirect checks that redirects are not followed, nor retried
+func TestDeliverRedirect(t *testing.T) {
+	target, targetServer := newReceiver(t, 0, 0)
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		attempts++
+		http.Redirect(w, req, targetServer.URL, http.StatusFound)
+	}))
+	t.Cleanup(server.Close)
+	d := NewDispatcher(time.Second, 5, time.Millisecond, true)
+	defer d.Close()
+
+	if err := d.Deliver(Subscription{ID: "sub", URL: server.URL}, Event{ID: "evt-1"}); err == nil {
+		t.Fatalf("expected the delivery to fail")
+	}
+	if attempts != 1 || len(target.requests) != 0 {
+		t.Errorf("expected a single attempt and no redirect, got %d attempts and %d redirects", attempts, len(target.requests))
+	}
+}
+
+// TestPrivateCallbacks checks that callbacks to private addresses are refused, when creating and when delivering
+func TestPrivateCallbacks(t *testing.T) {
+	e.SetCatalogue(testCatalogue)
+	if err := Init(config.AlertConfig{Enabled: true, Driver: DriverMemory}, nil); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	t.Cleanup(func() { _ = Close() })
+
+	for _, callbackURL := range []string{
+		"http://127.0.0.1:8080/hook",
+		"http://localhost/hook",
+		"http://[::1]/hook",
+		"http://10.1.2.3/hook",
+		"https://192.168.0.10/hook",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://100.100.100.200/",
+		"http://0.0.0.0/",
+	} {
+		_, err := Create("EUR", "USD", "cross", decimal.MustParse("1.1"), callbackURL, "key-1")
+		if e.FromError(err).GetCode() != "eAlUr2" || !IsRequestError(err) {
+			t.Errorf("%s: expected eAlUr2, got %v", callbackURL, err)
+		}
+	}
+	if _, err := Create("EUR", "USD", "cross", decimal.MustParse("1.1"), "https://93.184.216.34/hook", "key-1"); err != nil {
+		t.Errorf("expected a public address to be accepted, got %v", err)
+	}
+
+	// A host resolving to a private address once registered is refused when connecting
+	r, server := newReceiver(t, 0, 0)
+	d := NewDispatcher(time.Second, 1, time.Millisecond, false)
+	defer d.Close()
+	if err := d.Deliver(Subscription{ID: "sub", URL: server.URL}, Event{ID: "evt-1"}); err == nil {
+		t.Errorf("expected the delivery to a loopback address to fail")
+	}
+	if len(r.requests) != 0 {
+		t.Errorf("expected no request to reach the loopback receiver, got %d", len(r.requests))
+	}
+}
+
+// TestClientKeys checks that only the configured API keys can subscribe
+func TestClientKeys(t *testing.T) {
+	e.SetCatalogue(testCatalogue)
+	cfg := config.AlertConfig{Enabled: true, Driver: DriverMemory, ClientKeys: []string{"key-1"}}
+	if err := Init(cfg, nil); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	t.Cleanup(func() { _ = Close() })
+
+	for _, apiKey := range []string{"key-2", ""} {
+		if _, err := Create("EUR", "USD", "cross", decimal.MustParse("1.1"), "https://93.184.216.34/hook", apiKey); e.FromError(err).GetCode() != "eAlAu1" {
+			t.Errorf("%q: expected eAlAu1, got %v", apiKey, err)
+		}
+	}
+	if _, err := Create("EUR", "USD", "cross", decimal.MustParse("1.1"), "https://93.184.216.34/hook", "key-1"); err != nil {
+		t.Errorf("expected key-1 to subscribe, got %v", err)
+	}
+}
+
+// TestObserve checks that a move observed by concurrent fetches is delivered once
+func TestObserve(t *testing.T) {
+	e.SetCatalogue(testCatalogue)
+	r, server := newReceiver(t, 0, 0)
+	cfg := config.AlertConfig{Enabled: true, Driver: DriverMemory, TimeoutSec: 1, MaxAttempts: 1, AllowPrivate: true}
+	if err := Init(cfg, nil); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	t.Cleanup(func() { _ = Close() })
+
+	sub, err := Create("EUR", "USD", "percent", decimal.NewFromInt(1), server.URL, "key-1")
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if _, err := Create("EUR", "USD", "percent", decimal.NewFromInt(1), "ftp://example.com", "key-1"); !IsRequestError(err) {
+		t.Errorf("expected an invalid URL to be rejected, got %v", err)
+	}
+
+	Observe("EUR", providers.RateList{"USD": decimal.MustParse("1.10")})
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			Observe("EUR", providers.RateList{"USD": decimal.MustParse("1.12")})
+		}()
+	}
+	wg.Wait()
+
+	select {
+	case <-r.received:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no callback received")
+	}
+	_ = Close()
+
+	if len(r.bodies) != 1 {
+		t.Fatalf("expected a single callback, got %d", len(r.bodies))
+	}
+	var event Event
+	if err := json.Unmarshal(r.bodies[0], &event); err != nil {
+		t.Fatalf("invalid callback body: %v", err)
+	}
+	if event.SubscriptionID != sub.ID || event.Rate.String() != "1.12" || event.PreviousRate.String() != "1.10" ||
+		event.Direction != DirectionUp || event.ChangePercent.String() != "1.81818182" {
+		t.Errorf("unexpected event: %+v", event)
+	}
+}
+
+// TestSQLiteStore checks that subscriptions survive a restart
+func TestSQLiteStore(t *testing.T) {
+	e.SetCatalogue(testCatalogue)
+	cfg := config.AlertConfig{Enabled: true, Driver: DriverSQLite, DSN: filepath.Join(t.TempDir(), "alerts.db")}
+	if err := Init(cfg, nil); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	t.Cleanup(func() { _ = Close() })
+
+	sub, err := Create("EUR", "USD", "cross", decimal.MustParse("1.1"), "https://example.com/hook", "key-1")
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	other, err := Create("EUR", "GBP", "absolute", decimal.MustParse("0.005"), "https://example.com/hook", "key-1")
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if err := Delete(other.ID, "key-2"); !IsNotFound(err) {
+		t.Errorf("expected other clients not to delete the subscription, got %v", err)
+	}
+	if err := Delete(other.ID, "key-1"); err != nil {
+		t.Errorf("Delete failed: %v", err)
+	}
+
+	if err := Init(cfg, nil); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	subs, err := List("key-1")
+	if err != nil || len(subs) != 1 {
+		t.Fatalf("expected 1 subscription after a restart, got %+v (%v)", subs, err)
+	}
+	if subs[0].ID != sub.ID || subs[0].Threshold.String() != "1.1" || subs[0].Secret != sub.Secret {
+		t.Errorf("unexpected subscription after a restart: %+v", subs[0])
+	}
+
+	store, err := NewSQLiteStore(cfg.DSN)
+	if err != nil {
+		t.Fatalf("could not open the sqlite store: %v", err)
+	}
+	defer store.Close()
+	if deleted, err := store.Delete(context.Background(), "missing"); err != nil || deleted {
+		t.Errorf("expected nothing to be deleted, got %v (%v)", deleted, err)
+	}
+}
@@ -0,0 +1,201 @@
+package alerts
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	c "fx-service/pkg/console"
+)
+
+// Webhook request headers
+const (
+	HeaderID        = "X-Webhook-Id"        // The event ID, the same for every delivery attempt
+	HeaderTimestamp = "X-Webhook-Timestamp" // Unix time of the delivery attempt
+	HeaderSignature = "X-Webhook-Signature" // "sha256=" followed by the hex HMAC-SHA256 of "{timestamp}.{body}"
+)
+
+const (
+	maxConcurrentDeliveries = 16              // Deliveries in flight at the same time
+	maxRetryDelay           = 5 * time.Minute // The back-off delay never grows beyond this
+)
+
+// Sign returns the signature of a webhook body, for the X-Webhook-Signature header.
+// Receivers compute the same HMAC with their secret to check the callback came from this service.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Dispatcher delivers events to webhooks asynchronously, retrying failed deliveries with exponential back-off
+type Dispatcher struct {
+	client      *http.Client
+	maxAttempts int
+	retryDelay  time.Duration // Delay before the first retry. Doubled after every failed attempt
+	slots       chan struct{}
+	stop        chan struct{}
+	wg          sync.WaitGroup
+	mu          sync.Mutex
+	closed      bool
+}
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598), which some clouds use for their metadata services
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// isPrivateIP returns true for the addresses a webhook must not reach from the internet: loopback, private (RFC 1918
+// and unique local), link-local (e.g. the 169.254.169.254 metadata services), shared, unspecified and multicast
+func isPrivateIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsUnspecified() || ip.IsMulticast() || sharedAddressSpace.Contains(ip)
+}
+
+// publicOnly is a net.Dialer control function refusing connections to private addresses. It runs once the host is
+// resolved, so that a name resolving to a private address after the subscription was validated is refused too.
+func publicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || isPrivateIP(ip) {
+		return fmt.Errorf("webhook address %s is not public", host)
+	}
+	return nil
+}
+
+// NewDispatcher creates a Dispatcher. Each attempt times out after the given timeout.
+// Redirects are not followed, and unless allowPrivate is set, callbacks to private addresses are refused.
+func NewDispatcher(timeout time.Duration, maxAttempts int, retryDelay time.Duration, allowPrivate bool) *Dispatcher {
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = publicOnly
+	}
+	// No proxy either, as the addresses checked would be the proxy's
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &Dispatcher{
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		maxAttempts: maxAttempts,
+		retryDelay:  retryDelay,
+		slots:       make(chan struct{}, maxConcurrentDeliveries),
+		stop:        make(chan struct{}),
+	}
+}
+
+// Send delivers the event to the subscription's webhook in the background. Does nothing once closed.
+func (d *Dispatcher) Send(sub Subscription, event Event) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return
+	}
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		select {
+		case d.slots <- struct{}{}:
+			defer func() { <-d.slots }()
+		case <-d.stop:
+			return
+		}
+		if err := d.Deliver(sub, event); err != nil {
+			c.Warnf("Webhook %s: could not deliver event %s: %s", sub.ID, event.ID, err.Error())
+		}
+	}()
+}
+
+// Deliver sends the event to the subscription's webhook, retrying until it is accepted (2xx status), it is rejected
+// permanently (3xx, or 4xx status other than 408 and 429), the attempts run out, or the dispatcher is closed
+func (d *Dispatcher) Deliver(sub Subscription, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	delay := d.retryDelay
+	for attempt := 1; ; attempt++ {
+		retry, err := d.attempt(sub, event, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= d.maxAttempts {
+			return fmt.Errorf("%w (after %d attempts)", err, attempt)
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-d.stop:
+			return fmt.Errorf("%w (stopped after %d attempts)", err, attempt)
+		}
+		delay = min(delay*2, maxRetryDelay)
+	}
+}
+
+// attempt makes a single delivery attempt. Returns whether a failed attempt may be retried.
+func (d *Dispatcher) attempt(sub Subscription, event Event, body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "fx-service-webhooks")
+	req.Header.Set(HeaderID, event.ID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(sub.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	_ = resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode >= 300 && resp.StatusCode < 400:
+		return false, fmt.Errorf("webhook redirected with status %d, redirects are not followed", resp.StatusCode)
+	case resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests:
+		return true, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		return false, fmt.Errorf("webhook rejected the event with status %d", resp.StatusCode)
+	default:
+		return true, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+}
+
+// Close stops retrying, and waits for the deliveries in flight
+func (d *Dispatcher) Close() {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return
+	}
+	d.closed = true
+	close(d.stop)
+	d.mu.Unlock()
+
+	d.wg.Wait()
+}
@@ -0,0 +1,423 @@
+package alerts
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"fx-service/internal/service/providers"
+	"fx-service/pkg/config"
+	c "fx-service/pkg/console"
+	"fx-service/pkg/decimal"
+	"fx-service/pkg/e"
+	util "fx-service/pkg/helpers"
+)
+
+// Supported store drivers
+const (
+	DriverMemory = "memory"
+	DriverSQLite = "sqlite"
+)
+
+const (
+	storeTimeout   = 5 * time.Second // Timeout of a single store operation
+	resolveTimeout = 5 * time.Second // Timeout of the lookup of a callback host
+)
+
+// FetchFunc gets the latest rates for a base currency, so that they are observed.
+// Rates which were freshly fetched from a provider are passed to Observe by the caller.
+type FetchFunc func(from string, to []string) error
+
+// manager holds the subscriptions and their state, and polls the subscribed pairs in the background
+type manager struct {
+	store         Store
+	dispatcher    *Dispatcher
+	fetch         FetchFunc
+	pollInterval  time.Duration
+	maxPerClient  int
+	clientKeys    []string // The API keys allowed to subscribe. Empty for any client
+	allowPrivate  bool     // Whether callbacks may target private addresses
+	mu            sync.Mutex
+	subscriptions map[string]Subscription // Keyed by ID
+	states        map[string]*subscriptionState
+	stop          chan struct{}
+	wg            sync.WaitGroup
+}
+
+var (
+	instance   *manager
+	instanceMu sync.RWMutex
+)
+
+// now returns the current time. Replaced in tests.
+var now = func() time.Time { return time.Now().UTC() }
+
+// NewStore opens a Store for the given driver name and data source
+func NewStore(driver, dsn string) (Store, error) {
+	switch strings.ToLower(driver) {
+	case DriverMemory:
+		return NewMemoryStore(), nil
+	case DriverSQLite:
+		return NewSQLiteStore(dsn)
+	default:
+		return nil, e.FromCode("eAlDr1", driver)
+	}
+}
+
+// Init opens the configured store, loads the subscriptions and starts polling the subscribed pairs with fetch.
+// Does nothing if alerts are disabled.
+func Init(cfg config.AlertConfig, fetch FetchFunc) error {
+	if !cfg.Enabled {
+		c.Info("Rate alerts are disabled")
+		return nil
+	}
+
+	driver := strings.ToLower(cfg.Driver)
+	if driver == "" {
+		driver = DriverMemory
+	}
+	store, err := NewStore(driver, cfg.DSN)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	subscriptions, err := store.List(ctx)
+	if err != nil {
+		_ = store.Close()
+		return err
+	}
+
+	m := &manager{
+		store: store,
+		dispatcher: NewDispatcher(time.Duration(cfg.TimeoutSec)*time.Second, cfg.MaxAttempts,
+			time.Duration(cfg.RetryDelayMs)*time.Millisecond, cfg.AllowPrivate),
+		fetch:         fetch,
+		pollInterval:  time.Duration(cfg.PollSec) * time.Second,
+		maxPerClient:  cfg.MaxPerClient,
+		clientKeys:    cfg.ClientKeys,
+		allowPrivate:  cfg.AllowPrivate,
+		subscriptions: make(map[string]Subscription, len(subscriptions)),
+		states:        make(map[string]*subscriptionState, len(subscriptions)),
+		stop:          make(chan struct{}),
+	}
+	for _, sub := range subscriptions {
+		m.subscriptions[sub.ID] = sub
+		m.states[sub.ID] = &subscriptionState{}
+	}
+
+	if m.pollInterval > 0 && fetch != nil {
+		m.wg.Add(1)
+		go m.pollLoop()
+	}
+
+	instanceMu.Lock()
+	previous := instance
+	instance = m
+	instanceMu.Unlock()
+	if previous != nil {
+		_ = previous.close()
+	}
+
+	c.Successf("Rate alerts are enabled, with %d subscriptions in the '%s' store", len(subscriptions), driver)
+	return nil
+}
+
+// Enabled returns true if webhooks can be registered
+func Enabled() bool {
+	instanceMu.RLock()
+	defer instanceMu.RUnlock()
+	return instance != nil
+}
+
+// Close stops polling and delivering, and closes the store. Does nothing if alerts are disabled.
+func Close() error {
+	instanceMu.Lock()
+	defer instanceMu.Unlock()
+	if instance == nil {
+		return nil
+	}
+	err := instance.close()
+	instance = nil
+	return err
+}
+
+// getManager returns the configured manager, or an error if alerts are disabled
+func getManager() (*manager, error) {
+	instanceMu.RLock()
+	defer instanceMu.RUnlock()
+	if instance == nil {
+		return nil, e.FromCode("eAlDs1")
+	}
+	return instance, nil
+}
+
+func (m *manager) close() error {
+	close(m.stop)
+	m.wg.Wait()
+	m.dispatcher.Close()
+	return m.store.Close()
+}
+
+// Observe evaluates the subscriptions of the pairs with a freshly fetched rate, and sends the alerts which fire.
+// Does nothing if alerts are disabled.
+func Observe(from string, rateList providers.RateList) {
+	instanceMu.RLock()
+	m := instance
+	instanceMu.RUnlock()
+	if m != nil {
+		m.observe(from, rateList, now())
+	}
+}
+
+func (m *manager) observe(from string, rateList providers.RateList, at time.Time) {
+	type delivery struct {
+		sub   Subscription
+		event Event
+	}
+	var deliveries []delivery
+
+	// Evaluate under the lock, so the same move is never sent twice by concurrent fetches
+	m.mu.Lock()
+	for id, sub := range m.subscriptions {
+		rate, ok := rateList[sub.Quote]
+		if sub.Base != from || !ok {
+			continue
+		}
+		event := evaluate(sub, m.states[id], rate, at)
+		if event == nil {
+			continue
+		}
+		eventID, err := newID()
+		if err != nil {
+			e.FromError(err).Print(-1, 0)
+			continue
+		}
+		event.ID = eventID
+		deliveries = append(deliveries, delivery{sub, *event})
+	}
+	m.mu.Unlock()
+
+	for _, d := range deliveries {
+		m.dispatcher.Send(d.sub, d.event)
+	}
+}
+
+// pollLoop fetches the subscribed pairs periodically, so that alerts fire even when nobody requests the rates
+func (m *manager) pollLoop() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(m.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+			m.poll()
+		}
+	}
+}
+
+// poll fetches the subscribed quote currencies, once per base currency
+func (m *manager) poll() {
+	m.mu.Lock()
+	quotesByBase := make(map[string][]string)
+	for _, sub := range m.subscriptions {
+		quotes := quotesByBase[sub.Base]
+		if !util.SliceContains(quotes, sub.Quote) {
+			quotesByBase[sub.Base] = append(quotes, sub.Quote)
+		}
+	}
+	m.mu.Unlock()
+
+	for base, quotes := range quotesByBase {
+		sort.Strings(quotes)
+		if err := m.fetch(base, quotes); err != nil {
+			e.FromError(err).Print(-1, 0)
+		}
+	}
+}
+
+// validate checks the condition, threshold and callback URL of a new subscription.
+// Unless allowPrivate is set, the callback host must not be, or resolve to, a private address.
+func validate(condition string, threshold decimal.Decimal, callbackURL string, allowPrivate bool) error {
+	switch condition {
+	case ConditionAbsolute, ConditionPercent, ConditionCross:
+	default:
+		return e.FromCode("eAlCd1", condition)
+	}
+	if threshold.Sign() <= 0 {
+		return e.FromCode("eAlTh1", threshold.String())
+	}
+	parsed, err := url.Parse(callbackURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+		return e.FromCode("eAlUr1", callbackURL)
+	}
+	if !allowPrivate && isPrivateHost(parsed.Hostname()) {
+		return e.FromCode("eAlUr2", callbackURL)
+	}
+	return nil
+}
+
+// isPrivateHost returns true if the host is a private address, or resolves to one.
+// A host which does not resolve yet is let through: the dispatcher checks the addresses again when it connects.
+func isPrivateHost(host string) bool {
+	if ip := net.ParseIP(host); ip != nil {
+		return isPrivateIP(ip)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+	defer cancel()
+	addresses, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return false
+	}
+	for _, address := range addresses {
+		if isPrivateIP(address.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+// Create registers a webhook for a pair. The returned subscription holds the secret used to sign the callbacks.
+func Create(from, to, condition string, threshold decimal.Decimal, callbackURL, apiKey string) (*Subscription, error) {
+	m, err := getManager()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(m.clientKeys) > 0 && !util.SliceContains(m.clientKeys, apiKey) {
+		return nil, e.FromCode("eAlAu1")
+	}
+
+	condition = strings.ToLower(condition)
+	if err := validate(condition, threshold, callbackURL, m.allowPrivate); err != nil {
+		return nil, err
+	}
+
+	id, err := newID()
+	if err != nil {
+		return nil, e.FromCode("eAlSv1").SetPrevious(err)
+	}
+	secret, err := newID()
+	if err != nil {
+		return nil, e.FromCode("eAlSv1").SetPrevious(err)
+	}
+	sub := Subscription{
+		ID:        id,
+		Base:      from,
+		Quote:     to,
+		Condition: condition,
+		Threshold: threshold,
+		URL:       callbackURL,
+		Secret:    secret,
+		APIKey:    apiKey,
+		CreatedAt: now(),
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.maxPerClient > 0 {
+		count := 0
+		for _, existing := range m.subscriptions {
+			if existing.APIKey == apiKey {
+				count++
+			}
+		}
+		if count >= m.maxPerClient {
+			return nil, e.FromCode("eAlLm1", m.maxPerClient)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	if err := m.store.Save(ctx, sub); err != nil {
+		return nil, err
+	}
+	m.subscriptions[id] = sub
+	m.states[id] = &subscriptionState{}
+	return &sub, nil
+}
+
+// Get returns the subscription with the given ID. Subscriptions of other clients are reported as not found.
+func Get(id, apiKey string) (*Subscription, error) {
+	m, err := getManager()
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sub, ok := m.subscriptions[id]
+	if !ok || sub.APIKey != apiKey {
+		return nil, e.FromCode("eAlNf1", id)
+	}
+	return &sub, nil
+}
+
+// List returns the subscriptions of a client, oldest first
+func List(apiKey string) ([]Subscription, error) {
+	m, err := getManager()
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	result := []Subscription{}
+	for _, sub := range m.subscriptions {
+		if sub.APIKey == apiKey {
+			result = append(result, sub)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.Before(result[j].CreatedAt) })
+	return result, nil
+}
+
+// Delete removes a subscription. Subscriptions of other clients are reported as not found.
+func Delete(id, apiKey string) error {
+	m, err := getManager()
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sub, ok := m.subscriptions[id]
+	if !ok || sub.APIKey != apiKey {
+		return e.FromCode("eAlNf1", id)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	if _, err := m.store.Delete(ctx, id); err != nil {
+		return err
+	}
+	delete(m.subscriptions, id)
+	delete(m.states, id)
+	return nil
+}
+
+// IsRequestError returns true if the error was caused by an invalid subscription, rather than by a failure
+func IsRequestError(err error) bool {
+	switch e.FromError(err).GetCode() {
+	case "eAlCd1", "eAlTh1", "eAlUr1", "eAlUr2", "eAlLm1", "eAlAu1":
+		return true
+	default:
+		return false
+	}
+}
+
+// IsNotFound returns true if the subscription does not exist, or belongs to another client
+func IsNotFound(err error) bool {
+	return e.FromError(err).GetCode() == "eAlNf1"
+}
@@ -0,0 +1,50 @@
+package alerts
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// memoryStore keeps subscriptions in memory. Subscriptions are lost when the service restarts.
+type memoryStore struct {
+	mu            sync.Mutex
+	subscriptions map[string]Subscription
+}
+
+// NewMemoryStore creates an in-memory subscription store
+func NewMemoryStore() Store {
+	return &memoryStore{subscriptions: make(map[string]Subscription)}
+}
+
+func (m *memoryStore) Save(_ context.Context, subscription Subscription) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.subscriptions[subscription.ID] = subscription
+	return nil
+}
+
+func (m *memoryStore) List(_ context.Context) ([]Subscription, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	result := make([]Subscription, 0, len(m.subscriptions))
+	for _, subscription := range m.subscriptions {
+		result = append(result, subscription)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.Before(result[j].CreatedAt) })
+	return result, nil
+}
+
+func (m *memoryStore) Delete(_ context.Context, id string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.subscriptions[id]; !ok {
+		return false, nil
+	}
+	delete(m.subscriptions, id)
+	return true, nil
+}
+
+func (m *memoryStore) Close() error {
+	return nil
+}
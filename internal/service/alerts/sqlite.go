@@ -0,0 +1,107 @@
+package alerts
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"fx-service/pkg/decimal"
+	"fx-service/pkg/e"
+
+	_ "modernc.org/sqlite" // Pure Go SQLite driver, registered as "sqlite"
+)
+
+// sqliteStore keeps subscriptions in a SQLite database, so they survive restarts
+type sqliteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore opens (or creates) a SQLite database file for subscriptions
+// The dsn is a file path, e.g. "alerts.db", optionally with query parameters supported by the driver
+func NewSQLiteStore(dsn string) (Store, error) {
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, e.FromCode("eAlOp1").SetPrevious(err)
+	}
+
+	// SQLite only supports a single writer, so avoid "database is locked" errors from concurrent connections
+	db.SetMaxOpenConns(1)
+
+	_, err = db.ExecContext(context.Background(), `CREATE TABLE IF NOT EXISTS fx_subscriptions (
+		id VARCHAR(64) PRIMARY KEY,
+		base VARCHAR(8) NOT NULL,
+		quote VARCHAR(8) NOT NULL,
+		condition VARCHAR(16) NOT NULL,
+		threshold TEXT NOT NULL,
+		url TEXT NOT NULL,
+		secret VARCHAR(128) NOT NULL,
+		api_key VARCHAR(256) NOT NULL,
+		created_at BIGINT NOT NULL
+	)`)
+	if err != nil {
+		_ = db.Close()
+		return nil, e.FromCode("eAlOp1").SetPrevious(err)
+	}
+	return &sqliteStore{db: db}, nil
+}
+
+func (s *sqliteStore) Save(ctx context.Context, subscription Subscription) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO fx_subscriptions (id, base, quote, condition, threshold, url, secret, api_key, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		subscription.ID, subscription.Base, subscription.Quote, subscription.Condition,
+		subscription.Threshold.String(), subscription.URL, subscription.Secret, subscription.APIKey,
+		subscription.CreatedAt.UnixNano(),
+	)
+	if err != nil {
+		return e.FromCode("eAlSv1").SetPrevious(err)
+	}
+	return nil
+}
+
+func (s *sqliteStore) List(ctx context.Context) ([]Subscription, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, base, quote, condition, threshold, url, secret, api_key, created_at
+		FROM fx_subscriptions ORDER BY created_at`)
+	if err != nil {
+		return nil, e.FromCode("eAlQr1").SetPrevious(err)
+	}
+	defer rows.Close()
+
+	var result []Subscription
+	for rows.Next() {
+		var sub Subscription
+		var threshold string
+		var createdAt int64
+		err := rows.Scan(&sub.ID, &sub.Base, &sub.Quote, &sub.Condition, &threshold, &sub.URL, &sub.Secret,
+			&sub.APIKey, &createdAt)
+		if err != nil {
+			return nil, e.FromCode("eAlQr1").SetPrevious(err)
+		}
+		if sub.Threshold, err = decimal.Parse(threshold); err != nil {
+			return nil, e.FromCode("eAlQr1").SetPrevious(err)
+		}
+		sub.CreatedAt = time.Unix(0, createdAt).UTC()
+		result = append(result, sub)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, e.FromCode("eAlQr1").SetPrevious(err)
+	}
+	return result, nil
+}
+
+func (s *sqliteStore) Delete(ctx context.Context, id string) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM fx_subscriptions WHERE id = ?`, id)
+	if err != nil {
+		return false, e.FromCode("eAlSv1").SetPrevious(err)
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return false, e.FromCode("eAlSv1").SetPrevious(err)
+	}
+	return deleted == 1, nil
+}
+
+func (s *sqliteStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,41 @@
+package history
+
+import (
+	"context"
+	"time"
+
+	"fx-service/pkg/decimal"
+)
+
+// Record is a single rate observation, as fetched from an upstream provider
+type Record struct {
+	Base       string
+	Quote      string
+	Rate       decimal.Decimal
+	Provider   string
+	SourceTime *time.Time // Timestamp reported by the upstream provider, when it gives one
+	FetchedAt  time.Time  // When we fetched the rate
+}
+
+// Store is a persistent store of historic rates. Implementations must be safe for concurrent use.
+type Store interface {
+	// Append saves the given records
+	Append(ctx context.Context, records []Record) error
+
+	// Series returns the records for a pair fetched within [start, end), oldest first
+	Series(ctx context.Context, base, quote string, start, end time.Time) ([]Record, error)
+
+	// Last returns the last record for each of the quote currencies, fetched within [start, end).
+	// Quotes with no record in that range are left out of the result.
+	Last(ctx context.Context, base string, quotes []string, start, end time.Time) (map[string]Record, error)
+
+	// Prune deletes every record fetched before the given time. Returns the number of records deleted.
+	Prune(ctx context.Context, before time.Time) (int64, error)
+
+	// Downsample keeps only the last record per pair, in each interval bucket, for records fetched before the given
+	// time. Returns the number of records deleted.
+	Downsample(ctx context.Context, before time.Time, interval time.Duration) (int64, error)
+
+	// Close releases the underlying resources
+	Close() error
+}
@@ -0,0 +1,186 @@
+package history
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"fx-service/pkg/decimal"
+)
+
+// newTestStore opens a SQLite store in a temporary directory
+func newTestStore(t *testing.T) *sqlStore {
+	t.Helper()
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("Failed to open SQLite store: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	return store.(*sqlStore)
+}
+
+// countRows counts the records in the store
+func countRows(t *testing.T, store *sqlStore) int {
+	t.Helper()
+	var count int
+	if err := store.db.QueryRow(`SELECT COUNT(*) FROM fx_rates`).Scan(&count); err != nil {
+		t.Fatalf("Failed to count rows: %v", err)
+	}
+	return count
+}
+
+// TestBind checks placeholder rewriting for numbered-parameter dialects
+func TestBind(t *testing.T) {
+	pg := &sqlStore{dialect: postgresDialect}
+	if got := pg.bind("a = ? AND b = ?"); got != "a = $1 AND b = $2" {
+		t.Errorf("Unexpected postgres query: %s", got)
+	}
+	lite := &sqlStore{dialect: sqliteDialect}
+	if got := lite.bind("a = ?"); got != "a = ?" {
+		t.Errorf("Unexpected sqlite query: %s", got)
+	}
+}
+
+// TestAppendAndPrune checks saving records and deleting old ones
+func TestAppendAndPrune(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now()
+	source := now.Add(-time.Minute)
+
+	err := store.Append(ctx, []Record{
+		{Base: "USD", Quote: "EUR", Rate: decimal.MustParse("0.85"), Provider: "FixerApi", SourceTime: &source, FetchedAt: now},
+		{Base: "USD", Quote: "GBP", Rate: decimal.MustParse("0.75"), Provider: "FixerApi", FetchedAt: now.Add(-48 * time.Hour)},
+	})
+	if err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	if count := countRows(t, store); count != 2 {
+		t.Fatalf("Expected 2 rows, got %d", count)
+	}
+
+	deleted, err := store.Prune(ctx, now.Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if deleted != 1 || countRows(t, store) != 1 {
+		t.Errorf("Expected 1 row pruned and 1 left, got %d pruned and %d left", deleted, countRows(t, store))
+	}
+}
+
+// TestExactRates checks that rates keep every digit through the store, beyond what a double holds
+func TestExactRates(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	rate := decimal.MustParse("151.23456789012345678")
+	if err := store.Append(ctx, []Record{{Base: "USD", Quote: "JPY", Rate: rate, Provider: "FixerApi", FetchedAt: now}}); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	last, err := store.Last(ctx, "USD", []string{"JPY"}, now.Add(-time.Hour), now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Last failed: %v", err)
+	}
+	if got := last["JPY"].Rate; got.String() != rate.String() {
+		t.Errorf("Expected %s, got %s", rate, got)
+	}
+}
+
+// TestDownsample checks that only the last record per pair and bucket is kept
+func TestDownsample(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	var records []Record
+	for hour := 0; hour < 48; hour += 6 {
+		at := day.Add(time.Duration(hour) * time.Hour)
+		records = append(records,
+			Record{Base: "USD", Quote: "EUR", Rate: decimal.MustParse("0.85"), Provider: "a", FetchedAt: at},
+			Record{Base: "USD", Quote: "GBP", Rate: decimal.MustParse("0.75"), Provider: "a", FetchedAt: at},
+		)
+	}
+	if err := store.Append(ctx, records); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+
+	// Downsample only the first day, to one record per pair
+	_, err := store.Downsample(ctx, day.Add(24*time.Hour), 24*time.Hour)
+	if err != nil {
+		t.Fatalf("Downsample failed: %v", err)
+	}
+	// 2 pairs x 1 record for day one, plus 2 pairs x 4 records for day two
+	if count := countRows(t, store); count != 10 {
+		t.Errorf("Expected 10 rows after downsampling, got %d", count)
+	}
+
+	var lastFetch int64
+	err = store.db.QueryRow(
+		`SELECT fetched_at FROM fx_rates WHERE base = 'USD' AND quote = 'EUR' ORDER BY fetched_at LIMIT 1`,
+	).Scan(&lastFetch)
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if expected := day.Add(18 * time.Hour).Unix(); lastFetch != expected {
+		t.Errorf("Expected the last record of the day (%d) to be kept, got %d", expected, lastFetch)
+	}
+}
+
+// memoryStore is a Store which keeps records in memory, for testing the recorder
+type memoryStore struct {
+	mu      sync.Mutex
+	records []Record
+	closed  bool
+}
+
+func (m *memoryStore) Append(_ context.Context, records []Record) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.records = append(m.records, records...)
+	return nil
+}
+
+func (m *memoryStore) Series(_ context.Context, _, _ string, _, _ time.Time) ([]Record, error) {
+	return nil, nil
+}
+
+func (m *memoryStore) Last(_ context.Context, _ string, _ []string, _, _ time.Time) (map[string]Record, error) {
+	return nil, nil
+}
+
+func (m *memoryStore) Prune(_ context.Context, _ time.Time) (int64, error) { return 0, nil }
+
+func (m *memoryStore) Downsample(_ context.Context, _ time.Time, _ time.Duration) (int64, error) {
+	return 0, nil
+}
+
+func (m *memoryStore) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closed = true
+	return nil
+}
+
+// TestRecorderFlushesOnClose checks that queued records are written before the store is closed
+func TestRecorderFlushesOnClose(t *testing.T) {
+	store := &memoryStore{}
+	recorder := NewRecorder(store, Policy{}, 10)
+
+	recorder.Add(
+		Record{Base: "USD", Quote: "EUR", Rate: decimal.MustParse("0.85"), FetchedAt: time.Now()},
+		Record{Base: "USD", Quote: "GBP", Rate: decimal.MustParse("0.75"), FetchedAt: time.Now()},
+	)
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if len(store.records) != 2 || !store.closed {
+		t.Errorf("Expected 2 records and a closed store, got %d records, closed: %v", len(store.records), store.closed)
+	}
+
+	// Adding after close must not panic
+	recorder.Add(Record{Base: "USD", Quote: "JPY"})
+}
@@ -0,0 +1,113 @@
+package history
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"fx-service/pkg/config"
+	c "fx-service/pkg/console"
+	"fx-service/pkg/e"
+)
+
+// Supported store drivers
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+var (
+	instance   *Recorder
+	instanceMu sync.RWMutex
+)
+
+// NewStore opens a Store for the given driver name and data source
+func NewStore(driver, dsn string) (Store, error) {
+	switch strings.ToLower(driver) {
+	case DriverSQLite:
+		return NewSQLiteStore(dsn)
+	case DriverPostgres:
+		return NewPostgresStore(dsn)
+	default:
+		return nil, e.FromCode("eHsDr1", driver)
+	}
+}
+
+// policyFromConfig converts the history config into a retention and downsampling Policy
+func policyFromConfig(cfg config.HistoryConfig) (Policy, error) {
+	policy := Policy{
+		Retention:       time.Duration(cfg.RetentionDays) * 24 * time.Hour,
+		DownsampleAfter: time.Duration(cfg.DownsampleAfterDays) * 24 * time.Hour,
+	}
+	if cfg.DownsampleInterval != "" {
+		interval, err := time.ParseDuration(cfg.DownsampleInterval)
+		if err != nil || interval <= 0 {
+			return policy, e.FromCode("eHsDi1", cfg.DownsampleInterval)
+		}
+		policy.DownsampleInterval = interval
+	}
+	return policy, nil
+}
+
+// Init opens the configured store and starts recording historic rates. Does nothing if history is disabled.
+func Init(cfg config.HistoryConfig) error {
+	if !cfg.Enabled {
+		c.Info("Historic rate storage is disabled")
+		return nil
+	}
+
+	policy, err := policyFromConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	store, err := NewStore(cfg.Driver, cfg.DSN)
+	if err != nil {
+		return err
+	}
+
+	instanceMu.Lock()
+	instance = NewRecorder(store, policy, cfg.BufferSize)
+	instanceMu.Unlock()
+
+	c.Successf("Historic rates are being stored with the '%s' driver", cfg.Driver)
+	return nil
+}
+
+// Enabled returns true if historic rates are being recorded
+func Enabled() bool {
+	instanceMu.RLock()
+	defer instanceMu.RUnlock()
+	return instance != nil
+}
+
+// Add queues records to be stored asynchronously. Does nothing if history is disabled.
+func Add(records ...Record) {
+	instanceMu.RLock()
+	defer instanceMu.RUnlock()
+	if instance != nil {
+		instance.Add(records...)
+	}
+}
+
+// GetStore returns the configured store, or nil if history is disabled
+func GetStore() Store {
+	instanceMu.RLock()
+	defer instanceMu.RUnlock()
+	if instance == nil {
+		return nil
+	}
+	return instance.Store()
+}
+
+// Close flushes pending records and closes the store. Does nothing if history is disabled.
+func Close() error {
+	instanceMu.Lock()
+	defer instanceMu.Unlock()
+	if instance == nil {
+		return nil
+	}
+	err := instance.Close()
+	instance = nil
+	return err
+}
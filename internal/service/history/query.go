@@ -0,0 +1,134 @@
+package history
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"fx-service/pkg/decimal"
+	"fx-service/pkg/e"
+)
+
+// SourceStore is the source of buckets built from the local store
+const SourceStore = "store"
+
+// Bucket is an OHLC summary of the rates observed for a pair in one interval
+type Bucket struct {
+	Start  time.Time       `json:"start"`
+	End    time.Time       `json:"end"`
+	Open   decimal.Decimal `json:"open"`
+	High   decimal.Decimal `json:"high"`
+	Low    decimal.Decimal `json:"low"`
+	Close  decimal.Decimal `json:"close"`
+	Count  int             `json:"count"`  // Number of observations. 0 means there is no data for the interval
+	Source string          `json:"source"` // "store", or the name of the provider used to fill a gap
+}
+
+// Observe adds a rate observation to the bucket. Observations must be added oldest first.
+func (b *Bucket) Observe(rate decimal.Decimal) {
+	if b.Count == 0 {
+		b.Open = rate
+		b.High = rate
+		b.Low = rate
+	}
+	if rate.Cmp(b.High) > 0 {
+		b.High = rate
+	}
+	if rate.Cmp(b.Low) < 0 {
+		b.Low = rate
+	}
+	b.Close = rate
+	b.Count++
+}
+
+// BuildBuckets splits [start, end) into consecutive intervals and summarises the records (oldest first) into OHLC
+// buckets. Buckets are aligned to the unix epoch in UTC, so daily buckets run from midnight to midnight.
+func BuildBuckets(records []Record, start, end time.Time, interval time.Duration) []Bucket {
+	var buckets []Bucket
+	for at := start.UTC().Truncate(interval); at.Before(end); at = at.Add(interval) {
+		buckets = append(buckets, Bucket{Start: at, End: at.Add(interval)})
+	}
+
+	i := 0
+	for _, record := range records {
+		for i < len(buckets) && !record.FetchedAt.Before(buckets[i].End) {
+			i++
+		}
+		if i == len(buckets) {
+			break
+		}
+		if record.FetchedAt.Before(buckets[i].Start) {
+			continue
+		}
+		buckets[i].Observe(record.Rate)
+		buckets[i].Source = SourceStore
+	}
+	return buckets
+}
+
+// CountBuckets returns the number of buckets BuildBuckets would create for the range
+func CountBuckets(start, end time.Time, interval time.Duration) int {
+	first := start.UTC().Truncate(interval)
+	if !first.Before(end) {
+		return 0
+	}
+	return int((end.Sub(first)-1)/interval) + 1
+}
+
+// PercentChange returns the change, in percent, from the open of the first bucket with data to the close of the last
+// one. Returns nil if there is no data.
+func PercentChange(buckets []Bucket) *float64 {
+	var first, last *Bucket
+	for i := range buckets {
+		if buckets[i].Count == 0 {
+			continue
+		}
+		if first == nil {
+			first = &buckets[i]
+		}
+		last = &buckets[i]
+	}
+	if first == nil || first.Open.IsZero() {
+		return nil
+	}
+
+	change := last.Close.Sub(first.Open).Mul(decimal.NewFromInt(100)).Div(first.Open, 4).Float64()
+	return &change
+}
+
+// ParseInterval parses a bucket interval. Accepts Go durations ("1h", "15m"), days ("1d") and weeks ("1w").
+func ParseInterval(s string) (time.Duration, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+
+	var interval time.Duration
+	var err error
+	switch {
+	case strings.HasSuffix(s, "d") || strings.HasSuffix(s, "w"):
+		unit := 24 * time.Hour
+		if strings.HasSuffix(s, "w") {
+			unit *= 7
+		}
+		var n int
+		n, err = strconv.Atoi(s[:len(s)-1])
+		interval = time.Duration(n) * unit
+	default:
+		interval, err = time.ParseDuration(s)
+	}
+
+	if err != nil || interval < time.Minute {
+		return 0, e.FromCode("eHsIv1", s)
+	}
+	return interval, nil
+}
+
+// ParseTime parses a date ("2006-01-02", as midnight UTC) or an RFC 3339 timestamp
+func ParseTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, e.FromCode("eHsTm1", s)
+	}
+	return t.UTC(), nil
+}
@@ -0,0 +1,134 @@
+package history
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"fx-service/pkg/decimal"
+)
+
+// TestBuildBuckets checks that records are summarised into OHLC buckets, with gaps left empty
+func TestBuildBuckets(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(3 * 24 * time.Hour)
+	records := []Record{
+		{Rate: decimal.MustParse("1.10"), FetchedAt: start.Add(1 * time.Hour)},
+		{Rate: decimal.MustParse("1.15"), FetchedAt: start.Add(2 * time.Hour)},
+		{Rate: decimal.MustParse("1.05"), FetchedAt: start.Add(3 * time.Hour)},
+		{Rate: decimal.MustParse("1.12"), FetchedAt: start.Add(4 * time.Hour)},
+		{Rate: decimal.MustParse("1.20"), FetchedAt: start.Add(50 * time.Hour)},
+	}
+
+	buckets := BuildBuckets(records, start, end, 24*time.Hour)
+	if len(buckets) != 3 {
+		t.Fatalf("Expected 3 buckets, got %d", len(buckets))
+	}
+
+	first := buckets[0]
+	if first.Open.String() != "1.10" || first.High.String() != "1.15" || first.Low.String() != "1.05" || first.Close.String() != "1.12" || first.Count != 4 {
+		t.Errorf("Unexpected first bucket: %+v", first)
+	}
+	if first.Source != SourceStore {
+		t.Errorf("Expected source %q, got %q", SourceStore, first.Source)
+	}
+	if buckets[1].Count != 0 || buckets[1].Source != "" {
+		t.Errorf("Expected an empty second bucket, got %+v", buckets[1])
+	}
+	if buckets[2].Close.String() != "1.20" || buckets[2].Count != 1 {
+		t.Errorf("Unexpected third bucket: %+v", buckets[2])
+	}
+
+	change := PercentChange(buckets)
+	if change == nil || *change != 9.0909 {
+		t.Errorf("Expected a change of 9.0909%%, got %v", change)
+	}
+}
+
+// TestBuildBucketsAlignment checks that buckets are aligned to the interval, and cover the whole range
+func TestBuildBucketsAlignment(t *testing.T) {
+	start := time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)
+	end := time.Date(2024, 3, 2, 1, 0, 0, 0, time.UTC)
+
+	buckets := BuildBuckets(nil, start, end, 24*time.Hour)
+	if len(buckets) != 2 || !buckets[0].Start.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected 2 buckets starting at midnight, got %+v", buckets)
+	}
+	if count := CountBuckets(start, end, 24*time.Hour); count != len(buckets) {
+		t.Errorf("Expected CountBuckets to match %d, got %d", len(buckets), count)
+	}
+	if PercentChange(buckets) != nil {
+		t.Error("Expected no change without data")
+	}
+}
+
+// TestParseInterval checks the supported interval formats
+func TestParseInterval(t *testing.T) {
+	tests := map[string]time.Duration{
+		"15m": 15 * time.Minute,
+		"1h":  time.Hour,
+		"1d":  24 * time.Hour,
+		"7D":  7 * 24 * time.Hour,
+		"2w":  14 * 24 * time.Hour,
+	}
+	for input, expected := range tests {
+		got, err := ParseInterval(input)
+		if err != nil || got != expected {
+			t.Errorf("ParseInterval(%q): expected %v, got %v (err: %v)", input, expected, got, err)
+		}
+	}
+
+	for _, input := range []string{"", "abc", "1x", "30s", "0d", "-1h"} {
+		if _, err := ParseInterval(input); err == nil {
+			t.Errorf("ParseInterval(%q): expected an error", input)
+		}
+	}
+}
+
+// TestParseTime checks dates and RFC 3339 timestamps
+func TestParseTime(t *testing.T) {
+	got, err := ParseTime("2024-03-01")
+	if err != nil || !got.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected date: %v (err: %v)", got, err)
+	}
+	got, err = ParseTime("2024-03-01T12:00:00+02:00")
+	if err != nil || !got.Equal(time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected timestamp: %v (err: %v)", got, err)
+	}
+	if _, err = ParseTime("01/03/2024"); err == nil {
+		t.Error("Expected an error for an unsupported format")
+	}
+}
+
+// TestSeriesAndLast checks querying the SQLite store
+func TestSeriesAndLast(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	err := store.Append(ctx, []Record{
+		{Base: "USD", Quote: "EUR", Rate: decimal.MustParse("0.91"), Provider: "a", FetchedAt: day.Add(2 * time.Hour)},
+		{Base: "USD", Quote: "EUR", Rate: decimal.MustParse("0.92"), Provider: "b", FetchedAt: day.Add(20 * time.Hour)},
+		{Base: "USD", Quote: "GBP", Rate: decimal.MustParse("0.78"), Provider: "a", FetchedAt: day.Add(3 * time.Hour)},
+		{Base: "USD", Quote: "EUR", Rate: decimal.MustParse("0.93"), Provider: "a", FetchedAt: day.Add(26 * time.Hour)},
+	})
+	if err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+
+	series, err := store.Series(ctx, "USD", "EUR", day, day.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("Series failed: %v", err)
+	}
+	if len(series) != 2 || series[0].Rate.String() != "0.91" || series[1].Rate.String() != "0.92" {
+		t.Errorf("Unexpected series: %+v", series)
+	}
+
+	last, err := store.Last(ctx, "USD", []string{"EUR", "GBP", "JPY"}, day, day.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("Last failed: %v", err)
+	}
+	if len(last) != 2 || last["EUR"].Rate.String() != "0.92" || last["EUR"].Provider != "b" || last["GBP"].Rate.String() != "0.78" {
+		t.Errorf("Unexpected last records: %+v", last)
+	}
+}
@@ -0,0 +1,177 @@
+package history
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	c "fx-service/pkg/console"
+	"fx-service/pkg/e"
+)
+
+const (
+	recorderBatchSize     = 100              // Maximum number of records written in one transaction
+	recorderFlushInterval = time.Second      // How long records may wait in the buffer before being written
+	maintenanceInterval   = time.Hour        // How often retention and downsampling policies are applied
+	storeWriteTimeout     = 30 * time.Second // Timeout for a single write or maintenance operation
+)
+
+// Policy defines how long historic rates are kept, and how they are downsampled as they age
+type Policy struct {
+	Retention          time.Duration // Records older than this are deleted. 0 keeps everything
+	DownsampleAfter    time.Duration // Records older than this are downsampled. 0 disables downsampling
+	DownsampleInterval time.Duration // Only the last record per pair is kept, for each interval bucket
+}
+
+// Recorder appends records to a Store asynchronously, in batches, and applies the retention policy periodically
+type Recorder struct {
+	store  Store
+	policy Policy
+	queue  chan Record
+	stop   chan struct{}
+	wg     sync.WaitGroup
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewRecorder creates a Recorder for the store and starts its background workers.
+// bufferSize is the number of records which can wait to be written, before new records are dropped.
+func NewRecorder(store Store, policy Policy, bufferSize int) *Recorder {
+	if bufferSize <= 0 {
+		bufferSize = 1000
+	}
+	r := &Recorder{
+		store:  store,
+		policy: policy,
+		queue:  make(chan Record, bufferSize),
+		stop:   make(chan struct{}),
+	}
+
+	r.wg.Add(2)
+	go r.writeLoop()
+	go r.maintenanceLoop()
+
+	return r
+}
+
+// Add queues records to be written. It never blocks: if the buffer is full, the records are dropped.
+func (r *Recorder) Add(records ...Record) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		return
+	}
+
+	for _, record := range records {
+		select {
+		case r.queue <- record:
+		default:
+			c.Warnf("History buffer is full, dropped rate %s/%s from %s", record.Base, record.Quote, record.Provider)
+		}
+	}
+}
+
+// Close flushes the queued records, stops the background workers and closes the store
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	close(r.queue)
+	close(r.stop)
+	r.mu.Unlock()
+
+	r.wg.Wait()
+	return r.store.Close()
+}
+
+// Store returns the underlying store
+func (r *Recorder) Store() Store {
+	return r.store
+}
+
+// writeLoop batches queued records and writes them to the store
+func (r *Recorder) writeLoop() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(recorderFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Record, 0, recorderBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), storeWriteTimeout)
+		defer cancel()
+		if err := r.store.Append(ctx, batch); err != nil {
+			c.Warnf("Failed to save %d historic rates", len(batch))
+			e.FromError(err).Print(-1, 0)
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case record, ok := <-r.queue:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, record)
+			if len(batch) >= recorderBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// maintenanceLoop applies the retention and downsampling policy periodically
+func (r *Recorder) maintenanceLoop() {
+	defer r.wg.Done()
+
+	if r.policy.Retention <= 0 && r.policy.DownsampleAfter <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(maintenanceInterval)
+	defer ticker.Stop()
+
+	r.applyPolicy(time.Now())
+	for {
+		select {
+		case <-r.stop:
+			return
+		case now := <-ticker.C:
+			r.applyPolicy(now)
+		}
+	}
+}
+
+// applyPolicy deletes expired records and downsamples aged ones, relative to the given time
+func (r *Recorder) applyPolicy(now time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), storeWriteTimeout)
+	defer cancel()
+
+	if r.policy.Retention > 0 {
+		deleted, err := r.store.Prune(ctx, now.Add(-r.policy.Retention))
+		if err != nil {
+			e.FromError(err).Print(-1, 0)
+		} else if deleted > 0 {
+			c.Infof("History retention removed %d records", deleted)
+		}
+	}
+
+	if r.policy.DownsampleAfter > 0 && r.policy.DownsampleInterval > 0 {
+		deleted, err := r.store.Downsample(ctx, now.Add(-r.policy.DownsampleAfter), r.policy.DownsampleInterval)
+		if err != nil {
+			e.FromError(err).Print(-1, 0)
+		} else if deleted > 0 {
+			c.Infof("History downsampling removed %d records", deleted)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package history
+
+import (
+	_ "modernc.org/sqlite" // Pure Go SQLite driver, registered as "sqlite"
+)
+
+var sqliteDialect = dialect{
+	name:           "sqlite",
+	idColumn:       "INTEGER PRIMARY KEY AUTOINCREMENT",
+	decimalColumn:  "TEXT",
+	numberedParams: false,
+}
+
+// NewSQLiteStore opens (or creates) a SQLite database file for historic rates
+// The dsn is a file path, e.g. "history.db", optionally with query parameters supported by the driver
+func NewSQLiteStore(dsn string) (Store, error) {
+	store, err := newSQLStore("sqlite", dsn, sqliteDialect)
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite only supports a single writer, so avoid "database is locked" errors from concurrent connections
+	store.db.SetMaxOpenConns(1)
+	return store, nil
+}
@@ -0,0 +1,232 @@
+package history
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"fx-service/pkg/decimal"
+	"fx-service/pkg/e"
+)
+
+// dialect holds the SQL differences between database engines
+type dialect struct {
+	name           string
+	idColumn       string // Auto-incrementing primary key column definition
+	decimalColumn  string // Column type keeping the exact digits of a decimal
+	numberedParams bool   // Whether placeholders are numbered ($1, $2) rather than "?"
+}
+
+// sqlStore implements Store on top of database/sql, for any supported dialect
+type sqlStore struct {
+	db      *sql.DB
+	dialect dialect
+}
+
+// newSQLStore opens the database and ensures the schema exists
+func newSQLStore(driverName, dsn string, d dialect) (*sqlStore, error) {
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, e.FromCode("eHsOp1", d.name).SetPrevious(err)
+	}
+
+	store := &sqlStore{db: db, dialect: d}
+	if err := store.migrate(context.Background()); err != nil {
+		_ = db.Close()
+		return nil, e.FromCode("eHsOp1", d.name).SetPrevious(err)
+	}
+	return store, nil
+}
+
+// bind rewrites "?" placeholders to the dialect's placeholder style
+func (s *sqlStore) bind(query string) string {
+	if !s.dialect.numberedParams {
+		return query
+	}
+	var sb strings.Builder
+	n := 0
+	for _, ch := range query {
+		if ch == '?' {
+			n++
+			sb.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		sb.WriteRune(ch)
+	}
+	return sb.String()
+}
+
+// migrate creates the rates table and its index, if they don't exist yet
+func (s *sqlStore) migrate(ctx context.Context) error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS fx_rates (
+			id ` + s.dialect.idColumn + `,
+			base VARCHAR(8) NOT NULL,
+			quote VARCHAR(8) NOT NULL,
+			rate ` + s.dialect.decimalColumn + ` NOT NULL,
+			provider VARCHAR(128) NOT NULL,
+			source_time BIGINT NULL,
+			fetched_at BIGINT NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS fx_rates_pair_time ON fx_rates (base, quote, fetched_at)`,
+		`CREATE INDEX IF NOT EXISTS fx_rates_time ON fx_rates (fetched_at)`,
+	}
+	for _, statement := range statements {
+		if _, err := s.db.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Append saves the given records in a single transaction
+func (s *sqlStore) Append(ctx context.Context, records []Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return e.FromCode("eHsAp1").SetPrevious(err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, s.bind(
+		`INSERT INTO fx_rates (base, quote, rate, provider, source_time, fetched_at) VALUES (?, ?, ?, ?, ?, ?)`,
+	))
+	if err != nil {
+		_ = tx.Rollback()
+		return e.FromCode("eHsAp1").SetPrevious(err)
+	}
+	defer func() { _ = stmt.Close() }()
+
+	for _, record := range records {
+		var sourceTime sql.NullInt64
+		if record.SourceTime != nil {
+			sourceTime = sql.NullInt64{Int64: record.SourceTime.Unix(), Valid: true}
+		}
+		_, err = stmt.ExecContext(ctx,
+			record.Base, record.Quote, record.Rate.String(), record.Provider, sourceTime, record.FetchedAt.Unix(),
+		)
+		if err != nil {
+			_ = tx.Rollback()
+			return e.FromCode("eHsAp1").SetPrevious(err).SetField("record", record)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return e.FromCode("eHsAp1").SetPrevious(err)
+	}
+	return nil
+}
+
+// recordColumns are the columns selected by scanRecord, in order
+const recordColumns = `base, quote, rate, provider, source_time, fetched_at`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanRecord reads a Record from a row of recordColumns.
+// Rates are read as text, so that they keep the exact digits they were fetched with.
+func scanRecord(row rowScanner) (Record, error) {
+	var (
+		record     Record
+		rate       string
+		sourceTime sql.NullInt64
+		fetchedAt  int64
+	)
+	err := row.Scan(&record.Base, &record.Quote, &rate, &record.Provider, &sourceTime, &fetchedAt)
+	if err != nil {
+		return record, err
+	}
+	if record.Rate, err = decimal.Parse(rate); err != nil {
+		return record, err
+	}
+	if sourceTime.Valid {
+		st := time.Unix(sourceTime.Int64, 0).UTC()
+		record.SourceTime = &st
+	}
+	record.FetchedAt = time.Unix(fetchedAt, 0).UTC()
+	return record, nil
+}
+
+// Series returns the records for a pair fetched within [start, end), oldest first
+func (s *sqlStore) Series(ctx context.Context, base, quote string, start, end time.Time) ([]Record, error) {
+	query := s.bind(`SELECT ` + recordColumns + ` FROM fx_rates
+		WHERE base = ? AND quote = ? AND fetched_at >= ? AND fetched_at < ?
+		ORDER BY fetched_at, id`)
+	rows, err := s.db.QueryContext(ctx, query, base, quote, start.Unix(), end.Unix())
+	if err != nil {
+		return nil, e.FromCode("eHsQr1").SetPrevious(err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var records []Record
+	for rows.Next() {
+		record, err := scanRecord(rows)
+		if err != nil {
+			return nil, e.FromCode("eHsQr1").SetPrevious(err)
+		}
+		records = append(records, record)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, e.FromCode("eHsQr1").SetPrevious(err)
+	}
+	return records, nil
+}
+
+// Last returns the last record for each of the quote currencies, fetched within [start, end)
+func (s *sqlStore) Last(ctx context.Context, base string, quotes []string, start, end time.Time) (map[string]Record, error) {
+	query := s.bind(`SELECT ` + recordColumns + ` FROM fx_rates
+		WHERE base = ? AND quote = ? AND fetched_at >= ? AND fetched_at < ?
+		ORDER BY fetched_at DESC, id DESC LIMIT 1`)
+
+	result := make(map[string]Record, len(quotes))
+	for _, quote := range quotes {
+		record, err := scanRecord(s.db.QueryRowContext(ctx, query, base, quote, start.Unix(), end.Unix()))
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, e.FromCode("eHsQr1").SetPrevious(err)
+		}
+		result[quote] = record
+	}
+	return result, nil
+}
+
+// Prune deletes every record fetched before the given time
+func (s *sqlStore) Prune(ctx context.Context, before time.Time) (int64, error) {
+	result, err := s.db.ExecContext(ctx, s.bind(`DELETE FROM fx_rates WHERE fetched_at < ?`), before.Unix())
+	if err != nil {
+		return 0, e.FromCode("eHsPr1").SetPrevious(err)
+	}
+	return result.RowsAffected()
+}
+
+// Downsample keeps only the last record per pair and interval bucket, for records fetched before the given time.
+// Buckets are aligned to the unix epoch, so a 24h interval keeps the last observation of each UTC day.
+func (s *sqlStore) Downsample(ctx context.Context, before time.Time, interval time.Duration) (int64, error) {
+	seconds := int64(interval.Seconds())
+	if seconds <= 0 {
+		return 0, nil
+	}
+
+	query := s.bind(`DELETE FROM fx_rates WHERE fetched_at < ? AND id NOT IN (
+		SELECT MAX(id) FROM fx_rates WHERE fetched_at < ? GROUP BY base, quote, fetched_at / ?
+	)`)
+	result, err := s.db.ExecContext(ctx, query, before.Unix(), before.Unix(), seconds)
+	if err != nil {
+		return 0, e.FromCode("eHsDs1").SetPrevious(err)
+	}
+	return result.RowsAffected()
+}
+
+// Close closes the database
+func (s *sqlStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,429 @@
+// Package overrides forces rates for currency pairs (pegs, regulatory fixings, emergencies), ahead of the cache and
+// every provider strategy. Overrides are saved in a JSON file, and every change is appended to an audit log.
+package overrides
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"fx-service/pkg/config"
+	c "fx-service/pkg/console"
+	"fx-service/pkg/decimal"
+	"fx-service/pkg/e"
+)
+
+// Audit log actions
+const (
+	ActionSet    = "set"    // An override was created or replaced
+	ActionExpire = "expire" // The expiry of an override was changed
+	ActionDelete = "delete" // An override was deleted
+)
+
+// Override is a rate forced for a currency pair, until it expires or is deleted
+type Override struct {
+	Base      string
+	Quote     string
+	Rate      decimal.Decimal
+	Reason    string
+	Author    string
+	CreatedAt time.Time
+	ExpiresAt *time.Time // Nil if the override never expires
+}
+
+// Active returns true if the override applies at the given time
+func (o *Override) Active(now time.Time) bool {
+	return o.ExpiresAt == nil || now.Before(*o.ExpiresAt)
+}
+
+// AuditEntry is a single change to the overrides, as written to the audit log
+type AuditEntry struct {
+	Time         time.Time  `json:"time"`
+	Action       string     `json:"action"`
+	Base         string     `json:"base"`
+	Quote        string     `json:"quote"`
+	Rate         string     `json:"rate,omitempty"`         // The rate after the change. Empty when deleted
+	PreviousRate string     `json:"previousRate,omitempty"` // The rate before the change. Empty when created
+	ExpiresAt    *time.Time `json:"expiresAt,omitempty"`
+	Reason       string     `json:"reason"`
+	Author       string     `json:"author"`
+}
+
+// storedOverride is an override as saved in the overrides file. Rates are strings, to keep them exact.
+type storedOverride struct {
+	Base      string     `json:"base"`
+	Quote     string     `json:"quote"`
+	Rate      string     `json:"rate"`
+	Reason    string     `json:"reason"`
+	Author    string     `json:"author"`
+	CreatedAt time.Time  `json:"createdAt"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+var (
+	mu        sync.RWMutex
+	overrides = make(map[string]Override) // Keyed by pairKey
+	file      string                      // Path of the overrides file. Empty to keep them in memory only
+	auditLog  string                      // Path of the audit log. Empty to disable it
+)
+
+// now returns the current time. Replaced in tests.
+var now = func() time.Time { return time.Now().UTC() }
+
+func pairKey(from, to string) string {
+	return from + "/" + to
+}
+
+// Init loads the overrides saved in the configured file, if it exists
+func Init(cfg config.OverrideConfig) error {
+	loaded, err := load(cfg.File)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	overrides = loaded
+	file = cfg.File
+	auditLog = cfg.AuditLog
+
+	if len(overrides) > 0 {
+		c.Warnf("%d rate overrides are loaded. They take precedence over every provider", len(overrides))
+	}
+	return nil
+}
+
+// load reads the overrides file. A missing file has no overrides.
+func load(path string) (map[string]Override, error) {
+	result := make(map[string]Override)
+	if path == "" {
+		return result, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return result, nil
+	}
+	if err != nil {
+		return nil, e.FromCode("eOvLd1", path).SetPrevious(err)
+	}
+
+	var stored []storedOverride
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, e.FromCode("eOvLd1", path).SetPrevious(err)
+	}
+	for _, s := range stored {
+		rate, err := decimal.Parse(s.Rate)
+		if err != nil {
+			return nil, e.FromCode("eOvLd1", path).SetPrevious(err)
+		}
+		result[pairKey(s.Base, s.Quote)] = Override{
+			Base:      s.Base,
+			Quote:     s.Quote,
+			Rate:      rate,
+			Reason:    s.Reason,
+			Author:    s.Author,
+			CreatedAt: s.CreatedAt,
+			ExpiresAt: s.ExpiresAt,
+		}
+	}
+	return result, nil
+}
+
+// save writes the overrides to the file, atomically. Does nothing if they are kept in memory only.
+func save(path string, list []Override) error {
+	if path == "" {
+		return nil
+	}
+
+	stored := make([]storedOverride, len(list))
+	for i, o := range list {
+		stored[i] = storedOverride{
+			Base:      o.Base,
+			Quote:     o.Quote,
+			Rate:      o.Rate.String(),
+			Reason:    o.Reason,
+			Author:    o.Author,
+			CreatedAt: o.CreatedAt,
+			ExpiresAt: o.ExpiresAt,
+		}
+	}
+	data, err := json.MarshalIndent(stored, "", "    ")
+	if err != nil {
+		return e.FromCode("eOvSv1", path).SetPrevious(err)
+	}
+
+	// Write to a temporary file first, so a crash never leaves a truncated file behind
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return e.FromCode("eOvSv1", path).SetPrevious(err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return e.FromCode("eOvSv1", path).SetPrevious(err)
+	}
+	if err := tmp.Close(); err != nil {
+		return e.FromCode("eOvSv1", path).SetPrevious(err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return e.FromCode("eOvSv1", path).SetPrevious(err)
+	}
+	return nil
+}
+
+// audit appends an entry to the audit log. Does nothing if the audit log is disabled.
+func audit(path string, entry AuditEntry) error {
+	if path == "" {
+		return nil
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return e.FromCode("eOvAu1", path).SetPrevious(err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return e.FromCode("eOvAu1", path).SetPrevious(err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return e.FromCode("eOvAu1", path).SetPrevious(err)
+	}
+	if err := f.Close(); err != nil {
+		return e.FromCode("eOvAu1", path).SetPrevious(err)
+	}
+	return nil
+}
+
+// sorted returns the overrides sorted by pair
+func sorted(m map[string]Override) []Override {
+	list := make([]Override, 0, len(m))
+	for _, o := range m {
+		list = append(list, o)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return pairKey(list[i].Base, list[i].Quote) < pairKey(list[j].Base, list[j].Quote)
+	})
+	return list
+}
+
+// apply saves the changed overrides and audits the change, then makes it visible.
+// If the audit log cannot be written, the previous overrides are saved back and the change is rejected.
+// Must be called with the write lock held.
+func apply(changed map[string]Override, entry AuditEntry) error {
+	if err := save(file, sorted(changed)); err != nil {
+		return err
+	}
+	if err := audit(auditLog, entry); err != nil {
+		_ = save(file, sorted(overrides))
+		return err
+	}
+	overrides = changed
+	return nil
+}
+
+// withoutExpired copies the overrides, without the ones which have expired. Must be called with the lock held.
+func withoutExpired(at time.Time) map[string]Override {
+	result := make(map[string]Override, len(overrides))
+	for key, o := range overrides {
+		if o.Active(at) {
+			result[key] = o
+		}
+	}
+	return result
+}
+
+// validate checks the reason and author of a change
+func validate(reason, author string) error {
+	if strings.TrimSpace(reason) == "" || strings.TrimSpace(author) == "" {
+		return e.FromCode("eOvRq1")
+	}
+	return nil
+}
+
+// Set creates or replaces the override for a pair. expiresAt may be nil for an override which never expires.
+func Set(from, to string, rate decimal.Decimal, reason, author string, expiresAt *time.Time) (*Override, error) {
+	if rate.Sign() <= 0 {
+		return nil, e.FromCode("eOvRt1", rate.String())
+	}
+	if err := validate(reason, author); err != nil {
+		return nil, err
+	}
+	at := now()
+	if expiresAt != nil && !expiresAt.After(at) {
+		return nil, e.FromCode("eOvEx1", expiresAt.Format(time.RFC3339))
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	override := Override{
+		Base:      from,
+		Quote:     to,
+		Rate:      rate,
+		Reason:    reason,
+		Author:    author,
+		CreatedAt: at,
+		ExpiresAt: expiresAt,
+	}
+	entry := AuditEntry{
+		Time:      at,
+		Action:    ActionSet,
+		Base:      from,
+		Quote:     to,
+		Rate:      rate.String(),
+		ExpiresAt: expiresAt,
+		Reason:    reason,
+		Author:    author,
+	}
+	if previous, ok := overrides[pairKey(from, to)]; ok && previous.Active(at) {
+		entry.PreviousRate = previous.Rate.String()
+	}
+
+	changed := withoutExpired(at)
+	changed[pairKey(from, to)] = override
+	if err := apply(changed, entry); err != nil {
+		return nil, err
+	}
+	return &override, nil
+}
+
+// Expire changes the expiry of the override for a pair. An expiry at or before now removes it straight away.
+func Expire(from, to string, expiresAt time.Time, reason, author string) (*Override, error) {
+	if err := validate(reason, author); err != nil {
+		return nil, err
+	}
+	at := now()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	override, ok := overrides[pairKey(from, to)]
+	if !ok || !override.Active(at) {
+		return nil, e.FromCode("eOvNf1", from, to)
+	}
+	override.ExpiresAt = &expiresAt
+
+	changed := withoutExpired(at)
+	if override.Active(at) {
+		changed[pairKey(from, to)] = override
+	} else {
+		delete(changed, pairKey(from, to))
+	}
+	entry := AuditEntry{
+		Time:      at,
+		Action:    ActionExpire,
+		Base:      from,
+		Quote:     to,
+		Rate:      override.Rate.String(),
+		ExpiresAt: &expiresAt,
+		Reason:    reason,
+		Author:    author,
+	}
+	if err := apply(changed, entry); err != nil {
+		return nil, err
+	}
+	return &override, nil
+}
+
+// Delete removes the override for a pair
+func Delete(from, to, reason, author string) error {
+	if err := validate(reason, author); err != nil {
+		return err
+	}
+	at := now()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	override, ok := overrides[pairKey(from, to)]
+	if !ok || !override.Active(at) {
+		return e.FromCode("eOvNf1", from, to)
+	}
+
+	changed := withoutExpired(at)
+	delete(changed, pairKey(from, to))
+	entry := AuditEntry{
+		Time:         at,
+		Action:       ActionDelete,
+		Base:         from,
+		Quote:        to,
+		PreviousRate: override.Rate.String(),
+		Reason:       reason,
+		Author:       author,
+	}
+	return apply(changed, entry)
+}
+
+// Get returns the active override for a pair, or nil if there is none
+func Get(from, to string) *Override {
+	mu.RLock()
+	defer mu.RUnlock()
+	override, ok := overrides[pairKey(from, to)]
+	if !ok || !override.Active(now()) {
+		return nil
+	}
+	return &override
+}
+
+// List returns the active overrides, sorted by pair
+func List() []Override {
+	mu.RLock()
+	defer mu.RUnlock()
+	return sorted(withoutExpired(now()))
+}
+
+// AuditTrail returns the last entries of the audit log (every entry if limit is not positive), oldest first
+func AuditTrail(limit int) ([]AuditEntry, error) {
+	mu.RLock()
+	path := auditLog
+	mu.RUnlock()
+	if path == "" {
+		return []AuditEntry{}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []AuditEntry{}, nil
+	}
+	if err != nil {
+		return nil, e.FromCode("eOvAu1", path).SetPrevious(err)
+	}
+
+	entries := []AuditEntry{}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		var entry AuditEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			return nil, e.FromCode("eOvAu1", path).SetPrevious(err)
+		}
+		entries = append(entries, entry)
+	}
+	if limit > 0 && len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+	return entries, nil
+}
+
+// IsRequestError returns true if the error was caused by an invalid change, rather than by a failure
+func IsRequestError(err error) bool {
+	switch e.FromError(err).GetCode() {
+	case "eOvRt1", "eOvRq1", "eOvEx1":
+		return true
+	default:
+		return false
+	}
+}
+
+// IsNotFound returns true if there is no active override for the pair
+func IsNotFound(err error) bool {
+	return e.FromError(err).GetCode() == "eOvNf1"
+}
@@ -0,0 +1,145 @@
+package overrides
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"fx-service/pkg/config"
+	"fx-service/pkg/decimal"
+	"fx-service/pkg/e"
+)
+
+// useTestFiles initializes the overrides with files in a temporary directory, and a fixed clock
+func useTestFiles(t *testing.T) (config.OverrideConfig, *time.Time) {
+	e.SetCatalogue(e.ErrorMap{
+		"eOvRt1": {Message: "Invalid override rate %s. The rate must be above zero"},
+		"eOvRq1": {Message: "A reason and an author are required to change a rate override"},
+		"eOvEx1": {Message: "Override expiry %s is in the past"},
+		"eOvNf1": {Message: "No active override for %s/%s"},
+		"eOvAu1": {Message: "Could not access the rate override audit log '%s'"},
+	})
+
+	dir := t.TempDir()
+	cfg := config.OverrideConfig{
+		File:     filepath.Join(dir, "overrides.json"),
+		AuditLog: filepath.Join(dir, "audit.jsonl"),
+	}
+	if err := Init(cfg); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	t.Cleanup(func() { _ = Init(config.OverrideConfig{}) })
+
+	clock := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return clock }
+	t.Cleanup(func() { now = func() time.Time { return time.Now().UTC() } })
+	return cfg, &clock
+}
+
+// TestOverrides checks setting, expiring and deleting overrides, and that every change is audited
+func TestOverrides(t *testing.T) {
+	_, clock := useTestFiles(t)
+
+	if _, err := Set("USD", "HKD", decimal.MustParse("7.8"), "", "treasury", nil); !IsRequestError(err) {
+		t.Errorf("expected a missing reason to be rejected, got %v", err)
+	}
+	if _, err := Set("USD", "HKD", decimal.Zero, "peg", "treasury", nil); !IsRequestError(err) {
+		t.Errorf("expected a zero rate to be rejected, got %v", err)
+	}
+
+	if _, err := Set("USD", "HKD", decimal.MustParse("7.8"), "peg", "treasury", nil); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if _, err := Set("USD", "HKD", decimal.MustParse("7.75"), "peg moved", "treasury", nil); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if o := Get("USD", "HKD"); o == nil || o.Rate.String() != "7.75" {
+		t.Errorf("expected the override 7.75, got %+v", o)
+	}
+	if o := Get("HKD", "USD"); o != nil {
+		t.Errorf("expected no override for the opposite pair, got %+v", o)
+	}
+
+	expiresAt := clock.Add(time.Hour)
+	if _, err := Expire("USD", "HKD", expiresAt, "fixing ends", "treasury"); err != nil {
+		t.Fatalf("Expire failed: %v", err)
+	}
+	*clock = clock.Add(2 * time.Hour)
+	if o := Get("USD", "HKD"); o != nil {
+		t.Errorf("expected the override to have expired, got %+v", o)
+	}
+	if err := Delete("USD", "HKD", "cleanup", "treasury"); !IsNotFound(err) {
+		t.Errorf("expected an expired override not to be found, got %v", err)
+	}
+
+	if _, err := Set("EUR", "USD", decimal.MustParse("1.1"), "bad feed", "ops", nil); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := Delete("EUR", "USD", "feed fixed", "ops"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if len(List()) != 0 {
+		t.Errorf("expected no active overrides, got %+v", List())
+	}
+
+	entries, err := AuditTrail(0)
+	if err != nil {
+		t.Fatalf("AuditTrail failed: %v", err)
+	}
+	actions := []string{ActionSet, ActionSet, ActionExpire, ActionSet, ActionDelete}
+	if len(entries) != len(actions) {
+		t.Fatalf("expected %d audit entries, got %+v", len(actions), entries)
+	}
+	for i, action := range actions {
+		if entries[i].Action != action {
+			t.Errorf("entry %d: expected action %s, got %s", i, action, entries[i].Action)
+		}
+	}
+	if entries[1].PreviousRate != "7.8" || entries[1].Rate != "7.75" || entries[1].Reason != "peg moved" {
+		t.Errorf("unexpected audit entry: %+v", entries[1])
+	}
+	if last, _ := AuditTrail(1); len(last) != 1 || last[0].Action != ActionDelete {
+		t.Errorf("expected the last audit entry only, got %+v", last)
+	}
+}
+
+// TestOverridesPersist checks that overrides survive a restart, with their exact rates
+func TestOverridesPersist(t *testing.T) {
+	cfg, clock := useTestFiles(t)
+
+	expiresAt := clock.Add(time.Hour)
+	if _, err := Set("USD", "CNY", decimal.MustParse("7.123456789012345678"), "fixing", "treasury", &expiresAt); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	if err := Init(cfg); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	o := Get("USD", "CNY")
+	if o == nil || o.Rate.String() != "7.123456789012345678" || o.Author != "treasury" ||
+		o.ExpiresAt == nil || !o.ExpiresAt.Equal(expiresAt) {
+		t.Errorf("unexpected override after a restart: %+v", o)
+	}
+}
+
+// TestOverridesAuditFailure checks that a change is rejected when it cannot be audited
+func TestOverridesAuditFailure(t *testing.T) {
+	cfg, _ := useTestFiles(t)
+
+	// A directory cannot be opened as the audit log
+	cfg.AuditLog = t.TempDir()
+	if err := Init(cfg); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+
+	if _, err := Set("USD", "HKD", decimal.MustParse("7.8"), "peg", "treasury", nil); err == nil {
+		t.Fatalf("expected the change to be rejected")
+	}
+	if o := Get("USD", "HKD"); o != nil {
+		t.Errorf("expected no override, got %+v", o)
+	}
+	if data, err := os.ReadFile(cfg.File); err != nil || string(data) != "[]" {
+		t.Errorf("expected the previous (empty) overrides to be saved back, got %q (%v)", data, err)
+	}
+}
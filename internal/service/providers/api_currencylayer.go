@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	c "fx-service/pkg/console"
+	"fx-service/pkg/currency"
+	"fx-service/pkg/decimal"
 	"fx-service/pkg/e"
 	util "fx-service/pkg/helpers"
 )
@@ -32,16 +35,25 @@ type CurrencyLayerError struct {
 	Info string `json:"info"`
 }
 
-type CurrencyLayerResponse struct {
+type CurrencyLayerTimeframeResponse struct {
 	Success bool                `json:"success"`
 	Error   *CurrencyLayerError `json:"error,omitempty"`
-	Quotes  RateList            `json:"quotes,omitempty"`
-	Symbols map[string]string   `json:"currencies,omitempty"` // FYI the documentation said "symbols".
+	Quotes  TimeSeries          `json:"quotes,omitempty"`
+}
+
+type CurrencyLayerResponse struct {
+	Success   bool                `json:"success"`
+	Timestamp int64               `json:"timestamp,omitempty"`
+	Error     *CurrencyLayerError `json:"error,omitempty"`
+	Quotes    RateList            `json:"quotes,omitempty"`
+	Symbols   map[string]string   `json:"currencies,omitempty"` // FYI the documentation said "symbols".
 }
 
 const currencyLayerBaseURL = "https://api.apilayer.com/currency_data"
 const currencyLayerLiveURL = "/live?source=%s&currencies=%s"
 const currencyLayerListURL = "/list"
+const currencyLayerHistoricalURL = "/historical?date=%s&source=%s&currencies=%s"
+const currencyLayerTimeframeURL = "/timeframe?start_date=%s&end_date=%s&source=%s&currencies=%s"
 
 // getHeaders private helper to Create http headers to be sent with the request (includes API key)
 func (api *CurrencyLayer) getHeaders() *map[string]string {
@@ -103,6 +115,8 @@ func (api *CurrencyLayer) updateSupportedCurrencies() error {
 		api.supportedCurrencies = append(api.supportedCurrencies, currency)
 	}
 
+	currency.MergeNames(response.Symbols)
+
 	c.Infof("Provider '%s' supports %v currencies", api.Name, len(response.Symbols))
 
 	return nil
@@ -128,7 +142,7 @@ func (api *CurrencyLayer) CheckApiKey() bool {
 	return true
 }
 
-func (api *CurrencyLayer) GetRate(from, to string) (float64, error) {
+func (api *CurrencyLayer) GetRate(from, to string) (decimal.Decimal, error) {
 	// set error fields for traceability
 	ef := e.Fields{"api": api.Name, "from": from, "to": to}
 
@@ -138,24 +152,24 @@ func (api *CurrencyLayer) GetRate(from, to string) (float64, error) {
 	// Make the request and validate the response
 	status, bodyData, err := makeGetRequest(url, api.Timeout, api.getHeaders())
 	if err != nil {
-		return 0, e.FromError(err).SetFields(ef.With("status", status))
+		return decimal.Zero, e.FromError(err).SetFields(ef.With("status", status))
 	}
 	if status != http.StatusOK {
 		msg := fmt.Sprintf("CurrencyLayer API got non-200 response code: %d", status)
-		return 0, e.Throw(errNon200, msg).SetFields(ef.With("status", status))
+		return decimal.Zero, e.Throw(errNon200, msg).SetFields(ef.With("status", status))
 	}
 
 	// Parse the response into our predefined structure
 	var response CurrencyLayerResponse
 	err = json.Unmarshal(bodyData, &response)
 	if err != nil {
-		return 0, err
+		return decimal.Zero, err
 	}
 
 	// Check if the response was successful
 	err = api.checkResponseError(response, ef)
 	if err != nil {
-		return 0, err
+		return decimal.Zero, err
 	}
 
 	// With CurrencyLayer, the response looks like
@@ -163,13 +177,19 @@ func (api *CurrencyLayer) GetRate(from, to string) (float64, error) {
 	rateKey := fmt.Sprintf("%s%s", from, to)
 	rate, ok := response.Quotes[rateKey]
 	if !ok {
-		return 0, e.Throwf("eClGr144", "rate not found").SetFields(ef)
+		return decimal.Zero, e.Throwf("eClGr144", "rate not found").SetFields(ef)
 	}
 
 	return rate, nil
 }
 
 func (api *CurrencyLayer) GetRates(from string, to []string) (RateList, error) {
+	rates, _, err := api.GetRatesAt(from, to)
+	return rates, err
+}
+
+// GetRatesAt gets the latest rates, and the time Currency Layer published them
+func (api *CurrencyLayer) GetRatesAt(from string, to []string) (RateList, time.Time, error) {
 	// set error fields for traceability
 	ef := e.Fields{"api": api.Name, "from": from, "to": to}
 
@@ -179,24 +199,24 @@ func (api *CurrencyLayer) GetRates(from string, to []string) (RateList, error) {
 	// Make the request and validate the response
 	status, bodyData, err := makeGetRequest(url, api.Timeout, api.getHeaders())
 	if err != nil {
-		return nil, e.FromError(err).SetFields(ef.With("status", status))
+		return nil, time.Time{}, e.FromError(err).SetFields(ef.With("status", status))
 	}
 	if status != http.StatusOK {
 		msg := fmt.Sprintf("CurrencyLayer API got non-200 response code: %d", status)
-		return nil, e.Throw(errNon200, msg).SetFields(ef.With("status", status))
+		return nil, time.Time{}, e.Throw(errNon200, msg).SetFields(ef.With("status", status))
 	}
 
 	// Parse the response into our predefined structure
 	var response CurrencyLayerResponse
 	err = json.Unmarshal(bodyData, &response)
 	if err != nil {
-		return nil, e.FromError(err).SetFields(ef)
+		return nil, time.Time{}, e.FromError(err).SetFields(ef)
 	}
 
 	// Ensure the response was successful
 	err = api.checkResponseError(response, ef)
 	if err != nil {
-		return nil, err
+		return nil, time.Time{}, err
 	}
 
 	// Extract the rates from the response
@@ -207,13 +227,104 @@ func (api *CurrencyLayer) GetRates(from string, to []string) (RateList, error) {
 			rates[currency] = rate
 		} else {
 			msg := fmt.Sprintf("Currency '%s' was not found in response from Currency Layer API", currency)
-			return nil, e.Throw("eClGr133", msg).SetFields(ef)
+			return nil, time.Time{}, e.Throw("eClGr133", msg).SetFields(ef)
+		}
+	}
+
+	return rates, publishedAt(response.Timestamp), nil
+}
+
+// GetRatesOn gets the rates for the given date, from the Currency Data API historical endpoint
+func (api *CurrencyLayer) GetRatesOn(date time.Time, from string, to []string) (RateList, error) {
+	// set error fields for traceability
+	day := date.UTC().Format(historicalDateFormat)
+	ef := e.Fields{"api": api.Name, "date": day, "from": from, "to": to}
+
+	// Format the URL for the get request
+	url := fmt.Sprintf(currencyLayerBaseURL+currencyLayerHistoricalURL, day, from, strings.Join(to, ","))
+
+	// Make the request and validate the response
+	status, bodyData, err := makeGetRequest(url, api.Timeout, api.getHeaders())
+	if err != nil {
+		return nil, e.FromError(err).SetFields(ef.With("status", status))
+	}
+	if status != http.StatusOK {
+		return nil, e.FromCode("eAGn2c", status).SetFields(ef.With("status", status))
+	}
+
+	// Parse the response into our predefined structure
+	var response CurrencyLayerResponse
+	err = json.Unmarshal(bodyData, &response)
+	if err != nil {
+		return nil, e.FromError(err).SetFields(ef)
+	}
+	err = api.checkResponseError(response, ef)
+	if err != nil {
+		return nil, err
+	}
+
+	// Quotes are keyed by the concatenated pair, e.g. "USDEUR"
+	rates := make(RateList)
+	for _, currency := range to {
+		rate, ok := response.Quotes[from+currency]
+		if !ok {
+			return nil, e.FromCode("ePrRnf").SetFields(ef.With("missingQuote", currency))
 		}
+		rates[currency] = rate
 	}
 
 	return rates, nil
 }
 
+// GetTimeSeries gets the daily rates between the given dates (inclusive), from the Currency Data API timeframe endpoint
+func (api *CurrencyLayer) GetTimeSeries(start, end time.Time, from string, to []string) (TimeSeries, error) {
+	// set error fields for traceability
+	startDay := start.UTC().Format(historicalDateFormat)
+	endDay := end.UTC().Format(historicalDateFormat)
+	ef := e.Fields{"api": api.Name, "start": startDay, "end": endDay, "from": from, "to": to}
+
+	// Format the URL for the get request
+	url := fmt.Sprintf(currencyLayerBaseURL+currencyLayerTimeframeURL, startDay, endDay, from, strings.Join(to, ","))
+
+	// Make the request and validate the response
+	status, bodyData, err := makeGetRequest(url, api.Timeout, api.getHeaders())
+	if err != nil {
+		return nil, e.FromError(err).SetFields(ef.With("status", status))
+	}
+	if status != http.StatusOK {
+		return nil, e.FromCode("eAGn2c", status).SetFields(ef.With("status", status))
+	}
+
+	// Parse the response into our predefined structure
+	var response CurrencyLayerTimeframeResponse
+	err = json.Unmarshal(bodyData, &response)
+	if err != nil {
+		return nil, e.FromError(err).SetFields(ef)
+	}
+	// The error shape is the same as for the other endpoints
+	err = api.checkResponseError(CurrencyLayerResponse{Success: response.Success, Error: response.Error}, ef)
+	if err != nil {
+		return nil, err
+	}
+	if len(response.Quotes) == 0 {
+		return nil, e.Throw(errNoResult, "response does not contain any quotes").SetFields(ef)
+	}
+
+	// Quotes are keyed by the concatenated pair, e.g. "USDEUR"
+	series := make(TimeSeries, len(response.Quotes))
+	for date, quotes := range response.Quotes {
+		rates := make(RateList, len(to))
+		for _, currency := range to {
+			if rate, ok := quotes[from+currency]; ok {
+				rates[currency] = rate
+			}
+		}
+		series[date] = rates
+	}
+
+	return series, nil
+}
+
 func (api *CurrencyLayer) Supports(currency string) bool {
 	return util.SliceContains(api.supportedCurrencies, currency)
 }
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	c "fx-service/pkg/console"
+	"fx-service/pkg/currency"
+	"fx-service/pkg/decimal"
 	"fx-service/pkg/e"
 	util "fx-service/pkg/helpers"
 	"net/http"
@@ -32,13 +34,13 @@ type ExchangeRateApi struct {
 }
 
 type exchangeRateAPIResponse struct {
-	Result          string     `json:"result"`                     // "success" field in the response
-	ErrorType       string     `json:"error-type"`                 // "error-type" field in the response
-	BaseCode        string     `json:"base_code"`                  // "source" field in the response
-	TargetCode      string     `json:"target_code"`                // "target" field in the response
-	ConversionRate  float64    `json:"conversion_rate"`            // "rate" field in the response
-	ConversionRates RateList   `json:"conversion_rates,omitempty"` // "rates" field in the response is a map of currency codes to rates
-	SupportedCodes  [][]string `json:"supported_codes,omitempty"`  // "supported_codes" field in the response (array of [code, name])
+	Result          string          `json:"result"`                     // "success" field in the response
+	ErrorType       string          `json:"error-type"`                 // "error-type" field in the response
+	BaseCode        string          `json:"base_code"`                  // "source" field in the response
+	TargetCode      string          `json:"target_code"`                // "target" field in the response
+	ConversionRate  decimal.Decimal `json:"conversion_rate"`            // "rate" field in the response
+	ConversionRates RateList        `json:"conversion_rates,omitempty"` // "rates" field in the response is a map of currency codes to rates
+	SupportedCodes  [][]string      `json:"supported_codes,omitempty"`  // "supported_codes" field in the response (array of [code, name])
 }
 
 func (api *ExchangeRateApi) getSupportedCurrencies() error {
@@ -74,6 +76,7 @@ func (api *ExchangeRateApi) getSupportedCurrencies() error {
 
 	// Extract the supported currencies from the response
 	api.supportedCurrencies = make([]string, 0, len(response.SupportedCodes))
+	names := make(map[string]string, len(response.SupportedCodes))
 	for _, code := range response.SupportedCodes {
 		if len(code) == 0 {
 			// Should never happen
@@ -81,7 +84,11 @@ func (api *ExchangeRateApi) getSupportedCurrencies() error {
 		}
 		supportedCode := code[0]
 		api.supportedCurrencies = append(api.supportedCurrencies, supportedCode)
+		if len(code) > 1 {
+			names[supportedCode] = code[1]
+		}
 	}
+	currency.MergeNames(names)
 
 	// Check length of supported currencies
 	if len(api.supportedCurrencies) == 0 {
@@ -114,7 +121,7 @@ func (api *ExchangeRateApi) GetName() string {
 	return api.Name
 }
 
-func (api *ExchangeRateApi) GetRate(from, to string) (float64, error) {
+func (api *ExchangeRateApi) GetRate(from, to string) (decimal.Decimal, error) {
 	// Error fields, for traceability
 	ef := e.Fields{"api": api.Name, "from": from, "to": to}
 
@@ -124,29 +131,29 @@ func (api *ExchangeRateApi) GetRate(from, to string) (float64, error) {
 	// Make the request
 	status, bodyData, err := makeGetRequest(url, api.Timeout, nil)
 	if err != nil {
-		return 0, err
+		return decimal.Zero, err
 	}
 	if status != http.StatusOK {
 		msg := fmt.Sprintf("ExchangeRate-API got non-200 response code: %d", status)
-		return 0, e.Throw(errNon200, msg).SetFields(ef.With("status", status))
+		return decimal.Zero, e.Throw(errNon200, msg).SetFields(ef.With("status", status))
 	}
 
 	// Parse the API response into a formal struct
 	var response exchangeRateAPIResponse
 	err = json.Unmarshal(bodyData, &response)
 	if err != nil {
-		return 0, err
+		return decimal.Zero, err
 	}
 
 	// Check if the response was successful
 	// "Result" usually comes with a "success" value
 	if response.Result != "success" {
-		return 0, e.Throw(response.ErrorType, "ExchangeRate-API response was not successful")
+		return decimal.Zero, e.Throw(response.ErrorType, "ExchangeRate-API response was not successful")
 	}
 
 	// Check conversion rate is set
-	if response.ConversionRate == 0 {
-		return 0, e.Throw("", "ExchangeRate-API Conversion rate is not set in response from API")
+	if response.ConversionRate.IsZero() {
+		return decimal.Zero, e.Throw("", "ExchangeRate-API Conversion rate is not set in response from API")
 	}
 
 	return response.ConversionRate, nil
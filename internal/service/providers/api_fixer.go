@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	c "fx-service/pkg/console"
+	"fx-service/pkg/currency"
+	"fx-service/pkg/decimal"
 	"fx-service/pkg/e"
 	util "fx-service/pkg/helpers"
 )
@@ -26,17 +29,26 @@ type FixerApiError struct {
 	Info string `json:"info"`
 }
 
+type FixerApiTimeSeriesResponse struct {
+	Success bool           `json:"success"`
+	Error   *FixerApiError `json:"error,omitempty"`
+	Rates   TimeSeries     `json:"rates,omitempty"`
+}
+
 type FixerApiResponse struct {
-	Success bool              `json:"success"`
-	Date    string            `json:"date,omitempty"`
-	Error   *FixerApiError    `json:"error,omitempty"`
-	Rates   RateList          `json:"rates,omitempty"`
-	Symbols map[string]string `json:"symbols,omitempty"` // FYI the documentation said "symbols".
+	Success   bool              `json:"success"`
+	Timestamp int64             `json:"timestamp,omitempty"`
+	Date      string            `json:"date,omitempty"`
+	Error     *FixerApiError    `json:"error,omitempty"`
+	Rates     RateList          `json:"rates,omitempty"`
+	Symbols   map[string]string `json:"symbols,omitempty"` // FYI the documentation said "symbols".
 }
 
 const fixerBaseUrl = "https://api.apilayer.com/fixer"
 const fixerSymbolsUrl = "/symbols"
 const fixerLatestUrl = "/latest?base=%s&symbols=%s"
+const fixerHistoricalUrl = "/%s?base=%s&symbols=%s"
+const fixerTimeSeriesUrl = "/timeseries?start_date=%s&end_date=%s&base=%s&symbols=%s"
 
 // getHeaders private helper to Create http headers to be sent with the request (includes API key)
 func (api *FixerApi) getHeaders() *map[string]string {
@@ -99,6 +111,8 @@ func (api *FixerApi) updateSupportedCurrencies() error {
 		api.supportedCurrencies = append(api.supportedCurrencies, currency)
 	}
 
+	currency.MergeNames(response.Symbols)
+
 	c.Infof("Provider '%s' supports %v currencies", api.Name, len(api.supportedCurrencies))
 
 	return nil
@@ -122,7 +136,7 @@ func (api *FixerApi) GetName() string {
 	return api.Name
 }
 
-func (api *FixerApi) GetRate(from, to string) (float64, error) {
+func (api *FixerApi) GetRate(from, to string) (decimal.Decimal, error) {
 	// Format the URL for the get request
 	url := fmt.Sprintf(fixerBaseUrl+fixerLatestUrl, from, to)
 
@@ -133,38 +147,44 @@ func (api *FixerApi) GetRate(from, to string) (float64, error) {
 	status, bodyData, err := makeGetRequest(url, api.Timeout, api.getHeaders())
 	if err != nil {
 		// Some unknown issue with making the request
-		return 0, e.FromError(err).SetFields(ef.With("status", status))
+		return decimal.Zero, e.FromError(err).SetFields(ef.With("status", status))
 	}
 	if status != http.StatusOK {
 		// response code is not 200
-		return 0, e.FromCode("eAGn2c", status).SetFields(ef.With("status", status))
+		return decimal.Zero, e.FromCode("eAGn2c", status).SetFields(ef.With("status", status))
 	}
 
 	// Parse the response into our predefined structure
 	var response FixerApiResponse
 	err = json.Unmarshal(bodyData, &response)
 	if err != nil {
-		return 0, e.FromError(err).SetFields(ef)
+		return decimal.Zero, e.FromError(err).SetFields(ef)
 	}
 
 	// Check if the response was successful
 	err = api.checkResponseError(response, ef)
 	if err != nil {
-		return 0, err
+		return decimal.Zero, err
 	}
 
 	rate, ok := response.Rates[to]
 	if !ok {
 		// Quote symbol not found in the response
-		return 0, e.FromCode("ePrRnf").SetFields(ef.With("rates", response.Rates))
+		return decimal.Zero, e.FromCode("ePrRnf").SetFields(ef.With("rates", response.Rates))
 	}
 
 	return rate, nil
 }
 
 func (api *FixerApi) GetRates(from string, to []string) (RateList, error) {
+	rates, _, err := api.GetRatesAt(from, to)
+	return rates, err
+}
+
+// GetRatesAt gets the latest rates, and the time Fixer published them
+func (api *FixerApi) GetRatesAt(from string, to []string) (RateList, time.Time, error) {
 	// Format the URL for the get request
-	url := fmt.Sprintf(currencyLayerBaseURL+currencyLayerLiveURL, from, strings.Join(to, ","))
+	url := fmt.Sprintf(fixerBaseUrl+fixerLatestUrl, from, strings.Join(to, ","))
 
 	// set error fields for traceability
 	ef := e.Fields{"api": api.Name, "from": from, "to": to, "url": url}
@@ -172,22 +192,22 @@ func (api *FixerApi) GetRates(from string, to []string) (RateList, error) {
 	// Make the request and validate the response
 	status, bodyData, err := makeGetRequest(url, api.Timeout, api.getHeaders())
 	if err != nil {
-		return nil, e.FromError(err).SetFields(ef.With("status", status))
+		return nil, time.Time{}, e.FromError(err).SetFields(ef.With("status", status))
 	}
 	if status != http.StatusOK {
-		return nil, e.FromCode("eAGn2c", status).SetFields(ef.With("status", status))
+		return nil, time.Time{}, e.FromCode("eAGn2c", status).SetFields(ef.With("status", status))
 	}
 
 	// Parse the response into our predefined structure
 	var response FixerApiResponse
 	err = json.Unmarshal(bodyData, &response)
 	if err != nil {
-		return nil, e.FromError(err).SetFields(ef)
+		return nil, time.Time{}, e.FromError(err).SetFields(ef)
 	}
 	// Ensure the response was successful
 	err = api.checkResponseError(response, ef)
 	if err != nil {
-		return nil, err // It's an e.Exception
+		return nil, time.Time{}, err // It's an e.Exception
 	}
 
 	// Extract the rates from the response
@@ -198,13 +218,92 @@ func (api *FixerApi) GetRates(from string, to []string) (RateList, error) {
 			rates[quoteCcy] = rate
 		} else {
 			msg := fmt.Sprintf("Currency '%s' not found in response from API", quoteCcy)
-			return nil, e.Throw("eFaG205", msg).SetFields(ef.With("missingQuote", quoteCcy))
+			return nil, time.Time{}, e.Throw("eFaG205", msg).SetFields(ef.With("missingQuote", quoteCcy))
 		}
 	}
 
+	return rates, publishedAt(response.Timestamp), nil
+}
+
+// GetRatesOn gets the rates for the given date, from Fixer's historical endpoint
+func (api *FixerApi) GetRatesOn(date time.Time, from string, to []string) (RateList, error) {
+	// Format the URL for the get request
+	day := date.UTC().Format(historicalDateFormat)
+	url := fmt.Sprintf(fixerBaseUrl+fixerHistoricalUrl, day, from, strings.Join(to, ","))
+
+	// set error fields for traceability
+	ef := e.Fields{"api": api.Name, "date": day, "from": from, "to": to}
+
+	// Make the request and validate the response
+	status, bodyData, err := makeGetRequest(url, api.Timeout, api.getHeaders())
+	if err != nil {
+		return nil, e.FromError(err).SetFields(ef.With("status", status))
+	}
+	if status != http.StatusOK {
+		return nil, e.FromCode("eAGn2c", status).SetFields(ef.With("status", status))
+	}
+
+	// Parse the response into our predefined structure
+	var response FixerApiResponse
+	err = json.Unmarshal(bodyData, &response)
+	if err != nil {
+		return nil, e.FromError(err).SetFields(ef)
+	}
+	err = api.checkResponseError(response, ef)
+	if err != nil {
+		return nil, err
+	}
+
+	// Extract the rates from the response
+	rates := make(RateList)
+	for _, quoteCcy := range to {
+		rate, ok := response.Rates[quoteCcy]
+		if !ok {
+			return nil, e.FromCode("ePrRnf").SetFields(ef.With("missingQuote", quoteCcy))
+		}
+		rates[quoteCcy] = rate
+	}
+
 	return rates, nil
 }
 
+// GetTimeSeries gets the daily rates between the given dates (inclusive), from Fixer's time-series endpoint
+func (api *FixerApi) GetTimeSeries(start, end time.Time, from string, to []string) (TimeSeries, error) {
+	// Format the URL for the get request
+	startDay := start.UTC().Format(historicalDateFormat)
+	endDay := end.UTC().Format(historicalDateFormat)
+	url := fmt.Sprintf(fixerBaseUrl+fixerTimeSeriesUrl, startDay, endDay, from, strings.Join(to, ","))
+
+	// set error fields for traceability
+	ef := e.Fields{"api": api.Name, "start": startDay, "end": endDay, "from": from, "to": to}
+
+	// Make the request and validate the response
+	status, bodyData, err := makeGetRequest(url, api.Timeout, api.getHeaders())
+	if err != nil {
+		return nil, e.FromError(err).SetFields(ef.With("status", status))
+	}
+	if status != http.StatusOK {
+		return nil, e.FromCode("eAGn2c", status).SetFields(ef.With("status", status))
+	}
+
+	// Parse the response into our predefined structure
+	var response FixerApiTimeSeriesResponse
+	err = json.Unmarshal(bodyData, &response)
+	if err != nil {
+		return nil, e.FromError(err).SetFields(ef)
+	}
+	// The error shape is the same as for the other endpoints
+	err = api.checkResponseError(FixerApiResponse{Success: response.Success, Error: response.Error}, ef)
+	if err != nil {
+		return nil, err
+	}
+	if len(response.Rates) == 0 {
+		return nil, e.Throw(errNoResult, "response does not contain any rates").SetFields(ef)
+	}
+
+	return filterTimeSeries(response.Rates, to), nil
+}
+
 func (api *FixerApi) Supports(currency string) bool {
 	return util.SliceContains(api.supportedCurrencies, currency)
 }
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	c "fx-service/pkg/console"
+	"fx-service/pkg/decimal"
 	"fx-service/pkg/e"
 	"net/http"
 	"strings"
@@ -129,7 +130,7 @@ func (api *FreeCurrencyApi) GetName() string {
 	return api.Name
 }
 
-func (api *FreeCurrencyApi) GetRate(from, to string) (float64, error) {
+func (api *FreeCurrencyApi) GetRate(from, to string) (decimal.Decimal, error) {
 	// set error fields for traceability
 	ef := e.Fields{"api": api.Name, "from": from, "to": to}
 
@@ -139,11 +140,11 @@ func (api *FreeCurrencyApi) GetRate(from, to string) (float64, error) {
 	// Make the request and validate the response
 	status, bodyData, err := makeGetRequest(url, api.Timeout, nil)
 	if err != nil {
-		return 0, e.FromError(err).SetFields(ef)
+		return decimal.Zero, e.FromError(err).SetFields(ef)
 	}
 	if status != http.StatusOK {
 		msg := fmt.Sprintf(api.Name+" got non-200 response code: %d", status)
-		return 0, e.Throw(errNon200, msg).SetFields(ef)
+		return decimal.Zero, e.Throw(errNon200, msg).SetFields(ef)
 	}
 
 	// Parse the response into our predefined structure
@@ -151,13 +152,13 @@ func (api *FreeCurrencyApi) GetRate(from, to string) (float64, error) {
 	err = json.Unmarshal(bodyData, &response)
 	if err != nil {
 		c.Warnf("Failed to parse response JSON from %s API", api.Name)
-		return 0, e.FromError(err).SetFields(ef)
+		return decimal.Zero, e.FromError(err).SetFields(ef)
 	}
 
 	result, exists := response.Data[to]
-	if !exists || result == 0 {
+	if !exists || result.IsZero() {
 		// We got a successful response, but didn't find the data
-		return 0, e.Throw(errNoResult, "unsupported API response format, does not contain 'to' currency").SetFields(ef)
+		return decimal.Zero, e.Throw(errNoResult, "unsupported API response format, does not contain 'to' currency").SetFields(ef)
 	}
 
 	return result, nil
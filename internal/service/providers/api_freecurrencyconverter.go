@@ -3,6 +3,7 @@ package providers
 import (
 	"encoding/json"
 	"fmt"
+	"fx-service/pkg/decimal"
 	util "fx-service/pkg/helpers"
 	"io"
 	"net/http"
@@ -39,12 +40,12 @@ func (api *FreeCurrencyConverterAPI) GetName() string {
 	return api.Name
 }
 
-func (api *FreeCurrencyConverterAPI) GetRate(from, to string) (float64, error) {
+func (api *FreeCurrencyConverterAPI) GetRate(from, to string) (decimal.Decimal, error) {
 	query := fmt.Sprintf("%s_%s", from, to)
 	url := fmt.Sprintf(freeCurrencyConverterAPIBaseURL, query, api.APIKey)
 	resp, err := http.Get(url)
 	if err != nil {
-		return 0, err
+		return decimal.Zero, err
 	}
 	defer func(Body io.ReadCloser) {
 		err := Body.Close()
@@ -53,16 +54,16 @@ func (api *FreeCurrencyConverterAPI) GetRate(from, to string) (float64, error) {
 		}
 	}(resp.Body)
 
-	var result map[string]interface{}
+	var result map[string]decimal.Decimal
 	err = json.NewDecoder(resp.Body).Decode(&result)
 	if err != nil {
-		return 0, err
+		return decimal.Zero, err
 	}
 
-	if rate, ok := result[query].(float64); ok {
+	if rate, ok := result[query]; ok {
 		return rate, nil
 	}
-	return 0, fmt.Errorf("unsupported API response format")
+	return decimal.Zero, fmt.Errorf("unsupported API response format")
 }
 
 func (api *FreeCurrencyConverterAPI) GetRates(from string, to []string) (RateList, error) {
@@ -79,7 +80,7 @@ func (api *FreeCurrencyConverterAPI) GetRates(from string, to []string) (RateLis
 		}
 	}(resp.Body)
 
-	var result map[string]interface{}
+	var result map[string]decimal.Decimal
 	err = json.NewDecoder(resp.Body).Decode(&result)
 	if err != nil {
 		return nil, err
@@ -88,7 +89,7 @@ func (api *FreeCurrencyConverterAPI) GetRates(from string, to []string) (RateLis
 	rates := RateList{}
 	for _, currency := range to {
 		key := fmt.Sprintf("%s_%s", from, currency)
-		if rate, ok := result[key].(float64); ok {
+		if rate, ok := result[key]; ok {
 			rates[currency] = rate
 		} else {
 			return nil, fmt.Errorf("unsupported API response format for currency: %s", currency)
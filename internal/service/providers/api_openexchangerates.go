@@ -5,10 +5,13 @@ import (
 	"fmt"
 
 	c "fx-service/pkg/console"
+	"fx-service/pkg/currency"
+	"fx-service/pkg/decimal"
 	"fx-service/pkg/e"
 	util "fx-service/pkg/helpers"
 	"net/http"
 	"strings"
+	"time"
 )
 
 /**
@@ -52,9 +55,19 @@ type OpenExchangeRatesResult struct {
 	Rates     RateList `json:"rates"`
 }
 
+// crossRatePlaces is the number of decimal places kept when dividing the USD rates to get a cross rate
+const crossRatePlaces = 12
+
 const openExchangeRatesBaseURL = "https://openexchangerates.org/api"
 const openExchangeRatesList = "/currencies.json?show_alternative=false&show_inactive=false"
 const openExchangeRatesLatest = "/latest.json?app_id=%s&symbols=%s&show_alternative=false"
+const openExchangeRatesHistorical = "/historical/%s.json?app_id=%s&symbols=%s&show_alternative=false"
+const openExchangeRatesTimeSeries = "/time-series.json?app_id=%s&start=%s&end=%s&symbols=%s&show_alternative=false"
+
+type OpenExchangeRatesTimeSeriesResult struct {
+	Base  string     `json:"base"`
+	Rates TimeSeries `json:"rates"`
+}
 
 func (api *OpenExchangeRates) updateSupportedCurrencies() *e.Exception {
 	ef := e.Fields{"api": api.Name}
@@ -84,6 +97,7 @@ func (api *OpenExchangeRates) updateSupportedCurrencies() *e.Exception {
 
 	// Parse the result into our preferred slice of string
 	api.supportedCurrencies = util.GetMapKeys(response)
+	currency.MergeNames(response)
 
 	c.Infof("Provider '%s' supports %v currencies", api.Name, len(api.supportedCurrencies))
 
@@ -130,7 +144,7 @@ func (api *OpenExchangeRates) GetName() string {
 	return api.Name
 }
 
-func (api *OpenExchangeRates) GetRate(from, to string) (float64, error) {
+func (api *OpenExchangeRates) GetRate(from, to string) (decimal.Decimal, error) {
 	// set error fields for traceability
 	ef := e.Fields{"api": api.Name, "from": from, "to": to}
 
@@ -144,28 +158,34 @@ func (api *OpenExchangeRates) GetRate(from, to string) (float64, error) {
 	// Make the request and validate the response
 	response, err := api.doRequest(url)
 	if err != nil {
-		return 0, e.FromError(err).SetFields(ef)
+		return decimal.Zero, e.FromError(err).SetFields(ef)
 	}
 
 	// Ensure the response was successful
 	// We should have the from and to rate in the response
 	// The base currency is assumed as USD for the free account
-	if response.Rates[from] == 0 {
-		return 0, e.Throw(errNoResult, "response does not contain the from rate").SetFields(ef)
+	if response.Rates[from].IsZero() {
+		return decimal.Zero, e.Throw(errNoResult, "response does not contain the from rate").SetFields(ef)
 	}
-	if response.Rates[to] == 0 {
-		return 0, e.Throw(errNoResult, "response does not contain the to rate").SetFields(ef)
+	if response.Rates[to].IsZero() {
+		return decimal.Zero, e.Throw(errNoResult, "response does not contain the to rate").SetFields(ef)
 	}
 
 	// Calculate the rate
 	// Both the "from" and "to" rates, in the result are in terms of USD
 	// Therefore to get the rate between the two currencies, we divide the "to" rate by the "from" rate
-	actualRate := response.Rates[to] / response.Rates[from]
+	actualRate := response.Rates[to].Div(response.Rates[from], crossRatePlaces)
 
 	return actualRate, nil
 }
 
 func (api *OpenExchangeRates) GetRates(from string, to []string) (RateList, error) {
+	rates, _, err := api.GetRatesAt(from, to)
+	return rates, err
+}
+
+// GetRatesAt gets the latest rates, and the time Open Exchange Rates published them
+func (api *OpenExchangeRates) GetRatesAt(from string, to []string) (RateList, time.Time, error) {
 	//set error fields for traceability
 	ef := e.Fields{"api": api.Name, "from": from, "to": to}
 
@@ -181,13 +201,13 @@ func (api *OpenExchangeRates) GetRates(from string, to []string) (RateList, erro
 	// Make the request and validate the response
 	response, err := api.doRequest(url)
 	if err != nil {
-		return nil, e.FromError(err).SetFields(ef)
+		return nil, time.Time{}, e.FromError(err).SetFields(ef)
 	}
 
 	// Ensure response.Rates contains all the requested currencies
 	for _, next := range quotesToFetch {
-		if response.Rates[next] == 0 {
-			return nil, e.Throw(errNoResult, "response does not contain rate for "+next).SetFields(ef)
+		if response.Rates[next].IsZero() {
+			return nil, time.Time{}, e.Throw(errNoResult, "response does not contain rate for "+next).SetFields(ef)
 		}
 	}
 
@@ -200,12 +220,103 @@ func (api *OpenExchangeRates) GetRates(from string, to []string) (RateList, erro
 			// The result will not want to have the "from" currency in it
 			continue
 		}
-		result[next] = response.Rates[next] / response.Rates[from]
+		result[next] = response.Rates[next].Div(response.Rates[from], crossRatePlaces)
+	}
+
+	return result, publishedAt(response.Timestamp), nil
+}
+
+// GetRatesOn gets the rates for the given date, from the historical endpoint
+// As with the latest rates, they come in terms of USD, so they are divided by the "from" rate
+func (api *OpenExchangeRates) GetRatesOn(date time.Time, from string, to []string) (RateList, error) {
+	//set error fields for traceability
+	day := date.UTC().Format(historicalDateFormat)
+	ef := e.Fields{"api": api.Name, "date": day, "from": from, "to": to}
+
+	quotesToFetch := append(append([]string{}, to...), from)
+	url := fmt.Sprintf(
+		openExchangeRatesBaseURL+openExchangeRatesHistorical,
+		day,
+		api.AppID,
+		strings.Join(quotesToFetch, ","),
+	)
+
+	// Make the request and validate the response
+	response, err := api.doRequest(url)
+	if err != nil {
+		return nil, e.FromError(err).SetFields(ef)
+	}
+	for _, next := range quotesToFetch {
+		if response.Rates[next].IsZero() {
+			return nil, e.Throw(errNoResult, "response does not contain rate for "+next).SetFields(ef)
+		}
+	}
+
+	result := make(RateList)
+	for _, next := range to {
+		result[next] = response.Rates[next].Div(response.Rates[from], crossRatePlaces)
 	}
 
 	return result, nil
 }
 
+// GetTimeSeries gets the daily rates between the given dates (inclusive), from the time-series endpoint
+// Note that the time-series endpoint is not available on every plan; the strategy moves on to another provider if it fails.
+// As with the latest rates, they come in terms of USD, so they are divided by the "from" rate
+func (api *OpenExchangeRates) GetTimeSeries(start, end time.Time, from string, to []string) (TimeSeries, error) {
+	//set error fields for traceability
+	startDay := start.UTC().Format(historicalDateFormat)
+	endDay := end.UTC().Format(historicalDateFormat)
+	ef := e.Fields{"api": api.Name, "start": startDay, "end": endDay, "from": from, "to": to}
+
+	quotesToFetch := append(append([]string{}, to...), from)
+	url := fmt.Sprintf(
+		openExchangeRatesBaseURL+openExchangeRatesTimeSeries,
+		api.AppID,
+		startDay,
+		endDay,
+		strings.Join(quotesToFetch, ","),
+	)
+
+	// Make the request and validate the response
+	status, bodyData, err := makeGetRequest(url, api.Timeout, nil)
+	if err != nil {
+		return nil, e.FromError(err).SetFields(ef.With("status", status))
+	}
+	if status != http.StatusOK {
+		msg := fmt.Sprintf("Got non-200 response code: %d", status)
+		return nil, e.Throw(errNon200, msg).SetFields(ef.With("status", status))
+	}
+
+	// Parse the response into our predefined structure
+	var response OpenExchangeRatesTimeSeriesResult
+	err = json.Unmarshal(bodyData, &response)
+	if err != nil {
+		return nil, e.Throw(errNotJson, "Could not unmarshal response body").SetFields(ef)
+	}
+	if len(response.Rates) == 0 {
+		return nil, e.Throw(errNoResult, "response does not contain any rates").SetFields(ef)
+	}
+
+	// Skip the days without a "from" rate, as we can't convert them
+	series := make(TimeSeries, len(response.Rates))
+	for date, usdRates := range response.Rates {
+		fromRate := usdRates[from]
+		if fromRate.IsZero() {
+			continue
+		}
+		rates := make(RateList, len(to))
+		for _, next := range to {
+			if rate, ok := usdRates[next]; ok {
+				rates[next] = rate.Div(fromRate, crossRatePlaces)
+			}
+		}
+		series[date] = rates
+	}
+
+	return series, nil
+}
+
 func (api *OpenExchangeRates) Supports(currency string) bool {
 	return util.SliceContains(api.supportedCurrencies, currency)
 }
@@ -3,8 +3,12 @@ package providers
 import (
 	"fx-service/pkg/config"
 	c "fx-service/pkg/console"
+	"fx-service/pkg/decimal"
+	"fx-service/pkg/e"
 	"os"
+	"sort"
 	"sync"
+	"time"
 )
 
 const (
@@ -15,16 +19,46 @@ const (
 	errNotJson   = "notJson"
 )
 
-type RateList map[string]float64
+type RateList map[string]decimal.Decimal
 
 type ProviderInterface interface {
 	CheckApiKey() bool
 	GetName() string
-	GetRate(from, to string) (float64, error)
+	GetRate(from, to string) (decimal.Decimal, error)
 	GetRates(from string, to []string) (RateList, error)
 	Supports(currency string) bool
 }
 
+// TimeSeries is a list of daily rates, keyed by date (YYYY-MM-DD)
+type TimeSeries map[string]RateList
+
+// HistoricalProvider is implemented by providers whose upstream API also serves rates for past dates.
+// This is an optional capability: strategies only dispatch historical requests to providers that implement it.
+type HistoricalProvider interface {
+	GetRatesOn(date time.Time, from string, to []string) (RateList, error)
+	GetTimeSeries(start, end time.Time, from string, to []string) (TimeSeries, error)
+}
+
+// TimestampedProvider is implemented by providers whose upstream API reports when it published the latest rates.
+// This is an optional capability: the time is kept with the rates in the history store, as their source time.
+type TimestampedProvider interface {
+	GetRatesAt(from string, to []string) (RateList, time.Time, error)
+}
+
+// publishedAt returns the time of an upstream unix timestamp, or the zero time when the response has none
+func publishedAt(timestamp int64) time.Time {
+	if timestamp <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(timestamp, 0).UTC()
+}
+
+// historicalDateFormat is the date format used by upstream historical endpoints
+const historicalDateFormat = "2006-01-02"
+
+// MaxTimeSeriesDays is the longest range (in days) that can be requested from GetTimeSeries in a single call
+const MaxTimeSeriesDays = 365
+
 // InstalledProviders is a map of provider names to their structs. These are the available providers
 // Do not confuse with EnabledProviders, which are the providers that are enabled and ready to use
 var InstalledProviders = map[string]ProviderInterface{
@@ -109,6 +143,16 @@ func NewOpenExchangeRates(apiKey string, timeout int) ProviderInterface {
 	}
 }
 
+// NewProvider constructs a provider by name (as in the config), with its API key.
+// Unlike the providers enabled from the config, the API key is not checked.
+func NewProvider(name, apiKey string, timeout int) (ProviderInterface, error) {
+	makeProvider, exists := providerConstructors[name]
+	if !exists {
+		return nil, e.FromCode("ePrUk1", name)
+	}
+	return makeProvider(apiKey, timeout), nil
+}
+
 // initProvider initializes a single provider
 func initProvider(name string, providerConfig config.ProviderConfig, timeout int, wg *sync.WaitGroup, mu *sync.Mutex) {
 	defer wg.Done()
@@ -145,6 +189,15 @@ func initProvider(name string, providerConfig config.ProviderConfig, timeout int
 
 // InitProviders initializes the API exchange rate providers in parallel. Performs various checks for each.
 func InitProviders(providers *map[string]config.ProviderConfig, timeout int) {
+	if err := LoadProviders(providers, timeout); err != nil {
+		c.Warnf("No providers enabled. Cannot continue\n")
+		os.Exit(1)
+	}
+}
+
+// LoadProviders initializes the API exchange rate providers in parallel, like InitProviders,
+// but returns an error instead of exiting when no provider could be enabled
+func LoadProviders(providers *map[string]config.ProviderConfig, timeout int) error {
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 
@@ -157,9 +210,35 @@ func InitProviders(providers *map[string]config.ProviderConfig, timeout int) {
 
 	// Check that we have at least one provider enabled
 	if len(EnabledProviders) == 0 {
-		c.Warnf("No providers enabled. Cannot continue\n")
-		os.Exit(1)
-	} else {
-		c.Outf("Enabled %v out of %v providers\n", len(EnabledProviders), len(*providers))
+		return e.FromCode("ePrNo1")
+	}
+	c.Outf("Enabled %v out of %v providers\n", len(EnabledProviders), len(*providers))
+	return nil
+}
+
+// filterTimeSeries keeps only the requested quotes, for each day of the time series
+func filterTimeSeries(series TimeSeries, to []string) TimeSeries {
+	filtered := make(TimeSeries, len(series))
+	for date, rates := range series {
+		dayRates := make(RateList, len(to))
+		for _, currency := range to {
+			if rate, ok := rates[currency]; ok {
+				dayRates[currency] = rate
+			}
+		}
+		filtered[date] = dayRates
+	}
+	return filtered
+}
+
+// SupportedBy returns the names of the enabled providers which support the currency, sorted by name
+func SupportedBy(currency string) []string {
+	names := make([]string, 0, len(EnabledProviders))
+	for name, provider := range EnabledProviders {
+		if provider.Supports(currency) {
+			names = append(names, name)
+		}
 	}
+	sort.Strings(names)
+	return names
 }
@@ -0,0 +1,160 @@
+package quotes
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"fx-service/pkg/config"
+	c "fx-service/pkg/console"
+	"fx-service/pkg/e"
+)
+
+// Supported store drivers
+const (
+	DriverMemory = "memory"
+	DriverSQLite = "sqlite"
+)
+
+const (
+	pruneInterval    = time.Minute      // How often old quotes are deleted
+	storeTimeout     = 5 * time.Second  // Timeout of a single store operation
+	defaultTTL       = 30 * time.Second // Used when the configured TTL is not positive
+	defaultRetention = 24 * time.Hour   // Used when the configured retention is not positive
+)
+
+// manager holds the store, and prunes old quotes in the background
+type manager struct {
+	store     Store
+	ttl       time.Duration // How long a quote can be accepted for
+	retention time.Duration // How long quotes are kept after they expire
+	stop      chan struct{}
+	wg        sync.WaitGroup
+}
+
+var (
+	instance   *manager
+	instanceMu sync.RWMutex
+)
+
+// NewStore opens a Store for the given driver name and data source
+func NewStore(driver, dsn string) (Store, error) {
+	switch strings.ToLower(driver) {
+	case DriverMemory:
+		return NewMemoryStore(), nil
+	case DriverSQLite:
+		return NewSQLiteStore(dsn)
+	default:
+		return nil, e.FromCode("eQtDr1", driver)
+	}
+}
+
+// Init opens the configured store and starts pruning old quotes. Does nothing if quotes are disabled.
+func Init(cfg config.QuoteConfig) error {
+	if !cfg.Enabled {
+		c.Info("Locked quotes are disabled")
+		return nil
+	}
+
+	driver := strings.ToLower(cfg.Driver)
+	if driver == "" {
+		driver = DriverMemory
+	}
+	store, err := NewStore(driver, cfg.DSN)
+	if err != nil {
+		return err
+	}
+
+	m := &manager{
+		store:     store,
+		ttl:       time.Duration(cfg.TTLSec) * time.Second,
+		retention: time.Duration(cfg.RetentionHours) * time.Hour,
+		stop:      make(chan struct{}),
+	}
+	if m.ttl <= 0 {
+		m.ttl = defaultTTL
+	}
+	if m.retention <= 0 {
+		m.retention = defaultRetention
+	}
+
+	m.wg.Add(1)
+	go m.pruneLoop()
+
+	instanceMu.Lock()
+	previous := instance
+	instance = m
+	instanceMu.Unlock()
+	if previous != nil {
+		_ = previous.close()
+	}
+
+	c.Successf("Locked quotes are valid for %s, with the '%s' store", m.ttl, driver)
+	return nil
+}
+
+// Enabled returns true if quotes can be created
+func Enabled() bool {
+	instanceMu.RLock()
+	defer instanceMu.RUnlock()
+	return instance != nil
+}
+
+// Close stops pruning and closes the store. Does nothing if quotes are disabled.
+func Close() error {
+	instanceMu.Lock()
+	defer instanceMu.Unlock()
+	if instance == nil {
+		return nil
+	}
+	err := instance.close()
+	instance = nil
+	return err
+}
+
+// getManager returns the configured manager, or an error if quotes are disabled
+func getManager() (*manager, error) {
+	instanceMu.RLock()
+	defer instanceMu.RUnlock()
+	if instance == nil {
+		return nil, e.FromCode("eQtDs1")
+	}
+	return instance, nil
+}
+
+func (m *manager) close() error {
+	close(m.stop)
+	m.wg.Wait()
+	return m.store.Close()
+}
+
+// pruneLoop deletes the quotes which expired longer ago than the retention, periodically
+func (m *manager) pruneLoop() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stop:
+			return
+		case now := <-ticker.C:
+			m.prune(now)
+		}
+	}
+}
+
+// prune deletes the quotes which expired longer ago than the retention, relative to the given time
+func (m *manager) prune(now time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	deleted, err := m.store.Prune(ctx, now.Add(-m.retention))
+	if err != nil {
+		e.FromError(err).Print(-1, 0)
+	} else if deleted > 0 {
+		c.Infof("Quote retention removed %d quotes", deleted)
+	}
+}
@@ -0,0 +1,64 @@
+package quotes
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memoryStore keeps quotes in memory. Quotes are lost when the service restarts.
+type memoryStore struct {
+	mu     sync.Mutex
+	quotes map[string]Quote
+}
+
+// NewMemoryStore creates an in-memory quote store
+func NewMemoryStore() Store {
+	return &memoryStore{quotes: make(map[string]Quote)}
+}
+
+func (m *memoryStore) Save(_ context.Context, quote Quote) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.quotes[quote.ID] = quote
+	return nil
+}
+
+func (m *memoryStore) Get(_ context.Context, id string) (*Quote, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	quote, ok := m.quotes[id]
+	if !ok {
+		return nil, nil
+	}
+	return &quote, nil
+}
+
+func (m *memoryStore) Accept(_ context.Context, id string, at time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	quote, ok := m.quotes[id]
+	if !ok || quote.Status(at) != StatusOpen {
+		return false, nil
+	}
+	quote.AcceptedAt = &at
+	m.quotes[id] = quote
+	return true, nil
+}
+
+func (m *memoryStore) Prune(_ context.Context, before time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var deleted int64
+	for id, quote := range m.quotes {
+		if quote.ExpiresAt.Before(before) {
+			delete(m.quotes, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (m *memoryStore) Close() error {
+	return nil
+}
@@ -0,0 +1,73 @@
+// Package quotes locks rates for a pair and amount, so that clients can honour exactly that rate later
+package quotes
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"fx-service/pkg/decimal"
+)
+
+// Quote statuses
+const (
+	StatusOpen     = "open"     // Can still be accepted
+	StatusAccepted = "accepted" // Accepted before it expired
+	StatusExpired  = "expired"  // Expired without being accepted
+)
+
+// Quote is a rate locked for a pair and amount, until it expires
+type Quote struct {
+	ID         string
+	Base       string
+	Quote      string
+	Amount     decimal.Decimal
+	Rate       decimal.Decimal // The mid rate
+	Bid        decimal.Decimal // Equal to the mid rate when spreads are disabled
+	Ask        decimal.Decimal // Equal to the mid rate when spreads are disabled
+	Result     decimal.Decimal // The amount converted at the bid, rounded to the minor units of the quote currency
+	Decimals   int             // The minor units of the quote currency
+	Rounding   string
+	APIKey     string // The client who requested the quote. Only that client can read or accept it
+	Provider   string
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+	AcceptedAt *time.Time
+}
+
+// Status returns the status of the quote at the given time
+func (q *Quote) Status(now time.Time) string {
+	switch {
+	case q.AcceptedAt != nil:
+		return StatusAccepted
+	case !now.Before(q.ExpiresAt):
+		return StatusExpired
+	default:
+		return StatusOpen
+	}
+}
+
+// Store saves quotes. Implementations must be safe for concurrent use.
+type Store interface {
+	// Save saves a new quote
+	Save(ctx context.Context, quote Quote) error
+	// Get returns the quote with the given ID, or nil if it does not exist
+	Get(ctx context.Context, id string) (*Quote, error)
+	// Accept marks the quote as accepted at the given time, only if it is still open at that time.
+	// Returns false if the quote does not exist, was already accepted, or has expired.
+	Accept(ctx context.Context, id string, at time.Time) (bool, error)
+	// Prune deletes the quotes which expired before the given time. Returns the number of deleted quotes.
+	Prune(ctx context.Context, before time.Time) (int64, error)
+	// Close releases the resources of the store
+	Close() error
+}
+
+// newID returns a random quote ID (32 hexadecimal characters)
+func newID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b[:]), nil
+}
@@ -0,0 +1,168 @@
+package quotes
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"fx-service/internal/service/ratecache"
+	"fx-service/internal/service/spreads"
+	"fx-service/pkg/config"
+	"fx-service/pkg/decimal"
+	"fx-service/pkg/e"
+)
+
+func testQuote(id string, expiresAt time.Time) Quote {
+	return Quote{
+		ID:        id,
+		Base:      "USD",
+		Quote:     "EUR",
+		Amount:    decimal.MustParse("1000"),
+		Rate:      decimal.MustParse("0.912345678901"),
+		Bid:       decimal.MustParse("0.91"),
+		Ask:       decimal.MustParse("0.915"),
+		Result:    decimal.MustParse("910.00"),
+		Decimals:  2,
+		Rounding:  "half-even",
+		APIKey:    "key-1",
+		Provider:  "test",
+		CreatedAt: expiresAt.Add(-30 * time.Second),
+		ExpiresAt: expiresAt,
+	}
+}
+
+// testStores returns every store implementation, closed at the end of the test
+func testStores(t *testing.T) map[string]Store {
+	sqlite, err := NewSQLiteStore(filepath.Join(t.TempDir(), "quotes.db"))
+	if err != nil {
+		t.Fatalf("could not open the sqlite store: %v", err)
+	}
+	stores := map[string]Store{DriverMemory: NewMemoryStore(), DriverSQLite: sqlite}
+	t.Cleanup(func() {
+		for _, store := range stores {
+			_ = store.Close()
+		}
+	})
+	return stores
+}
+
+// TestStores checks saving, reading, accepting and pruning quotes in every store
+func TestStores(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			open := testQuote("open", now.Add(30*time.Second))
+			expired := testQuote("expired", now.Add(-time.Second))
+			for _, quote := range []Quote{open, expired} {
+				if err := store.Save(ctx, quote); err != nil {
+					t.Fatalf("Save failed: %v", err)
+				}
+			}
+
+			got, err := store.Get(ctx, "open")
+			if err != nil || got == nil {
+				t.Fatalf("Get failed: %v", err)
+			}
+			if got.Rate.String() != "0.912345678901" || got.Result.String() != "910.00" ||
+				!got.ExpiresAt.Equal(open.ExpiresAt) || got.APIKey != "key-1" || got.Status(now) != StatusOpen {
+				t.Errorf("unexpected quote: %+v", got)
+			}
+			if missing, err := store.Get(ctx, "missing"); err != nil || missing != nil {
+				t.Errorf("expected no quote, got %+v (%v)", missing, err)
+			}
+
+			if ok, err := store.Accept(ctx, "expired", now); err != nil || ok {
+				t.Errorf("expected an expired quote not to be accepted, got %v (%v)", ok, err)
+			}
+			if ok, err := store.Accept(ctx, "open", now); err != nil || !ok {
+				t.Errorf("expected the quote to be accepted, got %v (%v)", ok, err)
+			}
+			if ok, err := store.Accept(ctx, "open", now); err != nil || ok {
+				t.Errorf("expected the quote to be accepted only once, got %v (%v)", ok, err)
+			}
+			if got, _ = store.Get(ctx, "open"); got == nil || got.AcceptedAt == nil || !got.AcceptedAt.Equal(now) {
+				t.Errorf("expected the quote to be accepted at %s, got %+v", now, got)
+			}
+
+			deleted, err := store.Prune(ctx, now)
+			if err != nil || deleted != 1 {
+				t.Errorf("expected 1 quote to be pruned, got %d (%v)", deleted, err)
+			}
+			if got, _ = store.Get(ctx, "expired"); got != nil {
+				t.Errorf("expected the expired quote to be pruned")
+			}
+		})
+	}
+}
+
+// TestCreateAndAccept checks that a quote locks the rate with the spread, and can only be accepted once, in time
+func TestCreateAndAccept(t *testing.T) {
+	e.SetCatalogue(e.ErrorMap{
+		"eQtNf1": {Message: "Quote '%s' not found"},
+		"eQtEx1": {Message: "Quote '%s' has expired"},
+		"eQtAc1": {Message: "Quote '%s' was already accepted"},
+	})
+
+	if err := Init(config.QuoteConfig{Enabled: true, Driver: DriverMemory, TTLSec: 30}); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	t.Cleanup(func() { _ = Close() })
+
+	if err := spreads.Init(config.SpreadConfig{
+		Enabled: true,
+		Rules:   []config.SpreadRule{{Pair: "*", Bps: decimal.NewFromInt(100)}},
+	}); err != nil {
+		t.Fatalf("spreads.Init failed: %v", err)
+	}
+	t.Cleanup(func() { _ = spreads.Init(config.SpreadConfig{}) })
+
+	cache := ratecache.GetInstance()
+	cache.SetExpiry(3600)
+	cache.Clear()
+	t.Cleanup(cache.Clear)
+	cache.Set("USD", "EUR", decimal.MustParse("0.9"))
+
+	clock := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return clock }
+	t.Cleanup(func() { now = func() time.Time { return time.Now().UTC() } })
+
+	client := spreads.Client{APIKey: "key-1"}
+	quote, err := Create("USD", "EUR", decimal.NewFromInt(1000), config.First, config.HalfEven, client)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if quote.Rate.String() != "0.9" || quote.Bid.String() != "0.891" || quote.Ask.String() != "0.909" ||
+		quote.Result.String() != "891.00" || !quote.ExpiresAt.Equal(clock.Add(30*time.Second)) {
+		t.Errorf("unexpected quote: %+v", quote)
+	}
+
+	// The locked rate does not change with the market
+	cache.Set("USD", "EUR", decimal.MustParse("0.8"))
+	if got, err := Get(quote.ID, client); err != nil || got.Result.String() != "891.00" {
+		t.Errorf("expected the locked result 891.00, got %+v (%v)", got, err)
+	}
+	if _, err := Get(quote.ID, spreads.Client{APIKey: "key-2"}); !IsNotFound(err) {
+		t.Errorf("expected other clients not to see the quote, got %v", err)
+	}
+
+	clock = clock.Add(10 * time.Second)
+	accepted, err := Accept(quote.ID, client)
+	if err != nil || accepted.AcceptedAt == nil || accepted.Status(clock) != StatusAccepted {
+		t.Fatalf("expected the quote to be accepted, got %+v (%v)", accepted, err)
+	}
+	if _, err := Accept(quote.ID, client); !IsAccepted(err) {
+		t.Errorf("expected the quote to be accepted only once, got %v", err)
+	}
+
+	late, err := Create("USD", "EUR", decimal.NewFromInt(10), config.First, config.HalfEven, client)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	clock = clock.Add(31 * time.Second)
+	if _, err := Accept(late.ID, client); !IsExpired(err) {
+		t.Errorf("expected the quote to have expired, got %v", err)
+	}
+}
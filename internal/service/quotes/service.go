@@ -0,0 +1,138 @@
+package quotes
+
+import (
+	"context"
+	"time"
+
+	"fx-service/internal/service/rates"
+	"fx-service/internal/service/spreads"
+	"fx-service/pkg/config"
+	"fx-service/pkg/decimal"
+	"fx-service/pkg/e"
+)
+
+// now returns the current time. Replaced in tests.
+var now = func() time.Time { return time.Now().UTC() }
+
+// Create locks the current rate (spread included) for the pair and amount, and saves it as a new quote
+func Create(from, to string, amount decimal.Decimal, mode config.Mode, rounding config.Rounding, client spreads.Client) (*Quote, error) {
+	m, err := getManager()
+	if err != nil {
+		return nil, err
+	}
+
+	converted, err := rates.Convert(from, to, amount, mode, rounding, client)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := newID()
+	if err != nil {
+		return nil, e.FromCode("eQtSv1").SetPrevious(err)
+	}
+
+	createdAt := now()
+	quote := Quote{
+		ID:        id,
+		Base:      converted.Base,
+		Quote:     converted.Quote,
+		Amount:    converted.Amount,
+		Rate:      converted.Rate,
+		Bid:       converted.Rate,
+		Ask:       converted.Rate,
+		Result:    converted.Result,
+		Decimals:  converted.Decimals,
+		Rounding:  rounding.String(),
+		APIKey:    client.APIKey,
+		CreatedAt: createdAt,
+		ExpiresAt: createdAt.Add(m.ttl),
+	}
+	if converted.Price != nil {
+		quote.Bid = converted.Price.Bid
+		quote.Ask = converted.Price.Ask
+	}
+	if converted.Provider != nil {
+		quote.Provider = *converted.Provider
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	if err := m.store.Save(ctx, quote); err != nil {
+		return nil, err
+	}
+	return &quote, nil
+}
+
+// Get returns the quote with the given ID. Quotes of other clients are reported as not found.
+func Get(id string, client spreads.Client) (*Quote, error) {
+	m, err := getManager()
+	if err != nil {
+		return nil, err
+	}
+	return m.get(id, client)
+}
+
+func (m *manager) get(id string, client spreads.Client) (*Quote, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	quote, err := m.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if quote == nil || quote.APIKey != client.APIKey {
+		return nil, e.FromCode("eQtNf1", id)
+	}
+	return quote, nil
+}
+
+// Accept accepts the quote with the given ID, so its rate is honoured. A quote can only be accepted once,
+// before it expires, by the client who requested it.
+func Accept(id string, client spreads.Client) (*Quote, error) {
+	m, err := getManager()
+	if err != nil {
+		return nil, err
+	}
+
+	quote, err := m.get(id, client)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	acceptedAt := now()
+	accepted, err := m.store.Accept(ctx, id, acceptedAt)
+	if err != nil {
+		return nil, err
+	}
+	if !accepted {
+		// Read it again: it may have been accepted by a concurrent request since
+		if latest, err := m.store.Get(ctx, id); err == nil && latest != nil {
+			quote = latest
+		}
+		if quote.Status(acceptedAt) == StatusAccepted {
+			return nil, e.FromCode("eQtAc1", id)
+		}
+		return nil, e.FromCode("eQtEx1", id)
+	}
+
+	quote.AcceptedAt = &acceptedAt
+	return quote, nil
+}
+
+// IsNotFound returns true if the quote does not exist, or belongs to another client
+func IsNotFound(err error) bool {
+	return e.FromError(err).GetCode() == "eQtNf1"
+}
+
+// IsExpired returns true if the quote expired before it could be accepted
+func IsExpired(err error) bool {
+	return e.FromError(err).GetCode() == "eQtEx1"
+}
+
+// IsAccepted returns true if the quote was already accepted
+func IsAccepted(err error) bool {
+	return e.FromError(err).GetCode() == "eQtAc1"
+}
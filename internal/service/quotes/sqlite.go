@@ -0,0 +1,152 @@
+package quotes
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"fx-service/pkg/decimal"
+	"fx-service/pkg/e"
+
+	_ "modernc.org/sqlite" // Pure Go SQLite driver, registered as "sqlite"
+)
+
+// sqliteStore keeps quotes in a SQLite database, so they survive restarts.
+// Decimals are stored as text, to keep the exact locked rates.
+type sqliteStore struct {
+	db *sql.DB
+}
+
+const quoteColumns = `id, base, quote, amount, rate, bid, ask, result, decimals, rounding, api_key, provider,
+	created_at, expires_at, accepted_at`
+
+// NewSQLiteStore opens (or creates) a SQLite database file for quotes
+// The dsn is a file path, e.g. "quotes.db", optionally with query parameters supported by the driver
+func NewSQLiteStore(dsn string) (Store, error) {
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, e.FromCode("eQtOp1").SetPrevious(err)
+	}
+
+	// SQLite only supports a single writer, so avoid "database is locked" errors from concurrent connections
+	db.SetMaxOpenConns(1)
+
+	store := &sqliteStore{db: db}
+	if err := store.migrate(context.Background()); err != nil {
+		_ = db.Close()
+		return nil, e.FromCode("eQtOp1").SetPrevious(err)
+	}
+	return store, nil
+}
+
+// migrate creates the quotes table and its index, if they don't exist yet
+func (s *sqliteStore) migrate(ctx context.Context) error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS fx_quotes (
+			id VARCHAR(64) PRIMARY KEY,
+			base VARCHAR(8) NOT NULL,
+			quote VARCHAR(8) NOT NULL,
+			amount TEXT NOT NULL,
+			rate TEXT NOT NULL,
+			bid TEXT NOT NULL,
+			ask TEXT NOT NULL,
+			result TEXT NOT NULL,
+			decimals INTEGER NOT NULL,
+			rounding VARCHAR(16) NOT NULL,
+			api_key VARCHAR(256) NOT NULL,
+			provider VARCHAR(128) NOT NULL,
+			created_at BIGINT NOT NULL,
+			expires_at BIGINT NOT NULL,
+			accepted_at BIGINT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS fx_quotes_expiry ON fx_quotes (expires_at)`,
+	}
+	for _, statement := range statements {
+		if _, err := s.db.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sqliteStore) Save(ctx context.Context, quote Quote) error {
+	var acceptedAt *int64
+	if quote.AcceptedAt != nil {
+		at := quote.AcceptedAt.UnixNano()
+		acceptedAt = &at
+	}
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO fx_quotes (`+quoteColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		quote.ID, quote.Base, quote.Quote, quote.Amount.String(), quote.Rate.String(), quote.Bid.String(),
+		quote.Ask.String(), quote.Result.String(), quote.Decimals, quote.Rounding, quote.APIKey, quote.Provider,
+		quote.CreatedAt.UnixNano(), quote.ExpiresAt.UnixNano(), acceptedAt,
+	)
+	if err != nil {
+		return e.FromCode("eQtSv1").SetPrevious(err)
+	}
+	return nil
+}
+
+func (s *sqliteStore) Get(ctx context.Context, id string) (*Quote, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+quoteColumns+` FROM fx_quotes WHERE id = ?`, id)
+
+	var quote Quote
+	var amount, rate, bid, ask, result string
+	var createdAt, expiresAt int64
+	var acceptedAt sql.NullInt64
+	err := row.Scan(&quote.ID, &quote.Base, &quote.Quote, &amount, &rate, &bid, &ask, &result, &quote.Decimals,
+		&quote.Rounding, &quote.APIKey, &quote.Provider, &createdAt, &expiresAt, &acceptedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, e.FromCode("eQtQr1").SetPrevious(err)
+	}
+
+	for _, field := range []struct {
+		text  string
+		value *decimal.Decimal
+	}{
+		{amount, &quote.Amount}, {rate, &quote.Rate}, {bid, &quote.Bid}, {ask, &quote.Ask}, {result, &quote.Result},
+	} {
+		if *field.value, err = decimal.Parse(field.text); err != nil {
+			return nil, e.FromCode("eQtQr1").SetPrevious(err)
+		}
+	}
+	quote.CreatedAt = time.Unix(0, createdAt).UTC()
+	quote.ExpiresAt = time.Unix(0, expiresAt).UTC()
+	if acceptedAt.Valid {
+		at := time.Unix(0, acceptedAt.Int64).UTC()
+		quote.AcceptedAt = &at
+	}
+	return &quote, nil
+}
+
+func (s *sqliteStore) Accept(ctx context.Context, id string, at time.Time) (bool, error) {
+	// A single conditional update, so a quote can only be accepted once, even with concurrent requests
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE fx_quotes SET accepted_at = ? WHERE id = ? AND accepted_at IS NULL AND expires_at > ?`,
+		at.UnixNano(), id, at.UnixNano(),
+	)
+	if err != nil {
+		return false, e.FromCode("eQtSv1").SetPrevious(err)
+	}
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return false, e.FromCode("eQtSv1").SetPrevious(err)
+	}
+	return updated == 1, nil
+}
+
+func (s *sqliteStore) Prune(ctx context.Context, before time.Time) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM fx_quotes WHERE expires_at < ?`, before.UnixNano())
+	if err != nil {
+		return 0, e.FromCode("eQtPr1").SetPrevious(err)
+	}
+	return res.RowsAffected()
+}
+
+func (s *sqliteStore) Close() error {
+	return s.db.Close()
+}
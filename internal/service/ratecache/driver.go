@@ -1,10 +1,13 @@
 package ratecache
 
+import "fx-service/pkg/decimal"
+
 // Driver interface for future implementations
 type Driver interface {
-	Set(from, to string, rate float64)
+	Set(from, to string, rate decimal.Decimal)
 	SetExpiry(seconds int)
-	Get(from, to string) *float64
+	SetTTLRules(rules map[string]int) error
+	Get(from, to string) *decimal.Decimal
 	Clear()
 }
 
@@ -0,0 +1,111 @@
+package ratecache
+
+import (
+	"sync"
+	"time"
+
+	"fx-service/pkg/decimal"
+)
+
+// Update is a rate saved in the cache, as received by subscribers
+type Update struct {
+	Base    string
+	Quote   string
+	Rate    decimal.Decimal
+	Time    time.Time
+	Skipped int // Older updates of the pair replaced by this one, because the subscriber did not keep up
+}
+
+// Subscriber receives the updates of a set of pairs.
+// Publishing never blocks: while the subscriber is busy, only the latest update of each pair is kept.
+type Subscriber struct {
+	rc      *RateCache
+	pairs   map[string]bool // Keyed by "{base}_{quote}"
+	mu      sync.Mutex
+	pending map[string]*Update
+	order   []string      // Keys of the pending updates, in the order they arrived
+	notify  chan struct{} // Signalled when updates are pending
+}
+
+// Subscribe registers a subscriber for the updates of the given pairs ("{base}_{quote}").
+// The subscriber must be closed when it is no longer used.
+func (rc *RateCache) Subscribe(from string, to []string) *Subscriber {
+	s := &Subscriber{
+		rc:      rc,
+		pairs:   make(map[string]bool, len(to)),
+		pending: make(map[string]*Update, len(to)),
+		notify:  make(chan struct{}, 1),
+	}
+	for _, quote := range to {
+		s.pairs[from+"_"+quote] = true
+	}
+
+	rc.subMu.Lock()
+	defer rc.subMu.Unlock()
+	if rc.subscribers == nil {
+		rc.subscribers = make(map[*Subscriber]struct{})
+	}
+	rc.subscribers[s] = struct{}{}
+	return s
+}
+
+// Subscribers returns the number of registered subscribers
+func (rc *RateCache) Subscribers() int {
+	rc.subMu.RLock()
+	defer rc.subMu.RUnlock()
+	return len(rc.subscribers)
+}
+
+// publish hands an update to the subscribers of the pair
+func (rc *RateCache) publish(key string, update Update) {
+	rc.subMu.RLock()
+	defer rc.subMu.RUnlock()
+	for s := range rc.subscribers {
+		if s.pairs[key] {
+			s.push(key, update)
+		}
+	}
+}
+
+// push queues an update, replacing the pending update of the same pair
+func (s *Subscriber) push(key string, update Update) {
+	s.mu.Lock()
+	if previous, ok := s.pending[key]; ok {
+		update.Skipped = previous.Skipped + 1
+		*previous = update
+	} else {
+		s.pending[key] = &update
+		s.order = append(s.order, key)
+	}
+	s.mu.Unlock()
+
+	select {
+	case s.notify <- struct{}{}:
+	default: // Already signalled
+	}
+}
+
+// Ready is signalled when updates are pending
+func (s *Subscriber) Ready() <-chan struct{} {
+	return s.notify
+}
+
+// Next returns the pending updates, in the order they arrived, and clears them
+func (s *Subscriber) Next() []Update {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	updates := make([]Update, len(s.order))
+	for i, key := range s.order {
+		updates[i] = *s.pending[key]
+		delete(s.pending, key)
+	}
+	s.order = s.order[:0]
+	return updates
+}
+
+// Close unregisters the subscriber
+func (s *Subscriber) Close() {
+	s.rc.subMu.Lock()
+	defer s.rc.subMu.Unlock()
+	delete(s.rc.subscribers, s)
+}
@@ -1,15 +1,25 @@
 package ratecache
 
 import (
+	"sort"
+	"strings"
 	"sync"
 	"time"
+
+	"fx-service/pkg/decimal"
 )
 
 type RateCache struct {
 	mu         sync.Mutex
-	rates      map[string]float64
-	expiry     time.Duration
+	rates      map[string]decimal.Decimal
+	expiry     time.Duration            // Default expiry, for pairs not matched by a TTL rule
+	ttlRules   map[string]time.Duration // Expiry rules by pair, base, quote or currency (see SetTTLRules)
 	timestamps map[string]time.Time
+	ttls       map[string]time.Duration // Expiry of each entry, evaluated when it was set
+	now        func() time.Time         // The clock used for the timestamps and the expiry (see SetClock)
+
+	subMu       sync.RWMutex
+	subscribers map[*Subscriber]struct{} // Notified of every Set (see Subscribe)
 }
 
 var instance *RateCache
@@ -18,14 +28,30 @@ var once sync.Once
 // GetInstance singleton pattern to get the RateCache instance
 func GetInstance() *RateCache {
 	once.Do(func() {
-		instance = &RateCache{
-			rates:      make(map[string]float64),
-			timestamps: make(map[string]time.Time),
-		}
+		instance = New()
 	})
 	return instance
 }
 
+// New returns an empty cache, independent of the shared instance
+func New() *RateCache {
+	return &RateCache{
+		rates:       make(map[string]decimal.Decimal),
+		ttlRules:    make(map[string]time.Duration),
+		timestamps:  make(map[string]time.Time),
+		ttls:        make(map[string]time.Duration),
+		now:         time.Now,
+		subscribers: make(map[*Subscriber]struct{}),
+	}
+}
+
+// SetClock replaces the clock used for the timestamps and the expiry, e.g. to replay rates or in tests
+func (rc *RateCache) SetClock(now func() time.Time) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.now = now
+}
+
 // SetExpiry globally set the expiry time for cache entries
 func (rc *RateCache) SetExpiry(seconds int) {
 	rc.mu.Lock()
@@ -33,17 +59,22 @@ func (rc *RateCache) SetExpiry(seconds int) {
 	rc.expiry = time.Duration(seconds) * time.Second
 }
 
-// Set saves a rate in the cache
-func (rc *RateCache) Set(from, to string, rate float64) {
+// Set saves a rate in the cache. The expiry for the entry is resolved from the TTL rules at this point.
+// The subscribers of the pair are notified.
+func (rc *RateCache) Set(from, to string, rate decimal.Decimal) {
 	rc.mu.Lock()
-	defer rc.mu.Unlock()
 	key := from + "_" + to
+	setAt := rc.now()
 	rc.rates[key] = rate
-	rc.timestamps[key] = time.Now()
+	rc.timestamps[key] = setAt
+	rc.ttls[key] = rc.ttlFor(from, to)
+	rc.mu.Unlock()
+
+	rc.publish(key, Update{Base: from, Quote: to, Rate: rate, Time: setAt})
 }
 
 // Get retrieves a rate from the cache. Returns nil if the rate is not found or expired
-func (rc *RateCache) Get(from, to string) *float64 {
+func (rc *RateCache) Get(from, to string) *decimal.Decimal {
 	rc.mu.Lock()
 	defer rc.mu.Unlock()
 	key := from + "_" + to
@@ -53,9 +84,8 @@ func (rc *RateCache) Get(from, to string) *float64 {
 		return nil
 	}
 
-	if time.Since(rc.timestamps[key]) > rc.expiry {
-		delete(rc.rates, key)
-		delete(rc.timestamps, key)
+	if rc.isExpired(key) {
+		rc.deleteKey(key)
 		return nil
 	}
 
@@ -63,7 +93,7 @@ func (rc *RateCache) Get(from, to string) *float64 {
 }
 
 // GetAll returns all rates in the cache
-func (rc *RateCache) GetAll() map[string]float64 {
+func (rc *RateCache) GetAll() map[string]decimal.Decimal {
 	rc.mu.Lock()
 	defer rc.mu.Unlock()
 	return rc.rates
@@ -73,6 +103,108 @@ func (rc *RateCache) GetAll() map[string]float64 {
 func (rc *RateCache) Clear() {
 	rc.mu.Lock()
 	defer rc.mu.Unlock()
-	rc.rates = make(map[string]float64)
+	rc.rates = make(map[string]decimal.Decimal)
 	rc.timestamps = make(map[string]time.Time)
+	rc.ttls = make(map[string]time.Duration)
+}
+
+// Entry is a snapshot of a single cache entry, with its age and expiry (in seconds)
+type Entry struct {
+	Base      string          `json:"base"`
+	Quote     string          `json:"quote"`
+	Rate      decimal.Decimal `json:"rate"`
+	SetAt     time.Time       `json:"setAt"`
+	Age       int             `json:"age"`
+	TTL       int             `json:"ttl"`
+	ExpiresIn int             `json:"expiresIn"`
+}
+
+// makeEntry builds an Entry snapshot for the given key. The caller must hold the lock.
+func (rc *RateCache) makeEntry(key string) Entry {
+	from, to, _ := strings.Cut(key, "_")
+	age := rc.now().Sub(rc.timestamps[key])
+	ttl := rc.ttls[key]
+	return Entry{
+		Base:      from,
+		Quote:     to,
+		Rate:      rc.rates[key],
+		SetAt:     rc.timestamps[key],
+		Age:       int(age.Seconds()),
+		TTL:       int(ttl.Seconds()),
+		ExpiresIn: int((ttl - age).Seconds()),
+	}
+}
+
+// isExpired checks if the entry for the given key has expired. The caller must hold the lock.
+func (rc *RateCache) isExpired(key string) bool {
+	return rc.now().Sub(rc.timestamps[key]) > rc.ttls[key]
+}
+
+// Entries returns a snapshot of all unexpired entries in the cache, sorted by pair
+func (rc *RateCache) Entries() []Entry {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	entries := make([]Entry, 0, len(rc.rates))
+	for key := range rc.rates {
+		if rc.isExpired(key) {
+			continue
+		}
+		entries = append(entries, rc.makeEntry(key))
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Base != entries[j].Base {
+			return entries[i].Base < entries[j].Base
+		}
+		return entries[i].Quote < entries[j].Quote
+	})
+	return entries
+}
+
+// GetEntry returns a snapshot of the cache entry for the given pair, or nil if it's not found or expired
+func (rc *RateCache) GetEntry(from, to string) *Entry {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	key := from + "_" + to
+
+	if _, exists := rc.rates[key]; !exists || rc.isExpired(key) {
+		return nil
+	}
+	entry := rc.makeEntry(key)
+	return &entry
+}
+
+// Delete removes a single pair from the cache. Returns true if the pair was cached.
+func (rc *RateCache) Delete(from, to string) bool {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	key := from + "_" + to
+
+	_, exists := rc.rates[key]
+	rc.deleteKey(key)
+	return exists
+}
+
+// DeleteBase removes every pair with the given base currency from the cache. Returns the number of pairs removed.
+func (rc *RateCache) DeleteBase(from string) int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	prefix := from + "_"
+
+	count := 0
+	for key := range rc.rates {
+		if strings.HasPrefix(key, prefix) {
+			rc.deleteKey(key)
+			count++
+		}
+	}
+	return count
+}
+
+// deleteKey removes an entry. The caller must hold the lock.
+func (rc *RateCache) deleteKey(key string) {
+	delete(rc.rates, key)
+	delete(rc.timestamps, key)
+	delete(rc.ttls, key)
 }
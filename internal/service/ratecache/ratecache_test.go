@@ -3,6 +3,8 @@ package ratecache
 import (
 	"testing"
 	"time"
+
+	"fx-service/pkg/decimal"
 )
 
 // TestGetInstance checks the singleton instance
@@ -27,9 +29,9 @@ func TestSetExpiry(t *testing.T) {
 func TestSetAndGetRate(t *testing.T) {
 	rc := GetInstance()
 	rc.Clear()
-	rc.Set("USD", "EUR", 0.85)
+	rc.Set("USD", "EUR", decimal.MustParse("0.85"))
 	rate := rc.Get("USD", "EUR")
-	if rate == nil || *rate != 0.85 {
+	if rate == nil || !rate.Equal(decimal.MustParse("0.85")) {
 		t.Errorf("Expected rate to be 0.85, got %v", rate)
 	}
 }
@@ -39,7 +41,7 @@ func TestGetExpiredRate(t *testing.T) {
 	rc := GetInstance()
 	rc.Clear()
 	rc.SetExpiry(1) // 1 second expiry
-	rc.Set("USD", "EUR", 0.85)
+	rc.Set("USD", "EUR", decimal.MustParse("0.85"))
 	time.Sleep(2 * time.Second)
 	rate := rc.Get("USD", "EUR")
 	if rate != nil {
@@ -61,10 +63,10 @@ func TestGetNonExistentRate(t *testing.T) {
 func TestGetAllRates(t *testing.T) {
 	rc := GetInstance()
 	rc.Clear()
-	rc.Set("USD", "EUR", 0.85)
-	rc.Set("USD", "GBP", 0.75)
+	rc.Set("USD", "EUR", decimal.MustParse("0.85"))
+	rc.Set("USD", "GBP", decimal.MustParse("0.75"))
 	rates := rc.GetAll()
-	if len(rates) != 2 || rates["USD_EUR"] != 0.85 || rates["USD_GBP"] != 0.75 {
+	if len(rates) != 2 || !rates["USD_EUR"].Equal(decimal.MustParse("0.85")) || !rates["USD_GBP"].Equal(decimal.MustParse("0.75")) {
 		t.Errorf("Expected rates to be {USD_EUR: 0.85, USD_GBP: 0.75}, got %v", rates)
 	}
 }
@@ -72,10 +74,204 @@ func TestGetAllRates(t *testing.T) {
 // TestClearRates checks clearing all rates
 func TestClearRates(t *testing.T) {
 	rc := GetInstance()
-	rc.Set("USD", "EUR", 0.85)
+	rc.Set("USD", "EUR", decimal.MustParse("0.85"))
 	rc.Clear()
 	rates := rc.GetAll()
 	if len(rates) != 0 {
 		t.Error("Expected all rates to be cleared")
 	}
 }
+
+// TestTTLRulePrecedence checks that the most specific TTL rule wins
+func TestTTLRulePrecedence(t *testing.T) {
+	rc := GetInstance()
+	rc.SetExpiry(3600)
+	err := rc.SetTTLRules(map[string]int{
+		"eur/usd": 60,
+		"HKD/*":   86400,
+		"*/AED":   43200,
+		"DKK":     7200,
+		"*":       1800,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error setting TTL rules: %v", err)
+	}
+	defer func() { _ = rc.SetTTLRules(nil) }()
+
+	tests := []struct {
+		from, to string
+		expected time.Duration
+	}{
+		{"EUR", "USD", 60 * time.Second},    // exact pair
+		{"HKD", "AED", 86400 * time.Second}, // base beats quote
+		{"USD", "AED", 43200 * time.Second}, // quote
+		{"DKK", "USD", 7200 * time.Second},  // currency on either side
+		{"USD", "DKK", 7200 * time.Second},  // currency on either side
+		{"USD", "EUR", 1800 * time.Second},  // wildcard
+		{"HKD", "DKK", 86400 * time.Second}, // base beats currency
+		{"GBP", "JPY", 1800 * time.Second},  // wildcard
+	}
+	for _, tt := range tests {
+		if got := rc.TTLFor(tt.from, tt.to); got != tt.expected {
+			t.Errorf("TTLFor(%s, %s): expected %v, got %v", tt.from, tt.to, tt.expected, got)
+		}
+	}
+}
+
+// TestTTLRuleDefault checks that pairs without a matching rule use the global expiry
+func TestTTLRuleDefault(t *testing.T) {
+	rc := GetInstance()
+	rc.SetExpiry(10)
+	if err := rc.SetTTLRules(map[string]int{"EUR/USD": 60}); err != nil {
+		t.Fatalf("Unexpected error setting TTL rules: %v", err)
+	}
+	defer func() { _ = rc.SetTTLRules(nil) }()
+
+	if got := rc.TTLFor("USD", "EUR"); got != 10*time.Second {
+		t.Errorf("Expected default expiry of %v, got %v", 10*time.Second, got)
+	}
+}
+
+// TestInvalidTTLRules checks that malformed rules are rejected
+func TestInvalidTTLRules(t *testing.T) {
+	rc := GetInstance()
+	for _, rules := range []map[string]int{
+		{"EUR/USD/GBP": 60},
+		{"/USD": 60},
+		{"": 60},
+		{"EUR/USD": -1},
+	} {
+		if err := rc.SetTTLRules(rules); err == nil {
+			t.Errorf("Expected an error for rules %v", rules)
+		}
+	}
+}
+
+// TestTTLRuleExpiry checks that a rule is applied when the rate is set
+func TestTTLRuleExpiry(t *testing.T) {
+	rc := GetInstance()
+	rc.Clear()
+	rc.SetExpiry(3600)
+	if err := rc.SetTTLRules(map[string]int{"USD/EUR": 1}); err != nil {
+		t.Fatalf("Unexpected error setting TTL rules: %v", err)
+	}
+	defer func() { _ = rc.SetTTLRules(nil) }()
+
+	rc.Set("USD", "EUR", decimal.MustParse("0.85"))
+	rc.Set("USD", "GBP", decimal.MustParse("0.75"))
+	time.Sleep(2 * time.Second)
+	if rate := rc.Get("USD", "EUR"); rate != nil {
+		t.Error("Expected USD/EUR to expire under its TTL rule")
+	}
+	if rate := rc.Get("USD", "GBP"); rate == nil {
+		t.Error("Expected USD/GBP to use the default expiry")
+	}
+}
+
+// TestEffectiveTTLs checks the effective TTL listing for a set of currencies
+func TestEffectiveTTLs(t *testing.T) {
+	rc := GetInstance()
+	rc.SetExpiry(3600)
+	if err := rc.SetTTLRules(map[string]int{"HKD": 86400}); err != nil {
+		t.Fatalf("Unexpected error setting TTL rules: %v", err)
+	}
+	defer func() { _ = rc.SetTTLRules(nil) }()
+
+	ttls := rc.EffectiveTTLs([]string{"USD", "EUR", "HKD"})
+	if len(ttls) != 6 {
+		t.Fatalf("Expected 6 pairs, got %d: %v", len(ttls), ttls)
+	}
+	if ttls["USD/HKD"] != 86400 || ttls["HKD/EUR"] != 86400 || ttls["USD/EUR"] != 3600 {
+		t.Errorf("Unexpected effective TTLs: %v", ttls)
+	}
+}
+
+// TestEntries checks listing and inspecting cache entries
+func TestEntries(t *testing.T) {
+	rc := GetInstance()
+	rc.Clear()
+	rc.SetExpiry(3600)
+	rc.Set("USD", "GBP", decimal.MustParse("0.75"))
+	rc.Set("USD", "EUR", decimal.MustParse("0.85"))
+
+	entries := rc.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].Quote != "EUR" || entries[1].Quote != "GBP" {
+		t.Errorf("Expected entries sorted by pair, got %v", entries)
+	}
+	if entries[0].TTL != 3600 || !entries[0].Rate.Equal(decimal.MustParse("0.85")) {
+		t.Errorf("Unexpected entry: %+v", entries[0])
+	}
+
+	if entry := rc.GetEntry("USD", "EUR"); entry == nil || entry.Base != "USD" {
+		t.Errorf("Expected USD/EUR entry, got %v", entry)
+	}
+	if entry := rc.GetEntry("USD", "JPY"); entry != nil {
+		t.Errorf("Expected nil entry for uncached pair, got %v", entry)
+	}
+}
+
+// TestDeleteAndDeleteBase checks invalidating a pair and a base currency
+func TestDeleteAndDeleteBase(t *testing.T) {
+	rc := GetInstance()
+	rc.Clear()
+	rc.SetExpiry(3600)
+	rc.Set("USD", "EUR", decimal.MustParse("0.85"))
+	rc.Set("USD", "GBP", decimal.MustParse("0.75"))
+	rc.Set("EUR", "USD", decimal.MustParse("1.18"))
+
+	if !rc.Delete("USD", "EUR") {
+		t.Error("Expected USD/EUR to be deleted")
+	}
+	if rc.Delete("USD", "EUR") {
+		t.Error("Expected second delete of USD/EUR to report nothing removed")
+	}
+	if removed := rc.DeleteBase("USD"); removed != 1 {
+		t.Errorf("Expected 1 pair removed for base USD, got %d", removed)
+	}
+	if rate := rc.Get("EUR", "USD"); rate == nil {
+		t.Error("Expected EUR/USD to remain in the cache")
+	}
+}
+
+// TestSubscribe checks subscribers only get their pairs, and that a busy subscriber gets the latest rate of each pair
+func TestSubscribe(t *testing.T) {
+	rc := GetInstance()
+	rc.Clear()
+	rc.SetExpiry(3600)
+	sub := rc.Subscribe("USD", []string{"EUR", "GBP"})
+	defer sub.Close()
+
+	rc.Set("USD", "EUR", decimal.MustParse("0.85"))
+	rc.Set("USD", "JPY", decimal.MustParse("150"))
+	rc.Set("USD", "GBP", decimal.MustParse("0.75"))
+	rc.Set("USD", "EUR", decimal.MustParse("0.86"))
+	rc.Set("USD", "EUR", decimal.MustParse("0.87"))
+
+	select {
+	case <-sub.Ready():
+	default:
+		t.Fatal("Expected the subscriber to be signalled")
+	}
+	updates := sub.Next()
+	if len(updates) != 2 {
+		t.Fatalf("Expected 2 updates, got %v", updates)
+	}
+	if updates[0].Quote != "EUR" || !updates[0].Rate.Equal(decimal.MustParse("0.87")) || updates[0].Skipped != 2 {
+		t.Errorf("Expected the latest USD/EUR rate 0.87 with 2 skipped, got %+v", updates[0])
+	}
+	if updates[1].Quote != "GBP" || updates[1].Skipped != 0 {
+		t.Errorf("Expected USD/GBP with none skipped, got %+v", updates[1])
+	}
+	if updates := sub.Next(); len(updates) != 0 {
+		t.Errorf("Expected no pending updates, got %v", updates)
+	}
+
+	sub.Close()
+	rc.Set("USD", "EUR", decimal.MustParse("0.88"))
+	if updates := sub.Next(); len(updates) != 0 {
+		t.Errorf("Expected no updates after closing, got %v", updates)
+	}
+}
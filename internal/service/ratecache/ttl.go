@@ -0,0 +1,95 @@
+package ratecache
+
+import (
+	"strings"
+	"time"
+
+	"fx-service/pkg/currency"
+	"fx-service/pkg/e"
+)
+
+// SetTTLRules sets the per-pair or per-currency expiry rules (in seconds) for cache entries.
+// Rule keys are pair patterns: a pair ("EUR/USD"), a base ("HKD/*"), a quote ("*/HKD"), a currency on either side
+// ("HKD"), or a wildcard ("*"). Pairs not matched by any rule use the global expiry set by SetExpiry.
+// Rules only apply to entries saved after they are set.
+func (rc *RateCache) SetTTLRules(rules map[string]int) error {
+	normalized := make(map[string]time.Duration, len(rules))
+	for rule, seconds := range rules {
+		key, ok := currency.NormalizePattern(rule)
+		if !ok {
+			return e.FromCode("eCcTr1", rule)
+		}
+		if seconds < 0 {
+			return e.FromCode("eCcTr2", rule, seconds)
+		}
+		normalized[key] = time.Duration(seconds) * time.Second
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.ttlRules = normalized
+	return nil
+}
+
+// GetTTLRules returns the configured expiry rules, in seconds
+func (rc *RateCache) GetTTLRules() map[string]int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rules := make(map[string]int, len(rc.ttlRules))
+	for rule, ttl := range rc.ttlRules {
+		rules[rule] = int(ttl.Seconds())
+	}
+	return rules
+}
+
+// GetExpiry returns the global (default) expiry time for cache entries
+func (rc *RateCache) GetExpiry() time.Duration {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return rc.expiry
+}
+
+// TTLFor returns the effective expiry time for the given currency pair
+func (rc *RateCache) TTLFor(from, to string) time.Duration {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return rc.ttlFor(from, to)
+}
+
+// EffectiveTTLs returns the effective expiry time (in seconds) for every pair of the given currencies.
+// Keys are in "BASE/QUOTE" form.
+func (rc *RateCache) EffectiveTTLs(currencies []string) map[string]int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	result := make(map[string]int)
+	for _, from := range currencies {
+		for _, to := range currencies {
+			if from == to {
+				continue
+			}
+			result[from+"/"+to] = int(rc.ttlFor(from, to).Seconds())
+		}
+	}
+	return result
+}
+
+// ttlFor resolves the expiry time for a pair. The caller must hold the lock.
+// If two equally specific rules match (e.g. "HKD" and "DKK" for HKD/DKK), the shorter expiry wins.
+func (rc *RateCache) ttlFor(from, to string) time.Duration {
+	from = strings.ToUpper(from)
+	to = strings.ToUpper(to)
+
+	best := currency.MatchNone
+	ttl := rc.expiry
+	for rule, ruleTTL := range rc.ttlRules {
+		specificity := currency.PatternSpecificity(rule, from, to)
+		if specificity == currency.MatchNone {
+			continue
+		}
+		if specificity < best || (specificity == best && ruleTTL < ttl) {
+			best = specificity
+			ttl = ruleTTL
+		}
+	}
+	return ttl
+}
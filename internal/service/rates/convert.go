@@ -0,0 +1,134 @@
+package rates
+
+import (
+	"fx-service/internal/service/spreads"
+	"fx-service/pkg/config"
+	"fx-service/pkg/currency"
+	"fx-service/pkg/decimal"
+	"fx-service/pkg/e"
+	util "fx-service/pkg/helpers"
+)
+
+// MaxConvertBatch is the maximum number of conversions in a single batch
+const MaxConvertBatch = 100
+
+type ConvertResult struct {
+	Base      string
+	Quote     string
+	Amount    decimal.Decimal
+	Rate      decimal.Decimal
+	Result    decimal.Decimal // The converted amount, rounded to the minor units of the quote currency
+	Decimals  int             // The minor units of the quote currency
+	Price     *spreads.Price  // Bid and ask prices, when spreads are enabled. The result is then converted at the bid
+	WasCached bool
+	Override  bool // The rate was forced by a manual override
+	Provider  *string
+}
+
+type ConvertRequest struct {
+	From   string           `json:"from"`
+	To     string           `json:"to"`
+	Amount *decimal.Decimal `json:"amount"` // A JSON number or string, required
+}
+
+// ConvertBatchItem is the result of a single conversion in a batch: either a result or an error
+type ConvertBatchItem struct {
+	Result *ConvertResult
+	Error  error
+}
+
+// newConvertResult converts the amount with the given mid rate, rounding it to the minor units of the quote currency.
+// When spreads are enabled and the result is priced, the amount is converted at the client's bid price.
+func newConvertResult(from, to string, amount, rate decimal.Decimal, rounding config.Rounding, client spreads.Client, priced bool) *ConvertResult {
+	result := &ConvertResult{
+		Base:     from,
+		Quote:    to,
+		Amount:   amount,
+		Rate:     rate,
+		Decimals: currency.MinorUnits(to),
+	}
+	if priced && spreads.Enabled() {
+		price := spreads.Quote(rate, from, to, client, &amount)
+		result.Price = &price
+		rate = price.Bid
+	}
+	result.Result = currency.Convert(amount, rate, to, rounding)
+	return result
+}
+
+// Convert converts an amount between two currencies, using the rate from GetRate
+func Convert(from, to string, amount decimal.Decimal, mode config.Mode, rounding config.Rounding, client spreads.Client) (*ConvertResult, error) {
+	return sharedService.Convert(from, to, amount, mode, rounding, client)
+}
+
+// Convert converts an amount between two currencies, using the rate from the service.
+// Only the shared service converts at the client's bid price, when spreads are enabled.
+func (s *Service) Convert(from, to string, amount decimal.Decimal, mode config.Mode, rounding config.Rounding, client spreads.Client) (*ConvertResult, error) {
+	rateResult, err := s.GetRate(from, to, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	result := newConvertResult(from, to, amount, rateResult.Rate, rounding, client, s.shared)
+	result.WasCached = rateResult.WasCached
+	result.Override = rateResult.Override
+	result.Provider = rateResult.Provider
+	return result, nil
+}
+
+// Convert converts an amount from the base currency to one of the quote currencies, with the rate already fetched
+func (r *GetRatesResult) Convert(to string, amount decimal.Decimal, rounding config.Rounding, client spreads.Client) (*ConvertResult, error) {
+	rate, err := r.Rate(to)
+	if err != nil {
+		return nil, err
+	}
+
+	result := newConvertResult(r.Base, to, amount, rate, rounding, client, true)
+	result.WasCached = r.WasCached
+	result.Override = util.SliceContains(r.Overrides, to)
+	result.Provider = r.Provider
+	return result, nil
+}
+
+// ConvertBatch converts several amounts, in the same order as requested.
+// Rates are fetched once per base currency, with GetRates; if that fails, every conversion from that base fails.
+func ConvertBatch(requests []ConvertRequest, mode config.Mode, rounding config.Rounding, client spreads.Client) []ConvertBatchItem {
+	// Group the quote currencies by base currency, without duplicates
+	var bases []string
+	quotesByBase := make(map[string][]string)
+	for _, req := range requests {
+		quotes, seen := quotesByBase[req.From]
+		if !seen {
+			bases = append(bases, req.From)
+		}
+		if !util.SliceContains(quotes, req.To) {
+			quotesByBase[req.From] = append(quotes, req.To)
+		}
+	}
+
+	// Get the rates for each base currency
+	ratesByBase := make(map[string]*GetRatesResult, len(bases))
+	errorsByBase := make(map[string]error)
+	for _, base := range bases {
+		ratesResult, err := GetRates(base, quotesByBase[base], mode)
+		if err != nil {
+			errorsByBase[base] = err
+			continue
+		}
+		ratesByBase[base] = ratesResult
+	}
+
+	items := make([]ConvertBatchItem, len(requests))
+	for i, req := range requests {
+		if err, failed := errorsByBase[req.From]; failed {
+			items[i].Error = err
+			continue
+		}
+		if req.Amount == nil {
+			items[i].Error = e.FromCode("eRqAm1")
+			continue
+		}
+		items[i].Result, items[i].Error = ratesByBase[req.From].Convert(req.To, *req.Amount, rounding, client)
+	}
+	return items
+}
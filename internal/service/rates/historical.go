@@ -0,0 +1,353 @@
+package rates
+
+import (
+	"context"
+	"time"
+
+	"fx-service/internal/service/history"
+	"fx-service/internal/service/providers"
+	"fx-service/pkg/config"
+	c "fx-service/pkg/console"
+	"fx-service/pkg/decimal"
+	"fx-service/pkg/e"
+	util "fx-service/pkg/helpers"
+)
+
+const (
+	maxHistoryBuckets   = 1000             // Maximum number of buckets in a single history query
+	maxBackfillDays     = 3650             // Maximum number of days to backfill in a single request
+	historyQueryTimeout = 10 * time.Second // Timeout for querying the local store
+	day                 = 24 * time.Hour
+)
+
+type GetHistoryResult struct {
+	Base     string
+	Quote    string
+	Start    time.Time
+	End      time.Time
+	Interval time.Duration
+	Buckets  []history.Bucket
+	Change   *float64 // Percentage change over the whole range, nil if there is no data
+}
+
+type BackfillResult struct {
+	Base    string
+	Quotes  []string
+	Start   time.Time
+	End     time.Time
+	Calls   int // Number of upstream time series calls
+	Saved   int // Number of records saved in the store
+	Skipped int // Number of rates skipped, as their day was already in the store
+}
+
+type GetRatesOnResult struct {
+	Base    string
+	Date    time.Time
+	Rates   providers.RateList
+	Sources map[string]string // Where each rate came from: "store", or the provider name
+}
+
+// getRatesOnFromProviders gets the rates for a past date from the providers with a historical endpoint,
+// using the given strategy mode
+func getRatesOnFromProviders(date time.Time, from string, toList []string, mode config.Mode) (providers.RateList, string, error) {
+	req := providerRequest{kind: requestOnDate, from: from, to: toList, start: date}
+	result, providerName, err := runAPIStrategy(req, mode)
+	if err != nil {
+		return nil, "", err
+	}
+	rateList, ok := result.(providers.RateList)
+	if !ok {
+		// Sanity check - this should never happen
+		return nil, "", e.Throw("eRhOd1", "runApiStrategy returned an invalid type. Expected 'RateList'; got: "+util.GetType(result))
+	}
+	return rateList, *providerName, nil
+}
+
+// getTimeSeriesFromProviders gets the daily rates between two dates from the providers with a historical endpoint,
+// using the given strategy mode. The range must not be longer than providers.MaxTimeSeriesDays.
+func getTimeSeriesFromProviders(start, end time.Time, from string, toList []string, mode config.Mode) (providers.TimeSeries, string, error) {
+	req := providerRequest{kind: requestTimeSeries, from: from, to: toList, start: start, end: end}
+	result, providerName, err := runAPIStrategy(req, mode)
+	if err != nil {
+		return nil, "", err
+	}
+	series, ok := result.(providers.TimeSeries)
+	if !ok {
+		// Sanity check - this should never happen
+		return nil, "", e.Throw("eRhTs1", "runApiStrategy returned an invalid type. Expected 'TimeSeries'; got: "+util.GetType(result))
+	}
+	return series, *providerName, nil
+}
+
+// GetHistory summarises the rates for a pair into OHLC buckets, from the local historic rate store.
+// For daily (or longer) intervals, buckets with no stored data are filled from providers with historical endpoints,
+// using the rate on the last day of the bucket. The filled rates are also saved in the store.
+func GetHistory(from, to string, start, end time.Time, interval time.Duration, mode config.Mode) (*GetHistoryResult, error) {
+	if !start.Before(end) {
+		return nil, e.FromCode("eRhRg1")
+	}
+	if count := history.CountBuckets(start, end, interval); count > maxHistoryBuckets {
+		return nil, e.FromCode("eRhTm1", count, maxHistoryBuckets)
+	}
+
+	var records []history.Record
+	if store := history.GetStore(); store != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), historyQueryTimeout)
+		defer cancel()
+
+		var err error
+		records, err = store.Series(ctx, from, to, start, end)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	buckets := history.BuildBuckets(records, start, end, interval)
+
+	// Upstream historical endpoints only have daily rates, so only fill gaps for intervals of a day or more
+	if interval >= day {
+		fillHistoryGaps(buckets, from, to, mode)
+	}
+
+	return &GetHistoryResult{
+		Base:     from,
+		Quote:    to,
+		Start:    start,
+		End:      end,
+		Interval: interval,
+		Buckets:  buckets,
+		Change:   history.PercentChange(buckets),
+	}, nil
+}
+
+// GetRatesOn gets the last observed rates on the given (UTC) date, from the local historic rate store.
+// Quotes not found in the store are fetched from a provider with a historical endpoint, and quotes the provider
+// does not have either are left out of the result.
+func GetRatesOn(date time.Time, from string, toList []string, mode config.Mode) (*GetRatesOnResult, error) {
+	date = date.UTC().Truncate(day)
+	result := GetRatesOnResult{
+		Base:    from,
+		Date:    date,
+		Rates:   make(providers.RateList),
+		Sources: make(map[string]string),
+	}
+
+	if store := history.GetStore(); store != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), historyQueryTimeout)
+		defer cancel()
+
+		stored, err := store.Last(ctx, from, toList, date, date.Add(day))
+		if err != nil {
+			return nil, err
+		}
+		for quote, record := range stored {
+			result.Rates[quote] = record.Rate
+			result.Sources[quote] = history.SourceStore
+		}
+	}
+
+	var missing []string
+	for _, quote := range toList {
+		if _, ok := result.Rates[quote]; !ok {
+			missing = append(missing, quote)
+		}
+	}
+	if len(missing) == 0 {
+		return &result, nil
+	}
+
+	rateList, providerName, err := getRatesOnFromProviders(date, from, missing, mode)
+	if err != nil {
+		return nil, err
+	}
+	// Quotes the provider left out stay absent, rather than reported as a zero rate
+	for _, quote := range missing {
+		if rate, ok := rateList[quote]; ok {
+			result.Rates[quote] = rate
+			result.Sources[quote] = providerName
+		}
+	}
+
+	return &result, nil
+}
+
+// fillHistoryGaps fills the buckets without data from a single upstream time series, covering the empty buckets.
+// Each empty bucket gets the rate of its last day found in the series.
+func fillHistoryGaps(buckets []history.Bucket, from, to string, mode config.Mode) {
+	// Find the range covered by the empty buckets, up to yesterday (today's rate is not final)
+	first, last := -1, -1
+	for i := range buckets {
+		if buckets[i].Count == 0 {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	if first < 0 {
+		return
+	}
+	start := buckets[first].Start
+	end := buckets[last].End.Add(-day)
+	if yesterday := time.Now().UTC().Truncate(day).Add(-day); end.After(yesterday) {
+		end = yesterday
+	}
+	// Keep the most recent part of the range, if it is too long for a single call
+	if earliest := end.Add(-(providers.MaxTimeSeriesDays - 1) * day); start.Before(earliest) {
+		start = earliest
+	}
+	if start.After(end) {
+		return
+	}
+
+	series, providerName, err := getTimeSeriesFromProviders(start, end, from, []string{to}, mode)
+	if err != nil {
+		e.FromError(err).SetFields(e.Fields{"from": from, "to": to, "start": start, "end": end}).Print(-1, 0)
+		return
+	}
+
+	var filled []history.Record
+	for i := range buckets {
+		if buckets[i].Count > 0 {
+			continue
+		}
+		// Walk back from the last day of the bucket, to the first day with a rate
+		for date := buckets[i].End.Add(-day); !date.Before(buckets[i].Start); date = date.Add(-day) {
+			rate, ok := series[date.UTC().Format(time.DateOnly)][to]
+			if !ok {
+				continue
+			}
+			buckets[i].Observe(rate)
+			buckets[i].Source = providerName
+			filled = append(filled, backfillRecord(date, from, to, rate, providerName))
+			break
+		}
+	}
+
+	// The buckets were empty, so these days were not in the store yet
+	history.Add(filled...)
+}
+
+// backfillRecord builds a history record for a daily rate fetched from an upstream historical endpoint.
+// The record is timestamped at the end of the day the rate is for.
+func backfillRecord(date time.Time, from, to string, rate decimal.Decimal, providerName string) history.Record {
+	sourceTime := date
+	return history.Record{
+		Base:       from,
+		Quote:      to,
+		Rate:       rate,
+		Provider:   providerName,
+		SourceTime: &sourceTime,
+		FetchedAt:  date.Add(day - time.Second),
+	}
+}
+
+// storedDays returns the (UTC) days which already have a rate in the store, keyed by "QUOTE YYYY-MM-DD"
+func storedDays(store history.Store, from string, toList []string, start, end time.Time) (map[string]bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), historyQueryTimeout)
+	defer cancel()
+
+	days := make(map[string]bool)
+	for _, to := range toList {
+		records, err := store.Series(ctx, from, to, start, end)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			days[to+" "+record.FetchedAt.UTC().Format(time.DateOnly)] = true
+		}
+	}
+	return days, nil
+}
+
+// Backfill fetches the daily rates between two dates (inclusive) from providers with a historical endpoint,
+// and saves them in the historic rate store. Days which already have a rate in the store are skipped.
+// Long ranges are fetched in several calls. Returns a summary of what was saved.
+func Backfill(from string, toList []string, start, end time.Time, mode config.Mode) (*BackfillResult, error) {
+	store := history.GetStore()
+	if store == nil {
+		return nil, e.FromCode("eRbNs1")
+	}
+
+	start = start.UTC().Truncate(day)
+	end = end.UTC().Truncate(day)
+	if yesterday := time.Now().UTC().Truncate(day).Add(-day); end.After(yesterday) {
+		end = yesterday
+	}
+	if start.After(end) {
+		return nil, e.FromCode("eRhRg1")
+	}
+	if days := int(end.Sub(start)/day) + 1; days > maxBackfillDays {
+		return nil, e.FromCode("eRbTl1", days, maxBackfillDays)
+	}
+
+	// Find the days we already have, for each quote
+	stored, err := storedDays(store, from, toList, start, end.Add(day))
+	if err != nil {
+		return nil, err
+	}
+
+	result := BackfillResult{Base: from, Quotes: toList, Start: start, End: end}
+	for chunkStart := start; !chunkStart.After(end); chunkStart = chunkStart.Add(providers.MaxTimeSeriesDays * day) {
+		chunkEnd := chunkStart.Add((providers.MaxTimeSeriesDays - 1) * day)
+		if chunkEnd.After(end) {
+			chunkEnd = end
+		}
+
+		series, providerName, err := getTimeSeriesFromProviders(chunkStart, chunkEnd, from, toList, mode)
+		if err != nil {
+			return nil, e.FromError(err).SetFields(e.Fields{"start": chunkStart, "end": chunkEnd})
+		}
+
+		var records []history.Record
+		for dateKey, rates := range series {
+			date, err := time.Parse(time.DateOnly, dateKey)
+			if err != nil || date.Before(chunkStart) || date.After(chunkEnd) {
+				continue
+			}
+			for to, rate := range rates {
+				if stored[to+" "+dateKey] {
+					result.Skipped++
+					continue
+				}
+				records = append(records, backfillRecord(date, from, to, rate, providerName))
+			}
+		}
+
+		if len(records) > 0 {
+			ctx, cancel := context.WithTimeout(context.Background(), historyQueryTimeout)
+			err = store.Append(ctx, records)
+			cancel()
+			if err != nil {
+				return nil, err
+			}
+		}
+		result.Saved += len(records)
+		result.Calls++
+		c.Infof("Backfilled %d rates for %s from %s to %s, using '%s'", len(records), from,
+			chunkStart.Format(time.DateOnly), chunkEnd.Format(time.DateOnly), providerName)
+	}
+
+	return &result, nil
+}
+
+// sourceProvider replaces provider names in results, when provider names should not be shown
+const sourceProvider = "provider"
+
+// HideProviderNames replaces the provider names used to fill gaps with a generic "provider" source
+func (r *GetHistoryResult) HideProviderNames() {
+	for i := range r.Buckets {
+		if r.Buckets[i].Source != "" && r.Buckets[i].Source != history.SourceStore {
+			r.Buckets[i].Source = sourceProvider
+		}
+	}
+}
+
+// HideProviderNames replaces provider names in the sources with a generic "provider" source
+func (r *GetRatesOnResult) HideProviderNames() {
+	for quote, source := range r.Sources {
+		if source != history.SourceStore {
+			r.Sources[quote] = sourceProvider
+		}
+	}
+}
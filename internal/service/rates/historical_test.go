@@ -0,0 +1,43 @@
+package rates
+
+import (
+	"testing"
+	"time"
+
+	"fx-service/internal/service/providers"
+	"fx-service/pkg/config"
+	"fx-service/pkg/decimal"
+)
+
+// partialHistoricalProvider is a fake historical provider which leaves a currency out of its rates
+type partialHistoricalProvider struct {
+	partialProvider
+}
+
+func (p *partialHistoricalProvider) GetRatesOn(date time.Time, from string, to []string) (providers.RateList, error) {
+	return p.GetRates(from, to)
+}
+
+func (p *partialHistoricalProvider) GetTimeSeries(start, end time.Time, from string, to []string) (providers.TimeSeries, error) {
+	return providers.TimeSeries{}, nil
+}
+
+// TestGetRatesOnMissingQuote checks that a quote the provider left out is absent from the result, rather than zero
+func TestGetRatesOnMissingQuote(t *testing.T) {
+	provider := &partialHistoricalProvider{partialProvider{latestProvider: latestProvider{name: "partial", rate: decimal.NewFromInt(2)}, omit: "GBP"}}
+	useProviders(t, map[string]providers.ProviderInterface{"partial": provider})
+
+	result, err := GetRatesOn(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), "USD", []string{"EUR", "GBP"}, config.First)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rate, ok := result.Rates["EUR"]; !ok || rate.String() != "2" || result.Sources["EUR"] != "partial" {
+		t.Errorf("expected EUR at 2 from partial, got %v from %q", rate, result.Sources["EUR"])
+	}
+	if rate, ok := result.Rates["GBP"]; ok {
+		t.Errorf("expected no GBP rate, got %v", rate)
+	}
+	if source, ok := result.Sources["GBP"]; ok {
+		t.Errorf("expected no GBP source, got %q", source)
+	}
+}
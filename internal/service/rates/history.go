@@ -0,0 +1,35 @@
+package rates
+
+import (
+	"time"
+
+	"fx-service/internal/service/history"
+	"fx-service/internal/service/providers"
+)
+
+// recordHistory queues freshly fetched rates (not cached ones) for the historic rate store.
+// sourceTime is the time the provider published the rates, when it reports one.
+func recordHistory(from string, rateList providers.RateList, providerName *string, sourceTime *time.Time) {
+	if !history.Enabled() {
+		return
+	}
+
+	name := ""
+	if providerName != nil {
+		name = *providerName
+	}
+
+	now := time.Now()
+	records := make([]history.Record, 0, len(rateList))
+	for to, rate := range rateList {
+		records = append(records, history.Record{
+			Base:       from,
+			Quote:      to,
+			Rate:       rate,
+			Provider:   name,
+			SourceTime: sourceTime,
+			FetchedAt:  now,
+		})
+	}
+	history.Add(records...)
+}
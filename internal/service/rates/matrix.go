@@ -0,0 +1,172 @@
+package rates
+
+import (
+	"fx-service/internal/service/overrides"
+	"fx-service/internal/service/ratecache"
+	"fx-service/pkg/config"
+	"fx-service/pkg/decimal"
+)
+
+const (
+	MaxMatrixCurrencies = 50 // Maximum number of currencies in a single matrix
+	derivedRatePlaces   = 12 // Decimal places of the rates derived by inversion or triangulation
+)
+
+// Sources of the rates in a matrix
+const (
+	MatrixSourceIdentity     = "identity"     // A currency against itself
+	MatrixSourceOverride     = "override"     // Forced by a manual override
+	MatrixSourceCache        = "cache"        // Found in the cache
+	MatrixSourceInverse      = "inverse"      // The inverse of the opposite pair
+	MatrixSourceProvider     = "provider"     // Fetched from a provider for this matrix
+	MatrixSourceTriangulated = "triangulated" // Derived from the rates of both currencies against the pivot currency
+	MatrixSourceUnavailable  = "unavailable"  // The providers did not return a rate needed for this cell. The rate is unset
+)
+
+type MatrixCell struct {
+	Rate     decimal.Decimal // Zero when the cell is unavailable
+	Source   string
+	Age      int     // Age in seconds of the oldest rate used for this cell
+	Provider *string // Set when a rate used for this cell was fetched from a provider
+}
+
+type GetMatrixResult struct {
+	Currencies []string
+	Cells      map[string]map[string]MatrixCell // Keyed by base, then quote
+	Pivot      string                           // The currency used for the inverted and triangulated rates
+	Calls      int                              // Number of upstream calls
+}
+
+// matrixLeg is the rate from the pivot currency to another currency
+type matrixLeg struct {
+	rate     decimal.Decimal
+	age      int
+	provider *string
+}
+
+// cachedLeg returns the overridden rate, the rate from the cache, or the inverse of the opposite pair from the cache
+func cachedLeg(from, to string) (MatrixCell, bool) {
+	if override := overrides.Get(from, to); override != nil {
+		return MatrixCell{Rate: override.Rate, Source: MatrixSourceOverride}, true
+	}
+	cache := ratecache.GetInstance()
+	if entry := cache.GetEntry(from, to); entry != nil {
+		return MatrixCell{Rate: entry.Rate, Source: MatrixSourceCache, Age: entry.Age}, true
+	}
+	if entry := cache.GetEntry(to, from); entry != nil && !entry.Rate.IsZero() {
+		rate := decimal.NewFromInt(1).Div(entry.Rate, derivedRatePlaces)
+		return MatrixCell{Rate: rate, Source: MatrixSourceInverse, Age: entry.Age}, true
+	}
+	return MatrixCell{}, false
+}
+
+// GetMatrix builds the table of rates between every pair of the given currencies.
+// Pairs (or their inverse) in the cache are used as they are. The other pairs are derived from the rates of both
+// currencies against a pivot currency: the one with the most rates in the cache. Its missing rates are fetched
+// with a single GetRates call, so a matrix costs at most one upstream call.
+// The cells needing a rate the providers did not return are marked unavailable.
+func GetMatrix(currencies []string, mode config.Mode) (*GetMatrixResult, error) {
+	result := GetMatrixResult{
+		Currencies: currencies,
+		Cells:      make(map[string]map[string]MatrixCell, len(currencies)),
+	}
+
+	// Fill the cells we can from the cache, and keep track of the others
+	type pair struct{ from, to string }
+	var missing []pair
+	for _, from := range currencies {
+		result.Cells[from] = make(map[string]MatrixCell, len(currencies))
+		for _, to := range currencies {
+			if from == to {
+				result.Cells[from][to] = MatrixCell{Rate: decimal.NewFromInt(1), Source: MatrixSourceIdentity}
+				continue
+			}
+			if cell, ok := cachedLeg(from, to); ok {
+				result.Cells[from][to] = cell
+				continue
+			}
+			missing = append(missing, pair{from, to})
+		}
+	}
+	if len(missing) == 0 {
+		return &result, nil
+	}
+
+	// Pick the pivot with the most rates against the other currencies
+	for _, candidate := range currencies {
+		if result.Pivot == "" || countCached(result.Cells, candidate) > countCached(result.Cells, result.Pivot) {
+			result.Pivot = candidate
+		}
+	}
+	pivot := result.Pivot
+
+	// Collect the rates from the pivot, and the ones to fetch
+	legs := map[string]matrixLeg{pivot: {rate: decimal.NewFromInt(1)}}
+	var toFetch []string
+	for _, to := range currencies {
+		if to == pivot {
+			continue
+		}
+		if cell, ok := result.Cells[pivot][to]; ok {
+			legs[to] = matrixLeg{rate: cell.Rate, age: cell.Age}
+		} else {
+			toFetch = append(toFetch, to)
+		}
+	}
+	if len(toFetch) > 0 {
+		ratesResult, err := GetRates(pivot, toFetch, mode)
+		if err != nil {
+			return nil, err
+		}
+		if !ratesResult.WasCached {
+			result.Calls++
+		}
+		for _, to := range toFetch {
+			if rate, ok := ratesResult.Rates[to]; ok && !rate.IsZero() {
+				legs[to] = matrixLeg{rate: rate, provider: ratesResult.Provider}
+			}
+		}
+	}
+
+	// Derive the missing cells from the pivot rates
+	for _, p := range missing {
+		fromLeg, fromOK := legs[p.from]
+		toLeg, toOK := legs[p.to]
+		if !fromOK || !toOK {
+			result.Cells[p.from][p.to] = MatrixCell{Source: MatrixSourceUnavailable}
+			continue
+		}
+		cell := MatrixCell{
+			Age:      max(fromLeg.age, toLeg.age),
+			Provider: fromLeg.provider,
+		}
+		if cell.Provider == nil {
+			cell.Provider = toLeg.provider
+		}
+		switch {
+		case p.from == pivot:
+			cell.Rate = toLeg.rate
+			cell.Source = MatrixSourceProvider
+		case p.to == pivot:
+			cell.Rate = decimal.NewFromInt(1).Div(fromLeg.rate, derivedRatePlaces)
+			cell.Source = MatrixSourceInverse
+		default:
+			cell.Rate = toLeg.rate.Div(fromLeg.rate, derivedRatePlaces)
+			cell.Source = MatrixSourceTriangulated
+		}
+		result.Cells[p.from][p.to] = cell
+	}
+
+	return &result, nil
+}
+
+// countCached counts the rates from the currency which were found in the cache
+func countCached(cells map[string]map[string]MatrixCell, from string) int {
+	count := 0
+	for _, cell := range cells[from] {
+		if cell.Source == MatrixSourceCache || cell.Source == MatrixSourceInverse || cell.Source == MatrixSourceOverride {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,128 @@
+package rates
+
+import (
+	"testing"
+
+	"fx-service/internal/service/providers"
+	"fx-service/internal/service/ratecache"
+	"fx-service/pkg/config"
+	"fx-service/pkg/decimal"
+)
+
+// countingProvider is a fake provider which counts the multi-quote calls
+type countingProvider struct {
+	latestProvider
+	calls int
+}
+
+func (p *countingProvider) GetRates(from string, to []string) (providers.RateList, error) {
+	p.calls++
+	return p.latestProvider.GetRates(from, to)
+}
+
+// TestGetMatrix checks that the matrix is filled from the cache, a single upstream call and triangulation
+func TestGetMatrix(t *testing.T) {
+	provider := &countingProvider{latestProvider: latestProvider{name: "counting", rate: decimal.NewFromInt(2)}}
+	useProviders(t, map[string]providers.ProviderInterface{"counting": provider})
+
+	cache := ratecache.GetInstance()
+	cache.SetExpiry(3600)
+	cache.Clear()
+	t.Cleanup(cache.Clear)
+	cache.Set("USD", "EUR", decimal.MustParse("0.5"))
+
+	result, err := GetMatrix([]string{"USD", "EUR", "GBP"}, config.First)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if provider.calls != 1 || result.Calls != 1 || result.Pivot != "USD" {
+		t.Errorf("expected 1 upstream call with the pivot USD, got %d calls (%d reported), pivot %s",
+			provider.calls, result.Calls, result.Pivot)
+	}
+
+	tests := []struct {
+		from, to, rate, source string
+	}{
+		{"USD", "USD", "1", MatrixSourceIdentity},
+		{"USD", "EUR", "0.5", MatrixSourceCache},
+		{"EUR", "USD", "2", MatrixSourceInverse},
+		{"USD", "GBP", "2", MatrixSourceProvider},
+		{"GBP", "USD", "0.5", MatrixSourceInverse},
+		{"EUR", "GBP", "4", MatrixSourceTriangulated},
+		{"GBP", "EUR", "0.25", MatrixSourceTriangulated},
+	}
+	for _, tt := range tests {
+		cell, ok := result.Cells[tt.from][tt.to]
+		if !ok {
+			t.Errorf("%s/%s: missing cell", tt.from, tt.to)
+			continue
+		}
+		if cell.Rate.String() != tt.rate || cell.Source != tt.source {
+			t.Errorf("%s/%s: expected %s from %s, got %s from %s", tt.from, tt.to, tt.rate, tt.source, cell.Rate, cell.Source)
+		}
+	}
+	if cell := result.Cells["EUR"]["GBP"]; cell.Provider == nil || *cell.Provider != "counting" {
+		t.Errorf("expected the provider of the fetched leg, got %v", cell.Provider)
+	}
+}
+
+// partialProvider is a fake provider which leaves a currency out of its rates
+type partialProvider struct {
+	latestProvider
+	omit string
+}
+
+func (p *partialProvider) GetRates(from string, to []string) (providers.RateList, error) {
+	rates, err := p.latestProvider.GetRates(from, to)
+	delete(rates, p.omit)
+	return rates, err
+}
+
+// TestGetMatrixUnavailable checks that the cells needing a rate the providers left out are marked unavailable
+func TestGetMatrixUnavailable(t *testing.T) {
+	provider := &partialProvider{latestProvider: latestProvider{name: "partial", rate: decimal.NewFromInt(2)}, omit: "GBP"}
+	useProviders(t, map[string]providers.ProviderInterface{"partial": provider})
+
+	cache := ratecache.GetInstance()
+	cache.SetExpiry(3600)
+	cache.Clear()
+	t.Cleanup(cache.Clear)
+
+	result, err := GetMatrix([]string{"USD", "EUR", "GBP"}, config.First)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, pair := range [][2]string{{"USD", "GBP"}, {"GBP", "USD"}, {"EUR", "GBP"}, {"GBP", "EUR"}} {
+		cell, ok := result.Cells[pair[0]][pair[1]]
+		if !ok || cell.Source != MatrixSourceUnavailable || !cell.Rate.IsZero() {
+			t.Errorf("%s/%s: expected an unavailable cell, got %+v", pair[0], pair[1], cell)
+		}
+	}
+	if cell := result.Cells["EUR"]["USD"]; cell.Source != MatrixSourceInverse || cell.Rate.String() != "0.5" {
+		t.Errorf("expected 0.5 inverse, got %s from %s", cell.Rate, cell.Source)
+	}
+}
+
+// TestGetMatrixCached checks that no upstream call is made when the cache covers every pair
+func TestGetMatrixCached(t *testing.T) {
+	provider := &countingProvider{latestProvider: latestProvider{name: "counting", rate: decimal.NewFromInt(2)}}
+	useProviders(t, map[string]providers.ProviderInterface{"counting": provider})
+
+	cache := ratecache.GetInstance()
+	cache.SetExpiry(3600)
+	cache.Clear()
+	t.Cleanup(cache.Clear)
+	cache.Set("USD", "EUR", decimal.MustParse("0.5"))
+	cache.Set("USD", "GBP", decimal.MustParse("0.8"))
+
+	result, err := GetMatrix([]string{"USD", "EUR", "GBP"}, config.First)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if provider.calls != 0 || result.Calls != 0 {
+		t.Errorf("expected no upstream calls, got %d", provider.calls)
+	}
+	if cell := result.Cells["EUR"]["GBP"]; cell.Rate.String() != "1.6" || cell.Source != MatrixSourceTriangulated {
+		t.Errorf("expected 1.6 triangulated, got %s from %s", cell.Rate, cell.Source)
+	}
+}
@@ -0,0 +1,54 @@
+package rates
+
+import (
+	"fx-service/internal/service/providers"
+)
+
+// ProviderSet is the providers the strategies choose from, with the state of the round robin and priority order.
+// The package-level functions use the providers enabled in the config; a Service can have its own set.
+type ProviderSet struct {
+	enabled  map[string]providers.ProviderInterface
+	priority map[string]uint
+	robin    *roundRobinState
+	pos      *posState
+}
+
+// NewProviderSet returns a set of providers, by name, with their priority (1 is the highest, 0 is none)
+func NewProviderSet(enabled map[string]providers.ProviderInterface, priority map[string]uint) *ProviderSet {
+	return &ProviderSet{
+		enabled:  enabled,
+		priority: priority,
+		robin:    newRobinState(enabled),
+		pos:      &posState{providers: sortProvidersByPriority(enabled, priority)},
+	}
+}
+
+// enabledProviderSet returns the providers enabled in the config, with the shared round robin and priority order
+func enabledProviderSet() *ProviderSet {
+	return &ProviderSet{enabled: providers.EnabledProviders, priority: providers.ProviderPriority}
+}
+
+// Names returns the names of the providers in the set
+func (set *ProviderSet) Names() []string {
+	names := make([]string, 0, len(set.enabled))
+	for name := range set.enabled {
+		names = append(names, name)
+	}
+	return names
+}
+
+// robinState returns the round robin state of the set
+func (set *ProviderSet) robinState() *roundRobinState {
+	if set.robin == nil {
+		return getRobinState()
+	}
+	return set.robin
+}
+
+// posState returns the priority order state of the set
+func (set *ProviderSet) posState() *posState {
+	if set.pos == nil {
+		return getPosState()
+	}
+	return set.pos
+}
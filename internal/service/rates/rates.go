@@ -1,23 +1,29 @@
 package rates
 
 import (
+	"time"
+
+	"fx-service/internal/service/alerts"
+	"fx-service/internal/service/overrides"
 	"fx-service/internal/service/providers"
 	"fx-service/internal/service/ratecache"
 	"fx-service/pkg/config"
+	"fx-service/pkg/decimal"
 	"fx-service/pkg/e"
 	util "fx-service/pkg/helpers"
 )
 
 type RateGetter interface {
-	GetRate(from, to string, mode config.Mode) (float64, bool, error)
-	GetRates(from string, to []string, mode config.Mode) (map[string]float64, error)
+	GetRate(from, to string, mode config.Mode) (decimal.Decimal, bool, error)
+	GetRates(from string, to []string, mode config.Mode) (providers.RateList, error)
 }
 
 type GetRateResult struct {
 	Base      string
 	Quote     string
-	Rate      float64
+	Rate      decimal.Decimal
 	WasCached bool
+	Override  bool // The rate was forced by a manual override
 	Provider  *string
 }
 
@@ -26,46 +32,133 @@ type GetRatesResult struct {
 	Quotes    []string
 	Rates     providers.RateList
 	WasCached bool
+	Overrides []string // The quote currencies whose rates were forced by a manual override
 	Provider  *string
 }
 
+// Rate returns the rate to one of the quote currencies, or an error when the providers left it out
+func (r *GetRatesResult) Rate(to string) (decimal.Decimal, error) {
+	rate, ok := r.Rates[to]
+	if !ok {
+		return decimal.Zero, e.FromCode("ePrRnf").SetField("to", to)
+	}
+	return rate, nil
+}
+
+// Cache is where a Service keeps the rates it got from the providers
+type Cache interface {
+	Get(from, to string) *decimal.Decimal
+	Set(from, to string, rate decimal.Decimal)
+}
+
+// Service gets the rates from a set of providers, through a cache.
+// The package-level functions use the providers enabled in the config and the shared cache, with the overrides,
+// the history and the alerts. Other services only use their own providers and cache, so several can run at once.
+type Service struct {
+	providers *ProviderSet
+	cache     Cache
+	shared    bool // Applies the overrides, and records the history and the alerts
+}
+
+// sharedService is the service of the package-level functions
+var sharedService = &Service{shared: true}
+
+// NewService returns a service with its own providers and cache
+func NewService(set *ProviderSet, cache Cache) *Service {
+	return &Service{providers: set, cache: cache}
+}
+
+// getCache returns the cache of the service
+func (s *Service) getCache() Cache {
+	if s.cache == nil {
+		return ratecache.GetInstance()
+	}
+	return s.cache
+}
+
+// latestRequest builds a request for the latest rate(s), from the providers of the service
+func (s *Service) latestRequest(from string, to interface{}, isMulti bool) providerRequest {
+	req := latestRequest(from, to, isMulti)
+	req.set = s.providers
+	return req
+}
+
+// override returns the manual override of the pair, if any. Only the shared service has overrides.
+func (s *Service) override(from, to string) *overrides.Override {
+	if !s.shared {
+		return nil
+	}
+	return overrides.Get(from, to)
+}
+
+// observe records the rates fetched from the providers in the history, and checks them against the alerts.
+// Only the shared service records them.
+func (s *Service) observe(from string, rates providers.RateList, providerName *string, sourceTime *time.Time) {
+	if !s.shared {
+		return
+	}
+	recordHistory(from, rates, providerName, sourceTime)
+	alerts.Observe(from, rates)
+}
+
 // GetRate obtains the rate for the given currency pair.
 // Returns the rate, a boolean indicating if the rate was found in the cache, or an error.
 func GetRate(from, to string, mode config.Mode) (*GetRateResult, error) {
+	return sharedService.GetRate(from, to, mode)
+}
+
+// GetRate obtains the rate for the given currency pair, from the providers of the service or its cache
+func (s *Service) GetRate(from, to string, mode config.Mode) (*GetRateResult, error) {
 	result := GetRateResult{
 		Base:  from,
 		Quote: to,
 	}
+	// Manual overrides take precedence over the cache and every provider
+	if override := s.override(from, to); override != nil {
+		result.Rate = override.Rate
+		result.Override = true
+		return &result, nil
+	}
+
 	// Check if we have the rate in the cache
-	if rate := ratecache.GetInstance().Get(from, to); rate != nil {
+	cache := s.getCache()
+	if rate := cache.Get(from, to); rate != nil {
 		result.Rate = *rate
 		result.WasCached = true
 		return &result, nil
 	}
 
 	// Get the rate from the provider
-	rate, providerName, err := runAPIStrategy(from, to, mode, false)
+	rate, providerName, err := runAPIStrategy(s.latestRequest(from, to, false), mode)
 	if err != nil {
 		return nil, err
 	}
+	rate, sourceTime := splitResult(rate)
 
-	// For single rates, the result from the API calling strategy is a float64
-	rateF64 := rate.(float64)
-	result.Rate = rateF64
+	// For single rates, the result from the API calling strategy is a Decimal
+	rateDec := rate.(decimal.Decimal)
+	result.Rate = rateDec
 	result.Provider = providerName
 
 	// Update the cache, asynchronously
 	defer func() {
 		go func() {
-			ratecache.GetInstance().Set(from, to, rateF64)
+			cache.Set(from, to, rateDec)
 		}()
 	}()
 
+	s.observe(from, providers.RateList{to: rateDec}, providerName, sourceTime)
+
 	return &result, nil
 }
 
 // GetRates obtains multiple quotes for the given currency rate
 func GetRates(from string, toList []string, mode config.Mode) (*GetRatesResult, error) {
+	return sharedService.GetRates(from, toList, mode)
+}
+
+// GetRates obtains multiple quotes for the given currency rate, from the providers of the service or its cache
+func (s *Service) GetRates(from string, toList []string, mode config.Mode) (*GetRatesResult, error) {
 	var ratesToGet []string
 	result := GetRatesResult{
 		Base:   from,
@@ -73,10 +166,13 @@ func GetRates(from string, toList []string, mode config.Mode) (*GetRatesResult,
 		Rates:  make(providers.RateList),
 	}
 
-	// Check which combinations we have in the cache
-	cache := ratecache.GetInstance()
+	// Check which combinations are overridden, or in the cache
+	cache := s.getCache()
 	for _, toCurrency := range toList {
-		if rate := cache.Get(from, toCurrency); rate != nil {
+		if override := s.override(from, toCurrency); override != nil {
+			result.Rates[toCurrency] = override.Rate
+			result.Overrides = append(result.Overrides, toCurrency)
+		} else if rate := cache.Get(from, toCurrency); rate != nil {
 			// Found it in the cache
 			result.Rates[toCurrency] = *rate
 		} else {
@@ -92,10 +188,11 @@ func GetRates(from string, toList []string, mode config.Mode) (*GetRatesResult,
 	}
 
 	// Get the rates from the provider, for the ones we don't have in the cache
-	strategyResult, providerName, err := runAPIStrategy(from, ratesToGet, mode, true)
+	strategyResult, providerName, err := runAPIStrategy(s.latestRequest(from, ratesToGet, true), mode)
 	if err != nil {
 		return nil, err
 	}
+	strategyResult, sourceTime := splitResult(strategyResult)
 
 	// Ensure strategyResult is a RateList type (should always be the case)
 	apiRatesResult, ok := strategyResult.(providers.RateList)
@@ -108,13 +205,14 @@ func GetRates(from string, toList []string, mode config.Mode) (*GetRatesResult,
 	// Update the cache asynchronously
 	defer func() {
 		go func() {
-			rc := ratecache.GetInstance()
 			for currency, newRate := range apiRatesResult {
-				rc.Set(from, currency, newRate)
+				cache.Set(from, currency, newRate)
 			}
 		}()
 	}()
 
+	s.observe(from, apiRatesResult, providerName, sourceTime)
+
 	// Combine the rates we just got from the API provider with the ones we already had in the cache
 	for currency, rate := range apiRatesResult {
 		result.Rates[currency] = rate
@@ -123,3 +221,42 @@ func GetRates(from string, toList []string, mode config.Mode) (*GetRatesResult,
 	result.Provider = providerName
 	return &result, nil
 }
+
+// RefreshRate fetches the rate for the given pair from upstream, bypassing the cache, and saves it in the cache.
+// If providerName is set, only that (enabled) provider is called; otherwise the given strategy mode is used.
+func RefreshRate(from, to string, mode config.Mode, providerName string) (*GetRateResult, error) {
+	result := GetRateResult{
+		Base:  from,
+		Quote: to,
+	}
+
+	var (
+		rate interface{}
+		name *string
+		err  error
+	)
+	if providerName != "" {
+		provider, ok := providers.EnabledProviders[providerName]
+		if !ok {
+			return nil, e.FromCode("eRrPn1", providerName)
+		}
+		rate, err = callProvider(provider, latestRequest(from, to, false))
+		name = &providerName
+	} else {
+		rate, name, err = runAPIStrategy(latestRequest(from, to, false), mode)
+	}
+	if err != nil {
+		return nil, err
+	}
+	rate, sourceTime := splitResult(rate)
+
+	result.Rate = rate.(decimal.Decimal)
+	result.Provider = name
+
+	// Update the cache synchronously, so the refreshed rate is served from the next request
+	ratecache.GetInstance().Set(from, to, result.Rate)
+	recordHistory(from, providers.RateList{to: result.Rate}, name, sourceTime)
+	alerts.Observe(from, providers.RateList{to: result.Rate})
+
+	return &result, nil
+}
@@ -0,0 +1,77 @@
+package rates
+
+import (
+	"testing"
+
+	"fx-service/internal/service/overrides"
+	"fx-service/internal/service/providers"
+	"fx-service/internal/service/ratecache"
+	"fx-service/internal/service/spreads"
+	"fx-service/pkg/config"
+	"fx-service/pkg/decimal"
+	"fx-service/pkg/e"
+)
+
+// TestGetRatesOverride checks that overrides take precedence over the cache and the providers
+func TestGetRatesOverride(t *testing.T) {
+	provider := &countingProvider{latestProvider: latestProvider{name: "counting", rate: decimal.NewFromInt(2)}}
+	useProviders(t, map[string]providers.ProviderInterface{"counting": provider})
+
+	if err := overrides.Init(config.OverrideConfig{}); err != nil {
+		t.Fatalf("overrides.Init failed: %v", err)
+	}
+	if _, err := overrides.Set("USD", "HKD", decimal.MustParse("7.8"), "peg", "treasury", nil); err != nil {
+		t.Fatalf("overrides.Set failed: %v", err)
+	}
+	t.Cleanup(func() { _ = overrides.Init(config.OverrideConfig{}) })
+
+	cache := ratecache.GetInstance()
+	cache.SetExpiry(3600)
+	cache.Clear()
+	t.Cleanup(cache.Clear)
+	cache.Set("USD", "HKD", decimal.MustParse("7.9"))
+
+	rateResult, err := GetRate("USD", "HKD", config.First)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rateResult.Rate.String() != "7.8" || !rateResult.Override || rateResult.WasCached {
+		t.Errorf("expected the override 7.8, got %+v", rateResult)
+	}
+
+	ratesResult, err := GetRates("USD", []string{"HKD", "EUR"}, config.First)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ratesResult.Rates["HKD"].String() != "7.8" || ratesResult.Rates["EUR"].String() != "2" ||
+		len(ratesResult.Overrides) != 1 || ratesResult.Overrides[0] != "HKD" {
+		t.Errorf("expected HKD to be overridden and EUR from the provider, got %+v", ratesResult)
+	}
+	if provider.calls != 1 {
+		t.Errorf("expected 1 upstream call for EUR only, got %d", provider.calls)
+	}
+}
+
+// TestConvertBatchMissingQuote checks that converting to a quote the providers left out fails, rather than giving zero
+func TestConvertBatchMissingQuote(t *testing.T) {
+	provider := &partialProvider{latestProvider: latestProvider{name: "partial", rate: decimal.NewFromInt(2)}, omit: "GBP"}
+	useProviders(t, map[string]providers.ProviderInterface{"partial": provider})
+	e.SetCatalogue(e.ErrorMap{"ePrRnf": {Message: "To-symbol (quote) not found in response from API provider"}})
+
+	cache := ratecache.GetInstance()
+	cache.SetExpiry(3600)
+	cache.Clear()
+	t.Cleanup(cache.Clear)
+
+	ten := decimal.NewFromInt(10)
+	items := ConvertBatch([]ConvertRequest{
+		{From: "USD", To: "EUR", Amount: &ten},
+		{From: "USD", To: "GBP", Amount: &ten},
+	}, config.First, config.HalfEven, spreads.Client{})
+	if items[0].Error != nil || items[0].Result.Result.String() != "20.00" {
+		t.Errorf("expected 20.00 EUR, got %+v", items[0])
+	}
+	if items[1].Result != nil || e.FromError(items[1].Error).GetCode() != "ePrRnf" {
+		t.Errorf("expected ePrRnf for GBP, got %+v", items[1])
+	}
+}
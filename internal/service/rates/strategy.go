@@ -1,33 +1,131 @@
 package rates
 
 import (
+	"time"
+
 	"fx-service/internal/service/providers"
 	"fx-service/pkg/config"
 	"fx-service/pkg/e"
 )
 
+// requestKind is the kind of rates a strategy asks from each provider
+type requestKind int
+
+const (
+	requestLatest     requestKind = iota // Latest rates: GetRate or GetRates
+	requestOnDate                        // Rates for a past date: GetRatesOn
+	requestTimeSeries                    // Daily rates over a date range: GetTimeSeries
+)
+
+// providerRequest describes the call a strategy makes on each provider it selects
+type providerRequest struct {
+	kind    requestKind
+	from    string
+	to      interface{}  // A string for a single latest rate; a []string otherwise
+	isMulti bool         // For latest rates, whether to call GetRates rather than GetRate
+	start   time.Time    // For historical requests, the date of the rates (or the start of the time series)
+	end     time.Time    // For time series requests, the end of the range
+	set     *ProviderSet // The providers to choose from; nil for the providers enabled in the config
+}
+
+// timedResult is the result of a provider which reports when it published its rates
+type timedResult struct {
+	result     interface{}
+	sourceTime time.Time
+}
+
+// splitResult returns the rate(s) of a provider or strategy result, and the time the provider published them, if known
+func splitResult(result interface{}) (interface{}, *time.Time) {
+	timed, ok := result.(timedResult)
+	if !ok {
+		return result, nil
+	}
+	if timed.sourceTime.IsZero() {
+		return timed.result, nil
+	}
+	return timed.result, &timed.sourceTime
+}
+
+// latestRequest builds a request for the latest rate(s)
+func latestRequest(from string, to interface{}, isMulti bool) providerRequest {
+	return providerRequest{kind: requestLatest, from: from, to: to, isMulti: isMulti}
+}
+
+// accepts checks if the provider is able to serve the request
+// Historical requests are only dispatched to providers implementing providers.HistoricalProvider
+func (req providerRequest) accepts(provider providers.ProviderInterface) bool {
+	if req.kind == requestLatest {
+		return true
+	}
+	_, ok := provider.(providers.HistoricalProvider)
+	return ok
+}
+
+// providerSet returns the set of providers to choose from
+func (req providerRequest) providerSet() *ProviderSet {
+	if req.set == nil {
+		return enabledProviderSet()
+	}
+	return req.set
+}
+
+// eligibleProviders returns the names of the enabled providers able to serve the request
+func (req providerRequest) eligibleProviders() []string {
+	enabled := req.providerSet().enabled
+	names := make([]string, 0, len(enabled))
+	for name, provider := range enabled {
+		if req.accepts(provider) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
 // callProvider calls GetRate, GetRates, or any other future method required on the specific provider
-func callProvider(provider providers.ProviderInterface, from string, to interface{}, isMulti bool) (interface{}, error) {
+func callProvider(provider providers.ProviderInterface, req providerRequest) (interface{}, error) {
 	var result interface{}
 	var err error
-	if isMulti {
-		// calling GetRates, for multi-currency result
+	switch req.kind {
+	case requestOnDate, requestTimeSeries:
+		// calling GetRatesOn or GetTimeSeries, for historical results
 
-		toList, ok := to.([]string) // Just make sure it's a slice of strings
+		historical, ok := provider.(providers.HistoricalProvider)
 		if !ok {
-			// This should never happen, as the API should have validated the input
-			return nil, e.Throw("eScp20", "invalid type for 'to' parameter, expected []string")
+			// This should never happen, as strategies only select providers which accept the request
+			return nil, e.FromCode("eRhNs1", provider.GetName())
 		}
-		result, err = provider.GetRates(from, toList)
-	} else {
-		// calling GetRate, for single-currency result
-
-		toCcy, ok := to.(string) // Just make sure it's a string
+		toList, ok := req.to.([]string)
 		if !ok {
-			// This should never happen, as the API should have validated the input
-			return nil, e.Throw("eScp30", "invalid type for 'to' parameter, expected string")
+			return nil, e.Throw("eScp40", "invalid type for 'to' parameter, expected []string")
+		}
+		if req.kind == requestOnDate {
+			result, err = historical.GetRatesOn(req.start, req.from, toList)
+		} else {
+			result, err = historical.GetTimeSeries(req.start, req.end, req.from, toList)
+		}
+	case requestLatest:
+		if timestamped, ok := provider.(providers.TimestampedProvider); ok {
+			return callTimestampedProvider(timestamped, req)
+		}
+		if req.isMulti {
+			// calling GetRates, for multi-currency result
+
+			toList, ok := req.to.([]string) // Just make sure it's a slice of strings
+			if !ok {
+				// This should never happen, as the API should have validated the input
+				return nil, e.Throw("eScp20", "invalid type for 'to' parameter, expected []string")
+			}
+			result, err = provider.GetRates(req.from, toList)
+		} else {
+			// calling GetRate, for single-currency result
+
+			toCcy, ok := req.to.(string) // Just make sure it's a string
+			if !ok {
+				// This should never happen, as the API should have validated the input
+				return nil, e.Throw("eScp30", "invalid type for 'to' parameter, expected string")
+			}
+			result, err = provider.GetRate(req.from, toCcy)
 		}
-		result, err = provider.GetRate(from, toCcy)
 	}
 	if err != nil {
 		return nil, err // Just pass the error through, the handler will deal with it
@@ -36,20 +134,58 @@ func callProvider(provider providers.ProviderInterface, from string, to interfac
 	return result, nil
 }
 
+// callTimestampedProvider gets the latest rate(s) from a provider which reports when it published them, with GetRatesAt.
+// The result is a timedResult, for the history store to keep the time of the rates.
+func callTimestampedProvider(provider providers.TimestampedProvider, req providerRequest) (interface{}, error) {
+	if req.isMulti {
+		toList, ok := req.to.([]string)
+		if !ok {
+			return nil, e.Throw("eScp20", "invalid type for 'to' parameter, expected []string")
+		}
+		rates, sourceTime, err := provider.GetRatesAt(req.from, toList)
+		if err != nil {
+			return nil, err
+		}
+		return timedResult{result: rates, sourceTime: sourceTime}, nil
+	}
+
+	toCcy, ok := req.to.(string)
+	if !ok {
+		return nil, e.Throw("eScp30", "invalid type for 'to' parameter, expected string")
+	}
+	rates, sourceTime, err := provider.GetRatesAt(req.from, []string{toCcy})
+	if err != nil {
+		return nil, err
+	}
+	rate, ok := rates[toCcy]
+	if !ok {
+		return nil, e.FromCode("ePrRnf").SetField("to", toCcy)
+	}
+	return timedResult{result: rate, sourceTime: sourceTime}, nil
+}
+
 // runAPIStrategy runs the API strategy based on the mode of calling API providers
-func runAPIStrategy(from string, to interface{}, mode config.Mode, isMulti bool) (interface{}, *string, error) {
+func runAPIStrategy(req providerRequest, mode config.Mode) (interface{}, *string, error) {
+	// Make sure there is at least one provider to call, e.g. for historical rates
+	if len(req.eligibleProviders()) == 0 {
+		if req.kind == requestLatest {
+			return nil, nil, e.FromCode("eGaPf1")
+		}
+		return nil, nil, e.FromCode("eRhNp1")
+	}
+
 	switch mode {
 	case config.Random:
-		return callProviderRandom(from, to, isMulti)
+		return callProviderRandom(req)
 	case config.Robin:
-		return callProviderRoundRobin(from, to, isMulti)
+		return callProviderRoundRobin(req)
 	case config.Priority:
-		return callPriorityOrder(from, to, isMulti)
+		return callPriorityOrder(req)
 	case config.Aggregate:
-		return callProviderAggregate(from, to, isMulti)
+		return callProviderAggregate(req)
 	case config.Race:
-		return callProviderRace(from, to, isMulti)
+		return callProviderRace(req)
 	default:
-		return callProviderFirst(from, to, isMulti)
+		return callProviderFirst(req)
 	}
 }
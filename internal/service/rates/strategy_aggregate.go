@@ -3,30 +3,35 @@ package rates
 import (
 	"fx-service/internal/service/providers"
 	c "fx-service/pkg/console"
+	"fx-service/pkg/decimal"
 	"fx-service/pkg/e"
-	util "fx-service/pkg/helpers"
 	"github.com/gofiber/fiber/v2/log"
 )
 
+// meanRatePlaces is the number of decimal places of the mean rates
+const meanRatePlaces = 8
+
 // aggregateSingleProvider aggregates results from all providers for a single currency conversion
-func aggregateSingleResult(from string, toCurrency string) (interface{}, *string, error) {
+func aggregateSingleResult(req providerRequest) (interface{}, *string, error) {
+	from, toCurrency := req.from, req.to.(string)
 	var (
-		totalRate float64
-		numRates  int
+		totalRate decimal.Decimal
+		numRates  int64
 	)
 
 	providerName := "Aggregate [all]"
-	for name, provider := range providers.EnabledProviders {
-		result, err := callProvider(provider, from, toCurrency, false)
+	for name, provider := range req.providerSet().enabled {
+		result, err := callProvider(provider, req)
+		result, _ = splitResult(result) // The mean of several providers has no source time
 		if err == nil {
-			// Assuming result is a float64 for single currency rate
-			rateF64, ok := result.(float64)
+			// Assuming result is a Decimal for single currency rate
+			rate, ok := result.(decimal.Decimal)
 			if !ok {
 				// Should never happen
 				log.Warnf("Provider %s aggregate failed for %s -> %s: invalid result type\n", providerName, from, toCurrency)
 				continue
 			}
-			totalRate += rateF64
+			totalRate = totalRate.Add(rate)
 			numRates++
 		} else {
 			log.Warnf("Provider %s failed for %s -> %s: %v\n", name, from, toCurrency, err)
@@ -41,26 +46,32 @@ func aggregateSingleResult(from string, toCurrency string) (interface{}, *string
 	c.Outf("GetRate - Averaged values from %d providers", numRates)
 
 	// Calculate mean rate (Round to 8 decimal places)
-	meanRate := util.Round(totalRate/float64(numRates), 8)
+	meanRate := totalRate.Div(decimal.NewFromInt(numRates), meanRatePlaces)
 
 	return meanRate, &providerName, nil
 }
 
 // aggregateMultiProvider aggregates results from all providers for multiple currency conversions
-func aggregateMultiResult(from string, toCurrencies []string) (interface{}, *string, error) {
+// Also used for the rates on a past date, which are returned in the same shape
+func aggregateMultiResult(req providerRequest) (interface{}, *string, error) {
+	from, toCurrencies := req.from, req.to.([]string)
 	// Initialize map to accumulate rates for each "to" currency
-	ratesMap := make(map[string]float64)
-	countMap := make(map[string]int)
+	ratesMap := make(map[string]decimal.Decimal)
+	countMap := make(map[string]int64)
 
 	providerName := "Aggregate [all]"
-	for name, provider := range providers.EnabledProviders {
-		result, err := callProvider(provider, from, toCurrencies, true)
+	for name, provider := range req.providerSet().enabled {
+		if !req.accepts(provider) {
+			continue
+		}
+		result, err := callProvider(provider, req)
+		result, _ = splitResult(result) // The mean of several providers has no source time
 		// TODO sanity check for result type
 		if err == nil {
-			// Assuming result is a map[string]float64 for multi currency rates
+			// Assuming result is a RateList for multi currency rates
 			rates := result.(providers.RateList)
 			for currency, rate := range rates {
-				ratesMap[currency] += rate
+				ratesMap[currency] = ratesMap[currency].Add(rate)
 				countMap[currency]++
 			}
 		} else {
@@ -79,7 +90,7 @@ func aggregateMultiResult(from string, toCurrencies []string) (interface{}, *str
 	meanRates := make(providers.RateList)
 	for currency, totalRate := range ratesMap {
 		count := countMap[currency]
-		meanRates[currency] = util.Round(totalRate/float64(count), 8)
+		meanRates[currency] = totalRate.Div(decimal.NewFromInt(count), meanRatePlaces)
 	}
 
 	return meanRates, &providerName, nil
@@ -89,9 +100,70 @@ func aggregateMultiResult(from string, toCurrencies []string) (interface{}, *str
 // Returns the result and provider name (Aggregate), or error
 // For single from-to results, it returns the mean of the "to" rate
 // For multi from-to results, it returns the mean of the "to" rates, for each "to" currency
-func callProviderAggregate(from string, to interface{}, isMulti bool) (interface{}, *string, error) {
-	if isMulti {
-		return aggregateMultiResult(from, to.([]string))
+// For time series, it returns the mean of the "to" rates, for each day and "to" currency
+func callProviderAggregate(req providerRequest) (interface{}, *string, error) {
+	switch {
+	case req.kind == requestTimeSeries:
+		return aggregateTimeSeriesResult(req)
+	case req.kind == requestOnDate || req.isMulti:
+		return aggregateMultiResult(req)
+	default:
+		return aggregateSingleResult(req)
+	}
+}
+
+// aggregateTimeSeriesResult aggregates time series from all historical providers, for each day
+func aggregateTimeSeriesResult(req providerRequest) (interface{}, *string, error) {
+	// Accumulate rates for each day and "to" currency
+	ratesMap := make(map[string]map[string]decimal.Decimal)
+	countMap := make(map[string]map[string]int64)
+	numProviders := 0
+
+	providerName := "Aggregate [all]"
+	for name, provider := range req.providerSet().enabled {
+		if !req.accepts(provider) {
+			continue
+		}
+		result, err := callProvider(provider, req)
+		if err != nil {
+			log.Warnf("Provider failed for %s -> %v time series: %v\n", req.from, req.to, err)
+			e.FromError(err).SetField("provider", name).Print(-1, 0)
+			continue
+		}
+		series, ok := result.(providers.TimeSeries)
+		if !ok {
+			// Should never happen
+			log.Warnf("Provider %s aggregate failed for %s -> %v: invalid result type\n", name, req.from, req.to)
+			continue
+		}
+		numProviders++
+		for date, rates := range series {
+			if ratesMap[date] == nil {
+				ratesMap[date] = make(map[string]decimal.Decimal)
+				countMap[date] = make(map[string]int64)
+			}
+			for currency, rate := range rates {
+				ratesMap[date][currency] = ratesMap[date][currency].Add(rate)
+				countMap[date][currency]++
+			}
+		}
+	}
+
+	if numProviders == 0 {
+		return nil, nil, e.Throw("eSaTs60", "all providers failed")
 	}
-	return aggregateSingleResult(from, to.(string))
+
+	c.Outf("GetTimeSeries - averaged values from %d providers", numProviders)
+
+	// Calculate mean rates for each day and "to" currency
+	meanSeries := make(providers.TimeSeries, len(ratesMap))
+	for date, totals := range ratesMap {
+		meanRates := make(providers.RateList, len(totals))
+		for currency, totalRate := range totals {
+			meanRates[currency] = totalRate.Div(decimal.NewFromInt(countMap[date][currency]), meanRatePlaces)
+		}
+		meanSeries[date] = meanRates
+	}
+
+	return meanSeries, &providerName, nil
 }
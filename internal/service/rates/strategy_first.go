@@ -1,17 +1,19 @@
 package rates
 
 import (
-	"fx-service/internal/service/providers"
 	c "fx-service/pkg/console"
 	"fx-service/pkg/e"
 )
 
 // callProviderFirst calls the first healthy provider that is available
-func callProviderFirst(from string, to interface{}, isMulti bool) (interface{}, *string, error) {
+func callProviderFirst(req providerRequest) (interface{}, *string, error) {
 	count := 0
-	for name, provider := range providers.EnabledProviders {
+	for name, provider := range req.providerSet().enabled {
+		if !req.accepts(provider) {
+			continue
+		}
 		count++
-		result, err := callProvider(provider, from, to, isMulti)
+		result, err := callProvider(provider, req)
 		if err != nil {
 			c.Warnf("Provider '%s' failed: %v", name, err.Error())
 			e.FromError(err).SetField("strategy", "first").Print(0, 0)
@@ -21,15 +21,15 @@ var (
 // Priority of 0 means no priority (they will go last, in a non-guaranteed order)
 // When priorities are equal, the order between them is not guaranteed either.
 // The highest priority is 1, the next highest is 2, and so on.
-func sortProvidersByPriority() []providers.ProviderInterface {
-	providerCount := len(providers.EnabledProviders)
+func sortProvidersByPriority(enabled map[string]providers.ProviderInterface, priorities map[string]uint) []providers.ProviderInterface {
+	providerCount := len(enabled)
 	providersWithPriority := make([]struct {
 		provider providers.ProviderInterface
 		priority uint
 	}, 0, providerCount)
 
-	for name, provider := range providers.EnabledProviders {
-		priority, exists := providers.ProviderPriority[name]
+	for name, provider := range enabled {
+		priority, exists := priorities[name]
 		if !exists {
 			priority = 0
 		}
@@ -56,23 +56,26 @@ func sortProvidersByPriority() []providers.ProviderInterface {
 	return sortedProviders
 }
 
-// getPosState returns the singleton priority order state
+// getPosState returns the singleton priority order state, of the providers enabled in the config
 func getPosState() *posState {
 	posOnce.Do(func() {
 		pos = &posState{
-			providers: sortProvidersByPriority(),
+			providers: sortProvidersByPriority(providers.EnabledProviders, providers.ProviderPriority),
 		}
 	})
 	return pos
 }
 
 // callPriorityOrder calls the providers in priority order until one returns a result
-func callPriorityOrder(from string, to interface{}, isMulti bool) (interface{}, *string, error) {
-	state := getPosState()
+func callPriorityOrder(req providerRequest) (interface{}, *string, error) {
+	state := req.providerSet().posState()
 
 	// Iterate through the providers in priority order
 	for i, provider := range state.providers {
-		result, err := callProvider(provider, from, to, isMulti)
+		if !req.accepts(provider) {
+			continue
+		}
+		result, err := callProvider(provider, req)
 		if err == nil {
 			c.Outf("Priority Order %d - Provider %s succeeded", i, provider.GetName())
 			providerName := provider.GetName()
@@ -81,7 +84,7 @@ func callPriorityOrder(from string, to interface{}, isMulti bool) (interface{},
 
 		// Log the error and continue to the next provider
 		// TODO - use logger, not the console output
-		c.Warnf("Provider failed for %s -> %v: %v\n", from, to, err)
+		c.Warnf("Provider failed for %s -> %v: %v\n", req.from, req.to, err)
 	}
 
 	return nil, nil, e.FromCode("eGaPf1")
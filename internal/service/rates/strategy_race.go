@@ -2,9 +2,10 @@ package rates
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"fx-service/internal/service/providers"
 	c "fx-service/pkg/console"
+	"fx-service/pkg/e"
 	"github.com/gofiber/fiber/v2/log"
 	"sync"
 )
@@ -12,11 +13,11 @@ import (
 // callProviderRace calls all healthy providers at the same time;
 // it waits for the first successful response and cancels other goroutines,
 // or returns an error if all providers fail.
-func callProviderRace(from string, to interface{}, isMulti bool) (interface{}, *string, error) {
+func callProviderRace(req providerRequest) (interface{}, *string, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	c.Outf("Race mode: %s -> %v", from, to)
+	c.Outf("Race mode: %s -> %v", req.from, req.to)
 
 	var (
 		wg          sync.WaitGroup
@@ -28,7 +29,10 @@ func callProviderRace(from string, to interface{}, isMulti bool) (interface{}, *
 		once      sync.Once
 	)
 
-	for name, provider := range providers.EnabledProviders {
+	enabled := req.providerSet().enabled
+	eligible := req.eligibleProviders()
+	for _, name := range eligible {
+		provider := enabled[name]
 		wg.Add(1)
 		go func(ctx context.Context, name string, provider providers.ProviderInterface) {
 			defer wg.Done()
@@ -39,7 +43,7 @@ func callProviderRace(from string, to interface{}, isMulti bool) (interface{}, *
 				return
 			default:
 				c.Outf("Race is calling provider: %s", name)
-				result, err := callProvider(provider, from, to, isMulti)
+				result, err := callProvider(provider, req)
 				if err == nil {
 					once.Do(func() {
 						successChan <- struct {
@@ -71,8 +75,8 @@ func callProviderRace(from string, to interface{}, isMulti bool) (interface{}, *
 			}
 		case err := <-errorChan:
 			collectedErrors = append(collectedErrors, err)
-			if len(collectedErrors) == len(providers.EnabledProviders) {
-				return nil, nil, fmt.Errorf("all providers failed: %v", collectedErrors)
+			if len(collectedErrors) == len(eligible) {
+				return nil, nil, e.FromCode("eGaPf1").SetPrevious(errors.Join(collectedErrors...))
 			}
 		}
 	}
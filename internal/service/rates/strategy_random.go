@@ -1,27 +1,26 @@
 package rates
 
 import (
-	"errors"
-	"fx-service/internal/service/providers"
+	"fx-service/pkg/e"
 	util "fx-service/pkg/helpers"
 	"github.com/gofiber/fiber/v2/log"
 )
 
 // callProviderRandom calls a random provider that is available and healthy
 // returns the rate result, provider name, or an error
-func callProviderRandom(from string, to interface{}, isMulti bool) (interface{}, *string, error) {
+func callProviderRandom(req providerRequest) (interface{}, *string, error) {
 	providersTried := make(map[string]bool)
-	providersNotTried := util.GetMapKeys(providers.EnabledProviders)
+	providersNotTried := req.eligibleProviders()
 
 	for len(providersNotTried) > 0 {
 		// get a random provider
 		nextIndex := util.GetRandomSliceIndex(providersNotTried)
 		providerName := providersNotTried[nextIndex]
 		util.RemoveSliceElement(providersNotTried, nextIndex)
-		provider := providers.EnabledProviders[providerName]
+		provider := req.providerSet().enabled[providerName]
 		providersTried[providerName] = true
 
-		result, err := callProvider(provider, from, to, isMulti)
+		result, err := callProvider(provider, req)
 		if err == nil {
 			// if more than 1 provider was tried, log it
 			if len(providersTried) > 1 {
@@ -33,5 +32,5 @@ func callProviderRandom(from string, to interface{}, isMulti bool) (interface{},
 	}
 
 	// If we get here, we've exhausted all providers available
-	return nil, nil, errors.New("all providers failed")
+	return nil, nil, e.FromCode("eGaPf1")
 }
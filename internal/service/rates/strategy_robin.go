@@ -23,24 +23,31 @@ type roundRobinState struct {
 	nextIndex int
 }
 
+// getRobinState returns the singleton round robin state, of the providers enabled in the config
 func getRobinState() *roundRobinState {
 	rrsOnce.Do(func() {
-		rrs = &roundRobinState{
-			providers: make([]providers.ProviderInterface, 0, len(providers.EnabledProviders)),
-		}
-		// We need to convert the EnabledProviders map to a slice to ensure the order is consistent
-		for _, provider := range providers.EnabledProviders {
-			rrs.providers = append(rrs.providers, provider)
-		}
+		rrs = newRobinState(providers.EnabledProviders)
 	})
 	return rrs
 }
 
+// newRobinState returns the round robin state of the given providers
+func newRobinState(enabled map[string]providers.ProviderInterface) *roundRobinState {
+	state := &roundRobinState{
+		providers: make([]providers.ProviderInterface, 0, len(enabled)),
+	}
+	// We need to convert the enabled providers map to a slice to ensure the order is consistent
+	for _, provider := range enabled {
+		state.providers = append(state.providers, provider)
+	}
+	return state
+}
+
 // callProviderRoundRobin calls the next healthy provider in a round-robin fashion.
 // It locks the mutex to ensure thread safety when accessing shared state.
-func callProviderRoundRobin(from string, to interface{}, isMulti bool) (interface{}, *string, error) {
+func callProviderRoundRobin(req providerRequest) (interface{}, *string, error) {
 	// Lazy initialization of providers slice if not already initialized
-	rr := getRobinState()
+	rr := req.providerSet().robinState()
 
 	// Acquire lock to ensure exclusive access to shared state
 	rr.mu.Lock()
@@ -53,8 +60,13 @@ func callProviderRoundRobin(from string, to interface{}, isMulti bool) (interfac
 		provider := rr.providers[index]                 // Select provider at the calculated index
 		rr.nextIndex = (index + 1) % len(rr.providers)  // Update nextIndex for next iteration
 
+		// Skip providers which can't serve this kind of request
+		if !req.accepts(provider) {
+			continue
+		}
+
 		// Call the provider to fetch the result
-		result, err := callProvider(provider, from, to, isMulti)
+		result, err := callProvider(provider, req)
 		if err == nil {
 			// Return result if provider call is successful
 			providerName := provider.GetName()
@@ -0,0 +1,186 @@
+package rates
+
+import (
+	"testing"
+	"time"
+
+	"fx-service/internal/service/providers"
+	"fx-service/pkg/config"
+	"fx-service/pkg/decimal"
+	"fx-service/pkg/e"
+)
+
+// latestProvider is a fake provider which only serves the latest rates
+type latestProvider struct {
+	name string
+	rate decimal.Decimal
+}
+
+func (p *latestProvider) CheckApiKey() bool             { return true }
+func (p *latestProvider) GetName() string               { return p.name }
+func (p *latestProvider) Supports(currency string) bool { return true }
+func (p *latestProvider) GetRate(from, to string) (decimal.Decimal, error) {
+	return p.rate, nil
+}
+func (p *latestProvider) GetRates(from string, to []string) (providers.RateList, error) {
+	rates := make(providers.RateList)
+	for _, currency := range to {
+		rates[currency] = p.rate
+	}
+	return rates, nil
+}
+
+// historicalProvider is a fake provider which also serves rates for past dates
+type historicalProvider struct {
+	latestProvider
+}
+
+func (p *historicalProvider) GetRatesOn(date time.Time, from string, to []string) (providers.RateList, error) {
+	return p.GetRates(from, to)
+}
+
+func (p *historicalProvider) GetTimeSeries(start, end time.Time, from string, to []string) (providers.TimeSeries, error) {
+	series := make(providers.TimeSeries)
+	for date := start; !date.After(end); date = date.Add(day) {
+		series[date.Format(time.DateOnly)], _ = p.GetRates(from, to)
+	}
+	return series, nil
+}
+
+// timestampedProvider is a fake provider which reports when it published its rates
+type timestampedProvider struct {
+	latestProvider
+	publishedAt time.Time
+}
+
+func (p *timestampedProvider) GetRatesAt(from string, to []string) (providers.RateList, time.Time, error) {
+	rates, err := p.GetRates(from, to)
+	return rates, p.publishedAt, err
+}
+
+// useProviders replaces the enabled providers for the duration of a test
+func useProviders(t *testing.T, enabled map[string]providers.ProviderInterface) {
+	previous := providers.EnabledProviders
+	providers.EnabledProviders = enabled
+	t.Cleanup(func() { providers.EnabledProviders = previous })
+}
+
+// TestRunAPIStrategyHistorical checks that every strategy only dispatches historical requests to historical providers
+func TestRunAPIStrategyHistorical(t *testing.T) {
+	useProviders(t, map[string]providers.ProviderInterface{
+		"latest":      &latestProvider{name: "latest", rate: decimal.NewFromInt(100)},
+		"historical1": &historicalProvider{latestProvider{name: "historical1", rate: decimal.NewFromInt(1)}},
+		"historical2": &historicalProvider{latestProvider{name: "historical2", rate: decimal.NewFromInt(3)}},
+	})
+
+	date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	req := providerRequest{kind: requestOnDate, from: "USD", to: []string{"EUR"}, start: date}
+	modes := []config.Mode{config.First, config.Random, config.Robin, config.Priority, config.Race, config.Aggregate}
+
+	for _, mode := range modes {
+		// Call several times, so that round robin and random go through every provider
+		for i := 0; i < 3; i++ {
+			result, providerName, err := runAPIStrategy(req, mode)
+			if err != nil {
+				t.Fatalf("mode %v: unexpected error: %v", mode, err)
+			}
+			rate := result.(providers.RateList)["EUR"]
+			switch mode {
+			case config.Aggregate:
+				if rate.String() != "2" {
+					t.Errorf("mode %v: expected the mean rate 2, got %v", mode, rate)
+				}
+			default:
+				if rate.String() != "1" && rate.String() != "3" {
+					t.Errorf("mode %v: rate %v came from a provider without historical rates (%s)", mode, rate, *providerName)
+				}
+			}
+		}
+	}
+}
+
+// TestRunAPIStrategyTimeSeries checks that time series are averaged per day in aggregate mode
+func TestRunAPIStrategyTimeSeries(t *testing.T) {
+	useProviders(t, map[string]providers.ProviderInterface{
+		"latest":      &latestProvider{name: "latest", rate: decimal.NewFromInt(100)},
+		"historical1": &historicalProvider{latestProvider{name: "historical1", rate: decimal.NewFromInt(1)}},
+		"historical2": &historicalProvider{latestProvider{name: "historical2", rate: decimal.NewFromInt(3)}},
+	})
+
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	req := providerRequest{kind: requestTimeSeries, from: "USD", to: []string{"EUR", "GBP"}, start: start, end: start.Add(2 * day)}
+
+	result, _, err := runAPIStrategy(req, config.Aggregate)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	series := result.(providers.TimeSeries)
+	if len(series) != 3 {
+		t.Fatalf("expected 3 days, got %d", len(series))
+	}
+	for date, rates := range series {
+		if rates["EUR"].String() != "2" || rates["GBP"].String() != "2" {
+			t.Errorf("%s: expected mean rates of 2, got %v", date, rates)
+		}
+	}
+}
+
+// TestRunAPIStrategyNoHistoricalProvider checks the error when no enabled provider supports historical rates
+func TestRunAPIStrategyNoHistoricalProvider(t *testing.T) {
+	useProviders(t, map[string]providers.ProviderInterface{
+		"latest": &latestProvider{name: "latest", rate: decimal.NewFromInt(100)},
+	})
+	e.SetCatalogue(e.ErrorMap{"eRhNp1": {Message: "No enabled provider supports historical rates"}})
+
+	req := providerRequest{kind: requestOnDate, from: "USD", to: []string{"EUR"}, start: time.Now()}
+	_, _, err := runAPIStrategy(req, config.First)
+	if err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+	if code := e.FromError(err).GetCode(); code != "eRhNp1" {
+		t.Errorf("expected error code eRhNp1, got %s", code)
+	}
+
+	// The latest rates are still served
+	result, _, err := runAPIStrategy(latestRequest("USD", "EUR", false), config.First)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.(decimal.Decimal).String() != "100" {
+		t.Errorf("expected rate 100, got %v", result)
+	}
+}
+
+// TestRunAPIStrategySourceTime checks that the time a provider published its rates comes with the strategy result
+func TestRunAPIStrategySourceTime(t *testing.T) {
+	publishedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	useProviders(t, map[string]providers.ProviderInterface{
+		"timestamped": &timestampedProvider{latestProvider{name: "timestamped", rate: decimal.NewFromInt(2)}, publishedAt},
+	})
+
+	for _, req := range []providerRequest{latestRequest("USD", "EUR", false), latestRequest("USD", []string{"EUR"}, true)} {
+		result, _, err := runAPIStrategy(req, config.First)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		result, sourceTime := splitResult(result)
+		if sourceTime == nil || !sourceTime.Equal(publishedAt) {
+			t.Errorf("expected source time %v, got %v", publishedAt, sourceTime)
+		}
+		if rates, ok := result.(providers.RateList); ok {
+			result = rates["EUR"]
+		}
+		if result.(decimal.Decimal).String() != "2" {
+			t.Errorf("expected rate 2, got %v", result)
+		}
+	}
+
+	// The mean of several providers has no source time
+	result, _, err := runAPIStrategy(latestRequest("USD", "EUR", false), config.Aggregate)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, sourceTime := splitResult(result); sourceTime != nil {
+		t.Errorf("expected no source time for an aggregate, got %v", sourceTime)
+	}
+}
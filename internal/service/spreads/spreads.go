@@ -0,0 +1,196 @@
+// Package spreads applies bid/ask markups to mid rates, by currency pair, client and amount band
+package spreads
+
+import (
+	"strings"
+	"sync"
+
+	"fx-service/pkg/config"
+	c "fx-service/pkg/console"
+	"fx-service/pkg/currency"
+	"fx-service/pkg/decimal"
+	"fx-service/pkg/e"
+)
+
+// Client specificity, from the most specific to the least specific
+const (
+	matchAPIKey    = iota // Rule for the client's API key
+	matchTier             // Rule for the client's tier
+	matchAnyClient        // Rule for any client
+)
+
+// bpsScale converts basis points to a fraction (1 bps = 0.0001)
+var bpsScale = decimal.New(1, 4)
+
+// maxBps is the largest markup: 10000 bps (100%) would make the bid zero
+var maxBps = decimal.NewFromInt(10000)
+
+// Client identifies who prices are for
+type Client struct {
+	APIKey string
+	Tier   string
+}
+
+// Price is the bid and ask prices around a mid rate
+type Price struct {
+	Bid decimal.Decimal
+	Mid decimal.Decimal
+	Ask decimal.Decimal
+	Bps decimal.Decimal // The markup applied on each side, in basis points
+}
+
+// rule is a validated spread rule
+type rule struct {
+	config.SpreadRule
+	pair string // Normalized pair pattern
+}
+
+var (
+	mu      sync.RWMutex
+	enabled bool
+	clients map[string]string
+	rules   []rule
+)
+
+// normalizePair validates a rule pair, and returns it as a normalized pair pattern (see currency.NormalizePattern).
+// An empty pair matches every pair.
+func normalizePair(pair string) (string, bool) {
+	if strings.TrimSpace(pair) == "" {
+		return currency.Wildcard, true
+	}
+	return currency.NormalizePattern(pair)
+}
+
+// Init validates and loads the spread rules from the config
+func Init(cfg config.SpreadConfig) error {
+	loaded := make([]rule, 0, len(cfg.Rules))
+	for i, spreadRule := range cfg.Rules {
+		pair, ok := normalizePair(spreadRule.Pair)
+		if !ok {
+			return e.FromCode("eSpRp1", i, spreadRule.Pair)
+		}
+		if spreadRule.Bps.Sign() < 0 || spreadRule.Bps.Cmp(maxBps) >= 0 {
+			return e.FromCode("eSpRb1", i, spreadRule.Bps.String())
+		}
+		if spreadRule.MinAmount.Sign() < 0 || spreadRule.MaxAmount.Sign() < 0 ||
+			(!spreadRule.MaxAmount.IsZero() && spreadRule.MaxAmount.Cmp(spreadRule.MinAmount) <= 0) {
+			return e.FromCode("eSpRa1", i)
+		}
+		loaded = append(loaded, rule{SpreadRule: spreadRule, pair: pair})
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	enabled = cfg.Enabled
+	clients = cfg.Clients
+	rules = loaded
+
+	if enabled {
+		c.Infof("Spreads enabled, with %d rules and %d clients", len(rules), len(clients))
+	}
+	return nil
+}
+
+// Enabled returns true if bid and ask prices should be returned
+func Enabled() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return enabled
+}
+
+// ClientFor returns the client for the given API key, with its tier. Unknown keys have no tier.
+func ClientFor(apiKey string) Client {
+	mu.RLock()
+	defer mu.RUnlock()
+	return Client{APIKey: apiKey, Tier: clients[apiKey]}
+}
+
+// clientSpecificity returns how specifically the rule matches the client, or -1 when it does not match
+func (r rule) clientSpecificity(client Client) int {
+	switch {
+	case r.APIKey != "":
+		if client.APIKey == "" || r.APIKey != client.APIKey {
+			return -1
+		}
+		return matchAPIKey
+	case r.Tier != "":
+		if client.Tier == "" || r.Tier != client.Tier {
+			return -1
+		}
+		return matchTier
+	default:
+		return matchAnyClient
+	}
+}
+
+// hasBand returns true if the rule only applies to a band of amounts
+func (r rule) hasBand() bool {
+	return !r.MinAmount.IsZero() || !r.MaxAmount.IsZero()
+}
+
+// inBand returns true if the amount is in the band of the rule. Banded rules never match an unknown amount.
+func (r rule) inBand(amount *decimal.Decimal) bool {
+	if !r.hasBand() {
+		return true
+	}
+	if amount == nil {
+		return false
+	}
+	abs := *amount
+	if abs.Sign() < 0 {
+		abs = abs.Neg()
+	}
+	return abs.Cmp(r.MinAmount) >= 0 && (r.MaxAmount.IsZero() || abs.Cmp(r.MaxAmount) < 0)
+}
+
+// BpsFor returns the markup (in basis points) of the most specific rule matching the pair, client and amount.
+// Rules for an API key are more specific than rules for a tier, which are more specific than rules for any client.
+// Then the most specific pair wins, then rules with an amount band. Ties go to the first rule in the config.
+// Returns zero if no rule matches. The amount (in the base currency) may be nil when unknown.
+func BpsFor(from, to string, client Client, amount *decimal.Decimal) decimal.Decimal {
+	from = strings.ToUpper(from)
+	to = strings.ToUpper(to)
+
+	mu.RLock()
+	defer mu.RUnlock()
+
+	var best *rule
+	var bestScore [3]int
+	for i := range rules {
+		r := &rules[i]
+		clientMatch := r.clientSpecificity(client)
+		pairMatch := currency.PatternSpecificity(r.pair, from, to)
+		if clientMatch < 0 || pairMatch == currency.MatchNone || !r.inBand(amount) {
+			continue
+		}
+		bandMatch := 1
+		if r.hasBand() {
+			bandMatch = 0
+		}
+		score := [3]int{clientMatch, pairMatch, bandMatch}
+		if best == nil || score[0] < bestScore[0] ||
+			(score[0] == bestScore[0] && (score[1] < bestScore[1] ||
+				(score[1] == bestScore[1] && score[2] < bestScore[2]))) {
+			best = r
+			bestScore = score
+		}
+	}
+
+	if best == nil {
+		return decimal.Zero
+	}
+	return best.Bps
+}
+
+// Quote applies the markup for the pair, client and amount on both sides of the mid rate
+func Quote(mid decimal.Decimal, from, to string, client Client, amount *decimal.Decimal) Price {
+	bps := BpsFor(from, to, client, amount)
+	markup := bps.Mul(bpsScale)
+	one := decimal.NewFromInt(1)
+	return Price{
+		Bid: mid.Mul(one.Sub(markup)).Trim(),
+		Mid: mid,
+		Ask: mid.Mul(one.Add(markup)).Trim(),
+		Bps: bps,
+	}
+}
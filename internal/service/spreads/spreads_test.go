@@ -0,0 +1,97 @@
+package spreads
+
+import (
+	"testing"
+
+	"fx-service/pkg/config"
+	"fx-service/pkg/decimal"
+	"fx-service/pkg/e"
+)
+
+// testConfig has rules at every level of specificity
+func testConfig() config.SpreadConfig {
+	return config.SpreadConfig{
+		Enabled: true,
+		Clients: map[string]string{"key-gold": "gold", "key-vip": "gold"},
+		Rules: []config.SpreadRule{
+			{Pair: "*", Bps: decimal.NewFromInt(50)},
+			{Pair: "EUR/USD", Bps: decimal.NewFromInt(20)},
+			{Pair: "EUR/USD", MinAmount: decimal.NewFromInt(100000), Bps: decimal.NewFromInt(10)},
+			{Pair: "JPY", Bps: decimal.NewFromInt(40)},
+			{Pair: "*", Tier: "gold", Bps: decimal.NewFromInt(30)},
+			{Pair: "EUR/USD", Tier: "gold", Bps: decimal.NewFromInt(15)},
+			{Pair: "*", APIKey: "key-vip", Bps: decimal.MustParse("2.5")},
+		},
+	}
+}
+
+// TestBpsFor checks that the most specific rule wins
+func TestBpsFor(t *testing.T) {
+	if err := Init(testConfig()); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	large := decimal.NewFromInt(250000)
+	small := decimal.NewFromInt(500)
+
+	tests := []struct {
+		name     string
+		from, to string
+		apiKey   string
+		amount   *decimal.Decimal
+		want     string
+	}{
+		{"Default rule", "GBP", "USD", "", nil, "50"},
+		{"Pair rule", "EUR", "USD", "", nil, "20"},
+		{"Pair rule, small amount", "EUR", "USD", "", &small, "20"},
+		{"Amount band", "EUR", "USD", "", &large, "10"},
+		{"Currency rule", "USD", "JPY", "", nil, "40"},
+		{"Unknown key has no tier", "GBP", "USD", "key-unknown", nil, "50"},
+		{"Tier rule", "GBP", "USD", "key-gold", nil, "30"},
+		{"Tier pair rule", "EUR", "USD", "key-gold", &large, "15"},
+		{"API key rule", "EUR", "USD", "key-vip", nil, "2.5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BpsFor(tt.from, tt.to, ClientFor(tt.apiKey), tt.amount); got.String() != tt.want {
+				t.Errorf("BpsFor() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestQuote checks the bid and ask prices around the mid rate
+func TestQuote(t *testing.T) {
+	if err := Init(testConfig()); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+
+	price := Quote(decimal.MustParse("1.0850"), "EUR", "USD", Client{}, nil)
+	if price.Bid.String() != "1.08283" || price.Ask.String() != "1.08717" || price.Mid.String() != "1.0850" {
+		t.Errorf("Unexpected price: bid %s, mid %s, ask %s", price.Bid, price.Mid, price.Ask)
+	}
+
+	if err := Init(config.SpreadConfig{}); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	price = Quote(decimal.MustParse("1.0850"), "EUR", "USD", Client{}, nil)
+	if !price.Bid.Equal(price.Mid) || !price.Ask.Equal(price.Mid) || Enabled() {
+		t.Errorf("Expected no spread without rules, got bid %s, ask %s", price.Bid, price.Ask)
+	}
+}
+
+// TestInitInvalidRules checks the validation of the rules
+func TestInitInvalidRules(t *testing.T) {
+	e.SetCatalogue(e.ErrorMap{"eSpRp1": {Message: "pair %d %s"}, "eSpRb1": {Message: "bps %d %s"}, "eSpRa1": {Message: "band %d"}})
+
+	tests := map[string]config.SpreadRule{
+		"eSpRp1": {Pair: "EUR/", Bps: decimal.NewFromInt(10)},
+		"eSpRb1": {Pair: "*", Bps: decimal.NewFromInt(10000)},
+		"eSpRa1": {Pair: "*", MinAmount: decimal.NewFromInt(100), MaxAmount: decimal.NewFromInt(50)},
+	}
+	for code, spreadRule := range tests {
+		err := Init(config.SpreadConfig{Rules: []config.SpreadRule{spreadRule}})
+		if err == nil || e.FromError(err).GetCode() != code {
+			t.Errorf("Expected error %s, got %v", code, err)
+		}
+	}
+}
@@ -47,6 +47,33 @@ func (s *Stats) GetStats() map[string]interface{} {
 	}
 }
 
+// Snapshot is a copy of the statistics, with typed counters
+type Snapshot struct {
+	HitCount     uint64
+	RequestCount uint64
+	ErrorCount   uint64
+	FailCount    uint64
+	PathCount    map[string]uint64
+}
+
+// GetSnapshot retrieves a typed copy of the current statistics in a thread-safe way
+func (s *Stats) GetSnapshot() Snapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pathCount := make(map[string]uint64, len(s.pathCount))
+	for path, count := range s.pathCount {
+		pathCount[path] = count
+	}
+	return Snapshot{
+		HitCount:     s.hitCount,
+		RequestCount: s.requestCount,
+		ErrorCount:   s.errorCount,
+		FailCount:    s.failCount,
+		PathCount:    pathCount,
+	}
+}
+
 // IncHitCount increments the global hit count (valid requests or not)
 func (s *Stats) IncHitCount() {
 	s.mu.Lock()
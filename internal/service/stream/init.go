@@ -0,0 +1,215 @@
+package stream
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"fx-service/internal/service/ratecache"
+	"fx-service/pkg/config"
+	c "fx-service/pkg/console"
+	"fx-service/pkg/e"
+	util "fx-service/pkg/helpers"
+)
+
+const (
+	defaultHeartbeat    = 15 * time.Second // Used when the configured heartbeat is not positive
+	defaultWriteTimeout = 10 * time.Second // Used when the configured write timeout is not positive
+)
+
+// FetchFunc gets the latest rates for a base currency. Rates which are fetched from a provider are saved in the
+// cache, which pushes them to the streams.
+type FetchFunc func(from string, to []string) error
+
+// manager holds the open streams, and refreshes the streamed pairs in the background
+type manager struct {
+	maxPairs     int
+	heartbeat    time.Duration
+	writeTimeout time.Duration
+	refresh      time.Duration
+	fetch        FetchFunc
+	mu           sync.Mutex
+	streams      map[*Stream]struct{}
+	stop         chan struct{}
+	wg           sync.WaitGroup
+}
+
+var (
+	instance   *manager
+	instanceMu sync.RWMutex
+)
+
+// now returns the current time. Replaced in tests.
+var now = func() time.Time { return time.Now().UTC() }
+
+// Init starts refreshing the streamed pairs with fetch. Does nothing if streaming is disabled.
+func Init(cfg config.StreamConfig, fetch FetchFunc) error {
+	if !cfg.Enabled {
+		c.Info("Rate streaming is disabled")
+		return nil
+	}
+
+	m := &manager{
+		maxPairs:     cfg.MaxPairs,
+		heartbeat:    time.Duration(cfg.HeartbeatSec) * time.Second,
+		writeTimeout: time.Duration(cfg.WriteTimeoutSec) * time.Second,
+		refresh:      time.Duration(cfg.RefreshSec) * time.Second,
+		fetch:        fetch,
+		streams:      make(map[*Stream]struct{}),
+		stop:         make(chan struct{}),
+	}
+	if m.heartbeat <= 0 {
+		m.heartbeat = defaultHeartbeat
+	}
+	if m.writeTimeout <= 0 {
+		m.writeTimeout = defaultWriteTimeout
+	}
+
+	if m.refresh > 0 && fetch != nil {
+		m.wg.Add(1)
+		go m.refreshLoop()
+	}
+
+	instanceMu.Lock()
+	previous := instance
+	instance = m
+	instanceMu.Unlock()
+	if previous != nil {
+		previous.close()
+	}
+
+	c.Successf("Rate streaming is enabled, with up to %d pairs per stream", m.maxPairs)
+	return nil
+}
+
+// Enabled returns true if streams can be opened
+func Enabled() bool {
+	instanceMu.RLock()
+	defer instanceMu.RUnlock()
+	return instance != nil
+}
+
+// Close ends the open streams, and stops refreshing. Does nothing if streaming is disabled.
+func Close() {
+	instanceMu.Lock()
+	defer instanceMu.Unlock()
+	if instance == nil {
+		return
+	}
+	instance.close()
+	instance = nil
+}
+
+// getManager returns the configured manager, or an error if streaming is disabled
+func getManager() (*manager, error) {
+	instanceMu.RLock()
+	defer instanceMu.RUnlock()
+	if instance == nil {
+		return nil, e.FromCode("eStDs1")
+	}
+	return instance, nil
+}
+
+func (m *manager) close() {
+	close(m.stop)
+	m.wg.Wait()
+}
+
+// Open opens a stream of the updates of a base currency against each quote currency
+func Open(from string, to []string) (*Stream, error) {
+	m, err := getManager()
+	if err != nil {
+		return nil, err
+	}
+
+	var quotes []string
+	for _, quote := range to {
+		if !util.SliceContains(quotes, quote) {
+			quotes = append(quotes, quote)
+		}
+	}
+	if m.maxPairs > 0 && len(quotes) > m.maxPairs {
+		return nil, e.FromCode("eStLm1", len(quotes), m.maxPairs)
+	}
+
+	s := &Stream{
+		m:      m,
+		base:   from,
+		quotes: quotes,
+		sub:    ratecache.GetInstance().Subscribe(from, quotes),
+	}
+	m.mu.Lock()
+	m.streams[s] = struct{}{}
+	m.mu.Unlock()
+	return s, nil
+}
+
+// Count returns the number of open streams. Returns 0 if streaming is disabled.
+func Count() int {
+	instanceMu.RLock()
+	m := instance
+	instanceMu.RUnlock()
+	if m == nil {
+		return 0
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.streams)
+}
+
+func (m *manager) remove(s *Stream) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.streams, s)
+}
+
+// refreshLoop fetches the streamed pairs periodically, so that the streams get new rates as the cached ones expire,
+// even when nobody requests them
+func (m *manager) refreshLoop() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(m.refresh)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+			m.refreshPairs()
+		}
+	}
+}
+
+// refreshPairs fetches the streamed quote currencies, once per base currency
+func (m *manager) refreshPairs() {
+	m.mu.Lock()
+	quotesByBase := make(map[string][]string)
+	for s := range m.streams {
+		quotes := quotesByBase[s.base]
+		for _, quote := range s.quotes {
+			if !util.SliceContains(quotes, quote) {
+				quotes = append(quotes, quote)
+			}
+		}
+		quotesByBase[s.base] = quotes
+	}
+	m.mu.Unlock()
+
+	for base, quotes := range quotesByBase {
+		sort.Strings(quotes)
+		if err := m.fetch(base, quotes); err != nil {
+			e.FromError(err).Print(-1, 0)
+		}
+	}
+}
+
+// IsRequestError returns true if the stream could not be opened because of the request, rather than a failure
+func IsRequestError(err error) bool {
+	switch e.FromError(err).GetCode() {
+	case "eStLm1", "eStWs1":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,156 @@
+// Package stream pushes rate updates to clients over Server-Sent Events or WebSocket, as they are saved in the cache
+package stream
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"fx-service/internal/service/ratecache"
+	"fx-service/pkg/decimal"
+)
+
+// Message types
+const (
+	MessageRate      = "rate"      // A rate saved in the cache (or already cached, when the stream opens)
+	MessageHeartbeat = "heartbeat" // Sent when nothing else was, so that clients and proxies keep the connection open
+)
+
+// Message is a single message of a stream, sent as an SSE event or a WebSocket text message
+type Message struct {
+	Type    string           `json:"type"`
+	Base    string           `json:"base,omitempty"`
+	Quote   string           `json:"quote,omitempty"`
+	Rate    *decimal.Decimal `json:"rate,omitempty"`
+	Skipped int              `json:"skipped,omitempty"` // Older updates of the pair not sent, because the client did not keep up
+	Time    time.Time        `json:"time"`
+}
+
+// Stream is an open stream of rate updates, for a base currency and a set of quote currencies
+type Stream struct {
+	m      *manager
+	base   string
+	quotes []string
+	sub    *ratecache.Subscriber
+}
+
+// Base returns the base currency of the stream
+func (s *Stream) Base() string {
+	return s.base
+}
+
+// Quotes returns the quote currencies of the stream
+func (s *Stream) Quotes() []string {
+	return s.quotes
+}
+
+// WriteTimeout returns how long a single write may block, before the client is considered gone
+func (s *Stream) WriteTimeout() time.Duration {
+	return s.m.writeTimeout
+}
+
+// Close stops receiving the updates. Must be called once the stream is no longer used.
+func (s *Stream) Close() {
+	s.sub.Close()
+	s.m.remove(s)
+}
+
+// Run sends the cached rates of the stream's pairs, then every update, until done is closed, the service stops,
+// or send fails. A heartbeat is sent whenever nothing else was for the heartbeat interval.
+// Updates never wait for a slow client: only the latest update of each pair is kept until it can be sent.
+func (s *Stream) Run(done <-chan struct{}, send func(Message) error) error {
+	cache := ratecache.GetInstance()
+	for _, quote := range s.quotes {
+		if entry := cache.GetEntry(s.base, quote); entry != nil {
+			if err := send(rateMessage(entry.Base, entry.Quote, entry.Rate, entry.SetAt, 0)); err != nil {
+				return err
+			}
+		}
+	}
+
+	heartbeat := time.NewTimer(s.m.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-done:
+			return nil
+		case <-s.m.stop:
+			return nil
+		case <-heartbeat.C:
+			if err := send(Message{Type: MessageHeartbeat, Time: now()}); err != nil {
+				return err
+			}
+		case <-s.sub.Ready():
+			for _, update := range s.sub.Next() {
+				if err := send(rateMessage(update.Base, update.Quote, update.Rate, update.Time, update.Skipped)); err != nil {
+					return err
+				}
+			}
+		}
+		heartbeat.Reset(s.m.heartbeat)
+	}
+}
+
+// ServeSSE runs the stream as Server-Sent Events. Each event is passed to write, which must flush it to the client.
+func (s *Stream) ServeSSE(done <-chan struct{}, write func([]byte) error) error {
+	return s.Run(done, func(msg Message) error {
+		event, err := EncodeSSE(msg)
+		if err != nil {
+			return err
+		}
+		return write(event)
+	})
+}
+
+// ServeWebSocket runs the stream over a WebSocket, until the client disconnects or the service stops,
+// and closes the connection
+func (s *Stream) ServeWebSocket(ws *WebSocket) error {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = ws.ReadLoop()
+	}()
+
+	err := s.Run(done, func(msg Message) error {
+		data, err := json.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		return ws.WriteText(data)
+	})
+
+	code := CloseNormal
+	if err != nil {
+		code = CloseGoingAway
+	}
+	_ = ws.Close(code)
+	<-done
+	return err
+}
+
+// EncodeSSE formats a message as a Server-Sent Event, named after the message type
+func EncodeSSE(msg Message) ([]byte, error) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	var event bytes.Buffer
+	event.WriteString("event: ")
+	event.WriteString(msg.Type)
+	event.WriteString("\ndata: ")
+	event.Write(data)
+	event.WriteString("\n\n")
+	return event.Bytes(), nil
+}
+
+func rateMessage(base, quote string, rate decimal.Decimal, at time.Time, skipped int) Message {
+	return Message{
+		Type:    MessageRate,
+		Base:    base,
+		Quote:   quote,
+		Rate:    &rate,
+		Skipped: skipped,
+		Time:    at.UTC(),
+	}
+}
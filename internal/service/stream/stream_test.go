@@ -0,0 +1,262 @@
+package stream
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"fx-service/internal/service/ratecache"
+	"fx-service/pkg/decimal"
+	"fx-service/pkg/e"
+)
+
+// testCatalogue has the error codes checked by the tests
+var testCatalogue = e.ErrorMap{
+	"eStDs1": {Message: "Rate streaming is disabled"},
+	"eStLm1": {Message: "Too many pairs in the stream (%d). The maximum is %d"},
+	"eStWs1": {Message: "Invalid WebSocket handshake: %s"},
+}
+
+// testManager replaces the configured manager with one using short intervals
+func testManager(t *testing.T, heartbeat time.Duration) *manager {
+	t.Helper()
+	m := &manager{
+		maxPairs:     2,
+		heartbeat:    heartbeat,
+		writeTimeout: time.Second,
+		streams:      make(map[*Stream]struct{}),
+		stop:         make(chan struct{}),
+	}
+	instanceMu.Lock()
+	instance = m
+	instanceMu.Unlock()
+	t.Cleanup(Close)
+	return m
+}
+
+// TestOpen checks the pair limit, and that streams are counted until closed
+func TestOpen(t *testing.T) {
+	e.SetCatalogue(testCatalogue)
+	Close()
+	if _, err := Open("USD", []string{"EUR"}); e.FromError(err).GetCode() != "eStDs1" {
+		t.Fatalf("Expected eStDs1 while disabled, got %v", err)
+	}
+
+	testManager(t, time.Minute)
+	if _, err := Open("USD", []string{"EUR", "GBP", "JPY"}); !IsRequestError(err) {
+		t.Fatalf("Expected a request error for 3 pairs, got %v", err)
+	}
+	s, err := Open("USD", []string{"EUR", "GBP", "EUR"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Quotes()) != 2 || Count() != 1 {
+		t.Errorf("Expected 1 stream of 2 pairs, got %d streams of %v", Count(), s.Quotes())
+	}
+	s.Close()
+	if Count() != 0 || ratecache.GetInstance().Subscribers() != 0 {
+		t.Errorf("Expected no streams and subscribers after closing, got %d and %d", Count(),
+			ratecache.GetInstance().Subscribers())
+	}
+}
+
+// TestRun checks a stream sends the cached rates, then the updates and heartbeats
+func TestRun(t *testing.T) {
+	e.SetCatalogue(testCatalogue)
+	testManager(t, 50*time.Millisecond)
+	cache := ratecache.GetInstance()
+	cache.Clear()
+	cache.SetExpiry(3600)
+	cache.Set("USD", "EUR", decimal.MustParse("0.9"))
+
+	s, err := Open("USD", []string{"EUR", "GBP"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	messages := make(chan Message, 10)
+	result := make(chan error, 1)
+	go func() {
+		result <- s.Run(nil, func(msg Message) error {
+			messages <- msg
+			return nil
+		})
+	}()
+
+	next := func() Message {
+		select {
+		case msg := <-messages:
+			return msg
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timed out waiting for a message")
+			return Message{}
+		}
+	}
+
+	if msg := next(); msg.Type != MessageRate || msg.Quote != "EUR" || msg.Rate.String() != "0.9" {
+		t.Errorf("Expected the cached USD/EUR rate first, got %+v", msg)
+	}
+	cache.Set("USD", "GBP", decimal.MustParse("0.8"))
+	cache.Set("USD", "JPY", decimal.MustParse("150"))
+	if msg := next(); msg.Type != MessageRate || msg.Quote != "GBP" || msg.Rate.String() != "0.8" {
+		t.Errorf("Expected the USD/GBP update, got %+v", msg)
+	}
+	if msg := next(); msg.Type != MessageHeartbeat {
+		t.Errorf("Expected a heartbeat, got %+v", msg)
+	}
+
+	Close()
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Errorf("Expected the stream to end cleanly, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the stream to end when the service stops")
+	}
+}
+
+// TestEncodeSSE checks the format of an event
+func TestEncodeSSE(t *testing.T) {
+	rate := decimal.MustParse("1.25")
+	at := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	event, err := EncodeSSE(Message{Type: MessageRate, Base: "USD", Quote: "EUR", Rate: &rate, Time: at})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "event: rate\ndata: {\"type\":\"rate\",\"base\":\"USD\",\"quote\":\"EUR\",\"rate\":\"1.25\",\"time\":\"2024-06-01T12:00:00Z\"}\n\n"
+	if string(event) != expected {
+		t.Errorf("Expected %q, got %q", expected, event)
+	}
+}
+
+// TestHandshake checks the upgrade detection and the accept key (the example of RFC 6455, section 1.3)
+func TestHandshake(t *testing.T) {
+	e.SetCatalogue(testCatalogue)
+	if !IsWebSocket("websocket", "keep-alive, Upgrade") || IsWebSocket("", "Upgrade") || IsWebSocket("websocket", "close") {
+		t.Error("Expected only websocket upgrades to be detected")
+	}
+	if err := CheckHandshake("13", "dGhlIHNhbXBsZSBub25jZQ=="); err != nil {
+		t.Errorf("Expected a valid handshake, got %v", err)
+	}
+	if err := CheckHandshake("8", "dGhlIHNhbXBsZSBub25jZQ=="); !IsRequestError(err) {
+		t.Errorf("Expected a request error for version 8, got %v", err)
+	}
+	if err := CheckHandshake("13", "short"); !IsRequestError(err) {
+		t.Errorf("Expected a request error for an invalid key, got %v", err)
+	}
+	if key := AcceptKey("dGhlIHNhbXBsZSBub25jZQ=="); key != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("Expected the RFC 6455 accept key, got %s", key)
+	}
+}
+
+// writeClientFrame sends a masked frame, as a client does
+func writeClientFrame(t *testing.T, conn net.Conn, op byte, payload []byte) {
+	t.Helper()
+	mask := [4]byte{1, 2, 3, 4}
+	frame := []byte{0x80 | op, 0x80 | byte(len(payload))}
+	frame = append(frame, mask[:]...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	if _, err := conn.Write(frame); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// readServerFrame reads an unmasked frame with a short payload, as sent by the server
+func readServerFrame(t *testing.T, reader *bufio.Reader) (byte, []byte) {
+	t.Helper()
+	var header [2]byte
+	if _, err := io.ReadFull(reader, header[:]); err != nil {
+		t.Fatal(err)
+	}
+	length := int(header[1] & 0x7F)
+	if length == 126 {
+		var extended [2]byte
+		if _, err := io.ReadFull(reader, extended[:]); err != nil {
+			t.Fatal(err)
+		}
+		length = int(binary.BigEndian.Uint16(extended[:]))
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		t.Fatal(err)
+	}
+	return header[0] & 0x0F, payload
+}
+
+// TestServeWebSocket checks the handshake, the messages, ping answers and the closing handshake
+func TestServeWebSocket(t *testing.T) {
+	e.SetCatalogue(testCatalogue)
+	testManager(t, time.Minute)
+	cache := ratecache.GetInstance()
+	cache.Clear()
+	cache.SetExpiry(3600)
+	cache.Set("USD", "EUR", decimal.MustParse("0.91"))
+
+	s, err := Open("USD", []string{"EUR"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	server, client := net.Pipe()
+	defer client.Close()
+	result := make(chan error, 1)
+	go func() {
+		ws, err := Accept(server, bufio.NewReader(server), "dGhlIHNhbXBsZSBub25jZQ==", time.Second)
+		if err != nil {
+			result <- err
+			return
+		}
+		result <- s.ServeWebSocket(ws)
+	}()
+
+	reader := bufio.NewReader(client)
+	var response strings.Builder
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.WriteString(line)
+		if line == "\r\n" {
+			break
+		}
+	}
+	if !strings.HasPrefix(response.String(), "HTTP/1.1 101") ||
+		!strings.Contains(response.String(), "Sec-WebSocket-Accept: s3pPLMBiTxaQ9kYGzzhZRbK+xOo=") {
+		t.Fatalf("Expected a 101 response with the accept key, got %q", response.String())
+	}
+
+	op, payload := readServerFrame(t, reader)
+	var msg Message
+	if err := json.Unmarshal(payload, &msg); op != opText || err != nil || msg.Quote != "EUR" || msg.Rate.String() != "0.91" {
+		t.Errorf("Expected a text message with the USD/EUR rate, got opcode %d and %s", op, payload)
+	}
+
+	writeClientFrame(t, client, opPing, []byte("hi"))
+	if op, payload := readServerFrame(t, reader); op != opPong || string(payload) != "hi" {
+		t.Errorf("Expected a pong with the ping payload, got opcode %d and %q", op, payload)
+	}
+
+	writeClientFrame(t, client, opClose, []byte{0x03, 0xE8})
+	if op, payload := readServerFrame(t, reader); op != opClose || binary.BigEndian.Uint16(payload) != CloseNormal {
+		t.Errorf("Expected the close frame to be echoed, got opcode %d and %v", op, payload)
+	}
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Errorf("Expected the stream to end cleanly, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the stream to end when the client closes")
+	}
+}
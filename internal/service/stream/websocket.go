@@ -0,0 +1,237 @@
+package stream
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"fx-service/pkg/e"
+)
+
+// WebSocket handshake headers
+const (
+	HeaderWebSocketKey     = "Sec-WebSocket-Key"
+	HeaderWebSocketVersion = "Sec-WebSocket-Version"
+	HeaderWebSocketAccept  = "Sec-WebSocket-Accept"
+)
+
+// websocketGUID is appended to the client key to compute the accept key (RFC 6455, section 1.3)
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// Frame opcodes
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+// Close status codes
+const (
+	CloseNormal        = 1000
+	CloseGoingAway     = 1001
+	CloseProtocolError = 1002
+	CloseTooBig        = 1009
+)
+
+// maxFrameSize is the largest frame accepted from a client. Clients are not expected to send anything but control frames.
+const maxFrameSize = 4096
+
+var errFrameTooBig = errors.New("websocket frame too big")
+var errProtocol = errors.New("websocket protocol error")
+
+// IsWebSocket returns true if the request headers ask to upgrade the connection to a WebSocket
+func IsWebSocket(upgrade, connection string) bool {
+	if !strings.EqualFold(strings.TrimSpace(upgrade), "websocket") {
+		return false
+	}
+	for _, token := range strings.Split(connection, ",") {
+		if strings.EqualFold(strings.TrimSpace(token), "upgrade") {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckHandshake checks the version and key of a WebSocket upgrade request
+func CheckHandshake(version, key string) error {
+	if strings.TrimSpace(version) != "13" {
+		return e.FromCode("eStWs1", "unsupported "+HeaderWebSocketVersion+" '"+version+"'. Use 13")
+	}
+	decoded, err := base64.StdEncoding.DecodeString(key)
+	if err != nil || len(decoded) != 16 {
+		return e.FromCode("eStWs1", "invalid "+HeaderWebSocketKey)
+	}
+	return nil
+}
+
+// AcceptKey returns the Sec-WebSocket-Accept header value for a client key
+func AcceptKey(key string) string {
+	hash := sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// WebSocket is the server side of a WebSocket connection, which only sends text messages
+type WebSocket struct {
+	conn         net.Conn
+	reader       *bufio.Reader
+	writeTimeout time.Duration
+	mu           sync.Mutex // Serialises the writes, as control frames are answered by the read loop
+	closeOnce    sync.Once
+}
+
+// Accept completes the handshake of a hijacked connection, whose upgrade request was checked with CheckHandshake.
+// The reader must hold the bytes already buffered from the connection, if any.
+func Accept(conn net.Conn, reader *bufio.Reader, key string, writeTimeout time.Duration) (*WebSocket, error) {
+	ws := &WebSocket{conn: conn, reader: reader, writeTimeout: writeTimeout}
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		HeaderWebSocketAccept + ": " + AcceptKey(key) + "\r\n\r\n"
+
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if err := ws.write([]byte(response)); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return ws, nil
+}
+
+// WriteText sends a text message
+func (ws *WebSocket) WriteText(data []byte) error {
+	return ws.writeFrame(opText, data)
+}
+
+// ReadLoop reads the frames sent by the client, answering pings, until the client closes the connection or it fails.
+// Messages from the client are ignored.
+func (ws *WebSocket) ReadLoop() error {
+	for {
+		op, payload, err := ws.readFrame()
+		if err != nil {
+			switch {
+			case errors.Is(err, errFrameTooBig):
+				_ = ws.Close(CloseTooBig)
+			case errors.Is(err, errProtocol):
+				_ = ws.Close(CloseProtocolError)
+			}
+			return err
+		}
+
+		switch op {
+		case opClose:
+			// Echo the status code, as required by the closing handshake
+			if len(payload) >= 2 {
+				_ = ws.Close(int(binary.BigEndian.Uint16(payload)))
+			} else {
+				_ = ws.Close(CloseNormal)
+			}
+			return nil
+		case opPing:
+			if err := ws.writeFrame(opPong, payload); err != nil {
+				return err
+			}
+		case opPong, opText, opBinary, opContinuation:
+		default:
+			_ = ws.Close(CloseProtocolError)
+			return errProtocol
+		}
+	}
+}
+
+// Close sends a close frame with the status code, and closes the connection. Only the first call has an effect.
+func (ws *WebSocket) Close(code int) error {
+	err := io.ErrClosedPipe
+	ws.closeOnce.Do(func() {
+		payload := make([]byte, 2)
+		binary.BigEndian.PutUint16(payload, uint16(code))
+		_ = ws.writeFrame(opClose, payload)
+		err = ws.conn.Close()
+	})
+	return err
+}
+
+// writeFrame sends a single, unfragmented frame. Server frames are not masked.
+func (ws *WebSocket) writeFrame(op byte, payload []byte) error {
+	header := make([]byte, 2, 10)
+	header[0] = 0x80 | op // FIN
+	switch length := len(payload); {
+	case length <= 125:
+		header[1] = byte(length)
+	case length <= 0xFFFF:
+		header[1] = 126
+		header = binary.BigEndian.AppendUint16(header, uint16(length))
+	default:
+		header[1] = 127
+		header = binary.BigEndian.AppendUint64(header, uint64(length))
+	}
+
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	return ws.write(append(header, payload...))
+}
+
+// write sends bytes within the write timeout. The caller must hold the lock.
+func (ws *WebSocket) write(data []byte) error {
+	if err := ws.conn.SetWriteDeadline(time.Now().Add(ws.writeTimeout)); err != nil {
+		return err
+	}
+	_, err := ws.conn.Write(data)
+	return err
+}
+
+// readFrame reads a single frame, and unmasks its payload
+func (ws *WebSocket) readFrame() (byte, []byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(ws.reader, header[:]); err != nil {
+		return 0, nil, err
+	}
+	op := header[0] & 0x0F
+	masked := header[1]&0x80 != 0
+	length := uint64(header[1] & 0x7F)
+
+	switch length {
+	case 126:
+		var extended [2]byte
+		if _, err := io.ReadFull(ws.reader, extended[:]); err != nil {
+			return 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(extended[:]))
+	case 127:
+		var extended [8]byte
+		if _, err := io.ReadFull(ws.reader, extended[:]); err != nil {
+			return 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(extended[:])
+	}
+
+	// Clients must mask their frames (RFC 6455, section 5.1)
+	if !masked {
+		return 0, nil, errProtocol
+	}
+	if length > maxFrameSize {
+		return 0, nil, errFrameTooBig
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(ws.reader, mask[:]); err != nil {
+		return 0, nil, err
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(ws.reader, payload); err != nil {
+		return 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return op, payload, nil
+}
@@ -0,0 +1,100 @@
+// Package validate Checks the currencies, amounts and rounding modes of requests, the same way for every API:
+// REST, gRPC, GraphQL and JSON-RPC. The errors are catalogued, with a 400 status.
+package validate
+
+import (
+	"strings"
+
+	"fx-service/pkg/config"
+	"fx-service/pkg/decimal"
+	"fx-service/pkg/e"
+)
+
+// CurrencyPair checks for case sensitivity and whether the given base and quote currencies are supported
+func CurrencyPair(cfg *config.Config, ccyBase, ccyQuote string) (string, string, error) {
+	if ccyBase == "" || ccyQuote == "" {
+		return "", "", e.FromCode("eRqCm1")
+	}
+
+	// Check if we need to make the currencies uppercase
+	if !cfg.CurrenciesCaseSensitive {
+		ccyBase = strings.ToUpper(ccyBase)
+		ccyQuote = strings.ToUpper(ccyQuote)
+	}
+
+	if !cfg.IsCurrencySupported(ccyBase) {
+		return "", "", e.FromCode("eRqCb1", ccyBase)
+	}
+
+	if !cfg.IsCurrencySupported(ccyQuote) {
+		return "", "", e.FromCode("eRqCq1", ccyQuote)
+	}
+
+	return ccyBase, ccyQuote, nil
+}
+
+// BaseAndQuotes checks for case sensitivity and whether the given base and quote currencies are supported.
+// The quote currencies are returned in a new list.
+func BaseAndQuotes(cfg *config.Config, ccyBase string, quotes []string) (string, []string, error) {
+	if ccyBase == "" || len(quotes) == 0 {
+		return "", nil, e.FromCode("eRqCm1")
+	}
+
+	ccyQuoteList := make([]string, len(quotes))
+	copy(ccyQuoteList, quotes)
+
+	// Check if we need to make the currencies uppercase
+	if !cfg.CurrenciesCaseSensitive {
+		ccyBase = strings.ToUpper(ccyBase)
+		for i, ccy := range ccyQuoteList {
+			ccyQuoteList[i] = strings.ToUpper(ccy)
+		}
+	}
+
+	// Validate the currencies
+	if !cfg.IsCurrencySupported(ccyBase) {
+		return "", nil, e.FromCode("eRqCb1", ccyBase)
+	}
+	for _, ccy := range ccyQuoteList {
+		if !cfg.IsCurrencySupported(ccy) {
+			return "", nil, e.FromCode("eRqCq1", ccy)
+		}
+	}
+
+	return ccyBase, ccyQuoteList, nil
+}
+
+// Currency checks for case sensitivity and whether a single currency is supported
+func Currency(cfg *config.Config, ccy string) (string, error) {
+	if !cfg.CurrenciesCaseSensitive {
+		ccy = strings.ToUpper(ccy)
+	}
+	if !cfg.IsCurrencySupported(ccy) {
+		return "", e.FromCode("eRqCc1", ccy)
+	}
+	return ccy, nil
+}
+
+// Amount parses the amount to convert, as an exact decimal number
+func Amount(amount string) (decimal.Decimal, error) {
+	if amount == "" {
+		return decimal.Zero, e.FromCode("eRqAm1")
+	}
+	value, err := decimal.Parse(amount)
+	if err != nil {
+		return decimal.Zero, e.FromCode("eRqAm2", amount)
+	}
+	return value, nil
+}
+
+// Rounding parses the rounding mode of a conversion. Defaults to the configured one.
+func Rounding(cfg *config.Config, name string) (config.Rounding, error) {
+	if name == "" {
+		return cfg.Rounding, nil
+	}
+	mode, err := config.ParseRounding(name)
+	if err != nil {
+		return cfg.Rounding, e.FromCode("eRqRd1", name, strings.Join(config.RoundingNameList(), ", "))
+	}
+	return mode, nil
+}
@@ -11,12 +11,80 @@ var defaultConfigMap = map[string]interface{}{
 	"apiTimeout":              10,      // 10 seconds
 	"cacheExpirySec":          60 * 60, // 1 hour, in seconds
 	"showProvider":            false,   // Whether to display the provider name in each response
+	// Cache expiry overrides in seconds, keyed by pair "EUR/USD", base "HKD/*", quote "*/HKD", currency "HKD" or "*"
+	"cacheTtlRules": map[string]int{},
+	// How converted amounts are rounded to the minor units of the target currency: "half-even", "half-up" or "truncate"
+	"rounding": "half-even",
+	// Whether rates and amounts are serialised as JSON numbers (floats), instead of exact decimal strings
+	"floatOutput":  false,
+	"maxBodyBytes": 1 << 20, // Largest request body, in bytes (1 MiB). Larger ones are refused with a 413
 	"RateLimiter": map[string]interface{}{ // Rate limit configuration (requests to us)
 		"Enabled":     true, // Whether rate limiting is enabled
 		"MaxRequests": 10,   // Maximum number of requests within the timeframe period
 		"Timeframe":   30,   // Timeframe period in seconds for the rate limit
 	},
+	"Admin": map[string]interface{}{ // Administrative endpoints, under /admin
+		"Enabled": false, // Whether the admin endpoints are registered
+		"Token":   "",    // Bearer token required to call the admin endpoints
+	},
+	"History": map[string]interface{}{ // Historic rate storage
+		"Enabled":             false,        // Whether fetched rates are stored
+		"Driver":              "sqlite",     // "sqlite" or "postgres"
+		"DSN":                 "history.db", // File path for sqlite, or a connection string for postgres
+		"RetentionDays":       0,            // Delete records older than this. 0 keeps everything
+		"DownsampleAfterDays": 0,            // Downsample records older than this. 0 disables downsampling
+		"DownsampleInterval":  "24h",        // When downsampling, keep the last record per pair in each interval
+		"BufferSize":          1000,         // Records waiting to be written, before new ones are dropped
+	},
+	"Spreads": map[string]interface{}{ // Bid/ask spreads, applied to the mid rates
+		"Enabled": false,                      // Whether bid and ask prices are returned
+		"Clients": map[string]string{},        // Client tiers, keyed by API key
+		"Rules":   []map[string]interface{}{}, // Markups by pair, client and amount band. The most specific rule wins
+	},
+	"Quotes": map[string]interface{}{ // Locked quotes, to honour a rate later
+		"Enabled":        false,       // Whether quotes can be created
+		"Driver":         "memory",    // "memory" or "sqlite"
+		"DSN":            "quotes.db", // File path for sqlite
+		"TTLSec":         30,          // How long a quote can be accepted for
+		"RetentionHours": 24,          // Delete quotes this long after they expire
+	},
+	"Overrides": map[string]interface{}{ // Manual rate overrides, set with the admin endpoints
+		"File":     "overrides.json",        // Overrides are saved in this file, to survive restarts
+		"AuditLog": "overrides-audit.jsonl", // Every change is appended to this file
+	},
+	"Alerts": map[string]interface{}{ // Rate alerts, sent to webhooks
+		"Enabled":      false,       // Whether webhooks can be registered
+		"Driver":       "memory",    // "memory" or "sqlite"
+		"DSN":          "alerts.db", // File path for sqlite
+		"PollSec":      60,          // How often the subscribed pairs are fetched. 0 to only use the live fetches
+		"TimeoutSec":   10,          // Timeout of a single delivery attempt
+		"MaxAttempts":  6,           // Delivery attempts, before an event is dropped
+		"RetryDelayMs": 1000,        // Delay before the first retry. Doubled after every failed attempt
+		"MaxPerClient": 100,         // Subscriptions per API key. 0 for no limit
+	},
+	"Stream": map[string]interface{}{ // Streaming of rate updates, over Server-Sent Events or WebSocket
+		"Enabled":         false, // Whether the /stream endpoint is registered
+		"MaxPairs":        20,    // Pairs per stream. 0 for no limit
+		"HeartbeatSec":    15,    // A heartbeat is sent when nothing else was for this long
+		"WriteTimeoutSec": 10,    // Clients which do not read for this long are disconnected
+		"RefreshSec":      60,    // How often the streamed pairs are fetched. 0 to only use the other fetches
+	},
+	"GRPC": map[string]interface{}{ // gRPC API, served next to the REST router
+		"Enabled": false, // Whether the gRPC server is started
+		"Port":    9090,  // The port of the gRPC server. Must differ from the REST port
+	},
+	"GraphQL": map[string]interface{}{ // The /graphql endpoint
+		"Enabled":       false, // Whether the /graphql endpoint is registered
+		"MaxComplexity": 200,   // Queries costing more are rejected. 0 for no limit
+	},
+	"JSONRPC": map[string]interface{}{ // The JSON-RPC 2.0 endpoint
+		"Enabled": false, // Whether the /rpc endpoint is registered
+	},
+	"OpenAPI": map[string]interface{}{ // The OpenAPI document, and the docs UI
+		"Enabled": true,  // Whether the /openapi.json endpoint is registered
+		"Docs":    false, // Whether the /docs UI is registered
+	},
 	"Mode":   "random", // The strategy to fetch exchange rates from different providers
-	"Router": "Fiber",  // The http router framework to use for the API
+	"Router": "Fiber",  // The http router framework to use for the API: Fiber, Gin, chi or http
 	"Port":   8080,     // The port to listen on for incoming HTTP requests
 }
@@ -34,28 +34,37 @@ func (m *Mode) String() string {
 	return [...]string{"race", "robin", "first", "random", "priority", "aggregate"}[*m]
 }
 
-// UnmarshalJSON method to convert JSON string to Mode type
-func (m *Mode) UnmarshalJSON(b []byte) error {
-	var s string
-	if err := json.Unmarshal(b, &s); err != nil {
-		return err
-	}
-
+// ParseMode converts a mode name (e.g. "robin") to its Mode value
+func ParseMode(s string) (Mode, error) {
 	switch s {
 	case "race":
-		*m = Race
+		return Race, nil
 	case "robin":
-		*m = Robin
+		return Robin, nil
 	case "first":
-		*m = First
+		return First, nil
 	case "random":
-		*m = Random
+		return Random, nil
 	case "priority":
-		*m = Priority
+		return Priority, nil
 	case "aggregate":
-		*m = Aggregate
+		return Aggregate, nil
 	default:
-		return fmt.Errorf("unsupported mode value '%s'. Use one of: %s", s, ModeNameList())
+		return First, fmt.Errorf("unsupported mode value '%s'. Use one of: %s", s, ModeNameList())
+	}
+}
+
+// UnmarshalJSON method to convert JSON string to Mode type
+func (m *Mode) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+
+	mode, err := ParseMode(s)
+	if err != nil {
+		return err
 	}
+	*m = mode
 	return nil
 }
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Rounding type - this is how converted amounts are rounded to the minor units of the target currency
+type Rounding int
+
+// Define constants for each rounding mode using iota
+const (
+	HalfEven Rounding = iota // Round half to even (banker's rounding), e.g. 2.345 -> 2.34, 2.355 -> 2.36
+	HalfUp                   // Round half away from zero, e.g. 2.345 -> 2.35
+	Truncate                 // Round towards zero, e.g. 2.349 -> 2.34
+)
+
+// RoundingNameList returns a simple list of rounding mode names
+func RoundingNameList() []string {
+	roundings := []Rounding{HalfEven, HalfUp, Truncate}
+	result := make([]string, len(roundings))
+	for i, rounding := range roundings {
+		result[i] = rounding.String()
+	}
+	return result
+}
+
+// String method to get the string representation of the rounding mode
+func (r Rounding) String() string {
+	return [...]string{"half-even", "half-up", "truncate"}[r]
+}
+
+// ParseRounding converts a rounding mode name (e.g. "half-up") to its Rounding value
+func ParseRounding(s string) (Rounding, error) {
+	switch s {
+	case "half-even":
+		return HalfEven, nil
+	case "half-up":
+		return HalfUp, nil
+	case "truncate":
+		return Truncate, nil
+	default:
+		return HalfEven, fmt.Errorf("unsupported rounding value '%s'. Use one of: %s", s, RoundingNameList())
+	}
+}
+
+// UnmarshalJSON method to convert JSON string to Rounding type
+func (r *Rounding) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+
+	rounding, err := ParseRounding(s)
+	if err != nil {
+		return err
+	}
+	*r = rounding
+	return nil
+}
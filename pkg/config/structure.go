@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fx-service/pkg/decimal"
 	"fx-service/pkg/helpers"
 	"strings"
 )
@@ -20,6 +21,101 @@ type RateLimiterConfig struct {
 	Timeframe   int  `json:"timeframe"`
 }
 
+// AdminConfig structure for the administrative endpoints (under /admin)
+type AdminConfig struct {
+	Enabled bool   `json:"enabled"`
+	Token   string `json:"token"` // Bearer token required in the Authorization header
+}
+
+// HistoryConfig structure for the historic rate store
+type HistoryConfig struct {
+	Enabled             bool   `json:"enabled"`
+	Driver              string `json:"driver"`              // "sqlite" or "postgres"
+	DSN                 string `json:"dsn"`                 // File path for sqlite, or a connection string for postgres
+	RetentionDays       int    `json:"retentionDays"`       // Records older than this are deleted. 0 keeps everything
+	DownsampleAfterDays int    `json:"downsampleAfterDays"` // Records older than this are downsampled. 0 disables it
+	DownsampleInterval  string `json:"downsampleInterval"`  // Keep the last record per pair in each interval, e.g. "24h"
+	BufferSize          int    `json:"bufferSize"`          // Records waiting to be written, before new ones are dropped
+}
+
+// SpreadRule is a markup (in basis points) applied on both sides of the mid rate, to get the bid and ask prices
+type SpreadRule struct {
+	Pair      string          `json:"pair"`      // "EUR/USD", "EUR/*", "*/USD", "EUR" or "*" (the default)
+	Tier      string          `json:"tier"`      // Only for clients of this tier. Empty for any client
+	APIKey    string          `json:"apiKey"`    // Only for the client with this API key. Empty for any client
+	MinAmount decimal.Decimal `json:"minAmount"` // Only for amounts (in the base currency) from this value
+	MaxAmount decimal.Decimal `json:"maxAmount"` // Only for amounts below this value. 0 for no upper limit
+	Bps       decimal.Decimal `json:"bps"`       // Markup in basis points (1 bps = 0.01%) on each side of the mid rate
+}
+
+// SpreadConfig structure for the bid/ask spreads
+type SpreadConfig struct {
+	Enabled bool              `json:"enabled"`
+	Clients map[string]string `json:"clients"` // Client tiers, keyed by API key (sent in the X-API-Key header)
+	Rules   []SpreadRule      `json:"rules"`
+}
+
+// QuoteConfig structure for the locked quotes
+type QuoteConfig struct {
+	Enabled        bool   `json:"enabled"`
+	Driver         string `json:"driver"`         // "memory" or "sqlite"
+	DSN            string `json:"dsn"`            // File path for sqlite
+	TTLSec         int    `json:"ttlSec"`         // How long a quote can be accepted for
+	RetentionHours int    `json:"retentionHours"` // Quotes are deleted this long after they expire
+}
+
+// OverrideConfig structure for the manual rate overrides
+type OverrideConfig struct {
+	File     string `json:"file"`     // Overrides are saved in this JSON file. Empty to keep them in memory only
+	AuditLog string `json:"auditLog"` // Every change is appended to this JSON lines file. Empty to disable it
+}
+
+// AlertConfig structure for the rate alerts, sent to webhooks
+type AlertConfig struct {
+	Enabled      bool     `json:"enabled"`
+	Driver       string   `json:"driver"`       // "memory" or "sqlite"
+	DSN          string   `json:"dsn"`          // File path for sqlite
+	PollSec      int      `json:"pollSec"`      // How often the subscribed pairs are fetched. 0 to only use the live fetches
+	TimeoutSec   int      `json:"timeoutSec"`   // Timeout of a single delivery attempt
+	MaxAttempts  int      `json:"maxAttempts"`  // Delivery attempts, before an event is dropped
+	RetryDelayMs int      `json:"retryDelayMs"` // Delay before the first retry. Doubled after every failed attempt
+	MaxPerClient int      `json:"maxPerClient"` // Subscriptions per API key. 0 for no limit
+	ClientKeys   []string `json:"clientKeys"`   // API keys allowed to subscribe. Empty for any client, on trusted networks only
+	AllowPrivate bool     `json:"allowPrivate"` // Whether callbacks may target loopback, private and link-local addresses
+}
+
+// StreamConfig structure for the streaming of rate updates, over Server-Sent Events or WebSocket
+type StreamConfig struct {
+	Enabled         bool `json:"enabled"`
+	MaxPairs        int  `json:"maxPairs"`        // Pairs per stream. 0 for no limit
+	HeartbeatSec    int  `json:"heartbeatSec"`    // A heartbeat is sent when nothing else was for this long
+	WriteTimeoutSec int  `json:"writeTimeoutSec"` // Clients which do not read for this long are disconnected
+	RefreshSec      int  `json:"refreshSec"`      // How often the streamed pairs are fetched. 0 to only use the other fetches
+}
+
+// GRPCConfig structure for the gRPC API, served next to the REST router
+type GRPCConfig struct {
+	Enabled bool   `json:"enabled"`
+	Port    uint64 `json:"port"` // Must differ from the REST port
+}
+
+// GraphQLConfig structure for the /graphql endpoint
+type GraphQLConfig struct {
+	Enabled       bool `json:"enabled"`
+	MaxComplexity int  `json:"maxComplexity"` // Queries costing more are rejected. 0 for no limit
+}
+
+// JSONRPCConfig structure for the JSON-RPC 2.0 endpoint (POST /rpc)
+type JSONRPCConfig struct {
+	Enabled bool `json:"enabled"`
+}
+
+// OpenAPIConfig structure for the OpenAPI document (GET /openapi.json) and the docs UI (GET /docs)
+type OpenAPIConfig struct {
+	Enabled bool `json:"enabled"`
+	Docs    bool `json:"docs"` // Whether the docs UI is served. Requires the document to be enabled
+}
+
 // Config - main (parent) struct for app configs
 type Config struct {
 	CurrenciesEnabled       []string                  `json:"currenciesEnabled"`
@@ -27,11 +123,26 @@ type Config struct {
 	APITimeout              int                       `json:"apiTimeout"`
 	RateLimiter             RateLimiterConfig         `json:"rateLimiter"`
 	CacheExpirySec          int                       `json:"cacheExpirySec"`
+	CacheTTLRules           map[string]int            `json:"cacheTtlRules"`
 	ShowProvider            bool                      `json:"showProvider"`
 	Mode                    Mode                      `json:"mode"`
+	Rounding                Rounding                  `json:"rounding"`
+	FloatOutput             bool                      `json:"floatOutput"`
 	Router                  string                    `json:"router"`
 	Port                    uint64                    `json:"port"`
+	MaxBodyBytes            int64                     `json:"maxBodyBytes"` // Larger request bodies are refused with a 413. 0 for no limit
 	Providers               map[string]ProviderConfig `json:"providers"`
+	Admin                   AdminConfig               `json:"admin"`
+	History                 HistoryConfig             `json:"history"`
+	Spreads                 SpreadConfig              `json:"spreads"`
+	Quotes                  QuoteConfig               `json:"quotes"`
+	Overrides               OverrideConfig            `json:"overrides"`
+	Alerts                  AlertConfig               `json:"alerts"`
+	Stream                  StreamConfig              `json:"stream"`
+	GRPC                    GRPCConfig                `json:"grpc"`
+	GraphQL                 GraphQLConfig             `json:"graphql"`
+	JSONRPC                 JSONRPCConfig             `json:"jsonRpc"`
+	OpenAPI                 OpenAPIConfig             `json:"openApi"`
 }
 
 // CurrenciesToUppercase converts all currencies, from the config, to uppercase
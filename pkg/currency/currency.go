@@ -0,0 +1,114 @@
+// Package currency ISO 4217 currency details
+package currency
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"fx-service/pkg/e"
+)
+
+// defaultMinorUnits is the number of decimal places used by most currencies
+const defaultMinorUnits = 2
+
+// Currency is the metadata of a currency
+type Currency struct {
+	Code       string   `json:"code"`
+	Numeric    string   `json:"numeric,omitempty"` // ISO 4217 numeric code, e.g. "978"
+	Name       string   `json:"name"`
+	MinorUnits int      `json:"minorUnits"` // Number of decimal places. -1 if not applicable (e.g. gold)
+	Symbol     string   `json:"symbol,omitempty"`
+	Countries  []string `json:"countries"` // ISO 3166 alpha-2 codes of the countries using the currency
+	ISO        bool     `json:"iso"`       // False for currencies only reported by providers (e.g. crypto currencies)
+}
+
+var (
+	mu        sync.RWMutex
+	catalogue = newCatalogue()
+)
+
+// newCatalogue builds the catalogue from the ISO 4217 currencies
+func newCatalogue() map[string]Currency {
+	result := make(map[string]Currency, len(isoCurrencies))
+	for _, ccy := range isoCurrencies {
+		ccy.ISO = true
+		if ccy.Countries == nil {
+			ccy.Countries = []string{}
+		}
+		result[ccy.Code] = ccy
+	}
+	return result
+}
+
+// Lookup returns the metadata of the currency, and whether it is in the catalogue
+func Lookup(code string) (Currency, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	ccy, ok := catalogue[strings.ToUpper(code)]
+	return ccy, ok
+}
+
+// IsKnown returns true if the currency is in the catalogue
+func IsKnown(code string) bool {
+	_, ok := Lookup(code)
+	return ok
+}
+
+// All returns every currency in the catalogue, sorted by code
+func All() []Currency {
+	mu.RLock()
+	defer mu.RUnlock()
+	result := make([]Currency, 0, len(catalogue))
+	for _, ccy := range catalogue {
+		result = append(result, ccy)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Code < result[j].Code })
+	return result
+}
+
+// MergeNames merges currency names reported by a provider (code => name) into the catalogue.
+// ISO 4217 entries keep their names; codes which are not in ISO 4217 (e.g. "BTC") are added with the reported name.
+func MergeNames(names map[string]string) {
+	mu.Lock()
+	defer mu.Unlock()
+	for code, name := range names {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		name = strings.TrimSpace(name)
+		if code == "" {
+			continue
+		}
+		ccy, ok := catalogue[code]
+		if !ok {
+			ccy = Currency{Code: code, MinorUnits: defaultMinorUnits, Countries: []string{}}
+		}
+		if ccy.Name == "" {
+			ccy.Name = name
+		}
+		catalogue[code] = ccy
+	}
+}
+
+// CheckCodes returns the eCyUk1 error, listing the given codes which are not in the catalogue.
+// Check the codes once the providers have merged their names, as they may add codes which are not in ISO 4217.
+func CheckCodes(codes []string) error {
+	var unknown []string
+	for _, code := range codes {
+		if !IsKnown(code) {
+			unknown = append(unknown, code)
+		}
+	}
+	if len(unknown) > 0 {
+		return e.FromCode("eCyUk1", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// MinorUnits returns the number of decimal places of the currency, as per ISO 4217 (e.g. 0 for JPY, 3 for KWD).
+// Unknown currencies, and currencies without minor units (e.g. gold), use 2 decimal places.
+func MinorUnits(code string) int {
+	if ccy, ok := Lookup(code); ok && ccy.MinorUnits >= 0 {
+		return ccy.MinorUnits
+	}
+	return defaultMinorUnits
+}
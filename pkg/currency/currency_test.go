@@ -0,0 +1,65 @@
+package currency
+
+import (
+	"testing"
+
+	"fx-service/pkg/e"
+)
+
+// TestLookup checks the ISO 4217 metadata of a few currencies
+func TestLookup(t *testing.T) {
+	eur, ok := Lookup("eur")
+	if !ok || eur.Numeric != "978" || eur.Name != "Euro" || eur.MinorUnits != 2 || eur.Symbol != "€" || !eur.ISO {
+		t.Errorf("Unexpected EUR: %+v", eur)
+	}
+	if len(eur.Countries) < 20 {
+		t.Errorf("Expected the euro area countries, got %v", eur.Countries)
+	}
+	if jpy, _ := Lookup("JPY"); jpy.MinorUnits != 0 {
+		t.Errorf("Expected 0 minor units for JPY, got %d", jpy.MinorUnits)
+	}
+	if _, ok := Lookup("ZZZ"); ok {
+		t.Error("Expected ZZZ to be unknown")
+	}
+}
+
+// TestMinorUnits checks the decimal places of currencies, with the fallback to 2
+func TestMinorUnits(t *testing.T) {
+	tests := map[string]int{"JPY": 0, "KWD": 3, "CLF": 4, "USD": 2, "XAU": 2, "ZZZ": 2}
+	for code, want := range tests {
+		if got := MinorUnits(code); got != want {
+			t.Errorf("MinorUnits(%s) = %d, want %d", code, got, want)
+		}
+	}
+}
+
+// TestMergeNames checks that provider names only add codes missing from the catalogue
+func TestMergeNames(t *testing.T) {
+	MergeNames(map[string]string{"USD": "United States Dollar", "btc": "Bitcoin"})
+
+	if usd, _ := Lookup("USD"); usd.Name != "US Dollar" {
+		t.Errorf("Expected the ISO name to be kept, got %q", usd.Name)
+	}
+	btc, ok := Lookup("BTC")
+	if !ok || btc.Name != "Bitcoin" || btc.ISO || btc.MinorUnits != 2 {
+		t.Errorf("Unexpected BTC: %+v", btc)
+	}
+}
+
+// TestCheckCodes checks that unknown codes are reported
+func TestCheckCodes(t *testing.T) {
+	if err := CheckCodes([]string{"USD", "EUR", "JPY"}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	e.SetCatalogue(e.ErrorMap{"eCyUk1": {Message: "Unknown currency codes: %s"}})
+	err := CheckCodes([]string{"USD", "EUT", "XYZ"})
+	if err == nil || e.FromError(err).GetCode() != "eCyUk1" || e.FromError(err).GetMessage() != "Unknown currency codes: EUT, XYZ" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	// Codes merged from the providers can be enabled
+	MergeNames(map[string]string{"XYZ": "Provider only"})
+	if err := CheckCodes([]string{"USD", "XYZ"}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,185 @@
+package currency
+
+// iso builds a catalogue entry for an ISO 4217 currency
+func iso(code, numeric, name string, minorUnits int, symbol string, countries ...string) Currency {
+	return Currency{
+		Code:       code,
+		Numeric:    numeric,
+		Name:       name,
+		MinorUnits: minorUnits,
+		Symbol:     symbol,
+		Countries:  countries,
+	}
+}
+
+// isoCurrencies lists the active ISO 4217 currencies, with the ISO 3166 (alpha-2) codes of the countries using them.
+// Precious metals and the SDR have no minor units (-1), and are not used by any country.
+var isoCurrencies = []Currency{
+	iso("AED", "784", "UAE Dirham", 2, "د.إ", "AE"),
+	iso("AFN", "971", "Afghani", 2, "؋", "AF"),
+	iso("ALL", "008", "Lek", 2, "L", "AL"),
+	iso("AMD", "051", "Armenian Dram", 2, "֏", "AM"),
+	iso("ANG", "532", "Netherlands Antillean Guilder", 2, "ƒ", "CW", "SX"),
+	iso("AOA", "973", "Kwanza", 2, "Kz", "AO"),
+	iso("ARS", "032", "Argentine Peso", 2, "$", "AR"),
+	iso("AUD", "036", "Australian Dollar", 2, "$", "AU", "CX", "CC", "HM", "KI", "NR", "NF", "TV"),
+	iso("AWG", "533", "Aruban Florin", 2, "ƒ", "AW"),
+	iso("AZN", "944", "Azerbaijan Manat", 2, "₼", "AZ"),
+	iso("BAM", "977", "Convertible Mark", 2, "KM", "BA"),
+	iso("BBD", "052", "Barbados Dollar", 2, "$", "BB"),
+	iso("BDT", "050", "Taka", 2, "৳", "BD"),
+	iso("BGN", "975", "Bulgarian Lev", 2, "лв", "BG"),
+	iso("BHD", "048", "Bahraini Dinar", 3, ".د.ب", "BH"),
+	iso("BIF", "108", "Burundi Franc", 0, "FBu", "BI"),
+	iso("BMD", "060", "Bermudian Dollar", 2, "$", "BM"),
+	iso("BND", "096", "Brunei Dollar", 2, "$", "BN"),
+	iso("BOB", "068", "Boliviano", 2, "Bs", "BO"),
+	iso("BRL", "986", "Brazilian Real", 2, "R$", "BR"),
+	iso("BSD", "044", "Bahamian Dollar", 2, "$", "BS"),
+	iso("BTN", "064", "Ngultrum", 2, "Nu.", "BT"),
+	iso("BWP", "072", "Pula", 2, "P", "BW"),
+	iso("BYN", "933", "Belarusian Ruble", 2, "Br", "BY"),
+	iso("BZD", "084", "Belize Dollar", 2, "$", "BZ"),
+	iso("CAD", "124", "Canadian Dollar", 2, "$", "CA"),
+	iso("CDF", "976", "Congolese Franc", 2, "FC", "CD"),
+	iso("CHF", "756", "Swiss Franc", 2, "CHF", "CH", "LI"),
+	iso("CLF", "990", "Unidad de Fomento", 4, "UF", "CL"),
+	iso("CLP", "152", "Chilean Peso", 0, "$", "CL"),
+	iso("CNY", "156", "Yuan Renminbi", 2, "¥", "CN"),
+	iso("COP", "170", "Colombian Peso", 2, "$", "CO"),
+	iso("CRC", "188", "Costa Rican Colon", 2, "₡", "CR"),
+	iso("CUP", "192", "Cuban Peso", 2, "$", "CU"),
+	iso("CVE", "132", "Cabo Verde Escudo", 2, "$", "CV"),
+	iso("CZK", "203", "Czech Koruna", 2, "Kč", "CZ"),
+	iso("DJF", "262", "Djibouti Franc", 0, "Fdj", "DJ"),
+	iso("DKK", "208", "Danish Krone", 2, "kr", "DK", "FO", "GL"),
+	iso("DOP", "214", "Dominican Peso", 2, "$", "DO"),
+	iso("DZD", "012", "Algerian Dinar", 2, "د.ج", "DZ"),
+	iso("EGP", "818", "Egyptian Pound", 2, "£", "EG"),
+	iso("ERN", "232", "Nakfa", 2, "Nfk", "ER"),
+	iso("ETB", "230", "Ethiopian Birr", 2, "Br", "ET"),
+	iso("EUR", "978", "Euro", 2, "€",
+		"AD", "AT", "BE", "CY", "DE", "EE", "ES", "FI", "FR", "GF", "GP", "GR", "HR", "IE", "IT", "LT",
+		"LU", "LV", "MC", "ME", "MQ", "MT", "NL", "PT", "RE", "SI", "SK", "SM", "VA", "XK", "YT"),
+	iso("FJD", "242", "Fiji Dollar", 2, "$", "FJ"),
+	iso("FKP", "238", "Falkland Islands Pound", 2, "£", "FK"),
+	iso("GBP", "826", "Pound Sterling", 2, "£", "GB", "GG", "IM", "JE"),
+	iso("GEL", "981", "Lari", 2, "₾", "GE"),
+	iso("GHS", "936", "Ghana Cedi", 2, "₵", "GH"),
+	iso("GIP", "292", "Gibraltar Pound", 2, "£", "GI"),
+	iso("GMD", "270", "Dalasi", 2, "D", "GM"),
+	iso("GNF", "324", "Guinean Franc", 0, "FG", "GN"),
+	iso("GTQ", "320", "Quetzal", 2, "Q", "GT"),
+	iso("GYD", "328", "Guyana Dollar", 2, "$", "GY"),
+	iso("HKD", "344", "Hong Kong Dollar", 2, "$", "HK"),
+	iso("HNL", "340", "Lempira", 2, "L", "HN"),
+	iso("HTG", "332", "Gourde", 2, "G", "HT"),
+	iso("HUF", "348", "Forint", 2, "Ft", "HU"),
+	iso("IDR", "360", "Rupiah", 2, "Rp", "ID"),
+	iso("ILS", "376", "New Israeli Sheqel", 2, "₪", "IL", "PS"),
+	iso("INR", "356", "Indian Rupee", 2, "₹", "IN", "BT"),
+	iso("IQD", "368", "Iraqi Dinar", 3, "ع.د", "IQ"),
+	iso("IRR", "364", "Iranian Rial", 2, "﷼", "IR"),
+	iso("ISK", "352", "Iceland Krona", 0, "kr", "IS"),
+	iso("JMD", "388", "Jamaican Dollar", 2, "$", "JM"),
+	iso("JOD", "400", "Jordanian Dinar", 3, "د.ا", "JO"),
+	iso("JPY", "392", "Yen", 0, "¥", "JP"),
+	iso("KES", "404", "Kenyan Shilling", 2, "KSh", "KE"),
+	iso("KGS", "417", "Som", 2, "сом", "KG"),
+	iso("KHR", "116", "Riel", 2, "៛", "KH"),
+	iso("KMF", "174", "Comorian Franc", 0, "CF", "KM"),
+	iso("KPW", "408", "North Korean Won", 2, "₩", "KP"),
+	iso("KRW", "410", "Won", 0, "₩", "KR"),
+	iso("KWD", "414", "Kuwaiti Dinar", 3, "د.ك", "KW"),
+	iso("KYD", "136", "Cayman Islands Dollar", 2, "$", "KY"),
+	iso("KZT", "398", "Tenge", 2, "₸", "KZ"),
+	iso("LAK", "418", "Lao Kip", 2, "₭", "LA"),
+	iso("LBP", "422", "Lebanese Pound", 2, "ل.ل", "LB"),
+	iso("LKR", "144", "Sri Lanka Rupee", 2, "Rs", "LK"),
+	iso("LRD", "430", "Liberian Dollar", 2, "$", "LR"),
+	iso("LSL", "426", "Loti", 2, "L", "LS"),
+	iso("LYD", "434", "Libyan Dinar", 3, "ل.د", "LY"),
+	iso("MAD", "504", "Moroccan Dirham", 2, "د.م.", "MA", "EH"),
+	iso("MDL", "498", "Moldovan Leu", 2, "L", "MD"),
+	iso("MGA", "969", "Malagasy Ariary", 2, "Ar", "MG"),
+	iso("MKD", "807", "Denar", 2, "ден", "MK"),
+	iso("MMK", "104", "Kyat", 2, "K", "MM"),
+	iso("MNT", "496", "Tugrik", 2, "₮", "MN"),
+	iso("MOP", "446", "Pataca", 2, "MOP$", "MO"),
+	iso("MRU", "929", "Ouguiya", 2, "UM", "MR"),
+	iso("MUR", "480", "Mauritius Rupee", 2, "₨", "MU"),
+	iso("MVR", "462", "Rufiyaa", 2, "Rf", "MV"),
+	iso("MWK", "454", "Malawi Kwacha", 2, "MK", "MW"),
+	iso("MXN", "484", "Mexican Peso", 2, "$", "MX"),
+	iso("MYR", "458", "Malaysian Ringgit", 2, "RM", "MY"),
+	iso("MZN", "943", "Mozambique Metical", 2, "MT", "MZ"),
+	iso("NAD", "516", "Namibia Dollar", 2, "$", "NA"),
+	iso("NGN", "566", "Naira", 2, "₦", "NG"),
+	iso("NIO", "558", "Cordoba Oro", 2, "C$", "NI"),
+	iso("NOK", "578", "Norwegian Krone", 2, "kr", "NO", "SJ", "BV"),
+	iso("NPR", "524", "Nepalese Rupee", 2, "₨", "NP"),
+	iso("NZD", "554", "New Zealand Dollar", 2, "$", "NZ", "CK", "NU", "PN", "TK"),
+	iso("OMR", "512", "Rial Omani", 3, "ر.ع.", "OM"),
+	iso("PAB", "590", "Balboa", 2, "B/.", "PA"),
+	iso("PEN", "604", "Sol", 2, "S/", "PE"),
+	iso("PGK", "598", "Kina", 2, "K", "PG"),
+	iso("PHP", "608", "Philippine Peso", 2, "₱", "PH"),
+	iso("PKR", "586", "Pakistan Rupee", 2, "₨", "PK"),
+	iso("PLN", "985", "Zloty", 2, "zł", "PL"),
+	iso("PYG", "600", "Guarani", 0, "₲", "PY"),
+	iso("QAR", "634", "Qatari Rial", 2, "ر.ق", "QA"),
+	iso("RON", "946", "Romanian Leu", 2, "lei", "RO"),
+	iso("RSD", "941", "Serbian Dinar", 2, "дин.", "RS"),
+	iso("RUB", "643", "Russian Ruble", 2, "₽", "RU"),
+	iso("RWF", "646", "Rwanda Franc", 0, "FRw", "RW"),
+	iso("SAR", "682", "Saudi Riyal", 2, "﷼", "SA"),
+	iso("SBD", "090", "Solomon Islands Dollar", 2, "$", "SB"),
+	iso("SCR", "690", "Seychelles Rupee", 2, "₨", "SC"),
+	iso("SDG", "938", "Sudanese Pound", 2, "ج.س.", "SD"),
+	iso("SEK", "752", "Swedish Krona", 2, "kr", "SE"),
+	iso("SGD", "702", "Singapore Dollar", 2, "$", "SG"),
+	iso("SHP", "654", "Saint Helena Pound", 2, "£", "SH"),
+	iso("SLE", "925", "Leone", 2, "Le", "SL"),
+	iso("SOS", "706", "Somali Shilling", 2, "Sh", "SO"),
+	iso("SRD", "968", "Surinam Dollar", 2, "$", "SR"),
+	iso("SSP", "728", "South Sudanese Pound", 2, "£", "SS"),
+	iso("STN", "930", "Dobra", 2, "Db", "ST"),
+	iso("SVC", "222", "El Salvador Colon", 2, "₡", "SV"),
+	iso("SYP", "760", "Syrian Pound", 2, "£", "SY"),
+	iso("SZL", "748", "Lilangeni", 2, "E", "SZ"),
+	iso("THB", "764", "Baht", 2, "฿", "TH"),
+	iso("TJS", "972", "Somoni", 2, "SM", "TJ"),
+	iso("TMT", "934", "Turkmenistan New Manat", 2, "m", "TM"),
+	iso("TND", "788", "Tunisian Dinar", 3, "د.ت", "TN"),
+	iso("TOP", "776", "Pa'anga", 2, "T$", "TO"),
+	iso("TRY", "949", "Turkish Lira", 2, "₺", "TR"),
+	iso("TTD", "780", "Trinidad and Tobago Dollar", 2, "$", "TT"),
+	iso("TWD", "901", "New Taiwan Dollar", 2, "$", "TW"),
+	iso("TZS", "834", "Tanzanian Shilling", 2, "TSh", "TZ"),
+	iso("UAH", "980", "Hryvnia", 2, "₴", "UA"),
+	iso("UGX", "800", "Uganda Shilling", 0, "USh", "UG"),
+	iso("USD", "840", "US Dollar", 2, "$",
+		"US", "AS", "BQ", "EC", "FM", "GU", "IO", "MH", "MP", "PA", "PR", "PW", "SV", "TC", "TL", "UM", "VG", "VI", "ZW"),
+	iso("UYI", "940", "Uruguay Peso en Unidades Indexadas (UI)", 0, "", "UY"),
+	iso("UYU", "858", "Peso Uruguayo", 2, "$", "UY"),
+	iso("UYW", "927", "Unidad Previsional", 4, "", "UY"),
+	iso("UZS", "860", "Uzbekistan Sum", 2, "soʻm", "UZ"),
+	iso("VED", "926", "Bolívar Soberano", 2, "Bs.D", "VE"),
+	iso("VES", "928", "Bolívar Soberano", 2, "Bs.S", "VE"),
+	iso("VND", "704", "Dong", 0, "₫", "VN"),
+	iso("VUV", "548", "Vatu", 0, "VT", "VU"),
+	iso("WST", "882", "Tala", 2, "T", "WS"),
+	iso("XAF", "950", "CFA Franc BEAC", 0, "FCFA", "CM", "CF", "CG", "GA", "GQ", "TD"),
+	iso("XAG", "961", "Silver", -1, ""),
+	iso("XAU", "959", "Gold", -1, ""),
+	iso("XCD", "951", "East Caribbean Dollar", 2, "$", "AG", "AI", "DM", "GD", "KN", "LC", "MS", "VC"),
+	iso("XDR", "960", "SDR (Special Drawing Right)", -1, ""),
+	iso("XOF", "952", "CFA Franc BCEAO", 0, "CFA", "BJ", "BF", "CI", "GW", "ML", "NE", "SN", "TG"),
+	iso("XPD", "964", "Palladium", -1, ""),
+	iso("XPF", "953", "CFP Franc", 0, "₣", "NC", "PF", "WF"),
+	iso("XPT", "962", "Platinum", -1, ""),
+	iso("YER", "886", "Yemeni Rial", 2, "﷼", "YE"),
+	iso("ZAR", "710", "Rand", 2, "R", "ZA", "LS", "NA"),
+	iso("ZMW", "967", "Zambian Kwacha", 2, "ZK", "ZM"),
+	iso("ZWG", "924", "Zimbabwe Gold", 2, "ZiG", "ZW"),
+}
@@ -0,0 +1,65 @@
+package currency
+
+import "strings"
+
+// Wildcard matches any currency in a pair pattern
+const Wildcard = "*"
+
+// Specificity of a pair pattern for a pair, from the most specific to the least specific.
+// When several patterns match a pair, the most specific one wins.
+const (
+	MatchPair     = iota // "EUR/USD" - exact pair
+	MatchBase            // "EUR/*" - any pair with this base currency
+	MatchQuote           // "*/USD" - any pair with this quote currency
+	MatchCurrency        // "EUR" - any pair with this currency on either side
+	MatchAny             // "*" or "*/*" - every pair
+	MatchNone
+)
+
+// NormalizePattern validates a pair pattern, and returns it in uppercase "BASE/QUOTE" or "CCY" form, or "*".
+// Returns false if the pattern is empty or not a supported pattern.
+func NormalizePattern(pattern string) (string, bool) {
+	pattern = strings.ToUpper(strings.TrimSpace(pattern))
+	if pattern == "" {
+		return "", false
+	}
+	if pattern == Wildcard || pattern == Wildcard+"/"+Wildcard {
+		return Wildcard, true
+	}
+
+	base, quote, isPair := strings.Cut(pattern, "/")
+	if !isPair {
+		return pattern, true
+	}
+	if base == "" || quote == "" || strings.Contains(quote, "/") {
+		return "", false
+	}
+	return pattern, true
+}
+
+// PatternSpecificity returns how specifically the (normalized) pattern matches the given (uppercase) pair,
+// or MatchNone when it does not match at all
+func PatternSpecificity(pattern, from, to string) int {
+	if pattern == Wildcard {
+		return MatchAny
+	}
+
+	base, quote, isPair := strings.Cut(pattern, "/")
+	if !isPair {
+		if pattern == from || pattern == to {
+			return MatchCurrency
+		}
+		return MatchNone
+	}
+
+	switch {
+	case base == from && quote == to:
+		return MatchPair
+	case base == from && quote == Wildcard:
+		return MatchBase
+	case base == Wildcard && quote == to:
+		return MatchQuote
+	default:
+		return MatchNone
+	}
+}
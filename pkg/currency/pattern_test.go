@@ -0,0 +1,53 @@
+package currency
+
+import "testing"
+
+// TestNormalizePattern checks the supported pair patterns, and the invalid ones
+func TestNormalizePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+		valid   bool
+	}{
+		{" eur/usd ", "EUR/USD", true},
+		{"hkd/*", "HKD/*", true},
+		{"*/hkd", "*/HKD", true},
+		{"hkd", "HKD", true},
+		{"*", "*", true},
+		{"*/*", "*", true},
+		{"", "", false},
+		{"EUR/", "", false},
+		{"/USD", "", false},
+		{"EUR/USD/GBP", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := NormalizePattern(tt.pattern)
+		if ok != tt.valid || got != tt.want {
+			t.Errorf("NormalizePattern(%q) = %q, %v; want %q, %v", tt.pattern, got, ok, tt.want, tt.valid)
+		}
+	}
+}
+
+// TestPatternSpecificity checks how specifically each kind of pattern matches a pair
+func TestPatternSpecificity(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    int
+	}{
+		{"EUR/USD", MatchPair},
+		{"EUR/*", MatchBase},
+		{"*/USD", MatchQuote},
+		{"USD", MatchCurrency},
+		{"EUR", MatchCurrency},
+		{"*", MatchAny},
+		{"USD/EUR", MatchNone},
+		{"GBP/*", MatchNone},
+		{"*/EUR", MatchNone},
+		{"GBP", MatchNone},
+	}
+	for _, tt := range tests {
+		if got := PatternSpecificity(tt.pattern, "EUR", "USD"); got != tt.want {
+			t.Errorf("PatternSpecificity(%q, EUR, USD) = %d, want %d", tt.pattern, got, tt.want)
+		}
+	}
+}
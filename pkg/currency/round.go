@@ -0,0 +1,29 @@
+package currency
+
+import (
+	"fx-service/pkg/config"
+	"fx-service/pkg/decimal"
+)
+
+// roundingMode maps a configured rounding mode to the decimal rounding mode
+func roundingMode(rounding config.Rounding) decimal.RoundingMode {
+	switch rounding {
+	case config.HalfUp:
+		return decimal.RoundHalfUp
+	case config.Truncate:
+		return decimal.RoundDown
+	default:
+		return decimal.RoundHalfEven
+	}
+}
+
+// Round rounds a number to exactly the given number of decimal places, with the given rounding mode
+func Round(value decimal.Decimal, decimals int, rounding config.Rounding) decimal.Decimal {
+	return value.Round(int32(decimals), roundingMode(rounding))
+}
+
+// Convert multiplies an amount by a rate, and rounds the result to the minor units of the target currency.
+// The multiplication is exact, so the result is only rounded once.
+func Convert(amount, rate decimal.Decimal, to string, rounding config.Rounding) decimal.Decimal {
+	return Round(amount.Mul(rate), MinorUnits(to), rounding)
+}
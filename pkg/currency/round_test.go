@@ -0,0 +1,71 @@
+package currency
+
+import (
+	"testing"
+
+	"fx-service/pkg/config"
+	"fx-service/pkg/decimal"
+)
+
+// TestRound checks rounding of halves for each rounding mode
+func TestRound(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		decimals int
+		rounding config.Rounding
+		want     string
+	}{
+		{"Half even rounds down to even", "2.345", 2, config.HalfEven, "2.34"},
+		{"Half even rounds up to even", "2.355", 2, config.HalfEven, "2.36"},
+		{"Half even above half", "2.3451", 2, config.HalfEven, "2.35"},
+		{"Half even negative", "-2.345", 2, config.HalfEven, "-2.34"},
+		{"Half up", "2.345", 2, config.HalfUp, "2.35"},
+		{"Half up exact half", "1.005", 2, config.HalfUp, "1.01"},
+		{"Half up negative", "-2.345", 2, config.HalfUp, "-2.35"},
+		{"Half up no decimals", "1234.5", 0, config.HalfUp, "1235"},
+		{"Truncate", "2.349", 2, config.Truncate, "2.34"},
+		{"Truncate negative", "-2.349", 2, config.Truncate, "-2.34"},
+		{"Pads to the decimal places", "12.5", 2, config.HalfEven, "12.50"},
+		{"Three decimals", "0.30449", 3, config.HalfEven, "0.304"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Round(decimal.MustParse(tt.value), tt.decimals, tt.rounding).String(); got != tt.want {
+				t.Errorf("Round() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestConvert checks that converted amounts are rounded to the minor units of the target currency
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   string
+		rate     string
+		to       string
+		rounding config.Rounding
+		want     string
+	}{
+		{"JPY has no minor units", "1234.56", "151.237", "JPY", config.HalfEven, "186711"},
+		{"KWD has 3 decimal places", "1234.56", "0.30712", "KWD", config.HalfEven, "379.158"},
+		{"EUR has 2 decimal places", "1234.56", "0.92345", "EUR", config.HalfEven, "1140.05"},
+		{"Exact half, half even", "10", "0.1225", "EUR", config.HalfEven, "1.22"},
+		{"Exact half, half up", "10", "0.1225", "EUR", config.HalfUp, "1.23"},
+		{"Truncate", "10", "0.12399", "EUR", config.Truncate, "1.23"},
+		{"Unknown currency uses 2 decimal places", "1", "1.23456", "ZZZ", config.HalfEven, "1.23"},
+		{"Lowercase code", "100", "1.5", "jpy", config.HalfEven, "150"},
+		{"Large amounts stay exact", "12345678901234567.89", "1", "EUR", config.HalfEven, "12345678901234567.89"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Convert(decimal.MustParse(tt.amount), decimal.MustParse(tt.rate), tt.to, tt.rounding)
+			if got.String() != tt.want {
+				t.Errorf("Convert() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
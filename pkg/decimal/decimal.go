@@ -0,0 +1,287 @@
+// Package decimal Exact decimal numbers, for rates and amounts
+package decimal
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Decimal is an exact decimal number, stored as an integer coefficient and a number of decimal places (the scale).
+// The zero value is 0. Decimals are immutable: every operation returns a new Decimal.
+type Decimal struct {
+	coef  *big.Int // The unscaled value, e.g. 12345 for 1.2345. nil means zero
+	scale int32    // Number of digits after the decimal point. Never negative
+}
+
+// RoundingMode is how a Decimal is rounded to fewer decimal places
+type RoundingMode int
+
+const (
+	RoundHalfEven RoundingMode = iota // Round half to even (banker's rounding), e.g. 2.345 -> 2.34, 2.355 -> 2.36
+	RoundHalfUp                       // Round half away from zero, e.g. 2.345 -> 2.35
+	RoundDown                         // Round towards zero (truncate), e.g. 2.349 -> 2.34
+)
+
+// Zero is the decimal 0
+var Zero = Decimal{}
+
+// Limits of the numbers Parse accepts, so a number sent by a client can't make it build huge coefficients
+const (
+	MaxDigits   = 38 // Significant digits, leading zeros aside
+	MaxPlaces   = 64 // Digits written after the decimal point
+	MaxExponent = 64 // Exponent of the exponent notation, up or down, e.g. 1e64
+)
+
+// New creates a Decimal from a coefficient and a number of decimal places, e.g. New(12345, 4) is 1.2345
+func New(coef int64, scale int32) Decimal {
+	if scale < 0 {
+		return Decimal{coef: new(big.Int).Mul(big.NewInt(coef), pow10(-scale))}
+	}
+	return Decimal{coef: big.NewInt(coef), scale: scale}
+}
+
+// NewFromInt creates a Decimal from an integer
+func NewFromInt(i int64) Decimal {
+	return New(i, 0)
+}
+
+// NewFromFloat creates a Decimal from the shortest decimal representation of a float, e.g. 0.1 is exactly 0.1.
+// NaN and infinite values give zero, as do the values beyond the limits of Parse.
+func NewFromFloat(f float64) Decimal {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Zero
+	}
+	d, _ := Parse(strconv.FormatFloat(f, 'f', -1, 64))
+	return d
+}
+
+// Parse parses a decimal number, e.g. "1.2345", "-0.5", "12" or "1.5e-3", without going through a float.
+// Numbers with more than MaxDigits significant digits, MaxPlaces decimal places or an exponent beyond MaxExponent are
+// refused.
+func Parse(s string) (Decimal, error) {
+	str := strings.TrimSpace(s)
+	if str == "" {
+		return Zero, fmt.Errorf("invalid decimal number '%s'", s)
+	}
+
+	// Split the exponent, if any
+	exponent := int64(0)
+	if i := strings.IndexAny(str, "eE"); i >= 0 {
+		var err error
+		exponent, err = strconv.ParseInt(str[i+1:], 10, 32)
+		if err != nil {
+			return Zero, fmt.Errorf("invalid decimal number '%s'", s)
+		}
+		if exponent > MaxExponent || exponent < -MaxExponent {
+			return Zero, fmt.Errorf("decimal number '%s' is out of range: the exponent must be between -%d and %d", s, MaxExponent, MaxExponent)
+		}
+		str = str[:i]
+	}
+
+	// Keep the sign apart, so the digits can be validated
+	sign := ""
+	if str != "" && (str[0] == '-' || str[0] == '+') {
+		sign = str[:1]
+		str = str[1:]
+	}
+
+	intPart, fracPart, _ := strings.Cut(str, ".")
+	if intPart == "" && fracPart == "" || !isDigits(intPart) || !isDigits(fracPart) {
+		return Zero, fmt.Errorf("invalid decimal number '%s'", s)
+	}
+
+	if len(strings.TrimLeft(intPart+fracPart, "0")) > MaxDigits || len(fracPart) > MaxPlaces {
+		return Zero, fmt.Errorf("decimal number '%s' is out of range: use at most %d digits and %d decimal places", s, MaxDigits, MaxPlaces)
+	}
+
+	coef, ok := new(big.Int).SetString(sign+intPart+fracPart, 10)
+	if !ok {
+		return Zero, fmt.Errorf("invalid decimal number '%s'", s)
+	}
+
+	// Both limits keep the scale within [-MaxExponent, MaxPlaces+MaxExponent]
+	scale := int64(len(fracPart)) - exponent
+	if scale < 0 {
+		return Decimal{coef: coef.Mul(coef, pow10(int32(-scale)))}, nil
+	}
+	return Decimal{coef: coef, scale: int32(scale)}, nil
+}
+
+// MustParse parses a decimal number, and panics if it is invalid. For constants and tests.
+func MustParse(s string) Decimal {
+	d, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// isDigits checks that the string only contains the digits 0-9 (an empty string is valid)
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// pow10 returns 10 to the power of n
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// int returns the coefficient, which is never nil
+func (d Decimal) int() *big.Int {
+	if d.coef == nil {
+		return new(big.Int)
+	}
+	return d.coef
+}
+
+// rescale returns the coefficient for a larger scale, e.g. 1.5 at scale 3 is 1500
+func (d Decimal) rescale(scale int32) *big.Int {
+	if scale == d.scale {
+		return new(big.Int).Set(d.int())
+	}
+	return new(big.Int).Mul(d.int(), pow10(scale-d.scale))
+}
+
+// Add returns d + other
+func (d Decimal) Add(other Decimal) Decimal {
+	scale := max(d.scale, other.scale)
+	return Decimal{coef: new(big.Int).Add(d.rescale(scale), other.rescale(scale)), scale: scale}
+}
+
+// Sub returns d - other
+func (d Decimal) Sub(other Decimal) Decimal {
+	scale := max(d.scale, other.scale)
+	return Decimal{coef: new(big.Int).Sub(d.rescale(scale), other.rescale(scale)), scale: scale}
+}
+
+// Mul returns d × other, exactly
+func (d Decimal) Mul(other Decimal) Decimal {
+	return Decimal{coef: new(big.Int).Mul(d.int(), other.int()), scale: d.scale + other.scale}
+}
+
+// Div returns d ÷ other, rounded half to even to the given number of decimal places, without trailing zeros.
+// Panics if other is zero.
+func (d Decimal) Div(other Decimal, places int32) Decimal {
+	if other.IsZero() {
+		panic("decimal: division by zero")
+	}
+	quotient := new(big.Rat).Quo(d.Rat(), other.Rat())
+	return roundRat(quotient, places, RoundHalfEven).Trim()
+}
+
+// Neg returns -d
+func (d Decimal) Neg() Decimal {
+	return Decimal{coef: new(big.Int).Neg(d.int()), scale: d.scale}
+}
+
+// Cmp compares d and other, and returns -1 if d < other, 0 if they are equal, or +1 if d > other
+func (d Decimal) Cmp(other Decimal) int {
+	scale := max(d.scale, other.scale)
+	return d.rescale(scale).Cmp(other.rescale(scale))
+}
+
+// Equal checks if d and other are the same number, regardless of their scale (1.5 equals 1.50)
+func (d Decimal) Equal(other Decimal) bool {
+	return d.Cmp(other) == 0
+}
+
+// Sign returns -1 if d < 0, 0 if d is zero, or +1 if d > 0
+func (d Decimal) Sign() int {
+	return d.int().Sign()
+}
+
+// IsZero checks if d is zero
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Scale returns the number of decimal places of d
+func (d Decimal) Scale() int32 {
+	return d.scale
+}
+
+// Rat returns d as an exact rational number
+func (d Decimal) Rat() *big.Rat {
+	return new(big.Rat).SetFrac(d.int(), pow10(d.scale))
+}
+
+// Round rounds d to exactly the given number of decimal places, padding with zeros if it has fewer
+func (d Decimal) Round(places int32, mode RoundingMode) Decimal {
+	if places < 0 {
+		places = 0
+	}
+	if places >= d.scale {
+		return Decimal{coef: d.rescale(places), scale: places}
+	}
+	return roundRat(d.Rat(), places, mode)
+}
+
+// Trim removes the trailing zeros after the decimal point, e.g. 1.2300 becomes 1.23
+func (d Decimal) Trim() Decimal {
+	coef := new(big.Int).Set(d.int())
+	scale := d.scale
+	ten := big.NewInt(10)
+	remainder := new(big.Int)
+	for scale > 0 {
+		quotient, rem := new(big.Int).QuoRem(coef, ten, remainder)
+		if rem.Sign() != 0 {
+			break
+		}
+		coef = quotient
+		scale--
+	}
+	return Decimal{coef: coef, scale: scale}
+}
+
+// roundRat rounds a rational number to the given number of decimal places
+func roundRat(r *big.Rat, places int32, mode RoundingMode) Decimal {
+	scaled := new(big.Rat).Mul(r, new(big.Rat).SetInt(pow10(places)))
+
+	// Truncated quotient and remainder, both with the sign of the number
+	quotient, remainder := new(big.Int).QuoRem(scaled.Num(), scaled.Denom(), new(big.Int))
+
+	if remainder.Sign() != 0 && mode != RoundDown {
+		// Compare the remainder with half of the denominator: cmp < 0 is below half, 0 is exactly half
+		cmp := new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2)).Cmp(scaled.Denom())
+		if cmp > 0 || (cmp == 0 && (mode == RoundHalfUp || quotient.Bit(0) == 1)) {
+			quotient.Add(quotient, big.NewInt(int64(scaled.Sign())))
+		}
+	}
+
+	return Decimal{coef: quotient, scale: places}
+}
+
+// Float64 returns the nearest float to d
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// String returns d in plain notation, with all of its decimal places, e.g. "-1.2300"
+func (d Decimal) String() string {
+	digits := d.int().String()
+	if d.scale == 0 {
+		return digits
+	}
+
+	sign := ""
+	if digits[0] == '-' {
+		sign = "-"
+		digits = digits[1:]
+	}
+
+	// Pad with leading zeros, so there is at least one digit before the decimal point
+	if pad := int(d.scale) + 1 - len(digits); pad > 0 {
+		digits = strings.Repeat("0", pad) + digits
+	}
+	point := len(digits) - int(d.scale)
+	return sign + digits[:point] + "." + digits[point:]
+}
@@ -0,0 +1,168 @@
+package decimal
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// TestParse checks parsing of plain and exponent notations, and invalid numbers
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"1.2345", "1.2345", false},
+		{"-0.5", "-0.5", false},
+		{"+12", "12", false},
+		{"0.000001", "0.000001", false},
+		{"1.5e-3", "0.0015", false},
+		{"1.2E+3", "1200", false},
+		{".5", "0.5", false},
+		{"123456789012345678901234.5", "123456789012345678901234.5", false},
+		{"", "", true},
+		{"abc", "", true},
+		{"1.2.3", "", true},
+		{"-", "", true},
+		{"1e", "", true},
+		{"NaN", "", true},
+		{"1e64", "1" + strings.Repeat("0", 64), false},
+		{"1e-64", "0." + strings.Repeat("0", 63) + "1", false},
+		{"1e65", "", true},
+		{"1e20000000", "", true},
+		{"1e-20000000", "", true},
+		{"0.000" + strings.Repeat("1", 38), "0.000" + strings.Repeat("1", 38), false},
+		{strings.Repeat("9", 39), "", true},
+		{"0." + strings.Repeat("0", 64) + "1", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got.String() != tt.want {
+				t.Errorf("Parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestArithmetic checks that additions and multiplications are exact
+func TestArithmetic(t *testing.T) {
+	tests := []struct {
+		name string
+		got  Decimal
+		want string
+	}{
+		{"Add", MustParse("0.1").Add(MustParse("0.2")), "0.3"},
+		{"Add different scales", MustParse("1.5").Add(MustParse("0.25")), "1.75"},
+		{"Sub", MustParse("1").Sub(MustParse("0.9")), "0.1"},
+		{"Mul", MustParse("1234.56").Mul(MustParse("0.92345")), "1140.0544320"},
+		{"Div rounds half to even", MustParse("1").Div(MustParse("3"), 8), "0.33333333"},
+		{"Div trims zeros", MustParse("3").Div(MustParse("2"), 8), "1.5"},
+		{"Neg", MustParse("1.5").Neg(), "-1.5"},
+		{"Zero value", Zero.Add(New(5, 1)), "0.5"},
+		{"New with negative scale", New(15, -2), "1500"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got.String() != tt.want {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRound checks each rounding mode, with positive and negative numbers
+func TestRound(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		places int32
+		mode   RoundingMode
+		want   string
+	}{
+		{"Half even rounds down to even", "2.345", 2, RoundHalfEven, "2.34"},
+		{"Half even rounds up to even", "2.355", 2, RoundHalfEven, "2.36"},
+		{"Half even above half", "2.3451", 2, RoundHalfEven, "2.35"},
+		{"Half even negative", "-2.345", 2, RoundHalfEven, "-2.34"},
+		{"Half up", "2.345", 2, RoundHalfUp, "2.35"},
+		{"Half up negative", "-2.345", 2, RoundHalfUp, "-2.35"},
+		{"Half up no decimals", "1234.5", 0, RoundHalfUp, "1235"},
+		{"Round down", "2.349", 2, RoundDown, "2.34"},
+		{"Round down negative", "-2.349", 2, RoundDown, "-2.34"},
+		{"Pads with zeros", "9", 2, RoundHalfEven, "9.00"},
+		{"Small number", "0.004", 2, RoundHalfEven, "0.00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MustParse(tt.value).Round(tt.places, tt.mode); got.String() != tt.want {
+				t.Errorf("Round() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestCompare checks comparisons regardless of scale, and trimming trailing zeros
+func TestCompare(t *testing.T) {
+	if !MustParse("1.50").Equal(MustParse("1.5")) {
+		t.Error("expected 1.50 to equal 1.5")
+	}
+	if MustParse("-1").Cmp(MustParse("0.5")) != -1 {
+		t.Error("expected -1 < 0.5")
+	}
+	if !Zero.IsZero() || MustParse("0.000").Sign() != 0 {
+		t.Error("expected zero")
+	}
+	if got := MustParse("1.2300").Trim().String(); got != "1.23" {
+		t.Errorf("Trim() = %v, want 1.23", got)
+	}
+	if got := MustParse("100").Trim().String(); got != "100" {
+		t.Errorf("Trim() = %v, want 100", got)
+	}
+}
+
+// TestFloat checks conversions from and to floats, using the shortest decimal representation
+func TestFloat(t *testing.T) {
+	if got := NewFromFloat(0.1).String(); got != "0.1" {
+		t.Errorf("NewFromFloat(0.1) = %v, want 0.1", got)
+	}
+	if got := NewFromFloat(1e-7).String(); got != "0.0000001" {
+		t.Errorf("NewFromFloat(1e-7) = %v, want 0.0000001", got)
+	}
+	if got := MustParse("151.237").Float64(); got != 151.237 {
+		t.Errorf("Float64() = %v, want 151.237", got)
+	}
+}
+
+// TestJSON checks that decimals are parsed from JSON numbers and strings, and serialised as strings or numbers
+func TestJSON(t *testing.T) {
+	var rates map[string]Decimal
+	if err := json.Unmarshal([]byte(`{"EUR": 0.92345678901234567890, "JPY": "151.237", "GBP": null}`), &rates); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := rates["EUR"].String(); got != "0.92345678901234567890" {
+		t.Errorf("EUR = %v, want the exact digits", got)
+	}
+
+	b, _ := json.Marshal(map[string]Decimal{"JPY": rates["JPY"]})
+	if string(b) != `{"JPY":"151.237"}` {
+		t.Errorf("Marshal() = %s, want a string", b)
+	}
+
+	SetFloatOutput(true)
+	defer SetFloatOutput(false)
+	b, _ = json.Marshal(map[string]Decimal{"JPY": rates["JPY"]})
+	if string(b) != `{"JPY":151.237}` {
+		t.Errorf("Marshal() = %s, want a number", b)
+	}
+
+	if err := json.Unmarshal([]byte(`{"EUR": "1,5"}`), &rates); err == nil {
+		t.Error("expected an error for an invalid number")
+	}
+}
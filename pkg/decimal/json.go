@@ -0,0 +1,47 @@
+package decimal
+
+import (
+	"bytes"
+	"strconv"
+	"sync/atomic"
+)
+
+// floatOutput is whether decimals are serialised as JSON numbers, rather than as exact strings
+var floatOutput atomic.Bool
+
+// SetFloatOutput sets whether decimals are serialised as JSON numbers (e.g. 1.2345) instead of strings ("1.2345").
+// Strings are the default, as JSON numbers are usually parsed into floats by clients.
+func SetFloatOutput(enabled bool) {
+	floatOutput.Store(enabled)
+}
+
+// MarshalJSON serialises the decimal as an exact string, or as a number if float output is enabled
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	if floatOutput.Load() {
+		return []byte(d.String()), nil
+	}
+	return []byte(strconv.Quote(d.String())), nil
+}
+
+// UnmarshalJSON parses a decimal from a JSON number or string, without going through a float.
+// null leaves the decimal unchanged.
+func (d *Decimal) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("null")) {
+		return nil
+	}
+
+	s := string(b)
+	if len(b) > 0 && b[0] == '"' {
+		var err error
+		if s, err = strconv.Unquote(s); err != nil {
+			return err
+		}
+	}
+
+	parsed, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
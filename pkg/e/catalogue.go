@@ -1,16 +1,66 @@
 package e
 
-// ErrorMap is a map of error codes to error messages
+import "sync"
+
+// Entry is an error of the catalogue, with how it is shown to the clients of the service
+type Entry struct {
+	Message   string   // The message of the exception, formatted with the arguments of FromCode
+	Status    int      // The HTTP status sent to clients. 500 if unset
+	Public    string   // The message sent to clients, when the message has internal details. Formatted like the message, if it has verbs
+	Retryable bool     // Whether the same request may succeed later, e.g. when the providers are back
+	Fields    []string // The fields of the exception which may be sent to clients. The others are internal, e.g. provider URLs
+}
+
+// ErrorMap is a map of error codes to their catalogue entries
 // These errors can then be called by e.FromCode("e12345")
-type ErrorMap map[string]string
+type ErrorMap map[string]Entry
 
-// catalogue is a map of error codes to error messages which can be used by the application
-// For example - {"e12345": "This is an example error"}
+// catalogue is a map of error codes to the errors which can be used by the application
+// For example - {"e12345": {Message: "This is an example error", Status: 400}}
 var catalogue = ErrorMap{
-	// Use e.SetCatalogue() to set the error catalogue
+	// Use e.SetCatalogue() or e.MergeCatalogue() to set the error catalogue
 }
 
-// SetCatalogue sets the error catalogue
+// catalogueMu guards the catalogue, which packages may merge their codes into while others read it
+var catalogueMu sync.RWMutex
+
+// SetCatalogue sets the error catalogue, replacing the codes already catalogued
 func SetCatalogue(c ErrorMap) {
+	catalogueMu.Lock()
+	defer catalogueMu.Unlock()
 	catalogue = c
 }
+
+// MergeCatalogue adds the codes of the given map to the error catalogue.
+// Codes which are already catalogued are kept, so packages sharing the catalogue don't override each other.
+func MergeCatalogue(c ErrorMap) {
+	catalogueMu.Lock()
+	defer catalogueMu.Unlock()
+	merged := make(ErrorMap, len(catalogue)+len(c))
+	for code, entry := range c {
+		merged[code] = entry
+	}
+	for code, entry := range catalogue {
+		merged[code] = entry
+	}
+	catalogue = merged
+}
+
+// lookup returns the catalogue entry of a code
+func lookup(code string) (Entry, bool) {
+	catalogueMu.RLock()
+	defer catalogueMu.RUnlock()
+	entry, ok := catalogue[code]
+	return entry, ok
+}
+
+// Catalogue returns a copy of the error catalogue
+func Catalogue() ErrorMap {
+	catalogueMu.RLock()
+	defer catalogueMu.RUnlock()
+	c := make(ErrorMap, len(catalogue))
+	for code, entry := range catalogue {
+		c[code] = entry
+	}
+	return c
+}
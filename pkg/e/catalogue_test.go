@@ -4,18 +4,43 @@ import "testing"
 
 func TestSetCatalogue(t *testing.T) {
 	c := ErrorMap{
-		"e12345": "This is an example error",
+		"e12345": {Message: "This is an example error"},
 	}
 	SetCatalogue(c)
 
-	if catalogue["e12345"] != "This is an example error" {
-		t.Errorf("expected 'This is an example error', got %s", catalogue["e12345"])
+	if catalogue["e12345"].Message != "This is an example error" {
+		t.Errorf("expected 'This is an example error', got %s", catalogue["e12345"].Message)
+	}
+}
+
+func TestCatalogue(t *testing.T) {
+	SetCatalogue(ErrorMap{"e12345": {Message: "This is an example error"}})
+
+	c := Catalogue()
+	c["e12345"] = Entry{Message: "changed"}
+	if catalogue["e12345"].Message != "This is an example error" {
+		t.Errorf("expected a copy of the catalogue, got %s", catalogue["e12345"].Message)
+	}
+}
+
+func TestMergeCatalogue(t *testing.T) {
+	SetCatalogue(ErrorMap{"e12345": {Message: "This is an example error"}})
+	MergeCatalogue(ErrorMap{
+		"e12345": {Message: "changed"},
+		"e67890": {Message: "This is another error"},
+	})
+
+	if catalogue["e12345"].Message != "This is an example error" {
+		t.Errorf("expected the catalogued code to be kept, got %s", catalogue["e12345"].Message)
+	}
+	if catalogue["e67890"].Message != "This is another error" {
+		t.Errorf("expected the new code to be merged, got %s", catalogue["e67890"].Message)
 	}
 }
 
 func TestThrowErrorFromCatalogue(t *testing.T) {
 	c := ErrorMap{
-		"e12345": "This is an example error",
+		"e12345": {Message: "This is an example error"},
 	}
 	SetCatalogue(c)
 
@@ -24,3 +49,17 @@ func TestThrowErrorFromCatalogue(t *testing.T) {
 		t.Errorf("expected 'This is an example error', got %s", ex.GetMessage())
 	}
 }
+
+func TestPublicMessageFromCatalogue(t *testing.T) {
+	SetCatalogue(ErrorMap{
+		"e12345": {Message: "Could not read '%s'", Public: "Could not read the file"},
+		"e67890": {Message: "Quote '%s' not found", Public: "No quote '%s'"},
+	})
+
+	if public := FromCode("e12345", "/etc/secret").public; public != "Could not read the file" {
+		t.Errorf("expected the public message without the argument, got %q", public)
+	}
+	if public := FromCode("e67890", "q1").public; public != "No quote 'q1'" {
+		t.Errorf("expected the formatted public message, got %q", public)
+	}
+}
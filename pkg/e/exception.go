@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"sync"
 
 	"fx-service/pkg/console"
@@ -42,6 +43,7 @@ type Exception struct {
 	trace    *Trace
 	fields   Fields
 	args     []interface{}
+	public   string // The public message of the catalogue entry, formatted, if it has one
 }
 
 // Throw creates the Exception struct.
@@ -63,18 +65,24 @@ func Throwf(code string, format string, args ...interface{}) *Exception {
 // If there are arguments, an attempt will be made to format them into the message.
 func FromCode(code string, args ...interface{}) *Exception {
 	// See if the error code exists
-	msg, ok := catalogue[code]
+	entry, ok := lookup(code)
 	if !ok {
 		return Throwf("", "Unknown error code '%s'. Ensure error is catalogued. ", code)
 	}
 
-	// If there are arguments, format the message
+	// If there are arguments, format the message, and the public message if it uses them
+	msg, public := entry.Message, entry.Public
 	if args != nil {
 		msg = fmt.Sprintf(msg, args...)
+		if strings.Contains(public, "%") {
+			public = fmt.Sprintf(public, args...)
+		}
 	}
 
 	traceString, trace := captureBacktrace()
-	return makeException(trace, code, msg, traceString)
+	ex := makeException(trace, code, msg, traceString)
+	ex.public = public
+	return ex
 }
 
 // FromError creates an Exception from an error interface.
@@ -194,6 +202,15 @@ func (e *Exception) GetStruct() string {
 	return e.origin.Struct
 }
 
+// GetFields returns a copy of the custom fields of the exception.
+func (e *Exception) GetFields() Fields {
+	fields := make(Fields, len(e.fields))
+	for key, value := range e.fields {
+		fields[key] = value
+	}
+	return fields
+}
+
 // GetField gets a custom field from the exception, as previously set in user-land.
 func (e *Exception) GetField(key string) interface{} {
 	return e.fields[key]
@@ -0,0 +1,47 @@
+package e
+
+import (
+	"errors"
+	"net/http"
+)
+
+// PublicError is what the clients of the service may see of an error
+type PublicError struct {
+	Status    int
+	Code      string // The code of the catalogue, or empty for the errors without one
+	Message   string
+	Retryable bool
+	Fields    Fields // The fields the catalogue entry allows to be sent, if any
+}
+
+// Public describes an error for the clients of the service, with the given status unless the catalogue has one.
+// A catalogued exception is described by its catalogue entry, with its public message and fields only.
+// Other exceptions may hold internal details, such as provider URLs, so only the status text is sent.
+// Other errors are not catalogued either, and may hold internal details, so they get the status text too.
+func Public(err error, status int) PublicError {
+	var ex *Exception
+	if !errors.As(err, &ex) || ex == nil {
+		return PublicError{Status: status, Message: http.StatusText(status)}
+	}
+	entry, ok := lookup(ex.code)
+	if ex.code == "" || !ok {
+		return PublicError{Status: status, Message: http.StatusText(status)}
+	}
+
+	public := PublicError{Status: entry.Status, Code: ex.code, Message: ex.public, Retryable: entry.Retryable}
+	if public.Status == 0 {
+		public.Status = http.StatusInternalServerError
+	}
+	if public.Message == "" {
+		public.Message = ex.message
+	}
+	for _, name := range entry.Fields {
+		if value, ok := ex.fields[name]; ok {
+			if public.Fields == nil {
+				public.Fields = Fields{}
+			}
+			public.Fields[name] = value
+		}
+	}
+	return public
+}
@@ -0,0 +1,64 @@
+package e
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestPublic(t *testing.T) {
+	SetCatalogue(ErrorMap{
+		"e12345": {Message: "Provider failed (status: %d)", Status: http.StatusBadGateway, Public: "Provider failed", Retryable: true, Fields: []string{"status"}},
+		"e67890": {Message: "Could not save"},
+	})
+
+	tests := []struct {
+		name     string
+		err      error
+		status   int
+		expected PublicError
+	}{
+		{
+			name: "catalogued exception",
+			err:  FromCode("e12345", 401).SetFields(Fields{"status": 401, "url": "https://api.example.com/?key=secret"}),
+			expected: PublicError{
+				Status: http.StatusBadGateway, Code: "e12345", Message: "Provider failed", Retryable: true, Fields: Fields{"status": 401},
+			},
+		},
+		{
+			name:     "catalogued exception without a status",
+			err:      FromCode("e67890").SetPrevious(errors.New("disk full")),
+			expected: PublicError{Status: http.StatusInternalServerError, Code: "e67890", Message: "Could not save"},
+		},
+		{
+			name:     "wrapped exception",
+			err:      fmt.Errorf("saving: %w", FromCode("e67890")),
+			expected: PublicError{Status: http.StatusInternalServerError, Code: "e67890", Message: "Could not save"},
+		},
+		{
+			name:     "exception without a catalogued code",
+			err:      Throw("non200", "GET https://api.example.com/?key=secret failed").SetField("url", "https://api.example.com/?key=secret"),
+			expected: PublicError{Status: http.StatusInternalServerError, Message: "Internal Server Error"},
+		},
+		{
+			name:     "error",
+			err:      errors.New("dial tcp 10.0.0.1:5432: connection refused"),
+			status:   http.StatusBadRequest,
+			expected: PublicError{Status: http.StatusBadRequest, Message: "Bad Request"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := tt.status
+			if status == 0 {
+				status = http.StatusInternalServerError
+			}
+			public := Public(tt.err, status)
+			if fmt.Sprint(public) != fmt.Sprint(tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, public)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package fx
+
+import (
+	"time"
+
+	"fx-service/internal/service/ratecache"
+	"fx-service/pkg/decimal"
+)
+
+// Cache is where an engine keeps the rates it got from the providers, for WithCache.
+// Get returns nil if the rate is not found or expired. Both are called concurrently.
+type Cache interface {
+	Get(from, to string) *decimal.Decimal
+	Set(from, to string, rate decimal.Decimal)
+}
+
+// NewMemoryCache returns an in-memory cache, where the rates expire after the given number of seconds.
+// Each cache is independent of the others, and of the cache of the HTTP server.
+func NewMemoryCache(expirySec int) Cache {
+	cache := ratecache.New()
+	cache.SetExpiry(expirySec)
+	return cache
+}
+
+// clockSetter is a cache whose clock can be replaced, like the in-memory cache
+type clockSetter interface {
+	SetClock(now func() time.Time)
+}
+
+// clearer is a cache which can be emptied, like the in-memory cache
+type clearer interface {
+	Clear()
+}
@@ -0,0 +1,30 @@
+package fx
+
+import (
+	"net/http"
+	"sync"
+
+	"fx-service/pkg/e"
+)
+
+// errorMap holds the error codes of the engines, and of the providers and strategies they use.
+// They are merged into the error catalogue, so the codes of the program using the engines are kept.
+var errorMap = e.ErrorMap{
+	"eFxCl1": {Message: "The engine is closed"},
+	"eFxCc1": {Message: "Invalid currency code '%s'", Status: http.StatusBadRequest},
+	"eGaPf1": {Message: "All providers have failed", Status: http.StatusServiceUnavailable, Retryable: true},
+	"eCRP68": {Message: "All providers failed in round-robin mode", Status: http.StatusServiceUnavailable, Retryable: true},
+	"ePrRnf": {Message: "To-symbol (quote) not found in response from API provider", Status: http.StatusNotFound},
+	"eAGn2c": {Message: "Got non-200 response code from API provider (status: %d)", Status: http.StatusBadGateway, Public: "Got an error response from the rate provider", Retryable: true, Fields: []string{"status"}},
+	"ePrNo1": {Message: "No providers enabled", Status: http.StatusServiceUnavailable},
+	"ePrUk1": {Message: "Unknown provider '%s'"},
+}
+
+var catalogueOnce sync.Once
+
+// mergeCatalogue merges the error codes of the engines into the error catalogue, the first time an engine is built
+func mergeCatalogue() {
+	catalogueOnce.Do(func() {
+		e.MergeCatalogue(errorMap)
+	})
+}
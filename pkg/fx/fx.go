@@ -0,0 +1,242 @@
+// Package fx Gets and converts exchange rates in-process, e.g. in batch jobs, without running the HTTP server.
+// Each Engine has its own providers, strategy and cache, so several can run in the same process.
+package fx
+
+import (
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"fx-service/internal/service/providers"
+	"fx-service/internal/service/rates"
+	"fx-service/internal/service/spreads"
+	"fx-service/pkg/config"
+	"fx-service/pkg/currency"
+	"fx-service/pkg/decimal"
+	"fx-service/pkg/e"
+	"fx-service/pkg/logger"
+)
+
+// Engine gets and converts exchange rates from its providers, through its cache
+type Engine struct {
+	service   *rates.Service
+	mode      config.Mode
+	rounding  config.Rounding
+	cache     Cache
+	ownsCache bool // The cache was created by the engine, and is emptied on Close
+	log       *logger.Logger
+	now       func() time.Time
+	closed    atomic.Bool
+}
+
+// Rate is an exchange rate between two currencies
+type Rate struct {
+	Base     string
+	Quote    string
+	Rate     decimal.Decimal
+	Cached   bool
+	Provider string // The provider which served the rate, unless it was cached
+	Time     time.Time
+}
+
+// Rates are the exchange rates between a base currency and several quote currencies
+type Rates struct {
+	Base     string
+	Rates    map[string]decimal.Decimal
+	Cached   bool   // All the rates were cached
+	Provider string // The provider which served the rates which were not cached, if any
+	Time     time.Time
+}
+
+// Conversion is an amount converted between two currencies
+type Conversion struct {
+	Base     string
+	Quote    string
+	Amount   decimal.Decimal
+	Rate     decimal.Decimal
+	Result   decimal.Decimal // The converted amount, rounded to the minor units of the quote currency
+	Decimals int             // The minor units of the quote currency
+	Cached   bool
+	Provider string // The provider which served the rate, unless it was cached
+	Time     time.Time
+}
+
+// New builds an engine with the given options. At least one provider is required (see WithProvider).
+func New(opts ...Option) (*Engine, error) {
+	mergeCatalogue()
+
+	o := &options{
+		providers: make(map[string]providers.ProviderInterface),
+		priority:  make(map[string]uint),
+		timeout:   defaultTimeout,
+		mode:      config.First,
+		rounding:  config.HalfEven,
+		now:       time.Now,
+	}
+	for _, opt := range opts {
+		if err := opt(o); err != nil {
+			return nil, err
+		}
+	}
+	if len(o.providers) == 0 {
+		return nil, e.FromCode("ePrNo1")
+	}
+
+	engine := &Engine{
+		mode:     o.mode,
+		rounding: o.rounding,
+		cache:    o.cache,
+		log:      o.log,
+		now:      o.now,
+	}
+	if engine.cache == nil {
+		engine.cache = NewMemoryCache(defaultCacheExpiry)
+		engine.ownsCache = true
+	}
+	if cache, ok := engine.cache.(clockSetter); ok {
+		cache.SetClock(o.now)
+	}
+	if engine.log == nil {
+		engine.log = logger.NewLogger()
+	}
+	engine.service = rates.NewService(rates.NewProviderSet(o.providers, o.priority), engine.cache)
+
+	return engine, nil
+}
+
+// parseCurrency makes the currency code uppercase, and checks it is a known currency
+func parseCurrency(code string) (string, error) {
+	code = strings.ToUpper(code)
+	if !currency.IsKnown(code) {
+		return "", e.FromCode("eFxCc1", code)
+	}
+	return code, nil
+}
+
+// parsePair parses the base and quote currencies
+func parsePair(from, to string) (string, string, error) {
+	from, err := parseCurrency(from)
+	if err != nil {
+		return "", "", err
+	}
+	to, err = parseCurrency(to)
+	if err != nil {
+		return "", "", err
+	}
+	return from, to, nil
+}
+
+// providerName returns the name of the provider, or an empty string
+func providerName(name *string) string {
+	if name == nil {
+		return ""
+	}
+	return *name
+}
+
+// checkOpen returns an error once the engine is closed
+func (engine *Engine) checkOpen() error {
+	if engine.closed.Load() {
+		return e.FromCode("eFxCl1")
+	}
+	return nil
+}
+
+// Rate returns the exchange rate between two currencies, from the cache or the providers
+func (engine *Engine) Rate(from, to string) (*Rate, error) {
+	if err := engine.checkOpen(); err != nil {
+		return nil, err
+	}
+	from, to, err := parsePair(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := engine.service.GetRate(from, to, engine.mode)
+	if err != nil {
+		return nil, err
+	}
+	engine.log.Debug("Got rate", map[string]interface{}{"base": from, "quote": to, "cached": result.WasCached})
+
+	return &Rate{
+		Base:     from,
+		Quote:    to,
+		Rate:     result.Rate,
+		Cached:   result.WasCached,
+		Provider: providerName(result.Provider),
+		Time:     engine.now(),
+	}, nil
+}
+
+// Rates returns the exchange rates between a base currency and several quote currencies.
+// The rates which are not cached are fetched in a single call to a provider.
+func (engine *Engine) Rates(from string, to ...string) (*Rates, error) {
+	if err := engine.checkOpen(); err != nil {
+		return nil, err
+	}
+	from, err := parseCurrency(from)
+	if err != nil {
+		return nil, err
+	}
+	quotes := make([]string, len(to))
+	for i, code := range to {
+		if quotes[i], err = parseCurrency(code); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := engine.service.GetRates(from, quotes, engine.mode)
+	if err != nil {
+		return nil, err
+	}
+	engine.log.Debug("Got rates", map[string]interface{}{"base": from, "quotes": quotes, "cached": result.WasCached})
+
+	return &Rates{
+		Base:     from,
+		Rates:    result.Rates,
+		Cached:   result.WasCached,
+		Provider: providerName(result.Provider),
+		Time:     engine.now(),
+	}, nil
+}
+
+// Convert converts an amount between two currencies, rounded to the minor units of the quote currency
+func (engine *Engine) Convert(from, to string, amount decimal.Decimal) (*Conversion, error) {
+	if err := engine.checkOpen(); err != nil {
+		return nil, err
+	}
+	from, to, err := parsePair(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := engine.service.Convert(from, to, amount, engine.mode, engine.rounding, spreads.Client{})
+	if err != nil {
+		return nil, err
+	}
+	engine.log.Debug("Converted amount", map[string]interface{}{"base": from, "quote": to, "cached": result.WasCached})
+
+	return &Conversion{
+		Base:     from,
+		Quote:    to,
+		Amount:   amount,
+		Rate:     result.Rate,
+		Result:   result.Result,
+		Decimals: result.Decimals,
+		Cached:   result.WasCached,
+		Provider: providerName(result.Provider),
+		Time:     engine.now(),
+	}, nil
+}
+
+// Close empties the cache of the engine, unless it was given with WithCache.
+// The engine returns an error from every call afterwards. Closing it again does nothing.
+func (engine *Engine) Close() error {
+	if engine.closed.Swap(true) {
+		return nil
+	}
+	if cache, ok := engine.cache.(clearer); ok && engine.ownsCache {
+		cache.Clear()
+	}
+	return nil
+}
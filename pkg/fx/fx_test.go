@@ -0,0 +1,168 @@
+package fx
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"fx-service/pkg/config"
+	"fx-service/pkg/decimal"
+	"fx-service/pkg/e"
+)
+
+// fixedProvider is a provider which serves the same rate for every pair, and counts its calls
+type fixedProvider struct {
+	name  string
+	rate  decimal.Decimal
+	fail  bool
+	calls atomic.Int32
+}
+
+func (p *fixedProvider) GetName() string { return p.name }
+
+func (p *fixedProvider) GetRate(from, to string) (decimal.Decimal, error) {
+	p.calls.Add(1)
+	if p.fail {
+		return decimal.Decimal{}, errors.New("upstream is down")
+	}
+	return p.rate, nil
+}
+
+func (p *fixedProvider) GetRates(from string, to []string) (map[string]decimal.Decimal, error) {
+	p.calls.Add(1)
+	if p.fail {
+		return nil, errors.New("upstream is down")
+	}
+	rates := make(map[string]decimal.Decimal, len(to))
+	for _, currency := range to {
+		rates[currency] = p.rate
+	}
+	return rates, nil
+}
+
+// waitCached waits for the rate to be saved in the cache, as the engine saves it asynchronously
+func waitCached(t *testing.T, cache Cache, from, to string) {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		if cache.Get(from, to) != nil {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("expected %s/%s to be cached", from, to)
+}
+
+// TestEngines checks that engines in the same process have their own providers and caches
+func TestEngines(t *testing.T) {
+	cacheA, cacheB := NewMemoryCache(60), NewMemoryCache(60)
+	a, err := New(WithCustomProvider(&fixedProvider{name: "a", rate: decimal.MustParse("0.9")}), WithCache(cacheA))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := New(WithCustomProvider(&fixedProvider{name: "b", rate: decimal.MustParse("0.8")}), WithCache(cacheB))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rateA, err := a.Rate("usd", "eur")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rateA.Base != "USD" || rateA.Rate.String() != "0.9" || rateA.Provider != "a" || rateA.Cached {
+		t.Errorf("expected 0.9 from a, got %+v", rateA)
+	}
+	waitCached(t, cacheA, "USD", "EUR")
+
+	rateB, err := b.Rate("USD", "EUR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rateB.Rate.String() != "0.8" || rateB.Provider != "b" || rateB.Cached {
+		t.Errorf("expected 0.8 from b, not from the cache of a, got %+v", rateB)
+	}
+
+	rateA, err = a.Rate("USD", "EUR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rateA.Rate.String() != "0.9" || !rateA.Cached {
+		t.Errorf("expected 0.9 from the cache of a, got %+v", rateA)
+	}
+}
+
+// TestEngineStrategy checks the providers are tried in the order given, in priority mode
+func TestEngineStrategy(t *testing.T) {
+	down := &fixedProvider{name: "down", fail: true}
+	backup := &fixedProvider{name: "backup", rate: decimal.MustParse("151.235")}
+	engine, err := New(WithCustomProvider(down), WithCustomProvider(backup), WithStrategy(config.Priority))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer engine.Close()
+
+	rates, err := engine.Rates("USD", "JPY", "EUR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rates.Rates["JPY"].String() != "151.235" || rates.Provider != "backup" || down.calls.Load() != 1 {
+		t.Errorf("expected the rates from backup, after down failed, got %+v", rates)
+	}
+
+	conversion, err := engine.Convert("USD", "JPY", decimal.MustParse("10.5"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conversion.Result.String() != "1588" || conversion.Decimals != 0 {
+		t.Errorf("expected 1588 JPY, got %+v", conversion)
+	}
+}
+
+// TestEngineClock checks the rates in the default cache expire with the clock of the engine
+func TestEngineClock(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	provider := &fixedProvider{name: "fixed", rate: decimal.MustParse("0.9")}
+	engine, err := New(WithCustomProvider(provider), WithClock(func() time.Time { return now }))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rate, err := engine.Rate("USD", "EUR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rate.Time.Equal(now) {
+		t.Errorf("expected the time of the clock, got %v", rate.Time)
+	}
+	waitCached(t, engine.cache, "USD", "EUR")
+
+	now = now.Add(2 * time.Hour)
+	if rate, err = engine.Rate("USD", "EUR"); err != nil || rate.Cached || provider.calls.Load() != 2 {
+		t.Errorf("expected the rate to expire after an hour, got %+v (%v)", rate, err)
+	}
+
+	if err := engine.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := engine.Rate("USD", "EUR"); e.FromError(err).GetCode() != "eFxCl1" {
+		t.Errorf("expected the engine to be closed, got %v", err)
+	}
+}
+
+// TestNewErrors checks the errors of invalid options and currencies
+func TestNewErrors(t *testing.T) {
+	if _, err := New(); e.FromError(err).GetCode() != "ePrNo1" {
+		t.Errorf("expected an error without providers, got %v", err)
+	}
+	if _, err := New(WithProvider("NoSuchApi", "key")); e.FromError(err).GetCode() != "ePrUk1" {
+		t.Errorf("expected an error for an unknown provider, got %v", err)
+	}
+
+	engine, err := New(WithProvider("FixerApi", "key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := engine.Rate("USD", "XYZ"); e.FromError(err).GetCode() != "eFxCc1" {
+		t.Errorf("expected an error for an unknown currency, got %v", err)
+	}
+}
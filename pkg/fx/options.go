@@ -0,0 +1,108 @@
+package fx
+
+import (
+	"time"
+
+	"fx-service/internal/service/providers"
+	"fx-service/pkg/config"
+	"fx-service/pkg/logger"
+)
+
+// defaultTimeout is the timeout of the calls to the providers, in seconds, as in the default config
+const defaultTimeout = 10
+
+// defaultCacheExpiry is the expiry of the rates in the default cache, in seconds, as in the default config
+const defaultCacheExpiry = 60 * 60
+
+// Option configures an Engine (see New)
+type Option func(opts *options) error
+
+// options is what the options configure, before the engine is built
+type options struct {
+	providers map[string]providers.ProviderInterface
+	priority  map[string]uint
+	timeout   int
+	mode      config.Mode
+	rounding  config.Rounding
+	cache     Cache
+	log       *logger.Logger
+	now       func() time.Time
+}
+
+// addProvider adds a provider, with the next priority: providers are tried in the order given, in priority mode
+func (opts *options) addProvider(name string, provider providers.ProviderInterface) {
+	opts.providers[name] = provider
+	opts.priority[name] = uint(len(opts.priority) + 1)
+}
+
+// WithTimeout sets the timeout of the calls to the providers added after it, in seconds. The default is 10 seconds.
+func WithTimeout(seconds int) Option {
+	return func(opts *options) error {
+		opts.timeout = seconds
+		return nil
+	}
+}
+
+// WithProvider adds one of the providers of the service, by its name in the config (e.g. "FixerApi"), with its API key.
+// The API key is not checked: a provider with an invalid key fails on each call, and the strategy moves on.
+func WithProvider(name, apiKey string) Option {
+	return func(opts *options) error {
+		provider, err := providers.NewProvider(name, apiKey, opts.timeout)
+		if err != nil {
+			return err
+		}
+		opts.addProvider(name, provider)
+		return nil
+	}
+}
+
+// WithCustomProvider adds a provider of your own, e.g. an internal rates feed, or a fixed rate in tests
+func WithCustomProvider(provider Provider) Option {
+	return func(opts *options) error {
+		opts.addProvider(provider.GetName(), customProvider{provider})
+		return nil
+	}
+}
+
+// WithStrategy sets how the providers are called (see config.Mode). The default is config.First.
+func WithStrategy(mode config.Mode) Option {
+	return func(opts *options) error {
+		opts.mode = mode
+		return nil
+	}
+}
+
+// WithRounding sets how converted amounts are rounded to the minor units of the quote currency.
+// The default is config.HalfEven.
+func WithRounding(rounding config.Rounding) Option {
+	return func(opts *options) error {
+		opts.rounding = rounding
+		return nil
+	}
+}
+
+// WithCache sets where the engine keeps the rates it got from the providers.
+// The default is an in-memory cache of its own, where the rates expire after an hour (see NewMemoryCache).
+func WithCache(cache Cache) Option {
+	return func(opts *options) error {
+		opts.cache = cache
+		return nil
+	}
+}
+
+// WithLogger sets the logger of the engine. The default logger writes JSON to stdout.
+func WithLogger(log *logger.Logger) Option {
+	return func(opts *options) error {
+		opts.log = log
+		return nil
+	}
+}
+
+// WithClock sets the clock of the engine, used for the time of the results and the expiry of the in-memory cache.
+// Use it to replay rates, or in tests. The default is time.Now.
+func WithClock(now func() time.Time) Option {
+	return func(opts *options) error {
+		opts.now = now
+		return nil
+	}
+}
@@ -0,0 +1,37 @@
+package fx
+
+import (
+	"fx-service/internal/service/providers"
+	"fx-service/pkg/decimal"
+)
+
+// Provider is an upstream source of exchange rates, for WithCustomProvider
+type Provider interface {
+	GetName() string
+	GetRate(from, to string) (decimal.Decimal, error)
+	GetRates(from string, to []string) (map[string]decimal.Decimal, error)
+}
+
+// customProvider calls a Provider from the strategies of the service
+type customProvider struct {
+	Provider
+}
+
+// CheckApiKey always succeeds, as custom providers handle their own credentials
+func (p customProvider) CheckApiKey() bool {
+	return true
+}
+
+// Supports always succeeds: a custom provider returns an error for the currencies it does not support
+func (p customProvider) Supports(currency string) bool {
+	return true
+}
+
+// GetRates returns the rates of the custom provider as a providers.RateList
+func (p customProvider) GetRates(from string, to []string) (providers.RateList, error) {
+	rates, err := p.Provider.GetRates(from, to)
+	if err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
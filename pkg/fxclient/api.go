@@ -0,0 +1,163 @@
+package fxclient
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"fx-service/pkg/currency"
+	"fx-service/pkg/decimal"
+)
+
+// Price is the bid, mid and ask prices of a rate, when spreads are enabled on the service
+type Price struct {
+	Bid decimal.Decimal `json:"bid"`
+	Mid decimal.Decimal `json:"mid"`
+	Ask decimal.Decimal `json:"ask"`
+}
+
+// Rate is the exchange rate between two currencies, as returned by /rate/{from}/{to}
+type Rate struct {
+	Base     string           `json:"base"`
+	Quote    string           `json:"quote"`
+	Rate     decimal.Decimal  `json:"rate"`
+	Cached   bool             `json:"cached"`
+	Override bool             `json:"override"` // The rate was forced by a manual override
+	Bid      *decimal.Decimal `json:"bid"`      // The prices, when spreads are enabled
+	Mid      *decimal.Decimal `json:"mid"`
+	Ask      *decimal.Decimal `json:"ask"`
+	Provider *string          `json:"provider"` // When the service shows the providers
+}
+
+// Rates are the exchange rates between a base currency and several quote currencies, as returned by /rates
+type Rates struct {
+	Base      string                     `json:"base"`
+	Quotes    map[string]decimal.Decimal `json:"quotes"`
+	Cached    bool                       `json:"cached"`
+	Overrides []string                   `json:"overrides"` // The quote currencies whose rates were forced by a manual override
+	Prices    map[string]Price           `json:"prices"`    // The prices by quote currency, when spreads are enabled
+	Provider  *string                    `json:"provider"`  // When the service shows the providers
+}
+
+// Conversion is an amount converted between two currencies, as returned by /convert
+type Conversion struct {
+	Base      string           `json:"base"`
+	Quote     string           `json:"quote"`
+	Amount    decimal.Decimal  `json:"amount"`
+	Rate      decimal.Decimal  `json:"rate"`
+	Result    decimal.Decimal  `json:"result"`   // The converted amount, rounded to the minor units of the quote currency
+	Decimals  int              `json:"decimals"` // The minor units of the quote currency
+	Rounding  string           `json:"rounding"`
+	Formatted string           `json:"formatted"` // When asked for with ConvertOptions.Format
+	Cached    bool             `json:"cached"`
+	Override  bool             `json:"override"`
+	Bid       *decimal.Decimal `json:"bid"` // The prices, when spreads are enabled. The amount is then converted at the bid
+	Mid       *decimal.Decimal `json:"mid"`
+	Ask       *decimal.Decimal `json:"ask"`
+	Provider  *string          `json:"provider"` // When the service shows the providers
+}
+
+// ConvertOptions are the optional parameters of a conversion
+type ConvertOptions struct {
+	Rounding string // "half-even", "half-up" or "truncate". Empty for the rounding of the service
+	Format   bool   // Also return the result formatted with thousands separators
+}
+
+// Currency is an enabled currency, with its metadata, as returned by /currencies
+type Currency struct {
+	currency.Currency
+	Supported bool     `json:"supported"` // Whether any enabled provider supports the currency
+	Providers []string `json:"providers"` // When the service shows the providers
+}
+
+// Status is the strategy, the request counts, the providers and the cache expiry of the service, as returned by /status
+type Status struct {
+	Mode  string `json:"mode"`
+	Stats struct {
+		HitCount     uint64            `json:"hitCount"`
+		RequestCount uint64            `json:"requestCount"`
+		ErrorCount   uint64            `json:"errorCount"`
+		FailCount    uint64            `json:"failCount"`
+		PathCount    map[string]uint64 `json:"pathCount"`
+	} `json:"stats"`
+	Providers struct {
+		Enabled   []string `json:"enabled"`
+		Available []string `json:"available"`
+	} `json:"providers"`
+	Cache struct {
+		DefaultTTL   int            `json:"defaultTtl"`   // In seconds
+		TTLRules     map[string]int `json:"ttlRules"`     // In seconds, by rule
+		EffectiveTTL map[string]int `json:"effectiveTtl"` // In seconds, by pair
+	} `json:"cache"`
+}
+
+// Health is the health of the service, as returned by /health
+type Health struct {
+	Status string `json:"status"`
+}
+
+// Rate returns the exchange rate between two currencies
+func (client *Client) Rate(ctx context.Context, from, to string) (*Rate, error) {
+	var result Rate
+	if err := client.get(ctx, "/rate/"+url.PathEscape(from)+"/"+url.PathEscape(to), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Rates returns the exchange rates between a base currency and several quote currencies
+func (client *Client) Rates(ctx context.Context, from string, to ...string) (*Rates, error) {
+	query := url.Values{"from": {from}, "to": {strings.Join(to, ",")}}
+	var result Rates
+	if err := client.get(ctx, "/rates", query, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Convert converts an amount between two currencies. The options may be nil.
+func (client *Client) Convert(ctx context.Context, from, to string, amount decimal.Decimal, opts *ConvertOptions) (*Conversion, error) {
+	query := url.Values{"from": {from}, "to": {to}, "amount": {amount.String()}}
+	if opts != nil {
+		if opts.Rounding != "" {
+			query.Set("rounding", opts.Rounding)
+		}
+		if opts.Format {
+			query.Set("format", "true")
+		}
+	}
+	var result Conversion
+	if err := client.get(ctx, "/convert", query, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Currencies returns the currencies enabled on the service
+func (client *Client) Currencies(ctx context.Context) ([]Currency, error) {
+	var result struct {
+		Currencies []Currency `json:"currencies"`
+	}
+	if err := client.get(ctx, "/currencies", nil, &result); err != nil {
+		return nil, err
+	}
+	return result.Currencies, nil
+}
+
+// Status returns the strategy, the request counts, the providers and the cache expiry of the service
+func (client *Client) Status(ctx context.Context) (*Status, error) {
+	var result Status
+	if err := client.get(ctx, "/status", nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Health returns the health of the service. An unreachable or unhealthy service returns an error.
+func (client *Client) Health(ctx context.Context) (*Health, error) {
+	var result Health
+	if err := client.get(ctx, "/health", nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
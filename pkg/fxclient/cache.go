@@ -0,0 +1,111 @@
+package fxclient
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cachedResponse is the body of a response, until it expires
+type cachedResponse struct {
+	body    []byte
+	expires time.Time
+}
+
+// responseCache keeps the bodies of the responses, by API key and URL
+type responseCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]cachedResponse
+}
+
+func newResponseCache(maxEntries int) *responseCache {
+	return &responseCache{
+		maxEntries: maxEntries,
+		entries:    make(map[string]cachedResponse),
+	}
+}
+
+// get returns the body of a fresh response, or nil
+func (rc *responseCache) get(key string, now time.Time) []byte {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	entry, ok := rc.entries[key]
+	if !ok {
+		return nil
+	}
+	if !now.Before(entry.expires) {
+		delete(rc.entries, key)
+		return nil
+	}
+	return entry.body
+}
+
+// set keeps the body of a response until it expires.
+// When the cache is full, the expired entries are removed first, or else the entry expiring the soonest.
+func (rc *responseCache) set(key string, body []byte, expires, now time.Time) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if rc.maxEntries <= 0 {
+		return
+	}
+	if _, exists := rc.entries[key]; !exists && len(rc.entries) >= rc.maxEntries {
+		rc.evict(now)
+	}
+	rc.entries[key] = cachedResponse{body: body, expires: expires}
+}
+
+// evict removes the expired entries, or else the entry expiring the soonest. The caller must hold the lock.
+func (rc *responseCache) evict(now time.Time) {
+	for key, entry := range rc.entries {
+		if !now.Before(entry.expires) {
+			delete(rc.entries, key)
+		}
+	}
+	if len(rc.entries) < rc.maxEntries {
+		return
+	}
+
+	var soonest string
+	for key, entry := range rc.entries {
+		if soonest == "" || entry.expires.Before(rc.entries[soonest].expires) {
+			soonest = key
+		}
+	}
+	delete(rc.entries, soonest)
+}
+
+// cacheExpiry returns when a response expires, from its Cache-Control or Expires headers.
+// Returns false if the response must not be cached.
+func cacheExpiry(header http.Header, now time.Time) (time.Time, bool) {
+	if cacheControl := header.Get("Cache-Control"); cacheControl != "" {
+		maxAge := -1
+		for _, directive := range strings.Split(cacheControl, ",") {
+			name, value, _ := strings.Cut(strings.TrimSpace(strings.ToLower(directive)), "=")
+			switch name {
+			case "no-store", "no-cache":
+				return time.Time{}, false
+			case "max-age":
+				if seconds, err := strconv.Atoi(strings.Trim(value, `"`)); err == nil {
+					maxAge = seconds
+				}
+			}
+		}
+		if maxAge >= 0 {
+			if age, err := strconv.Atoi(header.Get("Age")); err == nil {
+				maxAge -= age
+			}
+			return now.Add(time.Duration(maxAge) * time.Second), maxAge > 0
+		}
+	}
+
+	if expires := header.Get("Expires"); expires != "" {
+		date, err := http.ParseTime(expires)
+		return date, err == nil && date.After(now)
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,205 @@
+// Package fxclient Calls the REST API of the FX service, with typed responses, retries and an optional cache
+package fxclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// apiKeyHeader identifies the client, for client-specific spreads
+const apiKeyHeader = "X-API-Key"
+
+const (
+	defaultMaxRetries    = 3
+	defaultRetryDelay    = 500 * time.Millisecond
+	defaultMaxRetryDelay = 30 * time.Second // The back-off delay, or the Retry-After delay, never grows beyond this
+)
+
+// Client calls the REST API of an FX service. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	apiKey     string
+	maxRetries int           // Retries of a request answered with 429 or 503, after the first attempt
+	retryDelay time.Duration // Delay before the first retry, without Retry-After. Doubled after every retry
+	cache      *responseCache
+	now        func() time.Time
+}
+
+// Option configures a Client (see New)
+type Option func(client *Client)
+
+// WithHTTPClient sets the HTTP client used for the requests, e.g. for its timeout or transport.
+// The default is http.DefaultClient.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(client *Client) {
+		client.httpClient = httpClient
+	}
+}
+
+// WithAPIKey sets the API key sent with each request, for the client-specific spreads
+func WithAPIKey(apiKey string) Option {
+	return func(client *Client) {
+		client.apiKey = apiKey
+	}
+}
+
+// WithRetries sets how many times a request answered with a retryable error is retried, and the delay before the first retry.
+// The errors are retryable when the service flags them so, e.g. when all the providers failed, or for a 429 or 503 without a flag.
+// The delay doubles after every retry, unless the response has a Retry-After header. The default is 3 retries after 500ms.
+// Use 0 retries to disable them.
+func WithRetries(maxRetries int, delay time.Duration) Option {
+	return func(client *Client) {
+		client.maxRetries = maxRetries
+		client.retryDelay = delay
+	}
+}
+
+// WithCache keeps up to maxEntries responses in memory, for as long as their Cache-Control or Expires headers allow.
+// Responses without these headers are not cached.
+func WithCache(maxEntries int) Option {
+	return func(client *Client) {
+		client.cache = newResponseCache(maxEntries)
+	}
+}
+
+// New returns a client of the API served at the base URL, e.g. "https://fx.example.com" or "http://localhost:8080/fx"
+func New(baseURL string, opts ...Option) *Client {
+	client := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: http.DefaultClient,
+		maxRetries: defaultMaxRetries,
+		retryDelay: defaultRetryDelay,
+		now:        time.Now,
+	}
+	for _, opt := range opts {
+		opt(client)
+	}
+	return client
+}
+
+// envelope is the standard response shape of the API
+type envelope struct {
+	Result json.RawMessage `json:"result"`
+	Error  json.RawMessage `json:"error"`
+}
+
+// get sends a GET request, and decodes the result of the response into result
+func (client *Client) get(ctx context.Context, path string, query url.Values, result interface{}) error {
+	target := client.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	body, err := client.cached(ctx, target)
+	if err != nil {
+		return err
+	}
+
+	var response envelope
+	if err := json.Unmarshal(body, &response); err != nil {
+		return fmt.Errorf("fxclient: invalid response from %s: %w", path, err)
+	}
+	return json.Unmarshal(response.Result, result)
+}
+
+// cached returns the body of a successful response, from the cache if it's still fresh
+func (client *Client) cached(ctx context.Context, target string) ([]byte, error) {
+	key := client.apiKey + " " + target
+	if client.cache != nil {
+		if body := client.cache.get(key, client.now()); body != nil {
+			return body, nil
+		}
+	}
+
+	res, body, err := client.send(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+
+	if client.cache != nil {
+		if expires, ok := cacheExpiry(res.Header, client.now()); ok {
+			client.cache.set(key, body, expires, client.now())
+		}
+	}
+	return body, nil
+}
+
+// send sends a GET request, retrying it with back-off while it's answered with a retryable error.
+// Returns the response and its body when successful, or an *Error.
+func (client *Client) send(ctx context.Context, target string) (*http.Response, []byte, error) {
+	delay := client.retryDelay
+	for attempt := 0; ; attempt++ {
+		res, body, err := client.attempt(ctx, target)
+		if err != nil {
+			return nil, nil, err
+		}
+		if res.StatusCode >= 200 && res.StatusCode < 300 {
+			return res, body, nil
+		}
+
+		apiErr := newError(res, body, client.now())
+		if !apiErr.Retryable || attempt >= client.maxRetries {
+			return nil, nil, apiErr
+		}
+
+		wait := delay
+		if apiErr.RetryAfter > 0 {
+			wait = apiErr.RetryAfter
+		}
+		timer := time.NewTimer(min(wait, defaultMaxRetryDelay))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, nil, ctx.Err()
+		}
+		delay = min(delay*2, defaultMaxRetryDelay)
+	}
+}
+
+// attempt sends a single GET request, and reads the body of the response
+func (client *Client) attempt(ctx context.Context, target string) (*http.Response, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if client.apiKey != "" {
+		req.Header.Set(apiKeyHeader, client.apiKey)
+	}
+
+	res, err := client.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	return res, body, nil
+}
+
+// retryAfter parses a Retry-After header, given in seconds or as an HTTP date. Returns 0 if there's none.
+func retryAfter(header http.Header, now time.Time) time.Duration {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
@@ -0,0 +1,178 @@
+package fxclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"fx-service/internal/application"
+	"fx-service/internal/router"
+	"fx-service/internal/service/providers"
+	"fx-service/internal/service/ratecache"
+	"fx-service/pkg/config"
+	"fx-service/pkg/decimal"
+	"fx-service/pkg/e"
+	"fx-service/pkg/logger"
+)
+
+// newServer serves the API with the net/http router, with USD/EUR and USD/JPY in the cache and no providers
+func newServer(t *testing.T) *httptest.Server {
+	e.SetCatalogue(application.GetErrorMap())
+	previous := providers.EnabledProviders
+	providers.EnabledProviders = map[string]providers.ProviderInterface{}
+	t.Cleanup(func() { providers.EnabledProviders = previous })
+
+	cache := ratecache.GetInstance()
+	cache.SetExpiry(3600)
+	cache.Clear()
+	t.Cleanup(cache.Clear)
+	cache.Set("USD", "EUR", decimal.MustParse("0.9"))
+	cache.Set("USD", "JPY", decimal.MustParse("151.235"))
+
+	log := logger.NewLogger()
+	log.Out = io.Discard
+	r := router.NewHTTPRouter(log, &config.Config{
+		CurrenciesEnabled: []string{"USD", "EUR", "GBP", "JPY"},
+		Mode:              config.First,
+		Rounding:          config.HalfEven,
+		RateLimiter:       config.RateLimiterConfig{Enabled: true, MaxRequests: 1000, Timeframe: 1},
+	})
+	r.RegisterMiddleware()
+	r.RegisterRoutes()
+
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// TestClient checks the typed responses and errors, against the API itself
+func TestClient(t *testing.T) {
+	client := New(newServer(t).URL, WithRetries(0, 0))
+	ctx := context.Background()
+
+	rate, err := client.Rate(ctx, "USD", "EUR")
+	if err != nil || rate.Rate.String() != "0.9" || !rate.Cached || rate.Bid != nil {
+		t.Errorf("expected the cached USD/EUR rate, got %+v (%v)", rate, err)
+	}
+
+	rates, err := client.Rates(ctx, "USD", "EUR", "JPY")
+	if err != nil || rates.Quotes["JPY"].String() != "151.235" || !rates.Cached {
+		t.Errorf("expected the cached USD rates, got %+v (%v)", rates, err)
+	}
+
+	conversion, err := client.Convert(ctx, "USD", "JPY", decimal.MustParse("10.5"), &ConvertOptions{Format: true})
+	if err != nil || conversion.Result.String() != "1588" || conversion.Formatted != "1,588" || conversion.Decimals != 0 {
+		t.Errorf("expected 1588 JPY, got %+v (%v)", conversion, err)
+	}
+
+	currencies, err := client.Currencies(ctx)
+	if err != nil || len(currencies) != 4 || currencies[1].Code != "EUR" || currencies[1].MinorUnits != 2 {
+		t.Errorf("expected the 4 enabled currencies, got %+v (%v)", currencies, err)
+	}
+
+	status, err := client.Status(ctx)
+	if err != nil || status.Mode != "first" || status.Cache.DefaultTTL != 3600 {
+		t.Errorf("expected the status of the service, got %+v (%v)", status, err)
+	}
+
+	health, err := client.Health(ctx)
+	if err != nil || health.Status != "healthy" {
+		t.Errorf("expected a healthy service, got %+v (%v)", health, err)
+	}
+
+	var apiErr *Error
+	_, err = client.Rate(ctx, "USD", "XXX")
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest || apiErr.Code != "eRqCq1" ||
+		apiErr.Message != "Invalid quote currency code, XXX" {
+		t.Errorf("expected an invalid currency error, got %v", err)
+	}
+
+	// GBP is not cached, and there are no providers
+	_, err = client.Rate(ctx, "USD", "GBP")
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable || apiErr.Code != "eGaPf1" ||
+		apiErr.Message != "All providers have failed" || !apiErr.Retryable {
+		t.Errorf("expected the eGaPf1 error, got %v", err)
+	}
+}
+
+// TestClientRetries checks requests answered with 429 and 503 are retried, after the delay asked for by Retry-After
+func TestClientRetries(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"result":null,"error":"Rate limit exceeded"}`))
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			_, _ = w.Write([]byte(`{"result":{"status":"healthy"}}`))
+		}
+	}))
+	defer server.Close()
+
+	start := time.Now()
+	health, err := New(server.URL, WithRetries(3, 10*time.Millisecond)).Health(context.Background())
+	if err != nil || health.Status != "healthy" || calls.Load() != 3 {
+		t.Fatalf("expected a healthy service after 2 retries, got %+v (%v) after %d calls", health, err, calls.Load())
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("expected the client to wait for Retry-After, waited %v", elapsed)
+	}
+
+	calls.Store(1)
+	var apiErr *Error
+	_, err = New(server.URL, WithRetries(0, 0)).Health(context.Background())
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected the 503 error without retries, got %v", err)
+	}
+
+	// A cancelled context stops the retries
+	calls.Store(0)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err = New(server.URL).Health(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the deadline to stop the retries, got %v", err)
+	}
+}
+
+// TestClientCache checks responses are cached for as long as their Cache-Control header allows
+func TestClientCache(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.URL.Path == "/rate/USD/EUR" {
+			w.Header().Set("Cache-Control", "public, max-age=60")
+		}
+		_, _ = w.Write([]byte(`{"result":{"base":"USD","quote":"EUR","rate":"0.9"}}`))
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	client := New(server.URL, WithCache(10))
+	client.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Rate(ctx, "USD", "EUR"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.Rate(ctx, "USD", "GBP"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls.Load() != 3 {
+		t.Errorf("expected USD/EUR to be cached and USD/GBP not, got %d calls", calls.Load())
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := client.Rate(ctx, "USD", "EUR"); err != nil || calls.Load() != 4 {
+		t.Errorf("expected USD/EUR to expire after a minute, got %d calls (%v)", calls.Load(), err)
+	}
+}
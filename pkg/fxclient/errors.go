@@ -0,0 +1,65 @@
+package fxclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+// exceptionPattern matches the errors of the service sent as text, e.g. "Code: eGaPf1, Message: All providers have failed"
+var exceptionPattern = regexp.MustCompile(`(?s)^Code: ([^,]*), Message: (.*?)(?:, Previous: .*)?$`)
+
+// Error is an error response of the API, with the error code of the service when it has one
+type Error struct {
+	StatusCode int
+	Code       string        // The error code of the service, e.g. "eGaPf1". Empty for errors without a code
+	Message    string        // The error message, e.g. "Invalid quote currency code, XXX"
+	Retryable  bool          // Whether the same request may succeed later, as flagged by the service, or for a 429 or 503
+	RetryAfter time.Duration // The delay asked for by the Retry-After header, if any
+}
+
+// Error returns the status, the code and the message of the error
+func (err *Error) Error() string {
+	if err.Code != "" {
+		return fmt.Sprintf("fxclient: %d %s: %s", err.StatusCode, err.Code, err.Message)
+	}
+	return fmt.Sprintf("fxclient: %d: %s", err.StatusCode, err.Message)
+}
+
+// newError decodes the error of a response, sent as {"code", "message"} or as a string
+func newError(res *http.Response, body []byte, now time.Time) *Error {
+	apiErr := &Error{
+		StatusCode: res.StatusCode,
+		Message:    http.StatusText(res.StatusCode),
+		Retryable:  res.StatusCode == http.StatusTooManyRequests || res.StatusCode == http.StatusServiceUnavailable,
+		RetryAfter: retryAfter(res.Header, now),
+	}
+
+	var response envelope
+	if err := json.Unmarshal(body, &response); err != nil || len(response.Error) == 0 {
+		return apiErr
+	}
+
+	var detail struct {
+		Code      string `json:"code"`
+		Message   string `json:"message"`
+		Retryable *bool  `json:"retryable"`
+	}
+	var message string
+	switch {
+	case json.Unmarshal(response.Error, &detail) == nil && detail.Message != "":
+		apiErr.Code, apiErr.Message = detail.Code, detail.Message
+		if detail.Retryable != nil {
+			apiErr.Retryable = *detail.Retryable
+		}
+	case json.Unmarshal(response.Error, &message) == nil && message != "":
+		if match := exceptionPattern.FindStringSubmatch(message); match != nil {
+			apiErr.Code, apiErr.Message = match[1], match[2]
+		} else {
+			apiErr.Message = message
+		}
+	}
+	return apiErr
+}
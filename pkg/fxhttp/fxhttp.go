@@ -0,0 +1,44 @@
+// Package fxhttp Mounts the FX API in an existing net/http server, instead of running it as a separate process
+package fxhttp
+
+import (
+	"net/http"
+
+	"fx-service/internal/application"
+	"fx-service/internal/router"
+	"fx-service/pkg/config"
+	"fx-service/pkg/logger"
+)
+
+// Handler serves the FX API as an http.Handler. Mount it with http.StripPrefix to serve it under a path.
+type Handler struct {
+	http.Handler
+	app *application.App
+}
+
+// New starts the services with the given config (see config.NewConfig), and returns the API as an http.Handler.
+// The API is served with the net/http router, whatever the router in the config. The gRPC API is not served.
+// The error codes of the API are merged into the error catalogue, keeping the codes the program already has.
+// The services are shared by the whole process, so there can only be one Handler at a time. Close it on shutdown.
+func New(cfg config.Config, log *logger.Logger) (*Handler, error) {
+	app := application.NewApp(log)
+	if err := app.UseConfig(&cfg); err != nil {
+		return nil, err
+	}
+	if err := app.Start(); err != nil {
+		_ = app.Close()
+		return nil, err
+	}
+
+	r := router.NewHTTPRouter(log, app.Config)
+	r.RegisterMiddleware()
+	r.RegisterRoutes()
+	app.Router = r
+
+	return &Handler{Handler: r, app: app}, nil
+}
+
+// Close stops the streams and the alerts, and closes the stores
+func (h *Handler) Close() error {
+	return h.app.Close()
+}
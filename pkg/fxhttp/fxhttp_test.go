@@ -0,0 +1,38 @@
+package fxhttp
+
+import (
+	"io"
+	"testing"
+
+	"fx-service/pkg/config"
+	"fx-service/pkg/e"
+	"fx-service/pkg/logger"
+)
+
+// TestNew checks a config which can't start returns an error, rather than exiting the program embedding the API
+func TestNew(t *testing.T) {
+	cfg, err := config.NewConfig("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Providers = map[string]config.ProviderConfig{}
+	log := logger.NewLogger()
+	log.Out = io.Discard
+
+	catalogue := e.Catalogue()
+	e.SetCatalogue(e.ErrorMap{"eHost1": {Message: "An error of the program embedding the API"}})
+	t.Cleanup(func() { e.SetCatalogue(catalogue) })
+
+	handler, err := New(cfg, log)
+	if handler != nil || e.FromError(err).GetCode() != "ePrNo1" {
+		t.Errorf("expected no providers to be enabled, got %v", err)
+	}
+	if _, ok := e.Catalogue()["eHost1"]; !ok {
+		t.Errorf("expected the codes of the program to be kept in the error catalogue")
+	}
+
+	cfg.CurrenciesEnabled = []string{"USD", "XYZ"}
+	if _, err := New(cfg, log); err == nil {
+		t.Errorf("expected an unknown currency to be rejected")
+	}
+}
@@ -0,0 +1,340 @@
+// gRPC API of the FX rate service. Regenerate the Go code in pkg/fxpb with:
+//   protoc -I proto --go_out=. --go_opt=module=fx-service --go-grpc_out=. --go-grpc_opt=module=fx-service proto/fx/v1/fx.proto
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: fx/v1/fx.proto
+
+package fxpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	FxService_GetRate_FullMethodName        = "/fx.v1.FxService/GetRate"
+	FxService_GetRates_FullMethodName       = "/fx.v1.FxService/GetRates"
+	FxService_Convert_FullMethodName        = "/fx.v1.FxService/Convert"
+	FxService_ListCurrencies_FullMethodName = "/fx.v1.FxService/ListCurrencies"
+	FxService_Status_FullMethodName         = "/fx.v1.FxService/Status"
+	FxService_WatchRates_FullMethodName     = "/fx.v1.FxService/WatchRates"
+)
+
+// FxServiceClient is the client API for FxService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// FxService serves the same rates as the REST API. Rates and amounts are exact decimal strings.
+// Errors carry a gRPC status code, and the service error code (e.g. "eGaPf1") in the "error-code" trailer.
+// Clients are identified by the "x-api-key" metadata, for the bid/ask spreads.
+type FxServiceClient interface {
+	// GetRate returns the exchange rate between two currencies
+	GetRate(ctx context.Context, in *GetRateRequest, opts ...grpc.CallOption) (*GetRateResponse, error)
+	// GetRates returns the exchange rates between a base currency and several quote currencies
+	GetRates(ctx context.Context, in *GetRatesRequest, opts ...grpc.CallOption) (*GetRatesResponse, error)
+	// Convert converts an amount, rounded to the minor units of the quote currency
+	Convert(ctx context.Context, in *ConvertRequest, opts ...grpc.CallOption) (*ConvertResponse, error)
+	// ListCurrencies returns the enabled currencies, with their ISO 4217 metadata
+	ListCurrencies(ctx context.Context, in *ListCurrenciesRequest, opts ...grpc.CallOption) (*ListCurrenciesResponse, error)
+	// Status returns the strategy mode, the request counts, the providers and the cache expiry
+	Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error)
+	// WatchRates sends the cached rates of the pairs, then every update, until the client cancels.
+	// Requires streaming to be enabled (stream.enabled in the config).
+	WatchRates(ctx context.Context, in *WatchRatesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RateUpdate], error)
+}
+
+type fxServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewFxServiceClient(cc grpc.ClientConnInterface) FxServiceClient {
+	return &fxServiceClient{cc}
+}
+
+func (c *fxServiceClient) GetRate(ctx context.Context, in *GetRateRequest, opts ...grpc.CallOption) (*GetRateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetRateResponse)
+	err := c.cc.Invoke(ctx, FxService_GetRate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fxServiceClient) GetRates(ctx context.Context, in *GetRatesRequest, opts ...grpc.CallOption) (*GetRatesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetRatesResponse)
+	err := c.cc.Invoke(ctx, FxService_GetRates_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fxServiceClient) Convert(ctx context.Context, in *ConvertRequest, opts ...grpc.CallOption) (*ConvertResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ConvertResponse)
+	err := c.cc.Invoke(ctx, FxService_Convert_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fxServiceClient) ListCurrencies(ctx context.Context, in *ListCurrenciesRequest, opts ...grpc.CallOption) (*ListCurrenciesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListCurrenciesResponse)
+	err := c.cc.Invoke(ctx, FxService_ListCurrencies_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fxServiceClient) Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StatusResponse)
+	err := c.cc.Invoke(ctx, FxService_Status_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fxServiceClient) WatchRates(ctx context.Context, in *WatchRatesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[RateUpdate], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &FxService_ServiceDesc.Streams[0], FxService_WatchRates_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchRatesRequest, RateUpdate]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FxService_WatchRatesClient = grpc.ServerStreamingClient[RateUpdate]
+
+// FxServiceServer is the server API for FxService service.
+// All implementations must embed UnimplementedFxServiceServer
+// for forward compatibility.
+//
+// FxService serves the same rates as the REST API. Rates and amounts are exact decimal strings.
+// Errors carry a gRPC status code, and the service error code (e.g. "eGaPf1") in the "error-code" trailer.
+// Clients are identified by the "x-api-key" metadata, for the bid/ask spreads.
+type FxServiceServer interface {
+	// GetRate returns the exchange rate between two currencies
+	GetRate(context.Context, *GetRateRequest) (*GetRateResponse, error)
+	// GetRates returns the exchange rates between a base currency and several quote currencies
+	GetRates(context.Context, *GetRatesRequest) (*GetRatesResponse, error)
+	// Convert converts an amount, rounded to the minor units of the quote currency
+	Convert(context.Context, *ConvertRequest) (*ConvertResponse, error)
+	// ListCurrencies returns the enabled currencies, with their ISO 4217 metadata
+	ListCurrencies(context.Context, *ListCurrenciesRequest) (*ListCurrenciesResponse, error)
+	// Status returns the strategy mode, the request counts, the providers and the cache expiry
+	Status(context.Context, *StatusRequest) (*StatusResponse, error)
+	// WatchRates sends the cached rates of the pairs, then every update, until the client cancels.
+	// Requires streaming to be enabled (stream.enabled in the config).
+	WatchRates(*WatchRatesRequest, grpc.ServerStreamingServer[RateUpdate]) error
+	mustEmbedUnimplementedFxServiceServer()
+}
+
+// UnimplementedFxServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedFxServiceServer struct{}
+
+func (UnimplementedFxServiceServer) GetRate(context.Context, *GetRateRequest) (*GetRateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRate not implemented")
+}
+func (UnimplementedFxServiceServer) GetRates(context.Context, *GetRatesRequest) (*GetRatesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRates not implemented")
+}
+func (UnimplementedFxServiceServer) Convert(context.Context, *ConvertRequest) (*ConvertResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Convert not implemented")
+}
+func (UnimplementedFxServiceServer) ListCurrencies(context.Context, *ListCurrenciesRequest) (*ListCurrenciesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCurrencies not implemented")
+}
+func (UnimplementedFxServiceServer) Status(context.Context, *StatusRequest) (*StatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Status not implemented")
+}
+func (UnimplementedFxServiceServer) WatchRates(*WatchRatesRequest, grpc.ServerStreamingServer[RateUpdate]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchRates not implemented")
+}
+func (UnimplementedFxServiceServer) mustEmbedUnimplementedFxServiceServer() {}
+func (UnimplementedFxServiceServer) testEmbeddedByValue()                   {}
+
+// UnsafeFxServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to FxServiceServer will
+// result in compilation errors.
+type UnsafeFxServiceServer interface {
+	mustEmbedUnimplementedFxServiceServer()
+}
+
+func RegisterFxServiceServer(s grpc.ServiceRegistrar, srv FxServiceServer) {
+	// If the following call pancis, it indicates UnimplementedFxServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&FxService_ServiceDesc, srv)
+}
+
+func _FxService_GetRate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FxServiceServer).GetRate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FxService_GetRate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FxServiceServer).GetRate(ctx, req.(*GetRateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FxService_GetRates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRatesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FxServiceServer).GetRates(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FxService_GetRates_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FxServiceServer).GetRates(ctx, req.(*GetRatesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FxService_Convert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConvertRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FxServiceServer).Convert(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FxService_Convert_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FxServiceServer).Convert(ctx, req.(*ConvertRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FxService_ListCurrencies_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCurrenciesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FxServiceServer).ListCurrencies(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FxService_ListCurrencies_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FxServiceServer).ListCurrencies(ctx, req.(*ListCurrenciesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FxService_Status_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FxServiceServer).Status(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FxService_Status_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FxServiceServer).Status(ctx, req.(*StatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FxService_WatchRates_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRatesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FxServiceServer).WatchRates(m, &grpc.GenericServerStream[WatchRatesRequest, RateUpdate]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FxService_WatchRatesServer = grpc.ServerStreamingServer[RateUpdate]
+
+// FxService_ServiceDesc is the grpc.ServiceDesc for FxService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var FxService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "fx.v1.FxService",
+	HandlerType: (*FxServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetRate",
+			Handler:    _FxService_GetRate_Handler,
+		},
+		{
+			MethodName: "GetRates",
+			Handler:    _FxService_GetRates_Handler,
+		},
+		{
+			MethodName: "Convert",
+			Handler:    _FxService_Convert_Handler,
+		},
+		{
+			MethodName: "ListCurrencies",
+			Handler:    _FxService_ListCurrencies_Handler,
+		},
+		{
+			MethodName: "Status",
+			Handler:    _FxService_Status_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchRates",
+			Handler:       _FxService_WatchRates_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "fx/v1/fx.proto",
+}
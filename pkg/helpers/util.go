@@ -66,7 +66,22 @@ func Round(num float64, precision int) float64 {
 // NumberFormat formats a number into a string, with decimal and thousands separators
 func NumberFormat(number float64, decimals int, decPoint, thousandsSep string) string {
 	format := fmt.Sprintf("%%.%df", decimals)
-	formatted := fmt.Sprintf(format, number)
+	return NumberFormatString(fmt.Sprintf(format, number), decimals, decPoint, thousandsSep)
+}
+
+// NumberFormatString adds decimal and thousands separators to a number in plain notation (e.g. "-1234.50"),
+// which already has the given number of decimals. Used for exact decimals, which are not formatted as floats.
+func NumberFormatString(formatted string, decimals int, decPoint, thousandsSep string) string {
+	if formatted == "" {
+		return formatted
+	}
+
+	// Keep the sign apart, so that no separator is inserted after it
+	sign := ""
+	if formatted[0] == '-' {
+		sign = "-"
+		formatted = formatted[1:]
+	}
 
 	// Split the number into integer and decimal parts
 	parts := []byte(formatted)
@@ -94,7 +109,7 @@ func NumberFormat(number float64, decimals int, decPoint, thousandsSep string) s
 	}
 
 	// Join integer and decimal parts with the decimal point
-	result := string(intPartWithSep)
+	result := sign + string(intPartWithSep)
 	if decimals > 0 {
 		result += decPoint + string(decPart)
 	}
@@ -3,6 +3,7 @@ package helpers
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -250,6 +251,14 @@ func TestNumberFormat(t *testing.T) {
 			thousandsSep: ",",
 			want:         "1,234,567.890",
 		},
+		{
+			name:         "Format negative number",
+			number:       -123456.789,
+			decimals:     2,
+			decPoint:     ".",
+			thousandsSep: ",",
+			want:         "-123,456.79",
+		},
 	}
 
 	for _, tt := range tests {
@@ -260,3 +269,21 @@ func TestNumberFormat(t *testing.T) {
 		})
 	}
 }
+
+func TestNumberFormatString(t *testing.T) {
+	tests := map[string]string{
+		"12345678901234567.89": "12,345,678,901,234,567.89",
+		"-1234.50":             "-1,234.50",
+		"999":                  "999",
+		"0.001":                "0.001",
+	}
+	for input, want := range tests {
+		decimals := 0
+		if i := strings.IndexByte(input, '.'); i >= 0 {
+			decimals = len(input) - i - 1
+		}
+		if got := NumberFormatString(input, decimals, ".", ","); got != want {
+			t.Errorf("NumberFormatString(%q) = %v, want %v", input, got, want)
+		}
+	}
+}